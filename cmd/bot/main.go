@@ -1,328 +1,62 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"os/signal"
-	"reflect"
 	"strconv"
 	"strings"
 	"syscall"
 
-	"go.uber.org/zap"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/discord-voice-lab/internal/events"
 	"github.com/discord-voice-lab/internal/logging"
+	"github.com/discord-voice-lab/internal/orchestrator"
 	"github.com/discord-voice-lab/internal/voice"
 )
 
-// sensitiveKeys lists JSON keys which should never be logged in plaintext.
-var sensitiveKeys = map[string]struct{}{
-	"token": {}, "session_id": {}, "access_token": {}, "refresh_token": {},
-	"authorization": {}, "password": {}, "email": {}, "client_secret": {},
+// voiceChannelPair is one guildID:channelID entry from VOICE_CHANNELS.
+type voiceChannelPair struct {
+	guildID   string
+	channelID string
 }
 
-// redactAny walks a decoded JSON value (map[string]any / []any) and replaces
-// values for sensitive keys with a placeholder. It modifies maps/slices in place.
-func redactAny(v any) any {
-	switch vv := v.(type) {
-	case map[string]any:
-		for k, val := range vv {
-			lk := strings.ToLower(k)
-			if _, ok := sensitiveKeys[lk]; ok {
-				vv[k] = "<redacted>"
-				continue
-			}
-			// Recurse into nested structures
-			vv[k] = redactAny(val)
-		}
-		return vv
-	case []any:
-		for i, it := range vv {
-			vv[i] = redactAny(it)
-		}
-		return vv
-	default:
-		return v
-	}
-}
-
-// extractMeta pulls common searchable fields from known event types.
-// extractMeta pulls common searchable fields from known event types and
-// also returns a flexible metadata map built from typed fields, JSON
-// payloads, or reflection. The returned meta map contains stringified
-// key/value pairs discovered on the event which can be used for richer
-// logging and exploration.
-func extractMeta(evt interface{}) (evtType, guildID, channelID, userID string, ssrc uint32, speaking bool, meta map[string]any) {
-	meta = make(map[string]any)
-	if evt == nil {
-		evtType = "<nil>"
-		return
-	}
-
-	// Default type name
-	evtType = fmt.Sprintf("%T", evt)
-
-	// Helper to add to meta if value non-empty
-	addMeta := func(k string, v any) {
-		if v == nil {
-			return
+// parseVoiceChannels parses a comma-separated list of "guildID:channelID"
+// pairs, skipping any entry that doesn't split cleanly into two non-empty
+// parts. An empty or unset input yields no pairs.
+func parseVoiceChannels(raw string) []voiceChannelPair {
+	var out []voiceChannelPair
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
 		}
-		// Preserve original types where possible. For numeric json decoded
-		// values (float64) we leave them as-is; callers can inspect types.
-		switch tv := v.(type) {
-		case string:
-			if tv != "" {
-				meta[k] = tv
-			}
-		default:
-			meta[k] = tv
+		idx := strings.Index(part, ":")
+		if idx <= 0 || idx == len(part)-1 {
+			continue
 		}
-	}
-
-	// Known typed cases (fast-path)
-	switch e := evt.(type) {
-	case *discordgo.VoiceStateUpdate:
-		evtType = "VoiceStateUpdate"
-		guildID = e.GuildID
-		channelID = e.ChannelID
-		userID = e.UserID
-		addMeta("guild_id", e.GuildID)
-		addMeta("channel_id", e.ChannelID)
-		addMeta("user_id", e.UserID)
-	case *discordgo.VoiceSpeakingUpdate:
-		evtType = "VoiceSpeakingUpdate"
-		userID = e.UserID
-		ssrc = uint32(e.SSRC)
-		speaking = e.Speaking
-		addMeta("user_id", e.UserID)
-		addMeta("ssrc", e.SSRC)
-		addMeta("speaking", e.Speaking)
-	case *discordgo.Ready:
-		evtType = "Ready"
-		if e.User != nil && e.User.ID != "" {
-			userID = e.User.ID
-			addMeta("user_id", e.User.ID)
-		}
-	case *discordgo.GuildCreate:
-		evtType = "GuildCreate"
-		if e.ID != "" {
-			guildID = e.ID
-			addMeta("guild_id", e.ID)
-		}
-	case *discordgo.Event:
-		// Event contains RawData which is JSON -- try to decode common keys
-		evtType = e.Type
-		var m map[string]any
-		if err := json.Unmarshal(e.RawData, &m); err == nil {
-			for k, v := range m {
-				addMeta(k, v)
-			}
-			// map common names
-			if v, ok := m["guild_id"].(string); ok {
-				guildID = v
-			}
-			if v, ok := m["channel_id"].(string); ok {
-				channelID = v
-			}
-			if v, ok := m["user_id"].(string); ok {
-				userID = v
-			}
-			// ssrc may be a number; try several numeric types
-			if v, ok := m["ssrc"].(float64); ok {
-				ssrc = uint32(v)
-			} else if v, ok := m["ssrc"].(int); ok {
-				ssrc = uint32(v)
-			} else if v, ok := m["ssrc"].(int64); ok {
-				ssrc = uint32(v)
-			}
-			if v, ok := m["speaking"].(bool); ok {
-				speaking = v
-			}
-		}
-	}
-
-	// If we didn't hit a known typed case, try to decode generically from
-	// some common shapes: map[string]any, json.RawMessage, []byte, or struct via reflection.
-	if len(meta) == 0 {
-		switch v := evt.(type) {
-		case map[string]any:
-			for k, val := range v {
-				addMeta(k, val)
-				if k == "guild_id" {
-					if s, ok := val.(string); ok {
-						guildID = s
-					}
-				}
-				if k == "channel_id" {
-					if s, ok := val.(string); ok {
-						channelID = s
-					}
-				}
-				if k == "user_id" {
-					if s, ok := val.(string); ok {
-						userID = s
-					}
-				}
-			}
-		case json.RawMessage:
-			var m map[string]any
-			if err := json.Unmarshal(v, &m); err == nil {
-				for k, val := range m {
-					addMeta(k, val)
-				}
-			}
-		case []byte:
-			var m map[string]any
-			if err := json.Unmarshal(v, &m); err == nil {
-				for k, val := range m {
-					addMeta(k, val)
-				}
-			}
-		default:
-			// Use reflection for structs: iterate exported fields and use json tag if present
-			rv := reflect.ValueOf(evt)
-			if rv.Kind() == reflect.Ptr {
-				rv = rv.Elem()
-			}
-			if rv.Kind() == reflect.Struct {
-				rt := rv.Type()
-				for i := 0; i < rt.NumField(); i++ {
-					f := rt.Field(i)
-					if f.PkgPath != "" { // unexported
-						continue
-					}
-					name := f.Name
-					if tag := f.Tag.Get("json"); tag != "" {
-						// json tag may be like "name,omitempty"
-						parts := strings.Split(tag, ",")
-						if parts[0] != "" {
-							name = parts[0]
-						}
-					}
-					fv := rv.Field(i)
-					if !fv.IsValid() || (fv.Kind() == reflect.Ptr && fv.IsNil()) {
-						continue
-					}
-					var val any
-					if fv.Kind() == reflect.Ptr {
-						val = fv.Elem().Interface()
-					} else {
-						val = fv.Interface()
-					}
-					addMeta(name, val)
-				}
-			}
-		}
-	}
-
-	// Populate canonical return values from meta if still empty
-	if guildID == "" {
-		if v, ok := meta["guild_id"]; ok {
-			if s, ok2 := v.(string); ok2 {
-				guildID = s
-			}
-		}
-	}
-	if channelID == "" {
-		if v, ok := meta["channel_id"]; ok {
-			if s, ok2 := v.(string); ok2 {
-				channelID = s
-			}
-		}
-	}
-	if userID == "" {
-		if v, ok := meta["user_id"]; ok {
-			if s, ok2 := v.(string); ok2 {
-				userID = s
-			}
-		}
-	}
-	// ssrc and speaking are already set where possible
-
-	return
-}
-
-// redactLargeValues inspects a generic JSON object (as bytes) and replaces
-// values larger than redactBytes with a placeholder. Only applies to string
-// values; other types are left intact. Returns the potentially-modified JSON
-// bytes. If parsing fails, returns original bytes.
-func redactLargeValues(raw []byte, redactBytes int64) []byte {
-	if redactBytes <= 0 {
-		return raw
-	}
-	var v any
-	if err := json.Unmarshal(raw, &v); err != nil {
-		return raw
-	}
-
-	var walk func(any) any
-	walk = func(x any) any {
-		switch vv := x.(type) {
-		case map[string]any:
-			for k, val := range vv {
-				vv[k] = walk(val)
-			}
-			return vv
-		case []any:
-			for i, it := range vv {
-				vv[i] = walk(it)
-			}
-			return vv
-		case string:
-			if int64(len(vv)) > redactBytes {
-				return fmt.Sprintf("<redacted %d bytes>", len(vv))
-			}
-			return vv
-		default:
-			return vv
-		}
-	}
-
-	cleaned := walk(v)
-	out, err := json.Marshal(cleaned)
-	if err != nil {
-		return raw
+		out = append(out, voiceChannelPair{guildID: part[:idx], channelID: part[idx+1:]})
 	}
 	return out
 }
 
-// (safeMarshal removed; safeMarshalIndent is used where indentation is needed)
-
-// safeMarshalIndent behaves like json.MarshalIndent but falls back to
-// fmt.Sprintf on error or panic.
-func safeMarshalIndent(v any) []byte {
-	defer func() {
-		if r := recover(); r != nil {
-			// swallow panic
-		}
-	}()
-	b, err := json.MarshalIndent(v, "", "  ")
-	if err == nil {
-		return b
-	}
-	return []byte(fmt.Sprintf("%+v", v))
-}
-
 func main() {
 	// Initialize centralized logging
-	loggingSugar := logging.Init()
-	if loggingSugar == nil {
-		// fallback to a basic zap logger if initialization failed
-		l, _ := zap.NewProduction()
-		defer l.Sync()
-		loggingSugar = l.Sugar()
-	}
-	sugar := loggingSugar
+	logging.Init()
 
 	token := os.Getenv("DISCORD_BOT_TOKEN")
 	if token == "" {
-		sugar.Fatal("DISCORD_BOT_TOKEN required")
+		logging.Error("DISCORD_BOT_TOKEN required")
+		os.Exit(1)
 	}
 	dg, err := discordgo.New("Bot " + token)
 	if err != nil {
-		sugar.Fatalf("discordgo.New: %v", err)
+		logging.Error("discordgo.New failed", "err", err)
+		os.Exit(1)
 	}
 
 	// By default set a conservative intent mask needed for voice functionality.
@@ -339,25 +73,63 @@ func main() {
 	// IntentsGuildMembers and IntentsGuildPresences.
 	privileged := discordgo.IntentsGuildMembers | discordgo.IntentsGuildPresences
 	if dg.Identify.Intents&privileged != 0 {
-		sugar.Warnw("bot is requesting privileged gateway intents; ensure these are enabled in the Discord Developer Portal", "intents", dg.Identify.Intents)
+		logging.Warn("bot is requesting privileged gateway intents; ensure these are enabled in the Discord Developer Portal", "intents", dg.Identify.Intents)
 	}
 
-	sugar.Infow("using gateway intents", "intents", dg.Identify.Intents)
+	logging.Info("using gateway intents", "intents", dg.Identify.Intents)
 
 	// Open the Discord session so the bot connects and can receive events.
-	sugar.Infow("opening discord session")
+	logging.Info("opening discord session")
 	if err := dg.Open(); err != nil {
-		sugar.Fatalf("discord session open failed: %v", err)
+		logging.Error("discord session open failed", "err", err)
+		os.Exit(1)
 	}
-	sugar.Infow("discord session opened")
-
-	// Create voice processor
-	sugar.Infow("creating voice processor")
-	vp, err := voice.NewProcessor()
-	if err != nil {
-		sugar.Fatalf("voice.NewProcessor: %v", err)
+	logging.Info("discord session opened")
+
+	// Build the event router and its configured bridges. ORCHESTRATOR_BRIDGES_CONFIG
+	// points at a YAML file listing bridge instances (type, endpoint, and an
+	// optional guild/channel filter); with it unset, events are published
+	// but nothing subscribes to them.
+	router := orchestrator.NewEventRouter(256)
+	if path := os.Getenv("ORCHESTRATOR_BRIDGES_CONFIG"); path != "" {
+		cfg, err := orchestrator.LoadConfig(path)
+		if err != nil {
+			logging.Warn("orchestrator bridge config load failed", "err", err)
+		} else {
+			for _, bc := range cfg.Bridges {
+				b, err := orchestrator.NewBridge(bc)
+				if err != nil {
+					logging.Warn("orchestrator bridge skipped", "type", bc.Type, "err", err)
+					continue
+				}
+				router.AddBridge(b, orchestrator.FilterFromConfig(bc))
+				logging.Info("orchestrator bridge registered", "name", b.Name())
+			}
+		}
 	}
-	sugar.Infow("voice processor created")
+	go router.Run(context.Background())
+
+	// resolver looks up human-friendly user/guild/channel names for logging
+	// and the voice.resolve_user MCP tool. rootCtx is also the parent
+	// context every Join call below passes into NewProcessorWithResolver
+	// (see Join's doc comment), so its own background flush loop and every
+	// guild's Processor share one shutdown signal. resolverDone is waited on
+	// below so the shutdown path doesn't race resolver.Run's final
+	// cache-snapshot persist against process exit.
+	rootCtx, cancelRoot := context.WithCancel(context.Background())
+	resolver := voice.NewDiscordResolver(dg)
+	resolverDone := make(chan struct{})
+	go func() {
+		defer close(resolverDone)
+		resolver.Run(rootCtx)
+	}()
+
+	// Create the voice session manager. It owns one Processor (and one
+	// VoiceConnection) per guild, replacing the old single global
+	// Processor/VoiceConnection pair so the bot can join more than one
+	// guild's voice channel at a time.
+	logging.Info("creating voice session manager")
+	sessions := voice.NewSessionManager(dg, resolver, router)
 
 	// PAYLOAD_MAX_BYTES controls how many bytes of payload we log
 	maxPayload := int64(8 * 1024)
@@ -365,7 +137,7 @@ func main() {
 		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
 			maxPayload = n
 		} else {
-			sugar.Warnf("invalid PAYLOAD_MAX_BYTES=%s; using default %d", v, maxPayload)
+			logging.Warn("invalid PAYLOAD_MAX_BYTES, using default", "value", v, "default", maxPayload)
 		}
 	}
 
@@ -387,26 +159,32 @@ func main() {
 		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
 			redactLarge = n
 		} else {
-			sugar.Warnf("invalid REDACT_LARGE_BYTES=%s; using default %d", v, redactLarge)
+			logging.Warn("invalid REDACT_LARGE_BYTES, using default", "value", v, "default", redactLarge)
 		}
 	}
 
 	// Wait for termination signal (Ctrl+C, Docker stop) and shutdown gracefully.
-	// Register the processor handlers for voice state and speaking updates so
-	// it can map SSRC <-> user IDs. Wrap the method calls in explicit
-	// functions so discordgo's reflection validation accepts them.
-	dg.AddHandler(func(s *discordgo.Session, vs *discordgo.VoiceStateUpdate) {
-		vp.HandleVoiceState(s, vs)
-	})
-	// Note: voice speaking updates are delivered on the VoiceConnection
-	// websocket. We'll register a voice-level handler after joining so
-	// the handler has access to the VoiceConnection. For now we omit a
-	// session-level VoiceSpeakingUpdate handler which would be invalid.
-
-	// Generic event logger: logs every event that comes across the wire.
-	// Use *discordgo.Event as the handler signature so discordgo's
-	// reflection validation accepts it. Prefer the populated evt.Struct
-	// (if present) which is a typed event; otherwise unmarshal RawData.
+	// Register session-level handlers that route to the right guild's
+	// Processor. SessionManager.Join registers the per-connection speaking
+	// update handler itself once a guild's VoiceConnection exists.
+	dg.AddHandler(sessions.HandleVoiceStateUpdate)
+	dg.AddHandler(sessions.HandleVoiceServerUpdate)
+
+	// Generic event logger: normalizes and logs every event that comes
+	// across the wire. Use *discordgo.Event as the handler signature so
+	// discordgo's reflection validation accepts it. Prefer the populated
+	// evt.Struct (if present) which is a typed event; otherwise unmarshal
+	// RawData. Normalization and redaction live in internal/events so the
+	// rules are table-driven and unit-testable instead of living here.
+	//
+	// EVENT_SAMPLING controls which event types get a full logging.Info dump vs.
+	// just a span event on their guild session: high-volume types like
+	// PRESENCE_UPDATE would otherwise drown out the voice-specific ones a
+	// human actually wants to grep for. events.ObserveEvent still records
+	// every occurrence in Prometheus regardless of sampling.
+	normalizer := events.NewNormalizer()
+	redactor := events.RedactorFromEnv("REDACT_KEYS")
+	sampler := logging.SamplerFromEnv("EVENT_SAMPLING")
 	dg.AddHandler(func(s *discordgo.Session, evt *discordgo.Event) {
 		var obj any
 		if evt.Struct != nil {
@@ -416,7 +194,7 @@ func main() {
 			var v any
 			if err := json.Unmarshal(evt.RawData, &v); err == nil {
 				// redact sensitive fields before using the decoded object
-				obj = redactAny(v)
+				obj = redactor.Redact(v)
 			} else {
 				// as a last resort, keep raw bytes as a string (not ideal)
 				// avoid logging raw bytes that might include tokens
@@ -424,77 +202,84 @@ func main() {
 			}
 		}
 
-		evtType, guildID, channelID, userID, ssrc, speaking, meta := extractMeta(obj)
-		// If extractMeta couldn't identify a typed event, use the gateway Type
-		if evtType == fmt.Sprintf("%T", obj) || evtType == "" {
-			evtType = evt.Type
+		ne := normalizer.Normalize(obj)
+		// If Normalize couldn't identify a typed event, use the gateway Type
+		if ne.Type == fmt.Sprintf("%T", obj) || ne.Type == "" {
+			ne.Type = evt.Type
+		}
+		events.ObserveEvent(ne.Type)
+
+		if !sampler.ShouldLog(ne.Type) {
+			// Sampled out: annotate the guild's session span instead of
+			// logging nothing at all.
+			sessions.AnnotateEvent(ne.GuildID, "event",
+				attribute.String("type", ne.Type),
+				attribute.String("user_id", ne.UserID),
+			)
+			return
 		}
 
 		// Marshal the event safely and redact/truncate according to config.
-		payload := safeMarshalIndent(obj)
+		payload := events.SafeMarshalIndent(obj)
 		// If this event type is in detailedEvents, include full payload but
 		// redact very large strings. Otherwise, truncate to maxPayload.
-		if _, ok := detailedEvents[evtType]; ok {
-			payload = redactLargeValues(payload, redactLarge)
+		if _, ok := detailedEvents[ne.Type]; ok {
+			payload = redactor.RedactLargeStrings(payload, redactLarge)
 		} else {
 			if int64(len(payload)) > maxPayload {
 				// include a short truncated note
+				truncated := len(payload) - int(maxPayload)
 				note := fmt.Sprintf("\n<truncated %d bytes>", len(payload))
 				payload = append(payload[:maxPayload], []byte(note)...)
+				events.ObservePayloadTruncation(truncated)
 			}
 		}
 
-		sugar.Infow("discord event", "type", evtType, "guild", guildID, "channel", channelID, "user", userID, "ssrc", ssrc, "speaking", speaking, "meta", meta, "payload", string(payload))
+		logging.Info("discord event", "type", ne.Type, "guild", ne.GuildID, "channel", ne.ChannelID, "user", ne.UserID, "ssrc", ne.SSRC, "speaking", ne.Speaking, "meta", ne.Meta, "payload", string(payload))
 	})
 
-	// If configured, attempt to auto-join a voice channel.
-	var vc *discordgo.VoiceConnection
-	guildID := os.Getenv("GUILD_ID")
-	voiceChannelID := os.Getenv("VOICE_CHANNEL_ID")
-	if guildID != "" && voiceChannelID != "" {
-		sugar.Infow("joining voice channel", "guild", guildID, "channel", voiceChannelID)
-		vconn, err := dg.ChannelVoiceJoin(guildID, voiceChannelID, false, false)
-		if err != nil {
-			sugar.Warnf("voice join failed: %v", err)
-		} else {
-			vc = vconn
-			// Register voice-level handler for speaking updates which provides
-			// the VoiceConnection and a *discordgo.VoiceSpeakingUpdate.
-			vc.AddHandler(func(v *discordgo.VoiceConnection, su *discordgo.VoiceSpeakingUpdate) {
-				// Log speaking updates observed on the voice websocket so we
-				// can confirm they arrive here. Then forward to the processor
-				// which will map SSRC -> user. Pass the session so the
-				// processor has access to session-based helpers if needed.
-				sugar.Infow("voice connection speaking update received", "user", su.UserID, "ssrc", su.SSRC, "speaking", su.Speaking)
-				vp.HandleSpeakingUpdate(dg, su)
-			})
-			sugar.Infow("voice joined", "guild", guildID, "channel", voiceChannelID)
+	// If configured, seed the manager with one guild/channel to auto-join on
+	// startup. VOICE_CHANNELS (comma-separated guildID:channelID pairs) joins
+	// several; GUILD_ID/VOICE_CHANNEL_ID remain supported as a single-channel
+	// convenience for existing deployments.
+	for _, pair := range parseVoiceChannels(os.Getenv("VOICE_CHANNELS")) {
+		logging.Info("joining voice channel", "guild", pair.guildID, "channel", pair.channelID)
+		if _, err := sessions.Join(rootCtx, pair.guildID, pair.channelID); err != nil {
+			logging.Warn("voice join failed", "err", err)
+		}
+	}
+	if guildID, voiceChannelID := os.Getenv("GUILD_ID"), os.Getenv("VOICE_CHANNEL_ID"); guildID != "" && voiceChannelID != "" {
+		logging.Info("joining voice channel", "guild", guildID, "channel", voiceChannelID)
+		if _, err := sessions.Join(rootCtx, guildID, voiceChannelID); err != nil {
+			logging.Warn("voice join failed", "err", err)
 		}
 	}
 
+	// Slash commands let an operator move the bot between voice channels at
+	// runtime, without restarting the process or editing VOICE_CHANNELS/
+	// GUILD_ID env vars. Registered per-guild (rather than globally) so they
+	// show up immediately instead of waiting out Discord's ~1h global command
+	// cache propagation.
+	registerVoiceSlashCommands(dg, sessions)
+
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
 	<-stop
-	sugar.Infow("shutdown signal received, closing resources")
+	logging.Info("shutdown signal received, closing resources")
 
-	if err := vp.Close(); err != nil {
-		sugar.Warnf("processor close error: %v", err)
-	}
-	// If we joined a voice channel, disconnect cleanly first.
-	if vc != nil {
-		if err := vc.Disconnect(); err != nil {
-			sugar.Warnf("voice disconnect error: %v", err)
-		}
-	}
+	sessions.Shutdown()
+
+	cancelRoot()
+	<-resolverDone // wait for the resolver's final cache-snapshot persist
 
 	if err := dg.Close(); err != nil {
-		sugar.Warnf("discord session close error: %v", err)
+		logging.Warn("discord session close error", "err", err)
 	}
 
 	// ensure any logging buffers are flushed
-	if l := zap.L(); l != nil {
-		_ = l.Sync()
+	if s := logging.Sugar(); s != nil {
+		_ = s.Sync()
 	}
-	sugar.Info("shutdown complete")
+	logging.Info("shutdown complete")
 }
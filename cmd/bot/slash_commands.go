@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/discord-voice-lab/internal/logging"
+	"github.com/discord-voice-lab/internal/voice"
+)
+
+// voiceSlashCommands are registered against each guild the bot is in,
+// letting an operator move the bot between voice channels at runtime
+// instead of editing VOICE_CHANNELS/GUILD_ID env vars and restarting.
+var voiceSlashCommands = []*discordgo.ApplicationCommand{
+	{
+		Name:        "join",
+		Description: "Join a voice channel",
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:         discordgo.ApplicationCommandOptionChannel,
+				Name:         "channel",
+				Description:  "Voice channel to join",
+				Required:     true,
+				ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildVoice},
+			},
+		},
+	},
+	{
+		Name:        "leave",
+		Description: "Leave the current voice channel",
+	},
+	{
+		Name:        "sessions",
+		Description: "List the bot's active voice sessions",
+	},
+}
+
+// registerVoiceSlashCommands registers voiceSlashCommands against every
+// guild the bot is already in, and against any guild it joins afterward, and
+// wires an InteractionCreate handler dispatching them to sessions. Commands
+// are registered per-guild rather than globally so they're usable right
+// away; Discord can take up to an hour to propagate global command updates.
+func registerVoiceSlashCommands(dg *discordgo.Session, sessions *voice.SessionManager) {
+	register := func(guildID string) {
+		for _, cmd := range voiceSlashCommands {
+			if _, err := dg.ApplicationCommandCreate(dg.State.User.ID, guildID, cmd); err != nil {
+				logging.Warn("slash command registration failed", "guild_id", guildID, "command", cmd.Name, "err", err)
+			}
+		}
+	}
+	for _, g := range dg.State.Guilds {
+		register(g.ID)
+	}
+	dg.AddHandler(func(s *discordgo.Session, gc *discordgo.GuildCreate) {
+		register(gc.ID)
+	})
+
+	dg.AddHandler(func(s *discordgo.Session, ic *discordgo.InteractionCreate) {
+		if ic.Type != discordgo.InteractionApplicationCommand {
+			return
+		}
+		data := ic.ApplicationCommandData()
+		switch data.Name {
+		case "join":
+			handleJoinCommand(s, ic, sessions, data)
+		case "leave":
+			handleLeaveCommand(s, ic, sessions)
+		case "sessions":
+			handleSessionsCommand(s, ic, sessions)
+		}
+	})
+}
+
+// handleJoinCommand defers its response rather than joining before acking:
+// ChannelVoiceJoin's connection handshake can take several seconds, well
+// past Discord's 3-second interaction-ack deadline, and a missed deadline
+// shows the user an error even though the join goes on to succeed.
+func handleJoinCommand(s *discordgo.Session, ic *discordgo.InteractionCreate, sessions *voice.SessionManager, data discordgo.ApplicationCommandInteractionData) {
+	var channelID string
+	for _, opt := range data.Options {
+		if opt.Name == "channel" {
+			channelID = opt.ChannelValue(s).ID
+		}
+	}
+	if err := s.InteractionRespond(ic.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+	}); err != nil {
+		logging.Warn("slash command defer failed", "err", err)
+		return
+	}
+	content := fmt.Sprintf("joined <#%s>", channelID)
+	if _, err := sessions.Join(context.Background(), ic.GuildID, channelID); err != nil {
+		content = "join failed: " + err.Error()
+	}
+	if _, err := s.FollowupMessageCreate(ic.Interaction, true, &discordgo.WebhookParams{Content: content}); err != nil {
+		logging.Warn("slash command followup failed", "err", err)
+	}
+}
+
+func handleLeaveCommand(s *discordgo.Session, ic *discordgo.InteractionCreate, sessions *voice.SessionManager) {
+	content := "left the voice channel"
+	if err := sessions.Leave(ic.GuildID); err != nil {
+		content = "leave failed: " + err.Error()
+	}
+	respondToInteraction(s, ic, content)
+}
+
+func handleSessionsCommand(s *discordgo.Session, ic *discordgo.InteractionCreate, sessions *voice.SessionManager) {
+	summaries := sessions.List()
+	if len(summaries) == 0 {
+		respondToInteraction(s, ic, "no active voice sessions")
+		return
+	}
+	var b strings.Builder
+	for _, sess := range summaries {
+		fmt.Fprintf(&b, "guild=%s channel=%s connected=%t\n", sess.GuildID, sess.ChannelID, sess.Connected)
+	}
+	respondToInteraction(s, ic, b.String())
+}
+
+// respondToInteraction replies to a slash command with a plain-text message
+// visible to whoever issued it.
+func respondToInteraction(s *discordgo.Session, ic *discordgo.InteractionCreate, content string) {
+	err := s.InteractionRespond(ic.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Content: content},
+	})
+	if err != nil {
+		logging.Warn("slash command response failed", "err", err)
+	}
+}
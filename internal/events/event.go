@@ -0,0 +1,19 @@
+// Package events normalizes raw discordgo gateway events into a single
+// typed shape and redacts sensitive fields before they reach logs or
+// bridges. It replaces the ad-hoc reflection switch that used to live
+// directly in cmd/bot/main.go.
+package events
+
+// NormalizedEvent is the common shape every discordgo event is reduced to
+// before logging, routing to orchestrator bridges, or anything else that
+// shouldn't need to know discordgo's full type surface.
+type NormalizedEvent struct {
+	Type       string
+	GuildID    string
+	ChannelID  string
+	UserID     string
+	SSRC       uint32
+	Speaking   bool
+	Meta       map[string]any
+	RawPayload []byte
+}
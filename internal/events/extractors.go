@@ -0,0 +1,187 @@
+package events
+
+import (
+	"encoding/json"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// registerBuiltins wires up extractors for the discordgo event types this
+// bot has historically cared about (voice state/speaking) plus the rest of
+// the commonly-used gateway surface from eventhandlers.go: messages,
+// interactions, threads, guild membership, and voice server updates.
+func registerBuiltins(n *Normalizer) {
+	n.Register("*discordgo.VoiceStateUpdate", func(evt any) NormalizedEvent {
+		e := evt.(*discordgo.VoiceStateUpdate)
+		meta := map[string]any{}
+		addMeta(meta, "guild_id", e.GuildID)
+		addMeta(meta, "channel_id", e.ChannelID)
+		addMeta(meta, "user_id", e.UserID)
+		return NormalizedEvent{Type: "VoiceStateUpdate", GuildID: e.GuildID, ChannelID: e.ChannelID, UserID: e.UserID, Meta: meta}
+	})
+
+	n.Register("*discordgo.VoiceSpeakingUpdate", func(evt any) NormalizedEvent {
+		e := evt.(*discordgo.VoiceSpeakingUpdate)
+		meta := map[string]any{}
+		addMeta(meta, "user_id", e.UserID)
+		addMeta(meta, "ssrc", e.SSRC)
+		addMeta(meta, "speaking", e.Speaking)
+		return NormalizedEvent{Type: "VoiceSpeakingUpdate", UserID: e.UserID, SSRC: uint32(e.SSRC), Speaking: e.Speaking, Meta: meta}
+	})
+
+	n.Register("*discordgo.VoiceServerUpdate", func(evt any) NormalizedEvent {
+		e := evt.(*discordgo.VoiceServerUpdate)
+		meta := map[string]any{}
+		addMeta(meta, "guild_id", e.GuildID)
+		addMeta(meta, "endpoint", e.Endpoint)
+		return NormalizedEvent{Type: "VoiceServerUpdate", GuildID: e.GuildID, Meta: meta}
+	})
+
+	n.Register("*discordgo.Ready", func(evt any) NormalizedEvent {
+		e := evt.(*discordgo.Ready)
+		ne := NormalizedEvent{Type: "Ready", Meta: map[string]any{}}
+		if e.User != nil && e.User.ID != "" {
+			ne.UserID = e.User.ID
+			addMeta(ne.Meta, "user_id", e.User.ID)
+		}
+		return ne
+	})
+
+	n.Register("*discordgo.GuildCreate", func(evt any) NormalizedEvent {
+		e := evt.(*discordgo.GuildCreate)
+		ne := NormalizedEvent{Type: "GuildCreate", Meta: map[string]any{}}
+		if e.ID != "" {
+			ne.GuildID = e.ID
+			addMeta(ne.Meta, "guild_id", e.ID)
+		}
+		return ne
+	})
+
+	n.Register("*discordgo.MessageCreate", func(evt any) NormalizedEvent {
+		e := evt.(*discordgo.MessageCreate)
+		return messageEvent("MessageCreate", e.Message)
+	})
+	n.Register("*discordgo.MessageUpdate", func(evt any) NormalizedEvent {
+		e := evt.(*discordgo.MessageUpdate)
+		return messageEvent("MessageUpdate", e.Message)
+	})
+	n.Register("*discordgo.MessageDelete", func(evt any) NormalizedEvent {
+		e := evt.(*discordgo.MessageDelete)
+		meta := map[string]any{}
+		addMeta(meta, "guild_id", e.GuildID)
+		addMeta(meta, "channel_id", e.ChannelID)
+		addMeta(meta, "message_id", e.ID)
+		return NormalizedEvent{Type: "MessageDelete", GuildID: e.GuildID, ChannelID: e.ChannelID, Meta: meta}
+	})
+
+	n.Register("*discordgo.InteractionCreate", func(evt any) NormalizedEvent {
+		e := evt.(*discordgo.InteractionCreate)
+		meta := map[string]any{}
+		ne := NormalizedEvent{Type: "InteractionCreate", GuildID: e.GuildID, ChannelID: e.ChannelID, Meta: meta}
+		addMeta(meta, "guild_id", e.GuildID)
+		addMeta(meta, "channel_id", e.ChannelID)
+		if e.Member != nil && e.Member.User != nil {
+			ne.UserID = e.Member.User.ID
+			addMeta(meta, "user_id", e.Member.User.ID)
+		} else if e.User != nil {
+			ne.UserID = e.User.ID
+			addMeta(meta, "user_id", e.User.ID)
+		}
+		return ne
+	})
+
+	n.Register("*discordgo.ThreadCreate", func(evt any) NormalizedEvent {
+		e := evt.(*discordgo.ThreadCreate)
+		return channelEvent("ThreadCreate", e.Channel)
+	})
+	n.Register("*discordgo.ThreadUpdate", func(evt any) NormalizedEvent {
+		e := evt.(*discordgo.ThreadUpdate)
+		return channelEvent("ThreadUpdate", e.Channel)
+	})
+	n.Register("*discordgo.ThreadDelete", func(evt any) NormalizedEvent {
+		e := evt.(*discordgo.ThreadDelete)
+		return channelEvent("ThreadDelete", e.Channel)
+	})
+
+	n.Register("*discordgo.GuildMemberAdd", func(evt any) NormalizedEvent {
+		e := evt.(*discordgo.GuildMemberAdd)
+		meta := map[string]any{}
+		ne := NormalizedEvent{Type: "GuildMemberAdd", GuildID: e.GuildID, Meta: meta}
+		addMeta(meta, "guild_id", e.GuildID)
+		if e.Member != nil && e.Member.User != nil {
+			ne.UserID = e.Member.User.ID
+			addMeta(meta, "user_id", e.Member.User.ID)
+		}
+		return ne
+	})
+	n.Register("*discordgo.GuildMemberRemove", func(evt any) NormalizedEvent {
+		e := evt.(*discordgo.GuildMemberRemove)
+		meta := map[string]any{}
+		ne := NormalizedEvent{Type: "GuildMemberRemove", GuildID: e.GuildID, Meta: meta}
+		addMeta(meta, "guild_id", e.GuildID)
+		if e.Member != nil && e.Member.User != nil {
+			ne.UserID = e.Member.User.ID
+			addMeta(meta, "user_id", e.Member.User.ID)
+		}
+		return ne
+	})
+
+	n.Register("*discordgo.Event", func(evt any) NormalizedEvent {
+		e := evt.(*discordgo.Event)
+		ne := NormalizedEvent{Type: e.Type, Meta: map[string]any{}}
+		var m map[string]any
+		if err := json.Unmarshal(e.RawData, &m); err == nil {
+			for k, v := range m {
+				addMeta(ne.Meta, k, v)
+			}
+			if v, ok := m["guild_id"].(string); ok {
+				ne.GuildID = v
+			}
+			if v, ok := m["channel_id"].(string); ok {
+				ne.ChannelID = v
+			}
+			if v, ok := m["user_id"].(string); ok {
+				ne.UserID = v
+			}
+			if v, ok := m["ssrc"].(float64); ok {
+				ne.SSRC = uint32(v)
+			}
+			if v, ok := m["speaking"].(bool); ok {
+				ne.Speaking = v
+			}
+		}
+		return ne
+	})
+}
+
+func messageEvent(typeName string, m *discordgo.Message) NormalizedEvent {
+	meta := map[string]any{}
+	ne := NormalizedEvent{Type: typeName, Meta: meta}
+	if m == nil {
+		return ne
+	}
+	ne.GuildID = m.GuildID
+	ne.ChannelID = m.ChannelID
+	addMeta(meta, "guild_id", m.GuildID)
+	addMeta(meta, "channel_id", m.ChannelID)
+	addMeta(meta, "message_id", m.ID)
+	if m.Author != nil {
+		ne.UserID = m.Author.ID
+		addMeta(meta, "user_id", m.Author.ID)
+	}
+	return ne
+}
+
+func channelEvent(typeName string, c *discordgo.Channel) NormalizedEvent {
+	meta := map[string]any{}
+	ne := NormalizedEvent{Type: typeName, Meta: meta}
+	if c == nil {
+		return ne
+	}
+	ne.GuildID = c.GuildID
+	ne.ChannelID = c.ID
+	addMeta(meta, "guild_id", c.GuildID)
+	addMeta(meta, "channel_id", c.ID)
+	addMeta(meta, "parent_id", c.ParentID)
+	return ne
+}
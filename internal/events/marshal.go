@@ -0,0 +1,22 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SafeMarshalIndent behaves like json.MarshalIndent but falls back to
+// fmt.Sprintf on error or panic, so a malformed event never takes down the
+// event-logging path.
+func SafeMarshalIndent(v any) (out []byte) {
+	defer func() {
+		if r := recover(); r != nil {
+			out = []byte(fmt.Sprintf("%+v", v))
+		}
+	}()
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err == nil {
+		return b
+	}
+	return []byte(fmt.Sprintf("%+v", v))
+}
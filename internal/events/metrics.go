@@ -0,0 +1,41 @@
+package events
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Package-level Prometheus collectors, following the same convention as
+// internal/voice/metrics.go: shared across every Normalizer/Redactor
+// instance in the process rather than threaded through as fields.
+var (
+	eventsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "gateway_events_total",
+		Help: "Total discordgo gateway events observed, labeled by normalized event type.",
+	}, []string{"type"})
+
+	redactionsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "event_redactions_total",
+		Help: "Total sensitive fields or oversized strings redacted out of gateway event payloads.",
+	})
+
+	payloadBytesTruncatedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "event_payload_bytes_truncated_total",
+		Help: "Total bytes removed from event payloads by length truncation before logging.",
+	})
+)
+
+// ObserveEvent records one occurrence of typeName, for events-per-type
+// dashboards independent of whatever sampling decides to actually log.
+func ObserveEvent(typeName string) {
+	eventsTotal.WithLabelValues(typeName).Inc()
+}
+
+// ObservePayloadTruncation records n bytes removed from a payload by
+// truncation; n <= 0 is a no-op.
+func ObservePayloadTruncation(n int) {
+	if n <= 0 {
+		return
+	}
+	payloadBytesTruncatedTotal.Add(float64(n))
+}
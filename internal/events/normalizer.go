@@ -0,0 +1,168 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// ExtractFunc pulls a NormalizedEvent's fields out of one concrete
+// discordgo event type. Normalize fills in the Type field (with the %T
+// type name) only when the extractor leaves it empty.
+type ExtractFunc func(evt any) NormalizedEvent
+
+// Normalizer dispatches an arbitrary discordgo event to the ExtractFunc
+// registered for its concrete type, falling back to generic reflection when
+// no extractor is registered. Safe for concurrent use.
+type Normalizer struct {
+	mu         sync.RWMutex
+	extractors map[string]ExtractFunc
+}
+
+// NewNormalizer returns a Normalizer pre-registered with extractors for the
+// discordgo event types this bot cares about (see extractors.go). Callers
+// can Register additional/overriding extractors afterward.
+func NewNormalizer() *Normalizer {
+	n := &Normalizer{extractors: make(map[string]ExtractFunc)}
+	registerBuiltins(n)
+	return n
+}
+
+// Register associates fn with typeName (as produced by fmt.Sprintf("%T", evt),
+// e.g. "*discordgo.MessageCreate"). A later Register for the same typeName
+// replaces the previous extractor.
+func (n *Normalizer) Register(typeName string, fn ExtractFunc) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.extractors[typeName] = fn
+}
+
+// Normalize converts evt into a NormalizedEvent, using a registered
+// extractor when one matches evt's concrete type, and a generic
+// reflection/JSON-based extraction otherwise.
+func (n *Normalizer) Normalize(evt any) NormalizedEvent {
+	if evt == nil {
+		return NormalizedEvent{Type: "<nil>", Meta: map[string]any{}}
+	}
+	typeName := fmt.Sprintf("%T", evt)
+
+	n.mu.RLock()
+	fn, ok := n.extractors[typeName]
+	n.mu.RUnlock()
+
+	var ne NormalizedEvent
+	if ok {
+		ne = fn(evt)
+	} else {
+		ne = genericExtract(evt)
+	}
+	if ne.Type == "" {
+		ne.Type = typeName
+	}
+	if ne.Meta == nil {
+		ne.Meta = map[string]any{}
+	}
+	return ne
+}
+
+// addMeta mirrors the old extractMeta helper: skip empty strings, keep
+// everything else as-is.
+func addMeta(meta map[string]any, k string, v any) {
+	if v == nil {
+		return
+	}
+	if s, ok := v.(string); ok {
+		if s == "" {
+			return
+		}
+		meta[k] = s
+		return
+	}
+	meta[k] = v
+}
+
+// genericExtract handles any event type with no registered extractor: a
+// generic map/json.RawMessage/[]byte (decoded as a JSON object), or falls
+// back to reflecting over a struct's exported fields, keyed by json tag
+// name where one is set.
+func genericExtract(evt any) NormalizedEvent {
+	meta := map[string]any{}
+	ne := NormalizedEvent{Meta: meta}
+
+	switch v := evt.(type) {
+	case map[string]any:
+		for k, val := range v {
+			addMeta(meta, k, val)
+		}
+	case json.RawMessage:
+		var m map[string]any
+		if err := json.Unmarshal(v, &m); err == nil {
+			for k, val := range m {
+				addMeta(meta, k, val)
+			}
+		}
+	case []byte:
+		var m map[string]any
+		if err := json.Unmarshal(v, &m); err == nil {
+			for k, val := range m {
+				addMeta(meta, k, val)
+			}
+		}
+	default:
+		rv := reflect.ValueOf(evt)
+		if rv.Kind() == reflect.Ptr {
+			rv = rv.Elem()
+		}
+		if rv.Kind() == reflect.Struct {
+			rt := rv.Type()
+			for i := 0; i < rt.NumField(); i++ {
+				f := rt.Field(i)
+				if f.PkgPath != "" { // unexported
+					continue
+				}
+				name := f.Name
+				if tag := f.Tag.Get("json"); tag != "" {
+					if parts := strings.Split(tag, ","); parts[0] != "" {
+						name = parts[0]
+					}
+				}
+				fv := rv.Field(i)
+				if !fv.IsValid() || (fv.Kind() == reflect.Ptr && fv.IsNil()) {
+					continue
+				}
+				var val any
+				if fv.Kind() == reflect.Ptr {
+					val = fv.Elem().Interface()
+				} else {
+					val = fv.Interface()
+				}
+				addMeta(meta, name, val)
+			}
+		}
+	}
+
+	fillCanonicalFromMeta(&ne)
+	return ne
+}
+
+// fillCanonicalFromMeta populates GuildID/ChannelID/UserID from well-known
+// meta keys when an extractor (or genericExtract) didn't set them directly.
+func fillCanonicalFromMeta(ne *NormalizedEvent) {
+	if ne.GuildID == "" {
+		if s, ok := ne.Meta["guild_id"].(string); ok {
+			ne.GuildID = s
+		}
+	}
+	if ne.ChannelID == "" {
+		if s, ok := ne.Meta["channel_id"].(string); ok {
+			ne.ChannelID = s
+		}
+	}
+	if ne.UserID == "" {
+		if s, ok := ne.Meta["user_id"].(string); ok {
+			ne.UserID = s
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// defaultSensitiveKeys mirrors the set main.go used to hardcode as a package
+// var; it's now only the fallback when REDACT_KEYS is unset.
+var defaultSensitiveKeys = []string{
+	"token", "session_id", "access_token", "refresh_token",
+	"authorization", "password", "email", "client_secret",
+}
+
+// Redactor scrubs sensitive fields out of decoded JSON values and long
+// string values out of raw JSON payloads before either reaches a log or a
+// bridge. Unlike the old package-level sensitiveKeys var, its key set is
+// instance configuration.
+type Redactor struct {
+	keys map[string]struct{}
+}
+
+// NewRedactor builds a Redactor matching keys (case-insensitive).
+func NewRedactor(keys []string) *Redactor {
+	r := &Redactor{keys: make(map[string]struct{}, len(keys))}
+	for _, k := range keys {
+		r.keys[strings.ToLower(k)] = struct{}{}
+	}
+	return r
+}
+
+// RedactorFromEnv builds a Redactor from a comma-separated key list in the
+// named environment variable, falling back to defaultSensitiveKeys when
+// envVar is unset or empty.
+func RedactorFromEnv(envVar string) *Redactor {
+	v := os.Getenv(envVar)
+	if v == "" {
+		return NewRedactor(defaultSensitiveKeys)
+	}
+	var keys []string
+	for _, part := range strings.Split(v, ",") {
+		if k := strings.TrimSpace(part); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return NewRedactor(keys)
+}
+
+// Redact walks a decoded JSON value (map[string]any / []any) and replaces
+// values for configured keys with a placeholder, recursing into nested
+// structures. Modifies maps/slices in place and also returns them.
+func (r *Redactor) Redact(v any) any {
+	switch vv := v.(type) {
+	case map[string]any:
+		for k, val := range vv {
+			if _, ok := r.keys[strings.ToLower(k)]; ok {
+				vv[k] = "<redacted>"
+				redactionsTotal.Inc()
+				continue
+			}
+			vv[k] = r.Redact(val)
+		}
+		return vv
+	case []any:
+		for i, it := range vv {
+			vv[i] = r.Redact(it)
+		}
+		return vv
+	default:
+		return v
+	}
+}
+
+// RedactLargeStrings parses raw as JSON and replaces string values longer
+// than maxBytes with a placeholder noting the original length. If raw isn't
+// valid JSON, or maxBytes <= 0, it's returned unchanged.
+func (r *Redactor) RedactLargeStrings(raw []byte, maxBytes int64) []byte {
+	if maxBytes <= 0 {
+		return raw
+	}
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return raw
+	}
+
+	var walk func(any) any
+	walk = func(x any) any {
+		switch vv := x.(type) {
+		case map[string]any:
+			for k, val := range vv {
+				vv[k] = walk(val)
+			}
+			return vv
+		case []any:
+			for i, it := range vv {
+				vv[i] = walk(it)
+			}
+			return vv
+		case string:
+			if int64(len(vv)) > maxBytes {
+				redactionsTotal.Inc()
+				return fmt.Sprintf("<redacted %d bytes>", len(vv))
+			}
+			return vv
+		default:
+			return vv
+		}
+	}
+
+	cleaned := walk(v)
+	out, err := json.Marshal(cleaned)
+	if err != nil {
+		return raw
+	}
+	return out
+}
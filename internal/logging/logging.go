@@ -2,6 +2,8 @@ package logging
 
 import (
 	"fmt"
+	"log"
+	"log/slog"
 	"os"
 	"strings"
 	"sync"
@@ -9,64 +11,158 @@ import (
 	"go.uber.org/zap"
 )
 
+// Logger is this package's logging handle. It's a plain *slog.Logger - Info,
+// Debug, Warn, Error, With, and WithGroup are exactly slog's own methods, so
+// there's no wrapper type to keep in sync with the stdlib's own semantics.
+type Logger = slog.Logger
+
 var (
+	current *Logger
+	once    sync.Once
+
+	// sugar is kept around only so Sugar() (and zap.RedirectStdLog, which
+	// needs a *zap.Logger) keep working when LOG_FORMAT selects the zap
+	// handler. It's nil under the json/text handlers.
 	sugar *zap.SugaredLogger
-	once  sync.Once
 )
 
-// Init initializes the global sugared logger based on LOG_LEVEL and redirects
-// the standard library logger to zap. It's safe to call multiple times.
-func Init() *zap.SugaredLogger {
+// Init initializes the global *slog.Logger from LOG_FORMAT (json, text, or
+// zap; default zap, matching this package's pre-slog behavior) and
+// LOG_LEVEL (debug, info, warn, error; default info), and makes it the
+// process-wide slog default. It's safe to call multiple times; only the
+// first call's environment takes effect.
+func Init() *Logger {
 	once.Do(func() {
-		level := strings.ToLower(os.Getenv("LOG_LEVEL"))
-		var logger *zap.Logger
-		if level == "debug" {
-			l, _ := zap.NewDevelopment()
-			logger = l
-		} else {
-			l, _ := zap.NewProduction()
-			logger = l
-		}
-		// Redirect standard library logs into zap so all logs are unified.
-		_ = zap.RedirectStdLog(logger)
-		sugar = logger.Sugar()
+		level := parseLevel(os.Getenv("LOG_LEVEL"))
+		handler := handlerFromEnv(level)
+		current = slog.New(handler)
+		slog.SetDefault(current)
+		redirectStdLog(handler, current)
 	})
-	return sugar
+	return current
+}
+
+// redirectStdLog points the standard library's default logger (the one
+// discordgo and anything else that just calls log.Println write to) at
+// handler, so every logger in the process ends up on one pipeline. Under
+// the zap handler this is zap.RedirectStdLog itself, which newZapHandler
+// already called on its own *zap.Logger; under json/text it's a thin
+// io.Writer that turns each stdlib log line into a slog record at info
+// level, since neither stdlib handler exposes anything closer to
+// RedirectStdLog. discordgo doesn't vendor into this build, so its own
+// logger hook (if it has a narrower one than "writes to log.Default()")
+// isn't wired here - this bridge only catches what actually goes through
+// the standard library logger.
+func redirectStdLog(handler slog.Handler, logger *Logger) {
+	if _, ok := handler.(*zapHandler); ok {
+		return
+	}
+	log.SetFlags(0)
+	log.SetOutput(stdLogWriter{logger: logger})
+}
+
+// stdLogWriter adapts the standard library log package's io.Writer output
+// into a slog record, so a line written via log.Println still lands in
+// whichever handler LOG_FORMAT selected.
+type stdLogWriter struct {
+	logger *Logger
+}
+
+func (w stdLogWriter) Write(p []byte) (int, error) {
+	w.logger.Info(strings.TrimRight(string(p), "\n"))
+	return len(p), nil
+}
+
+// parseLevel maps LOG_LEVEL's value onto a standard slog level, defaulting
+// to info for anything unrecognized (including unset).
+func parseLevel(v string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
 }
 
-// Sugar returns the initialized sugared logger. Call Init first.
+// handlerFromEnv picks a slog.Handler based on LOG_FORMAT. "json" and
+// "text" use slog's own stdlib handlers writing to stdout; anything else
+// (including unset, the default) keeps this package's original zap output
+// via zapHandler, so existing deployments parsing zap's JSON lines see no
+// change unless they opt in to LOG_FORMAT.
+func handlerFromEnv(level slog.Level) slog.Handler {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("LOG_FORMAT"))) {
+	case "json":
+		return slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level})
+	case "text":
+		return slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: level})
+	default:
+		return newZapHandler(level)
+	}
+}
+
+// Sugar returns the zap sugared logger backing the zap handler, or nil if
+// LOG_FORMAT selected the json or text handler instead. Exists for the rare
+// caller that still needs direct zap access rather than going through the
+// slog.Logger returned by Init/Default.
 func Sugar() *zap.SugaredLogger { return sugar }
 
 func init() {
 	Init()
 }
 
-// Helper functions that return sugared logger key/value pairs for common
-// Discord entities. They include both the numeric ID and an optional human
-// readable name. Callers can use them with the SugaredLogger's structured
-// logging helpers, for example:
-//
-//	logging.Sugar().Infow("joined voice", logging.UserFields("12345", "alice")...)
-//
-// These helpers are intentionally small and return []interface{} so they
-// can be spliced into the variadic key/value list that Infow/Debugw expect.
-func UserFields(userID, userName string) []interface{} {
-	if userName == "" {
-		return []interface{}{"user_id", userID}
+// Default returns the process-wide logger Init configured, initializing it
+// with defaults first if nothing has called Init yet.
+func Default() *Logger {
+	if current == nil {
+		return Init()
 	}
-	return []interface{}{"user_id", userID, "user_name", userName, "user", fmt.Sprintf("%s (%s)", userName, userID)}
+	return current
 }
 
-func GuildFields(guildID, guildName string) []interface{} {
-	if guildName == "" {
-		return []interface{}{"guild_id", guildID}
-	}
-	return []interface{}{"guild_id", guildID, "guild_name", guildName, "guild", fmt.Sprintf("%s (%s)", guildName, guildID)}
+// Info, Debug, Warn, and Error log msg at their level through Default(),
+// with args taken the same way slog.Logger accepts them: alternating
+// key/value pairs, slog.Attr values, or a mix of both.
+func Info(msg string, args ...any)  { Default().Info(msg, args...) }
+func Debug(msg string, args ...any) { Default().Debug(msg, args...) }
+func Warn(msg string, args ...any)  { Default().Warn(msg, args...) }
+func Error(msg string, args ...any) { Default().Error(msg, args...) }
+
+// With returns Default() with args attached to every subsequent record,
+// matching slog.Logger.With.
+func With(args ...any) *Logger { return Default().With(args...) }
+
+// WithGroup returns Default() with name as the group for every attr added
+// after it, matching slog.Logger.WithGroup.
+func WithGroup(name string) *Logger { return Default().WithGroup(name) }
+
+// User, Guild, and Channel return a slog.Attr for the given Discord entity,
+// usable directly as a logging.Info/Warn/... arg or via
+// logging.With(logging.User(id, name)). They replace the old
+// UserFields/GuildFields/ChannelFields, which returned []interface{} for
+// zap's Infow-style variadic calls; slog takes a single Attr just as
+// naturally, so there's no longer a reason to spread a slice into it. name
+// is omitted from the group when blank, since callers that only have an ID
+// (the common case for voice state events that don't resolve a member)
+// shouldn't log an empty "name" field.
+func User(userID, userName string) slog.Attr {
+	return entityAttr("user", userID, userName)
+}
+
+func Guild(guildID, guildName string) slog.Attr {
+	return entityAttr("guild", guildID, guildName)
+}
+
+func Channel(channelID, channelName string) slog.Attr {
+	return entityAttr("channel", channelID, channelName)
 }
 
-func ChannelFields(channelID, channelName string) []interface{} {
-	if channelName == "" {
-		return []interface{}{"channel_id", channelID}
+func entityAttr(kind, id, name string) slog.Attr {
+	if name == "" {
+		return slog.String(kind+"_id", id)
 	}
-	return []interface{}{"channel_id", channelID, "channel_name", channelName, "channel", fmt.Sprintf("%s (%s)", channelName, channelID)}
+	return slog.Group(kind, "id", id, "name", name, "display", fmt.Sprintf("%s (%s)", name, id))
 }
@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Sampler decides whether a given gateway event type should be logged in
+// full, so high-volume types (PRESENCE_UPDATE, TYPING_START, ...) don't
+// drown out the voice-specific events a human actually greps for. A rate of
+// 1 (the default for any type with no matching entry) always logs.
+type Sampler struct {
+	mu          sync.Mutex
+	rates       map[string]int // exact event type -> every Nth occurrence logs
+	prefixRates map[string]int // prefix (key with trailing '*' stripped) -> rate
+	counts      map[string]uint64
+}
+
+// SamplerFromEnv parses envVar as a comma-separated list of "TYPE=N" or
+// "PREFIX*=N" entries. N is a 1-in-N rate: 1 logs every occurrence, 10 logs
+// every 10th. Types matching no entry default to a rate of 1, so sampling
+// is strictly opt-in. Example:
+//
+//	EVENT_SAMPLING="PRESENCE_UPDATE=100,TYPING_START=20,VOICE_*=1"
+//
+// An unset or empty envVar yields a Sampler that always logs.
+func SamplerFromEnv(envVar string) *Sampler {
+	s := &Sampler{
+		rates:       map[string]int{},
+		prefixRates: map[string]int{},
+		counts:      map[string]uint64{},
+	}
+	for _, part := range strings.Split(os.Getenv(envVar), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(kv[0])
+		n, err := strconv.Atoi(strings.TrimSpace(kv[1]))
+		if err != nil || n <= 0 {
+			continue
+		}
+		if strings.HasSuffix(key, "*") {
+			s.prefixRates[strings.TrimSuffix(key, "*")] = n
+		} else {
+			s.rates[key] = n
+		}
+	}
+	return s
+}
+
+// ShouldLog reports whether the next occurrence of eventType should be
+// logged in full, advancing eventType's internal counter. A nil Sampler
+// always logs. Safe for concurrent use.
+func (s *Sampler) ShouldLog(eventType string) bool {
+	if s == nil {
+		return true
+	}
+	rate := s.rateFor(eventType)
+	if rate <= 1 {
+		return true
+	}
+	s.mu.Lock()
+	s.counts[eventType]++
+	c := s.counts[eventType]
+	s.mu.Unlock()
+	return c%uint64(rate) == 1
+}
+
+// rateFor resolves eventType's configured rate: an exact match wins,
+// otherwise the longest matching prefix entry, otherwise 1.
+func (s *Sampler) rateFor(eventType string) int {
+	if n, ok := s.rates[eventType]; ok {
+		return n
+	}
+	rate, longest := 1, -1
+	for prefix, n := range s.prefixRates {
+		if strings.HasPrefix(eventType, prefix) && len(prefix) > longest {
+			longest, rate = len(prefix), n
+		}
+	}
+	return rate
+}
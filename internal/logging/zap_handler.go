@@ -0,0 +1,92 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// zapHandler is a slog.Handler backed by a *zap.Logger, so LOG_FORMAT's
+// default (unset, or explicitly "zap") keeps producing the same zap output
+// this package always has, while every call site now goes through
+// slog.Logger instead of a *zap.SugaredLogger.
+type zapHandler struct {
+	logger *zap.Logger
+	level  slog.Level
+	groups []string
+}
+
+// newZapHandler builds a zap.Logger matching this package's pre-slog
+// Init (development encoder at debug, production JSON otherwise),
+// redirects the standard library logger into it, and keeps its sugared
+// form available via Sugar().
+func newZapHandler(level slog.Level) *zapHandler {
+	var zl *zap.Logger
+	if level <= slog.LevelDebug {
+		zl, _ = zap.NewDevelopment()
+	} else {
+		zl, _ = zap.NewProduction()
+	}
+	_ = zap.RedirectStdLog(zl)
+	sugar = zl.Sugar()
+	return &zapHandler{logger: zl, level: level}
+}
+
+func (h *zapHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *zapHandler) Handle(_ context.Context, r slog.Record) error {
+	fields := make([]zap.Field, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		fields = append(fields, h.field(a))
+		return true
+	})
+	switch {
+	case r.Level >= slog.LevelError:
+		h.logger.Error(r.Message, fields...)
+	case r.Level >= slog.LevelWarn:
+		h.logger.Warn(r.Message, fields...)
+	case r.Level >= slog.LevelInfo:
+		h.logger.Info(r.Message, fields...)
+	default:
+		h.logger.Debug(r.Message, fields...)
+	}
+	return nil
+}
+
+func (h *zapHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]zap.Field, 0, len(attrs))
+	for _, a := range attrs {
+		fields = append(fields, h.field(a))
+	}
+	return &zapHandler{logger: h.logger.With(fields...), level: h.level, groups: h.groups}
+}
+
+func (h *zapHandler) WithGroup(name string) slog.Handler {
+	groups := make([]string, len(h.groups)+1)
+	copy(groups, h.groups)
+	groups[len(h.groups)] = name
+	return &zapHandler{logger: h.logger, level: h.level, groups: groups}
+}
+
+// field converts one slog.Attr to a zap.Field, prefixing its key with the
+// handler's accumulated WithGroup names so a grouped attr (e.g. from
+// logging.User) reads as "user.id" rather than two unrelated top-level
+// keys colliding with another group's "id".
+func (h *zapHandler) field(a slog.Attr) zap.Field {
+	key := a.Key
+	if len(h.groups) > 0 {
+		key = strings.Join(h.groups, ".") + "." + key
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		m := make(map[string]interface{}, len(a.Value.Group()))
+		for _, ga := range a.Value.Group() {
+			m[ga.Key] = ga.Value.Any()
+		}
+		return zap.Any(key, m)
+	}
+	return zap.Any(key, a.Value.Any())
+}
@@ -2,35 +2,230 @@ package mcp
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"math/rand"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// ConnState is a ClientWrapper's current connection lifecycle state, as
+// tracked across the initial ConnectWebSocket call and any later
+// supervisor-driven reconnects.
+type ConnState int
+
+const (
+	Disconnected ConnState = iota
+	Connecting
+	Connected
+)
+
+func (s ConnState) String() string {
+	switch s {
+	case Disconnected:
+		return "disconnected"
+	case Connecting:
+		return "connecting"
+	case Connected:
+		return "connected"
+	default:
+		return "unknown"
+	}
+}
+
+// defaultPingInterval, defaultBackoffBase, and defaultBackoffMax are
+// ClientWrapper's out-of-the-box keepalive cadence and reconnect backoff
+// bounds; override via WithPingInterval/WithBackoff.
+const (
+	defaultPingInterval = 30 * time.Second
+	defaultBackoffBase  = 250 * time.Millisecond
+	defaultBackoffMax   = 30 * time.Second
+)
+
+// ClientWrapperOption configures optional ClientWrapper behavior passed to
+// NewClientWrapper.
+type ClientWrapperOption func(*ClientWrapper)
+
+// WithPingInterval overrides the keepalive Ping cadence (default 30s).
+func WithPingInterval(d time.Duration) ClientWrapperOption {
+	return func(w *ClientWrapper) { w.pingInterval = d }
+}
+
+// WithBackoff overrides the reconnect supervisor's full-jitter backoff
+// bounds (default 250ms base, 30s cap) - see fullJitterBackoff.
+func WithBackoff(base, max time.Duration) ClientWrapperOption {
+	return func(w *ClientWrapper) {
+		w.backoffBase = base
+		w.backoffMax = max
+	}
+}
+
+// WithFraming selects the wire framing dialWebSocket's transport writes:
+// FramingBinary (the default, and what every Go MCP peer in this repo
+// speaks) or FramingText, for interop with a non-Go peer that only accepts
+// TextMessage frames.
+func WithFraming(f wsFraming) ClientWrapperOption {
+	return func(w *ClientWrapper) { w.framing = f }
+}
+
+// WithReadLimit overrides the maximum inbound WebSocket message size
+// (default defaultWSReadLimitBytes), via websocket.Conn.SetReadLimit.
+func WithReadLimit(n int64) ClientWrapperOption {
+	return func(w *ClientWrapper) { w.readLimit = n }
+}
+
 // ClientWrapper provides a small helper to connect to an MCP server over
-// websocket and manage the client session lifecycle.
+// websocket and manage the client session lifecycle, including supervised
+// reconnection: once ConnectWebSocket succeeds, a background goroutine
+// pings the session on pingInterval and, if a ping (or any read/write error
+// surfaced through the SDK transport) fails, marks the session broken and
+// redials with full-jitter exponential backoff until it succeeds or Close
+// is called.
 type ClientWrapper struct {
-	client  *sdk.Client
-	session *sdk.ClientSession
+	client *sdk.Client
+
+	pingInterval time.Duration
+	backoffBase  time.Duration
+	backoffMax   time.Duration
+	framing      wsFraming
+	readLimit    int64
+
+	mu          sync.Mutex
+	session     *sdk.ClientSession
+	state       ConnState
+	url         string
+	readyCh     chan struct{}
+	onReconnect func(ctx context.Context, w *ClientWrapper)
+	supCancel   context.CancelFunc
+	supDone     chan struct{}
 }
 
 // NewClientWrapper creates a new wrapper with the given name/version.
-func NewClientWrapper(name, version string) *ClientWrapper {
+func NewClientWrapper(name, version string, opts ...ClientWrapperOption) *ClientWrapper {
 	impl := &sdk.Implementation{Name: name, Version: version}
 	c := sdk.NewClient(impl, nil)
-	return &ClientWrapper{client: c}
+	w := &ClientWrapper{
+		client:       c,
+		pingInterval: defaultPingInterval,
+		backoffBase:  defaultBackoffBase,
+		backoffMax:   defaultBackoffMax,
+		readyCh:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// OnReconnect registers fn to be invoked every time the initial connect
+// (from ConnectWebSocket's own goroutine) or a later reconnect (from the
+// supervisor goroutine) succeeds, so a caller
+// that discovered tools from this wrapper (see mcp.ToolRegistry.Discover)
+// can redo that discovery and pick up whatever tool set the server exposes
+// after coming back. Only the most recently registered fn is kept.
+func (w *ClientWrapper) OnReconnect(fn func(ctx context.Context, w *ClientWrapper)) {
+	w.mu.Lock()
+	w.onReconnect = fn
+	w.mu.Unlock()
+}
+
+// State returns the wrapper's current connection state.
+func (w *ClientWrapper) State() ConnState {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.state
+}
+
+// Wait blocks until the wrapper reaches ConnState Connected or ctx is done,
+// whichever comes first.
+func (w *ClientWrapper) Wait(ctx context.Context) error {
+	w.mu.Lock()
+	if w.state == Connected {
+		w.mu.Unlock()
+		return nil
+	}
+	ch := w.readyCh
+	w.mu.Unlock()
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// setState updates the wrapper's state and, on entering/leaving Connected,
+// swaps readyCh so a Wait call racing with the transition observes the
+// right outcome: closed (ready) on Connected, a fresh unclosed channel
+// otherwise.
+func (w *ClientWrapper) setState(s ConnState) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.state == s {
+		return
+	}
+	w.state = s
+	if s == Connected {
+		close(w.readyCh)
+	} else {
+		w.readyCh = make(chan struct{})
+	}
+}
+
+// currentSession returns the wrapper's session under lock, or nil if not
+// currently connected.
+func (w *ClientWrapper) currentSession() *sdk.ClientSession {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.session
 }
 
-// ConnectWebSocket connects to the MCP server websocket endpoint and creates a session.
+// ConnectWebSocket connects to the MCP server websocket endpoint, creates a
+// session, and starts the supervisor goroutine that keeps it alive (ping +
+// reconnect-with-backoff) until Close is called. A failure on this first
+// attempt is returned directly rather than handed to the supervisor - a
+// caller configuring a new MCP server wants to know immediately if the URL
+// or handshake is wrong, not have it silently retried in the background.
 func (w *ClientWrapper) ConnectWebSocket(ctx context.Context, rawurl string) error {
-	u, err := url.Parse(rawurl)
+	w.mu.Lock()
+	w.url = rawurl
+	w.mu.Unlock()
+	w.setState(Connecting)
+
+	sess, err := w.dialWebSocket(ctx, rawurl)
 	if err != nil {
+		w.setState(Disconnected)
 		return err
 	}
-	// Ensure ws scheme
+	w.mu.Lock()
+	w.session = sess
+	w.mu.Unlock()
+	w.setState(Connected)
+	log.Printf("mcp client connected to %s", rawurl)
+	w.notifyReconnect(ctx)
+
+	supCtx, cancel := context.WithCancel(context.Background())
+	w.mu.Lock()
+	w.supCancel = cancel
+	w.supDone = make(chan struct{})
+	w.mu.Unlock()
+	go w.supervise(supCtx)
+	return nil
+}
+
+// dialWebSocket parses rawurl, dials it (upgrading http/https to ws/wss),
+// and performs the SDK client handshake, returning the new session. Shared
+// by ConnectWebSocket's first attempt and the supervisor's reconnect loop.
+func (w *ClientWrapper) dialWebSocket(ctx context.Context, rawurl string) (*sdk.ClientSession, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, err
+	}
 	if u.Scheme != "ws" && u.Scheme != "wss" {
 		if u.Scheme == "http" {
 			u.Scheme = "ws"
@@ -40,39 +235,174 @@ func (w *ClientWrapper) ConnectWebSocket(ctx context.Context, rawurl string) err
 		}
 	}
 	dialer := websocket.DefaultDialer
-	// Connect
 	conn, _, err := dialer.DialContext(ctx, u.String(), nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	// Wrap connection into io.ReadWriteCloser and use sdk.IOTransport
-	t := newClientWebSocketTransport(conn)
-	// Connect client
-	sess, err := w.client.Connect(ctx, t, nil)
-	if err != nil {
-		return err
+	t := newClientWebSocketTransportWithConfig(conn, wsClientTransportConfig{
+		Framing:      w.framing,
+		PingInterval: w.pingInterval,
+		PongWait:     w.pingInterval * 2,
+		ReadLimit:    w.readLimit,
+	})
+	return w.client.Connect(ctx, t, nil)
+}
+
+// notifyReconnect calls the registered OnReconnect callback, if any.
+func (w *ClientWrapper) notifyReconnect(ctx context.Context) {
+	w.mu.Lock()
+	fn := w.onReconnect
+	w.mu.Unlock()
+	if fn != nil {
+		fn(ctx, w)
 	}
-	w.session = sess
-	// Start optional keepalive ping using SDK's Ping if desired
-	go func() {
-		ticker := time.NewTicker(30 * time.Second)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				_ = sess.Ping(context.Background(), nil)
+}
+
+// supervise runs for the life of the wrapper (until ctx - derived from
+// Close - is canceled), pinging the current session every pingInterval and
+// driving a reconnect once a ping fails. Ping failure is the only break
+// detector here: the SDK transport surfaces a dead read/write connection
+// the same way, as the next ping's error, rather than through a separate
+// callback this package would otherwise need to wire up.
+func (w *ClientWrapper) supervise(ctx context.Context) {
+	defer close(w.supDone)
+	ticker := time.NewTicker(w.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sess := w.currentSession()
+			if sess == nil {
+				continue
+			}
+			if err := sess.Ping(ctx, nil); err != nil {
+				var ce *CloseError
+				if errors.As(err, &ce) {
+					log.Printf("mcp client: keepalive ping failed (peer closed, code=%d %s), reconnecting", ce.Code, ce.Text)
+				} else {
+					log.Printf("mcp client: keepalive ping failed, reconnecting: %v", err)
+				}
+				w.reconnect(ctx)
 			}
 		}
-	}()
-	log.Printf("mcp client connected to %s", rawurl)
-	return nil
+	}
 }
 
+// reconnect marks the wrapper broken and redials w.url with full-jitter
+// exponential backoff (fullJitterBackoff) until dialWebSocket succeeds or
+// ctx is canceled (Close). A successful redial re-invokes the OnReconnect
+// callback, if any, so registered tools get re-listed against the new
+// session.
+func (w *ClientWrapper) reconnect(ctx context.Context) {
+	w.setState(Disconnected)
+	w.mu.Lock()
+	rawurl := w.url
+	w.session = nil
+	w.mu.Unlock()
+
+	for attempt := 0; ; attempt++ {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+		w.setState(Connecting)
+		dialCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		sess, err := w.dialWebSocket(dialCtx, rawurl)
+		cancel()
+		if err == nil {
+			w.mu.Lock()
+			w.session = sess
+			w.mu.Unlock()
+			w.setState(Connected)
+			log.Printf("mcp client: reconnected to %s", rawurl)
+			w.notifyReconnect(ctx)
+			return
+		}
+		log.Printf("mcp client: reconnect attempt %d to %s failed: %v", attempt, rawurl, err)
+		wait := fullJitterBackoff(w.backoffBase, attempt, w.backoffMax)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// fullJitterBackoff implements the "full jitter" strategy from
+// https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/:
+// sleep = rand(0, min(max, base*2^attempt)). Same approach
+// voice.fullJitterBackoff/outbound.fullJitterBackoff take for their own
+// retry loops, reimplemented here rather than imported from either since
+// this package has no other reason to depend on internal/voice or
+// internal/voice/outbound.
+func fullJitterBackoff(base time.Duration, attempt int, max time.Duration) time.Duration {
+	d := base * time.Duration(uint64(1)<<uint(attempt))
+	if d <= 0 || d > max {
+		d = max
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// Close shuts down the supervisor goroutine and the underlying session.
+// Safe to call even if ConnectWebSocket never succeeded.
+//
+// w.session is read only after supDone closes, not before canceling the
+// supervisor: reconnect runs synchronously inside supervise's loop, so
+// waiting for supDone guarantees any reconnect in flight when Close was
+// called has already finished (successfully, installing a new w.session -
+// or not, because it observed ctx.Done() first). Reading session earlier
+// could race with a reconnect that installs a new session just after Close
+// sampled the old (already-nil) one, leaking the new connection.
 func (w *ClientWrapper) Close() error {
-	if w.session != nil {
-		_ = w.session.Close()
+	w.mu.Lock()
+	cancel := w.supCancel
+	done := w.supDone
+	w.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	if done != nil {
+		<-done
+	}
+
+	w.mu.Lock()
+	sess := w.session
+	w.session = nil
+	w.mu.Unlock()
+
+	w.setState(Disconnected)
+	if sess != nil {
+		_ = sess.Close()
 	}
 	return nil
 }
+
+// ListTools returns every tool the connected session's MCP server exposes.
+// ConnectWebSocket must have succeeded first; this returns an error rather
+// than a nil-pointer panic otherwise (including while the supervisor is
+// mid-reconnect).
+func (w *ClientWrapper) ListTools(ctx context.Context) ([]*sdk.Tool, error) {
+	sess := w.currentSession()
+	if sess == nil {
+		return nil, fmt.Errorf("mcp: client not connected")
+	}
+	res, err := sess.ListTools(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return res.Tools, nil
+}
+
+// CallTool invokes name on the connected session with args as the tool's
+// input, same not-connected guard as ListTools.
+func (w *ClientWrapper) CallTool(ctx context.Context, name string, args map[string]any) (*sdk.CallToolResult, error) {
+	sess := w.currentSession()
+	if sess == nil {
+		return nil, fmt.Errorf("mcp: client not connected")
+	}
+	return sess.CallTool(ctx, &sdk.CallToolParams{Name: name, Arguments: args})
+}
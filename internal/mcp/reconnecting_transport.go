@@ -0,0 +1,246 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultReconnectQueueSize bounds reconnectingConnection's outgoing queue;
+// override via ReconnectingTransportConfig.QueueSize.
+const defaultReconnectQueueSize = 256
+
+// ReconnectingTransportConfig configures NewReconnectingWebSocketTransport.
+// Zero-valued fields are resolved to the same defaults ClientWrapper uses
+// (defaultPingInterval, defaultBackoffBase/Max, defaultWSReadLimitBytes).
+type ReconnectingTransportConfig struct {
+	URL          string
+	Framing      wsFraming
+	PingInterval time.Duration
+	PongWait     time.Duration
+	ReadLimit    int64
+	QueueSize    int
+	BackoffBase  time.Duration
+	BackoffMax   time.Duration
+}
+
+// reconnectingTransport is an sdk.Transport that owns its own dial/redial
+// loop, rather than handing a single already-dialed *websocket.Conn to its
+// caller the way wsClientTransport does. It exists for a caller that talks
+// to an sdk.Transport directly (outside of ClientWrapper, which already
+// owns reconnection at the session layer via its own supervise/reconnect):
+// its single Connection survives redials transparently, queuing Write
+// calls made while disconnected (bounded, drop-oldest) and replaying them
+// once a new socket is up, instead of surfacing the blip as a dead
+// session the caller has to notice and recreate.
+type reconnectingTransport struct {
+	cfg ReconnectingTransportConfig
+}
+
+// NewReconnectingWebSocketTransport returns an sdk.Transport that dials
+// cfg.URL and keeps redialing (full-jitter backoff) for the life of the
+// returned Connection.
+func NewReconnectingWebSocketTransport(cfg ReconnectingTransportConfig) sdk.Transport {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultReconnectQueueSize
+	}
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = defaultPingInterval
+	}
+	if cfg.PongWait <= 0 {
+		cfg.PongWait = cfg.PingInterval * 2
+	}
+	if cfg.ReadLimit <= 0 {
+		cfg.ReadLimit = defaultWSReadLimitBytes
+	}
+	if cfg.BackoffBase <= 0 {
+		cfg.BackoffBase = defaultBackoffBase
+	}
+	if cfg.BackoffMax <= 0 {
+		cfg.BackoffMax = defaultBackoffMax
+	}
+	return &reconnectingTransport{cfg: cfg}
+}
+
+func (t *reconnectingTransport) Connect(ctx context.Context) (sdk.Connection, error) {
+	c := &reconnectingConnection{cfg: t.cfg, closed: make(chan struct{})}
+	if err := c.dial(ctx); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// reconnectingConnection implements sdk.Connection by redialing cfg.URL
+// with full-jitter backoff whenever the active wsConnection breaks. A
+// message that Write can't hand to a live wsConnection is appended to
+// pending (dropping the oldest entry first once it hits cfg.QueueSize, so
+// a peer that never comes back can't grow this without bound) and
+// replayed once reconnect succeeds - this also covers replaying any
+// request still "in flight" when the break happened, since by
+// construction it's a message the broken connection never got to send.
+type reconnectingConnection struct {
+	cfg ReconnectingTransportConfig
+
+	mu      sync.Mutex
+	current *wsConnection
+
+	pendingMu sync.Mutex
+	pending   []jsonrpc.Message
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func (c *reconnectingConnection) dial(ctx context.Context) error {
+	u, err := url.Parse(c.cfg.URL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "http" {
+		u.Scheme = "ws"
+	}
+	if u.Scheme == "https" {
+		u.Scheme = "wss"
+	}
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	wsConn := newWSConnection(conn, c.cfg.Framing, c.cfg.PingInterval, c.cfg.PongWait, c.cfg.ReadLimit)
+	c.mu.Lock()
+	prev := c.current
+	c.current = wsConn
+	c.mu.Unlock()
+	if prev != nil {
+		// Already broken (that's why we're here) but not yet closed: Write's
+		// and Read's reconnect paths replace c.current without ever calling
+		// Close on the connection they're replacing, which would otherwise
+		// leak its keepalive goroutine and socket on every redial.
+		_ = prev.Close()
+	}
+	return nil
+}
+
+func (c *reconnectingConnection) currentConn() *wsConnection {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.current
+}
+
+// reconnect redials cfg.URL until it succeeds, ctx is done, or Close was
+// called, then flushes pending over the new connection.
+func (c *reconnectingConnection) reconnect(ctx context.Context) error {
+	for attempt := 0; ; attempt++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.closed:
+			return errors.New("mcp: reconnecting transport closed")
+		default:
+		}
+		if err := c.dial(ctx); err == nil {
+			c.flushPending(ctx)
+			return nil
+		}
+		wait := fullJitterBackoff(c.cfg.BackoffBase, attempt, c.cfg.BackoffMax)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.closed:
+			return errors.New("mcp: reconnecting transport closed")
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (c *reconnectingConnection) flushPending(ctx context.Context) {
+	c.pendingMu.Lock()
+	pending := c.pending
+	c.pending = nil
+	c.pendingMu.Unlock()
+	conn := c.currentConn()
+	for i, msg := range pending {
+		if conn == nil {
+			c.requeue(pending[i:])
+			return
+		}
+		if err := conn.Write(ctx, msg); err != nil {
+			log.Printf("mcp: reconnecting transport: replay write failed: %v", err)
+			c.requeue(pending[i:])
+			return
+		}
+	}
+}
+
+func (c *reconnectingConnection) requeue(msgs []jsonrpc.Message) {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+	c.pending = append(msgs, c.pending...)
+}
+
+// enqueue appends msg to pending, dropping the oldest entry first if
+// already at cfg.QueueSize.
+func (c *reconnectingConnection) enqueue(msg jsonrpc.Message) {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+	if len(c.pending) >= c.cfg.QueueSize {
+		log.Printf("mcp: reconnecting transport: outgoing queue full, dropping oldest")
+		c.pending = c.pending[1:]
+	}
+	c.pending = append(c.pending, msg)
+}
+
+func (c *reconnectingConnection) Write(ctx context.Context, msg jsonrpc.Message) error {
+	conn := c.currentConn()
+	if conn == nil {
+		c.enqueue(msg)
+		return nil
+	}
+	if err := conn.Write(ctx, msg); err != nil {
+		c.enqueue(msg)
+		go func() { _ = c.reconnect(context.Background()) }()
+		return nil
+	}
+	return nil
+}
+
+func (c *reconnectingConnection) Read(ctx context.Context) (jsonrpc.Message, error) {
+	for {
+		conn := c.currentConn()
+		if conn != nil {
+			msg, err := conn.Read(ctx)
+			if err == nil {
+				return msg, nil
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-c.closed:
+				return nil, errors.New("mcp: reconnecting transport closed")
+			default:
+			}
+			log.Printf("mcp: reconnecting transport: read failed, reconnecting: %v", err)
+		}
+		if err := c.reconnect(ctx); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *reconnectingConnection) Close() error {
+	c.closeOnce.Do(func() { close(c.closed) })
+	conn := c.currentConn()
+	if conn != nil {
+		return conn.Close()
+	}
+	return nil
+}
+
+func (c *reconnectingConnection) SessionID() string { return "" }
@@ -0,0 +1,133 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ToolRegistry collects tools exposed by one or more connected ClientWrapper
+// sessions and converts them into the OpenAI-style tools:[...] chat-payload
+// entries, so a caller building a chat completion request doesn't need to
+// know anything about MCP itself. A tool name is assumed unique across
+// every session registered via Discover; a later Discover registering the
+// same name silently overwrites the earlier owner.
+type ToolRegistry struct {
+	mu      sync.Mutex
+	owners  map[string]*ClientWrapper
+	schemas map[string]*sdk.Tool
+}
+
+// NewToolRegistry returns an empty ToolRegistry. Use Discover to populate it
+// from a connected ClientWrapper.
+func NewToolRegistry() *ToolRegistry {
+	return &ToolRegistry{
+		owners:  make(map[string]*ClientWrapper),
+		schemas: make(map[string]*sdk.Tool),
+	}
+}
+
+// Discover calls w.ListTools and registers every tool it returns, owned by w
+// for future CallTool dispatch.
+func (r *ToolRegistry) Discover(ctx context.Context, w *ClientWrapper) error {
+	tools, err := w.ListTools(ctx)
+	if err != nil {
+		return fmt.Errorf("mcp: list tools: %w", err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range tools {
+		r.owners[t.Name] = w
+		r.schemas[t.Name] = t
+	}
+	return nil
+}
+
+// Len returns the number of distinct tool names currently registered.
+func (r *ToolRegistry) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.schemas)
+}
+
+// Close closes every distinct ClientWrapper registered via Discover (each
+// one's supervisor goroutine and underlying session), so a caller that owns
+// a ToolRegistry's lifetime - e.g. voice.Processor.Close - has a single call
+// to release everything it connected, rather than needing to have kept its
+// own list of wrappers alongside the registry.
+func (r *ToolRegistry) Close() error {
+	r.mu.Lock()
+	seen := make(map[*ClientWrapper]struct{}, len(r.owners))
+	for _, w := range r.owners {
+		seen[w] = struct{}{}
+	}
+	r.mu.Unlock()
+	var firstErr error
+	for w := range seen {
+		if err := w.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// ChatTools converts every registered tool into an OpenAI-style
+// tools:[{type:"function", function:{name, description, parameters}}]
+// entry, in no particular order (map iteration) - chat completion APIs
+// don't document tool order as meaningful.
+func (r *ToolRegistry) ChatTools() []map[string]interface{} {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]map[string]interface{}, 0, len(r.schemas))
+	for _, t := range r.schemas {
+		fn := map[string]interface{}{
+			"name":        t.Name,
+			"description": t.Description,
+		}
+		if t.InputSchema != nil {
+			fn["parameters"] = t.InputSchema
+		}
+		out = append(out, map[string]interface{}{
+			"type":     "function",
+			"function": fn,
+		})
+	}
+	return out
+}
+
+// CallTool dispatches name to its owning session's CallTool and flattens the
+// result into a single string: concatenated text content on success, or a
+// stand-in message when the MCP server set IsError with no text of its own.
+// Either way the caller appends this string as a role:"tool" message and
+// lets the model see and recover from it. err is only returned for a
+// dispatch failure that never reached an MCP server at all - an
+// unregistered tool name, or CallTool's own transport error - so the caller
+// can still surface that as tool content too.
+func (r *ToolRegistry) CallTool(ctx context.Context, name string, args map[string]interface{}) (string, error) {
+	r.mu.Lock()
+	w, ok := r.owners[name]
+	r.mu.Unlock()
+	if !ok {
+		return "", fmt.Errorf("mcp: unknown tool %q", name)
+	}
+	res, err := w.CallTool(ctx, name, args)
+	if err != nil {
+		return "", fmt.Errorf("mcp: call tool %q: %w", name, err)
+	}
+	var out strings.Builder
+	for _, c := range res.Content {
+		if tc, ok := c.(*sdk.TextContent); ok {
+			if out.Len() > 0 {
+				out.WriteString("\n")
+			}
+			out.WriteString(tc.Text)
+		}
+	}
+	if res.IsError && out.Len() == 0 {
+		out.WriteString("tool reported an error with no message")
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,186 @@
+package mcp
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"context"
+
+	"github.com/gorilla/websocket"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// wsFraming selects the WebSocket frame type newWSConnection writes:
+// binary (the default, and what every Go peer in this repo speaks) or text,
+// for interop with non-Go MCP peers that only accept TextMessage frames.
+type wsFraming int
+
+const (
+	FramingBinary wsFraming = iota
+	FramingText
+)
+
+// defaultWSReadLimitBytes bounds a single inbound WebSocket message
+// (websocket.Conn.SetReadLimit) so a misbehaving or malicious peer can't
+// force unbounded buffering for one frame.
+const defaultWSReadLimitBytes = 1 << 20 // 1MiB
+
+// CloseError is returned from wsConnection.Read in place of the underlying
+// *websocket.CloseError, so callers in this package (ClientWrapper's
+// supervisor) can branch on a graceful peer close without importing
+// gorilla/websocket themselves.
+type CloseError struct {
+	Code int
+	Text string
+}
+
+func (e *CloseError) Error() string {
+	return fmt.Sprintf("mcp: websocket closed (code=%d): %s", e.Code, e.Text)
+}
+
+// wsConnection implements sdk.Connection over a single websocket.Conn, with
+// a background keepalive goroutine that sends a WebSocket ping every
+// pingInterval and, via SetPongHandler, extends the read deadline by
+// pongWait each time the peer answers - so a half-open TCP connection
+// (peer gone but no FIN ever arrives) gets detected by Read timing out
+// instead of blocking forever.
+type wsConnection struct {
+	conn    *websocket.Conn
+	framing wsFraming
+
+	writeMu  sync.Mutex
+	stopOnce sync.Once
+	stopPing chan struct{}
+	pingDone chan struct{}
+}
+
+// newWSConnection wraps conn, applies readLimit, and starts the keepalive
+// goroutine. pongWait should be comfortably larger than pingInterval (the
+// caller's responsibility) so a single missed pong due to scheduling jitter
+// doesn't trip a false disconnect.
+func newWSConnection(conn *websocket.Conn, framing wsFraming, pingInterval, pongWait time.Duration, readLimit int64) *wsConnection {
+	if readLimit <= 0 {
+		readLimit = defaultWSReadLimitBytes
+	}
+	conn.SetReadLimit(readLimit)
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	w := &wsConnection{
+		conn:     conn,
+		framing:  framing,
+		stopPing: make(chan struct{}),
+		pingDone: make(chan struct{}),
+	}
+	go w.keepalive(pingInterval)
+	return w
+}
+
+// keepalive sends a WebSocket ping every interval until stopPing is closed
+// (by Close) or a ping write fails (the peer, or the TCP path to it, is
+// already gone - the next Read will surface that on its own once
+// SetReadDeadline expires).
+func (w *wsConnection) keepalive(interval time.Duration) {
+	defer close(w.pingDone)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stopPing:
+			return
+		case <-ticker.C:
+			w.writeMu.Lock()
+			err := w.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second))
+			w.writeMu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Read does not apply ctx's deadline to the underlying read: SetReadDeadline
+// is owned by newWSConnection's initial call plus the pong handler's rolling
+// extension, and overwriting it per-call (then resetting to zero afterward,
+// the way a one-shot transport would) would undo that keepalive-driven
+// half-open detection between calls. A caller wanting to bound an
+// individual Read should cancel ctx, which still unblocks the SDK's own
+// call site even though ReadMessage itself keeps running until the next
+// inbound frame or the pong-deadline timeout.
+func (w *wsConnection) Read(ctx context.Context) (jsonrpc.Message, error) {
+	_, data, err := w.conn.ReadMessage()
+	if err != nil {
+		if ce, ok := err.(*websocket.CloseError); ok {
+			return nil, &CloseError{Code: ce.Code, Text: ce.Text}
+		}
+		return nil, err
+	}
+	return jsonrpc.DecodeMessage(data)
+}
+
+func (w *wsConnection) Write(ctx context.Context, msg jsonrpc.Message) error {
+	data, err := jsonrpc.EncodeMessage(msg)
+	if err != nil {
+		return err
+	}
+	frameType := websocket.BinaryMessage
+	if w.framing == FramingText {
+		frameType = websocket.TextMessage
+	}
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	if dl, ok := ctx.Deadline(); ok {
+		_ = w.conn.SetWriteDeadline(dl)
+		defer w.conn.SetWriteDeadline(time.Time{})
+	}
+	return w.conn.WriteMessage(frameType, data)
+}
+
+func (w *wsConnection) Close() error {
+	w.stopOnce.Do(func() { close(w.stopPing) })
+	<-w.pingDone
+	return w.conn.Close()
+}
+
+func (w *wsConnection) SessionID() string { return "" }
+
+// wsClientTransportConfig configures newClientWebSocketTransportWithConfig.
+// A zero Framing is FramingBinary and a ReadLimit <= 0 is resolved to
+// defaultWSReadLimitBytes by newWSConnection; PingInterval and PongWait are
+// used as given (dialWebSocket passes the ClientWrapper's pingInterval and
+// twice that).
+type wsClientTransportConfig struct {
+	Framing      wsFraming
+	PingInterval time.Duration
+	PongWait     time.Duration
+	ReadLimit    int64
+}
+
+type wsClientTransport struct {
+	conn *websocket.Conn
+	cfg  wsClientTransportConfig
+}
+
+func (t *wsClientTransport) Connect(ctx context.Context) (sdk.Connection, error) {
+	return newWSConnection(t.conn, t.cfg.Framing, t.cfg.PingInterval, t.cfg.PongWait, t.cfg.ReadLimit), nil
+}
+
+// newClientWebSocketTransport wraps conn with the package defaults
+// (binary framing, defaultPingInterval keepalive). dialWebSocket always
+// uses newClientWebSocketTransportWithConfig instead, passing the
+// ClientWrapper's own framing, ping interval and read limit.
+func newClientWebSocketTransport(conn *websocket.Conn) sdk.Transport {
+	return newClientWebSocketTransportWithConfig(conn, wsClientTransportConfig{
+		Framing:      FramingBinary,
+		PingInterval: defaultPingInterval,
+		PongWait:     defaultPingInterval * 2,
+		ReadLimit:    defaultWSReadLimitBytes,
+	})
+}
+
+func newClientWebSocketTransportWithConfig(conn *websocket.Conn, cfg wsClientTransportConfig) sdk.Transport {
+	return &wsClientTransport{conn: conn, cfg: cfg}
+}
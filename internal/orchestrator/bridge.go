@@ -0,0 +1,17 @@
+package orchestrator
+
+import "context"
+
+// Bridge delivers Events to one external sink (Slack, IRC, a generic HTTP
+// endpoint, stdout, ...). Implementations should not block Send for long;
+// the router calls Send from a new goroutine for every event and bridge, so
+// a slow or wedged bridge doesn't delay the others but does accumulate
+// goroutines of its own.
+type Bridge interface {
+	// Name identifies the bridge instance in logs (e.g. "slack:#general").
+	Name() string
+	// Send delivers evt to the sink. On error the router retries (up to
+	// bridgeSendRetries attempts in all, with doubling backoff) and then
+	// only logs; the event is not re-queued.
+	Send(ctx context.Context, evt Event) error
+}
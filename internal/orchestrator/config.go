@@ -0,0 +1,50 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BridgeConfig describes one configured Bridge instance: its type, an
+// optional endpoint (webhook/HTTP URL), and an optional guild/channel
+// filter restricting which events it receives. Fields below Endpoint are
+// only meaningful for specific types, documented per-field; NewBridge
+// validates the ones its type requires.
+type BridgeConfig struct {
+	Type      string `yaml:"type"`
+	Endpoint  string `yaml:"endpoint,omitempty"`
+	GuildID   string `yaml:"guild_id,omitempty"`
+	ChannelID string `yaml:"channel_id,omitempty"`
+
+	// BotToken is the Discord bot token used by type "discord" to post to
+	// ChannelID via the REST API (distinct from the guild/channel filter
+	// above, which ChannelID doubles as the destination for).
+	BotToken string `yaml:"bot_token,omitempty"`
+	// Server is the "host:port" IRC server address used by type "irc".
+	Server string `yaml:"server,omitempty"`
+	// Nick is the nickname used by type "irc".
+	Nick string `yaml:"nick,omitempty"`
+	// Room is the IRC channel (e.g. "#general") joined by type "irc".
+	Room string `yaml:"room,omitempty"`
+}
+
+// Config is the top-level bridge configuration, typically loaded from a
+// YAML file referenced by ORCHESTRATOR_BRIDGES_CONFIG.
+type Config struct {
+	Bridges []BridgeConfig `yaml:"bridges"`
+}
+
+// LoadConfig reads and parses a bridge config YAML file.
+func LoadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read bridge config %s: %w", path, err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return nil, fmt.Errorf("parse bridge config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,62 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// discordAPIBase is Discord's REST API root. Posting a message only needs
+// this one endpoint, so DiscordBridge talks to it directly with net/http
+// rather than pulling in discordgo (which would make orchestrator depend on
+// the voice package's Discord session instead of the other way around).
+const discordAPIBase = "https://discord.com/api/v10"
+
+// DiscordBridge posts transcript text into a Discord text channel via the
+// bot REST API, using the same bot token the voice pipeline authenticates
+// with (DISCORD_BOT_TOKEN), configured per-bridge so a transcript feed can
+// target a different channel than the one being transcribed.
+type DiscordBridge struct {
+	BotToken  string
+	ChannelID string
+	Client    *http.Client
+}
+
+// NewDiscordBridge returns a DiscordBridge posting to channelID with botToken.
+func NewDiscordBridge(botToken, channelID string) *DiscordBridge {
+	return &DiscordBridge{BotToken: botToken, ChannelID: channelID, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (b *DiscordBridge) Name() string { return "discord:" + b.ChannelID }
+
+func (b *DiscordBridge) Send(ctx context.Context, evt Event) error {
+	body, err := json.Marshal(struct {
+		Content string `json:"content"`
+	}{Content: fmt.Sprintf("**%s**: %s", evt.Username, evt.Text)})
+	if err != nil {
+		return fmt.Errorf("marshal message: %w", err)
+	}
+	url := fmt.Sprintf("%s/channels/%s/messages", discordAPIBase, b.ChannelID)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bot "+b.BotToken)
+	client := b.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post to channel %s: %w", b.ChannelID, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("discord channel %s returned status %d", b.ChannelID, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+// Package orchestrator fans out normalized voice-pipeline events to
+// configurable sinks (bridges). It turns the bot from a logging-only
+// process into something that can, e.g., post "user X started speaking in
+// #general" to Slack, IRC, or an internal event bus.
+package orchestrator
+
+import "time"
+
+// EventType names a kind of normalized voice-pipeline event.
+type EventType string
+
+const (
+	EventSpeakingStart    EventType = "speaking_start"
+	EventSpeakingStop     EventType = "speaking_stop"
+	EventVoiceStateUpdate EventType = "voice_state_update"
+	EventTranscript       EventType = "transcript"
+)
+
+// Event is a normalized voice-pipeline event, built from the same fields
+// internal/events' Normalizer pulls out of raw discordgo events plus
+// transcripts emitted by the voice Processor.
+type Event struct {
+	Type      EventType
+	GuildID   string
+	ChannelID string
+	UserID    string
+	Username  string
+	SSRC      uint32
+	Text      string
+	// CorrelationID ties a transcript event back to the utterance that
+	// produced it (the same ID threaded through sendPCMToWhisper/sidecar
+	// files), empty for non-transcript event types.
+	CorrelationID string
+	At            time.Time
+}
@@ -0,0 +1,51 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+)
+
+// NewBridge constructs the Bridge described by cfg. "slack" and "webhook"
+// are aliases for a generic HTTP POST, since an incoming Slack webhook is
+// just an HTTP endpoint; Matrix, XMPP, and NATS/Redis stream sinks are
+// real, named extension points but have no implementation here yet since
+// this build has no Matrix/XMPP/NATS client available to wire up.
+func NewBridge(cfg BridgeConfig) (Bridge, error) {
+	switch cfg.Type {
+	case "noop":
+		return NoopBridge{}, nil
+	case "stdout":
+		return StdoutBridge{}, nil
+	case "http", "webhook", "slack":
+		if cfg.Endpoint == "" {
+			return nil, fmt.Errorf("bridge type %q requires endpoint", cfg.Type)
+		}
+		return NewHTTPBridge(cfg.Endpoint), nil
+	case "discord":
+		// bot_token falls back to DISCORD_BOT_TOKEN (the same token the
+		// voice pipeline authenticates with) so a bridge targeting the
+		// bot's own guilds doesn't need the token repeated in the bridge config file.
+		botToken := cfg.BotToken
+		if botToken == "" {
+			botToken = os.Getenv("DISCORD_BOT_TOKEN")
+		}
+		if botToken == "" || cfg.ChannelID == "" {
+			return nil, fmt.Errorf("bridge type %q requires bot_token (or DISCORD_BOT_TOKEN) and channel_id", cfg.Type)
+		}
+		return NewDiscordBridge(botToken, cfg.ChannelID), nil
+	case "irc":
+		if cfg.Server == "" || cfg.Nick == "" || cfg.Room == "" {
+			return nil, fmt.Errorf("bridge type %q requires server, nick and room", cfg.Type)
+		}
+		return NewIRCBridge(cfg.Server, cfg.Nick, cfg.Room), nil
+	case "matrix", "xmpp", "nats", "redis":
+		return nil, fmt.Errorf("bridge type %q is not implemented yet", cfg.Type)
+	default:
+		return nil, fmt.Errorf("unknown bridge type %q", cfg.Type)
+	}
+}
+
+// FilterFromConfig extracts cfg's guild/channel filter.
+func FilterFromConfig(cfg BridgeConfig) Filter {
+	return Filter{GuildID: cfg.GuildID, ChannelID: cfg.ChannelID}
+}
@@ -0,0 +1,58 @@
+package orchestrator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// HTTPBridge POSTs a JSON-encoded Event to Endpoint. This is the generic
+// sink: a Slack incoming webhook, a Matrix/XMPP bridge's REST gateway, or
+// any other HTTP-based consumer all just look like a POST endpoint from
+// here, so one implementation covers all of them.
+type HTTPBridge struct {
+	EndpointName string // used only for Name(); defaults to Endpoint if empty
+	Endpoint     string
+	Client       *http.Client
+}
+
+// NewHTTPBridge returns an HTTPBridge posting to endpoint with a default
+// client timeout.
+func NewHTTPBridge(endpoint string) *HTTPBridge {
+	return &HTTPBridge{Endpoint: endpoint, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (b *HTTPBridge) Name() string {
+	if b.EndpointName != "" {
+		return "http:" + b.EndpointName
+	}
+	return "http:" + b.Endpoint
+}
+
+func (b *HTTPBridge) Send(ctx context.Context, evt Event) error {
+	body, err := json.Marshal(evt)
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", b.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := b.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post to %s: %w", b.Endpoint, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned status %d", b.Endpoint, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package orchestrator
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/discord-voice-lab/internal/logging"
+)
+
+// IRCBridge relays transcript text as PRIVMSGs to a single IRC channel over
+// a persistent TCP connection, registering with NICK/USER/JOIN on connect
+// and reconnecting lazily the next time Send is called after the connection
+// drops. It speaks plain IRC (RFC 1459/2812), no SASL or TLS, matching the
+// minimal surface this backend needs.
+type IRCBridge struct {
+	Server string
+	Nick   string
+	Room   string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewIRCBridge returns an IRCBridge that dials server (host:port) on first
+// Send, registers as nick, and joins room.
+func NewIRCBridge(server, nick, room string) *IRCBridge {
+	return &IRCBridge{Server: server, Nick: nick, Room: room}
+}
+
+func (b *IRCBridge) Name() string { return "irc:" + b.Room }
+
+// Send joins b.Room's PRIVMSG conversation with evt's transcript text,
+// dialing and registering the connection first if it isn't already up.
+func (b *IRCBridge) Send(ctx context.Context, evt Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.conn == nil {
+		if err := b.connectLocked(ctx); err != nil {
+			return err
+		}
+	}
+	line := fmt.Sprintf("PRIVMSG %s :%s: %s\r\n", b.Room, sanitizeIRCLine(evt.Username), sanitizeIRCLine(evt.Text))
+	if _, err := b.conn.Write([]byte(line)); err != nil {
+		_ = b.conn.Close()
+		b.conn = nil
+		return fmt.Errorf("irc write to %s: %w", b.Room, err)
+	}
+	return nil
+}
+
+// writeLine writes line to conn, guarded by b.mu so it can't interleave with
+// Send's PRIVMSG writes on the same underlying connection. It's a no-op if
+// conn is no longer b.conn (superseded by a reconnect).
+func (b *IRCBridge) writeLine(conn net.Conn, line string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.conn != conn {
+		return nil
+	}
+	_, err := conn.Write([]byte(line))
+	return err
+}
+
+// connectLocked dials b.Server and registers, leaving b.conn set on
+// success. Callers must hold b.mu.
+func (b *IRCBridge) connectLocked(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", b.Server)
+	if err != nil {
+		return fmt.Errorf("irc dial %s: %w", b.Server, err)
+	}
+	for _, line := range []string{
+		fmt.Sprintf("NICK %s\r\n", b.Nick),
+		fmt.Sprintf("USER %s 0 * :%s\r\n", b.Nick, b.Nick),
+		fmt.Sprintf("JOIN %s\r\n", b.Room),
+	} {
+		if _, err := conn.Write([]byte(line)); err != nil {
+			_ = conn.Close()
+			return fmt.Errorf("irc register %s: %w", b.Server, err)
+		}
+	}
+	b.conn = conn
+	go b.drain(conn)
+	return nil
+}
+
+// drain reads and discards server traffic (PINGs, join confirmations,
+// etc.) so the connection's TCP receive buffer never fills up; it exits
+// once the connection closes or errors. IRC servers expect a PONG reply to
+// PING within a timeout window or they disconnect idle clients, so this
+// also answers PINGs directly rather than ignoring them.
+func (b *IRCBridge) drain(conn net.Conn) {
+	reader := bufio.NewReader(conn)
+	tp := textproto.NewReader(reader)
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			logging.Debug("irc bridge: connection closed", "server", b.Server, "err", err)
+			return
+		}
+		if strings.HasPrefix(line, "PING") {
+			reply := "PONG" + strings.TrimPrefix(line, "PING") + "\r\n"
+			if err := b.writeLine(conn, reply); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// sanitizeIRCLine strips CR/LF so a transcript can't inject additional IRC
+// protocol lines through PRIVMSG's text argument.
+func sanitizeIRCLine(s string) string {
+	s = strings.ReplaceAll(s, "\r", " ")
+	s = strings.ReplaceAll(s, "\n", " ")
+	return s
+}
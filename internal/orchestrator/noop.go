@@ -0,0 +1,11 @@
+package orchestrator
+
+import "context"
+
+// NoopBridge discards every event. Useful as a config placeholder and in
+// tests that need a Bridge but don't care about delivery.
+type NoopBridge struct{}
+
+func (NoopBridge) Name() string { return "noop" }
+
+func (NoopBridge) Send(ctx context.Context, evt Event) error { return nil }
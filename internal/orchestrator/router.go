@@ -0,0 +1,125 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/discord-voice-lab/internal/logging"
+)
+
+// bridgeSendRetries and bridgeSendBackoff bound how hard Run retries a
+// failing bridge before giving up on one event: 3 attempts, doubling from
+// 500ms, so a destination's transient blip (a Slack 503, an IRC reconnect)
+// doesn't silently drop the transcript, but a consistently down destination
+// can't back up the router's per-event goroutines indefinitely.
+const (
+	bridgeSendRetries = 3
+	bridgeSendBackoff = 500 * time.Millisecond
+)
+
+// Filter restricts a subscribed bridge to events from a specific guild
+// and/or channel. An empty field matches anything.
+type Filter struct {
+	GuildID   string
+	ChannelID string
+}
+
+func (f Filter) matches(evt Event) bool {
+	if f.GuildID != "" && f.GuildID != evt.GuildID {
+		return false
+	}
+	if f.ChannelID != "" && f.ChannelID != evt.ChannelID {
+		return false
+	}
+	return true
+}
+
+type subscription struct {
+	bridge Bridge
+	filter Filter
+}
+
+// EventRouter publishes normalized Events onto a channel and fans each one
+// out to every subscribed Bridge whose Filter matches. It replaces the
+// previous design where the bot's event handler logged directly to zap;
+// bridges are now independent subscribers and new sinks can be added
+// without touching the Discord event-handling code at all.
+type EventRouter struct {
+	ch chan Event
+
+	mu   sync.Mutex
+	subs []subscription
+}
+
+// NewEventRouter creates a router with the given channel buffer size.
+// Publish drops events (logging a warning) if the buffer is full, so a
+// stuck bridge can't block the Discord event loop.
+func NewEventRouter(bufSize int) *EventRouter {
+	if bufSize <= 0 {
+		bufSize = 256
+	}
+	return &EventRouter{ch: make(chan Event, bufSize)}
+}
+
+// AddBridge subscribes b to events matching filter.
+func (r *EventRouter) AddBridge(b Bridge, filter Filter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subs = append(r.subs, subscription{bridge: b, filter: filter})
+}
+
+// Publish enqueues evt for delivery to subscribed bridges. Non-blocking.
+func (r *EventRouter) Publish(evt Event) {
+	select {
+	case r.ch <- evt:
+	default:
+		logging.Warn("orchestrator: event router buffer full, dropping event", "type", evt.Type, "guild_id", evt.GuildID)
+	}
+}
+
+// Run drains published events and dispatches them to matching bridges until
+// ctx is cancelled. Each bridge's Send is called from its own goroutine so
+// one slow bridge doesn't delay delivery to the others.
+func (r *EventRouter) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt := <-r.ch:
+			r.mu.Lock()
+			subs := make([]subscription, len(r.subs))
+			copy(subs, r.subs)
+			r.mu.Unlock()
+			for _, sub := range subs {
+				if !sub.filter.matches(evt) {
+					continue
+				}
+				go sendWithRetry(ctx, sub.bridge, evt)
+			}
+		}
+	}
+}
+
+// sendWithRetry calls bridge.Send up to bridgeSendRetries times in all,
+// with doubling backoff between failed attempts. Giving up only logs, same as before this
+// retry loop existed: Run does not re-queue the event.
+func sendWithRetry(ctx context.Context, bridge Bridge, evt Event) {
+	backoff := bridgeSendBackoff
+	var err error
+	for attempt := 1; attempt <= bridgeSendRetries; attempt++ {
+		if err = bridge.Send(ctx, evt); err == nil {
+			return
+		}
+		if attempt == bridgeSendRetries {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	logging.Warn("orchestrator: bridge send failed after retries", "bridge", bridge.Name(), "type", evt.Type, "attempts", bridgeSendRetries, "err", err)
+}
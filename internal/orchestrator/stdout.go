@@ -0,0 +1,20 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// StdoutBridge writes a one-line human-readable summary of each event to
+// stdout. Handy for local development and tests where standing up a real
+// sink isn't worth it.
+type StdoutBridge struct{}
+
+func (StdoutBridge) Name() string { return "stdout" }
+
+func (StdoutBridge) Send(ctx context.Context, evt Event) error {
+	_, err := fmt.Fprintf(os.Stdout, "[%s] guild=%s channel=%s user=%s ssrc=%d %s\n",
+		evt.Type, evt.GuildID, evt.ChannelID, evt.UserID, evt.SSRC, evt.Text)
+	return err
+}
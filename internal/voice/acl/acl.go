@@ -0,0 +1,206 @@
+// Package acl gates which users' transcripts get forwarded to the
+// orchestrator/TTS pipeline, JSON-file-backed the same way other pluggable
+// config in this module is (see provider_routing.go's *_ROUTING_JSON).
+package acl
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/discord-voice-lab/internal/logging"
+)
+
+// Config is the JSON shape of the ACL file, e.g.
+// {"mode":"allow","users":["123456789012345678"],"ssrcs":[123],"wake_required":true}.
+type Config struct {
+	Mode         string   `json:"mode"` // "allow" or "deny"
+	Users        []string `json:"users"`
+	SSRCs        []uint32 `json:"ssrcs"`
+	WakeRequired bool     `json:"wake_required"`
+}
+
+// Decision is the result of evaluating a Manager's Config against one
+// transcript. RuleID names which part of the Config produced the verdict,
+// for the sidecar audit trail (see Processor.recordACLDecisionInSidecar).
+type Decision struct {
+	Allowed bool
+	RuleID  string
+}
+
+// Manager loads Config from a JSON file and re-reads it whenever
+// StartReloadLoop's fsnotify watch fires. A Manager built with path == ""
+// always allows everything, unconfigured.
+type Manager struct {
+	path string
+
+	mu      sync.RWMutex
+	cfg     Config
+	modTime time.Time
+}
+
+// NewManager builds a Manager reading from path, loading it once
+// immediately if the file exists. path == "" (ACL_FILE unset) yields a
+// Manager that allows everything.
+func NewManager(path string) *Manager {
+	m := &Manager{path: strings.TrimSpace(path)}
+	if m.path != "" {
+		m.reload()
+	}
+	return m
+}
+
+// Configured reports whether this Manager has an ACL file to enforce, for
+// callers deciding whether an ACL audit entry is worth recording.
+func (m *Manager) Configured() bool {
+	return m.path != ""
+}
+
+// StartReloadLoop watches path for changes via fsnotify and reloads on every
+// write/create/rename event, until ctx is canceled. No-op if path == "".
+// Intended to run in its own goroutine for the Manager's lifetime. The
+// containing directory is watched rather than the file itself, since
+// editors and config-management tools commonly replace a file via
+// rename-over rather than an in-place write, which a file-level watch
+// would miss once the original inode is gone. If the watcher itself fails
+// to start (e.g. inotify instance limits exhausted), this falls back to
+// polling path's mtime on interval so a reload path always exists.
+func (m *Manager) StartReloadLoop(ctx context.Context, interval time.Duration) {
+	if m.path == "" {
+		return
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		logging.Warn("acl: fsnotify watcher unavailable, falling back to polling", "err", err)
+		m.pollReloadLoop(ctx, interval)
+		return
+	}
+	defer watcher.Close()
+	if err := watcher.Add(filepath.Dir(m.path)); err != nil {
+		logging.Warn("acl: fsnotify watch failed, falling back to polling", "path", m.path, "err", err)
+		m.pollReloadLoop(ctx, interval)
+		return
+	}
+
+	base := filepath.Base(m.path)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Base(ev.Name) == base {
+				m.reload()
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			logging.Warn("acl: fsnotify watch error", "path", m.path, "err", err)
+		}
+	}
+}
+
+// pollReloadLoop is StartReloadLoop's fallback when fsnotify can't watch
+// m.path, re-checking its mtime every interval until ctx is canceled.
+func (m *Manager) pollReloadLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.reload()
+		}
+	}
+}
+
+func (m *Manager) reload() {
+	st, err := os.Stat(m.path)
+	if err != nil {
+		return // leave last-known-good config in place
+	}
+	m.mu.RLock()
+	unchanged := st.ModTime().Equal(m.modTime)
+	m.mu.RUnlock()
+	if unchanged {
+		return
+	}
+	b, err := os.ReadFile(m.path)
+	if err != nil {
+		logging.Warn("acl: failed to read file", "path", m.path, "err", err)
+		return
+	}
+	var cfg Config
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		logging.Warn("acl: failed to parse file, keeping previous config", "path", m.path, "err", err)
+		return
+	}
+	m.mu.Lock()
+	m.cfg = cfg
+	m.modTime = st.ModTime()
+	m.mu.Unlock()
+	logging.Info("acl: reloaded", "path", m.path, "mode", cfg.Mode, "users", len(cfg.Users), "ssrcs", len(cfg.SSRCs), "wake_required", cfg.WakeRequired)
+}
+
+// Decide reports whether a transcript from uid/ssrc should be forwarded,
+// given whether it already matched a wake phrase. An unconfigured Manager,
+// a file that has never loaded successfully (missing, unreadable or
+// invalid), or a Config with no mode set all allow everything (RuleID
+// "no_acl") — a missing or broken ACL file fails open rather than silently
+// blocking every user. A file that turns unreadable or invalid after a
+// successful load leaves the last good Config in force.
+func (m *Manager) Decide(uid string, ssrc uint32, wakeMatched bool) Decision {
+	if m.path == "" {
+		return Decision{Allowed: true, RuleID: "no_acl"}
+	}
+	m.mu.RLock()
+	cfg := m.cfg
+	m.mu.RUnlock()
+	if cfg.Mode == "" {
+		return Decision{Allowed: true, RuleID: "no_acl"}
+	}
+
+	listed := false
+	for _, u := range cfg.Users {
+		if u == uid {
+			listed = true
+			break
+		}
+	}
+	if !listed {
+		for _, s := range cfg.SSRCs {
+			if s == ssrc {
+				listed = true
+				break
+			}
+		}
+	}
+
+	switch strings.ToLower(cfg.Mode) {
+	case "deny":
+		if listed {
+			return Decision{Allowed: false, RuleID: "deny_listed"}
+		}
+	case "allow":
+		if !listed {
+			return Decision{Allowed: false, RuleID: "not_allow_listed"}
+		}
+	default:
+		logging.Warn("acl: unrecognized mode, treating as allow-all", "mode", cfg.Mode)
+		return Decision{Allowed: true, RuleID: "unknown_mode"}
+	}
+	if cfg.WakeRequired && !wakeMatched {
+		return Decision{Allowed: false, RuleID: "wake_required"}
+	}
+	return Decision{Allowed: true, RuleID: strings.ToLower(cfg.Mode) + "_ok"}
+}
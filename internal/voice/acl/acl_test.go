@@ -0,0 +1,97 @@
+package acl
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfig(t *testing.T, cfg Config) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "acl.json")
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("marshal config: %v", err)
+	}
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+// TestManagerUnconfiguredAllowsEverything verifies a Manager with no ACL
+// file (path == "") fails open rather than blocking every user.
+func TestManagerUnconfiguredAllowsEverything(t *testing.T) {
+	m := NewManager("")
+	got := m.Decide("user-1", 42, false)
+	if !got.Allowed || got.RuleID != "no_acl" {
+		t.Errorf("Decide = %+v, want allowed with no_acl", got)
+	}
+}
+
+// TestManagerDenyModeBlocksListedUsers verifies deny mode blocks users/SSRCs
+// on the list and allows everyone else.
+func TestManagerDenyModeBlocksListedUsers(t *testing.T) {
+	path := writeConfig(t, Config{Mode: "deny", Users: []string{"user-1"}, SSRCs: []uint32{99}})
+	m := NewManager(path)
+
+	if got := m.Decide("user-1", 1, false); got.Allowed || got.RuleID != "deny_listed" {
+		t.Errorf("denied-by-user Decide = %+v, want blocked with deny_listed", got)
+	}
+	if got := m.Decide("other-user", 99, false); got.Allowed || got.RuleID != "deny_listed" {
+		t.Errorf("denied-by-ssrc Decide = %+v, want blocked with deny_listed", got)
+	}
+	if got := m.Decide("other-user", 1, false); !got.Allowed {
+		t.Errorf("unlisted Decide = %+v, want allowed", got)
+	}
+}
+
+// TestManagerAllowModeBlocksUnlistedUsers verifies allow mode is the
+// inverse of deny mode: only listed users/SSRCs pass.
+func TestManagerAllowModeBlocksUnlistedUsers(t *testing.T) {
+	path := writeConfig(t, Config{Mode: "allow", Users: []string{"user-1"}})
+	m := NewManager(path)
+
+	if got := m.Decide("user-1", 1, false); !got.Allowed {
+		t.Errorf("listed Decide = %+v, want allowed", got)
+	}
+	if got := m.Decide("other-user", 1, false); got.Allowed || got.RuleID != "not_allow_listed" {
+		t.Errorf("unlisted Decide = %+v, want blocked with not_allow_listed", got)
+	}
+}
+
+// TestManagerWakeRequiredGatesAfterListMatch verifies wake_required is
+// evaluated after the allow/deny match, not instead of it.
+func TestManagerWakeRequiredGatesAfterListMatch(t *testing.T) {
+	path := writeConfig(t, Config{Mode: "allow", Users: []string{"user-1"}, WakeRequired: true})
+	m := NewManager(path)
+
+	if got := m.Decide("user-1", 1, false); got.Allowed || got.RuleID != "wake_required" {
+		t.Errorf("no-wake Decide = %+v, want blocked with wake_required", got)
+	}
+	if got := m.Decide("user-1", 1, true); !got.Allowed || got.RuleID != "allow_ok" {
+		t.Errorf("wake-matched Decide = %+v, want allowed with allow_ok", got)
+	}
+}
+
+// TestManagerUnrecognizedModeFailsOpen verifies a Config with an unknown
+// mode string allows everything rather than blocking on a config typo.
+func TestManagerUnrecognizedModeFailsOpen(t *testing.T) {
+	path := writeConfig(t, Config{Mode: "block-all-the-things"})
+	m := NewManager(path)
+
+	if got := m.Decide("user-1", 1, false); !got.Allowed || got.RuleID != "unknown_mode" {
+		t.Errorf("Decide = %+v, want allowed with unknown_mode", got)
+	}
+}
+
+// TestManagerMissingFileFailsOpen verifies a configured-but-unreadable ACL
+// path allows everything rather than blocking on a broken deployment.
+func TestManagerMissingFileFailsOpen(t *testing.T) {
+	m := NewManager(filepath.Join(t.TempDir(), "does-not-exist.json"))
+	got := m.Decide("user-1", 1, false)
+	if !got.Allowed || got.RuleID != "no_acl" {
+		t.Errorf("Decide = %+v, want allowed with no_acl", got)
+	}
+}
@@ -0,0 +1,280 @@
+package voice
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/discord-voice-lab/internal/logging"
+)
+
+// manifestFileName is the append-only checksum log AtomicWriter maintains
+// per directory: one line per write, "sha256  size  mtime  name". Append-
+// only rather than rewritten in place, matching this package's usual
+// durable-write primitives (sidecarIndex snapshots, log sinks) being
+// tmp+rename rather than in-place mutation - a MANIFEST that's only ever
+// appended to can't be torn by a crash mid-write the way a rewritten one
+// could.
+const manifestFileName = "MANIFEST"
+
+// ManifestEntry is one parsed MANIFEST line.
+type ManifestEntry struct {
+	SHA256 string
+	Size   int64
+	Mtime  int64
+	Name   string
+}
+
+// AtomicWriter writes files durably: tmp file in the same directory,
+// fsync the file, rename into place, then fsync the containing directory
+// too - SaveFileAtomic's fsync-the-file-only version leaves the rename
+// itself at risk of being lost on a crash before the directory entry hits
+// disk on ext4/xfs. Every write also appends a MANIFEST entry so
+// Verify/VerifyAll can later detect silent corruption. Stateless (same as
+// the free-function SaveFileAtomic it replaces); a value is fine to share
+// or recreate per call.
+type AtomicWriter struct{}
+
+// NewAtomicWriter returns a ready-to-use AtomicWriter.
+func NewAtomicWriter() *AtomicWriter { return &AtomicWriter{} }
+
+// dirLocks serializes the rename+MANIFEST-append sequence and MANIFEST
+// reads within a single directory, across every AtomicWriter instance.
+// Without this, VerifyAll can read a file's new (just-renamed) content
+// against its still-stale MANIFEST entry in the instant between Write's
+// rename and its manifest append, and wrongly quarantine a file that was
+// never actually corrupt.
+var (
+	dirLocksMu sync.Mutex
+	dirLocks   = map[string]*sync.Mutex{}
+)
+
+func lockForDir(dir string) *sync.Mutex {
+	dirLocksMu.Lock()
+	defer dirLocksMu.Unlock()
+	l, ok := dirLocks[dir]
+	if !ok {
+		l = &sync.Mutex{}
+		dirLocks[dir] = l
+	}
+	return l
+}
+
+// Write durably writes data to path: write tmp + fsync + close, rename
+// into place, fsync the containing directory, then append a MANIFEST
+// entry recording data's SHA-256, size, and mtime.
+func (w *AtomicWriter) Write(path string, data []byte, mode os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		logging.Warn("atomicwriter: mkdirall failed", "dir", dir, "err", err)
+		return err
+	}
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		logging.Warn("atomicwriter: open tmp failed", "tmp", tmp, "err", err)
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		logging.Warn("atomicwriter: write failed", "tmp", tmp, "err", err)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		logging.Warn("atomicwriter: fsync failed", "tmp", tmp, "err", err)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		logging.Warn("atomicwriter: close tmp failed", "tmp", tmp, "err", err)
+		return err
+	}
+	lock := lockForDir(dir)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		logging.Warn("atomicwriter: rename failed", "tmp", tmp, "final", path, "err", err)
+		return err
+	}
+	if err := fsyncDir(dir); err != nil {
+		logging.Warn("atomicwriter: dir fsync failed", "dir", dir, "err", err)
+		return err
+	}
+	// A MANIFEST append failure doesn't undo the write above: the data is
+	// already durably on disk under path. Log it rather than returning an
+	// error that would make a caller believe the write itself failed - the
+	// file just won't be fsck-able by Verify/VerifyAll until a later write
+	// to the same path succeeds in appending its entry.
+	if err := appendManifest(dir, path, data); err != nil {
+		logging.Warn("atomicwriter: manifest append failed", "dir", dir, "path", path, "err", err)
+	}
+	return nil
+}
+
+// fsyncDir fsyncs a directory's inode, so a rename into it is durable
+// against a crash immediately after - on Linux this is the only way to
+// make a rename (or any other directory-entry change) survive a crash,
+// since the rename itself doesn't imply the directory's own fsync.
+func fsyncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// appendManifest adds one line to dir's MANIFEST recording path's current
+// SHA-256, size, and mtime.
+func appendManifest(dir, path string, data []byte) error {
+	sum := sha256.Sum256(data)
+	mtime := time.Now().Unix()
+	if st, err := os.Stat(path); err == nil {
+		mtime = st.ModTime().Unix()
+	}
+	line := fmt.Sprintf("%x  %d  %d  %s\n", sum, len(data), mtime, filepath.Base(path))
+	f, err := os.OpenFile(filepath.Join(dir, manifestFileName), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.WriteString(line); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// readManifest parses dir's MANIFEST, in file order (oldest first). A
+// missing MANIFEST is not an error - it just means no writes through
+// AtomicWriter have happened in dir yet.
+func readManifest(dir string) ([]ManifestEntry, error) {
+	b, err := os.ReadFile(filepath.Join(dir, manifestFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []ManifestEntry
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 4 {
+			continue
+		}
+		size, _ := strconv.ParseInt(fields[1], 10, 64)
+		mtime, _ := strconv.ParseInt(fields[2], 10, 64)
+		entries = append(entries, ManifestEntry{SHA256: fields[0], Size: size, Mtime: mtime, Name: fields[3]})
+	}
+	return entries, nil
+}
+
+// latestManifestEntries reduces dir's append-only MANIFEST to the most
+// recent entry per filename, keyed by name - a file rewritten through
+// MergeUpdatesForCID accumulates one MANIFEST line per write, and only the
+// last one reflects its current on-disk content.
+func latestManifestEntries(dir string) (map[string]ManifestEntry, error) {
+	entries, err := readManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	latest := make(map[string]ManifestEntry, len(entries))
+	for _, e := range entries {
+		latest[e.Name] = e
+	}
+	return latest, nil
+}
+
+// Verify recomputes path's SHA-256 and compares it against the most
+// recent MANIFEST entry for its name in path's directory.
+func (w *AtomicWriter) Verify(path string) error {
+	dir := filepath.Dir(path)
+	lock := lockForDir(dir)
+	lock.Lock()
+	defer lock.Unlock()
+	latest, err := latestManifestEntries(dir)
+	if err != nil {
+		return fmt.Errorf("read manifest: %w", err)
+	}
+	return verifyAgainst(path, latest[filepath.Base(path)], latest)
+}
+
+// verifyAgainst checks path's current content against entry, which the
+// caller must already have looked up (via latest[filepath.Base(path)]).
+// Factored out so VerifyAll can check every manifested file in a
+// directory against one manifest read instead of one per file.
+func verifyAgainst(path string, entry ManifestEntry, latest map[string]ManifestEntry) error {
+	name := filepath.Base(path)
+	if _, ok := latest[name]; !ok {
+		return fmt.Errorf("no manifest entry for %s", name)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", path, err)
+	}
+	sum := sha256.Sum256(data)
+	got := fmt.Sprintf("%x", sum)
+	if got != entry.SHA256 {
+		return fmt.Errorf("checksum mismatch for %s: manifest=%s actual=%s", name, entry.SHA256, got)
+	}
+	return nil
+}
+
+// VerifyAll checks every file dir's MANIFEST has a record for, moving any
+// whose content no longer matches (or is missing/unreadable) into a
+// corrupt/ subdirectory of dir instead of leaving it where
+// StartSaveAudioCleaner or a caller might otherwise trip over it, and
+// returns the paths it quarantined. Used to give operators an offline
+// fsck: run VerifyAll across the save-audio directories and see what
+// rotted. Reads the MANIFEST once up front and reuses it for every file,
+// rather than the one-read-per-file a series of Verify calls would do.
+func (w *AtomicWriter) VerifyAll(dir string) ([]string, error) {
+	lock := lockForDir(dir)
+	lock.Lock()
+	defer lock.Unlock()
+	latest, err := latestManifestEntries(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	var quarantined []string
+	for name, entry := range latest {
+		path := filepath.Join(dir, name)
+		if verr := verifyAgainst(path, entry, latest); verr != nil {
+			qpath, qerr := quarantineFile(dir, path)
+			if qerr != nil {
+				logging.Warn("atomicwriter: quarantine failed", "path", path, "verify_err", verr, "quarantine_err", qerr)
+				continue
+			}
+			logging.Warn("atomicwriter: quarantined corrupt file", "path", path, "quarantined_to", qpath, "verify_err", verr)
+			quarantined = append(quarantined, qpath)
+		}
+	}
+	return quarantined, nil
+}
+
+// quarantineFile moves path into dir/corrupt, creating that subdirectory
+// if needed, and returns its new location.
+func quarantineFile(dir, path string) (string, error) {
+	qdir := filepath.Join(dir, "corrupt")
+	if err := os.MkdirAll(qdir, 0o755); err != nil {
+		return "", err
+	}
+	dest := filepath.Join(qdir, filepath.Base(path))
+	if err := os.Rename(path, dest); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
@@ -0,0 +1,74 @@
+package voice
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestAtomicWriterVerifyDetectsCorruption verifies that Verify passes for a
+// file untouched since Write, and fails once the file is overwritten
+// out-of-band (bypassing AtomicWriter, simulating on-disk bit rot or a
+// careless external edit).
+func TestAtomicWriterVerifyDetectsCorruption(t *testing.T) {
+	dir := t.TempDir()
+	w := NewAtomicWriter()
+	path := filepath.Join(dir, "clip.wav")
+
+	if err := w.Write(path, []byte("original audio bytes"), 0o644); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Verify(path); err != nil {
+		t.Fatalf("Verify on untouched file: %v", err)
+	}
+
+	// Corrupt the file directly, bypassing AtomicWriter, so its MANIFEST
+	// entry is now stale.
+	if err := os.WriteFile(path, []byte("corrupted!!"), 0o644); err != nil {
+		t.Fatalf("corrupt file: %v", err)
+	}
+	if err := w.Verify(path); err == nil {
+		t.Fatalf("want Verify to detect checksum mismatch after corruption, got nil error")
+	}
+}
+
+// TestAtomicWriterVerifyAllQuarantinesCorruptFiles verifies that VerifyAll
+// moves only the corrupted file into dir/corrupt, leaving an untouched
+// sibling file in place.
+func TestAtomicWriterVerifyAllQuarantinesCorruptFiles(t *testing.T) {
+	dir := t.TempDir()
+	w := NewAtomicWriter()
+	goodPath := filepath.Join(dir, "good.wav")
+	badPath := filepath.Join(dir, "bad.wav")
+
+	if err := w.Write(goodPath, []byte("good bytes"), 0o644); err != nil {
+		t.Fatalf("Write good: %v", err)
+	}
+	if err := w.Write(badPath, []byte("bytes that will be corrupted"), 0o644); err != nil {
+		t.Fatalf("Write bad: %v", err)
+	}
+	if err := os.WriteFile(badPath, []byte("tampered"), 0o644); err != nil {
+		t.Fatalf("corrupt bad file: %v", err)
+	}
+
+	quarantined, err := w.VerifyAll(dir)
+	if err != nil {
+		t.Fatalf("VerifyAll: %v", err)
+	}
+	if len(quarantined) != 1 {
+		t.Fatalf("want exactly 1 file quarantined, got %d: %v", len(quarantined), quarantined)
+	}
+	wantQuarantinePath := filepath.Join(dir, "corrupt", "bad.wav")
+	if quarantined[0] != wantQuarantinePath {
+		t.Fatalf("want quarantined path %s, got %s", wantQuarantinePath, quarantined[0])
+	}
+	if _, err := os.Stat(badPath); !os.IsNotExist(err) {
+		t.Fatalf("want bad.wav removed from its original location, stat err=%v", err)
+	}
+	if _, err := os.Stat(wantQuarantinePath); err != nil {
+		t.Fatalf("want bad.wav present at quarantine path: %v", err)
+	}
+	if _, err := os.Stat(goodPath); err != nil {
+		t.Fatalf("want good.wav left untouched: %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+package voice
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/discord-voice-lab/internal/logging"
+)
+
+// defaultZombieTimeout is the fallback interval of total voice-traffic
+// silence (no Opus packets on any SSRC) that marks a session zombied.
+// discordgo's VoiceConnection doesn't expose the underlying voice
+// gateway's HeartbeatInterval or a heartbeat-ack timestamp, so this can't
+// be derived as "2 x HeartbeatInterval + jitter" the way a from-scratch
+// voice gateway client could. It's deliberately long: Discord sends no
+// RTP at all while nobody in the channel is speaking, so an ordinary
+// conversational lull is ongoing silence, not a failure. This timer is
+// only the backstop for a connection that's stopped working *without*
+// OpusRecv ever closing; the common case - OpusRecv closing outright -
+// is caught immediately by receiveLoop calling signalDisconnected below,
+// with no timeout at all.
+const defaultZombieTimeout = 5 * time.Minute
+
+// zombieTimeoutFromEnv reads VOICE_ZOMBIE_TIMEOUT_SEC, falling back to
+// defaultZombieTimeout.
+func zombieTimeoutFromEnv() time.Duration {
+	if v := os.Getenv("VOICE_ZOMBIE_TIMEOUT_SEC"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return time.Duration(n) * time.Second
+		}
+	}
+	return defaultZombieTimeout
+}
+
+// ConnectionSupervisor watches one GuildSession's voice connection for two
+// failure modes discordgo doesn't surface on its own: (1) a "zombie"
+// session, where the underlying voice websocket has gone quiet (no Opus
+// traffic at all) without OpusRecv ever closing, and (2) a scheduled
+// reconnect set by HandleVoiceServerUpdate (a region migration, usually
+// resumable by simply rejoining the same channel). It replaces the
+// reconnect loop GuildSession used to run inline as its own supervise
+// method, adding zombie detection, an accumulator flush before
+// reconnecting so in-flight audio isn't lost, and full-jitter backoff
+// shared with the rest of this package's retry loops.
+//
+// Run picks between a resumable reconnect (leave the existing
+// *discordgo.VoiceConnection's session state in place) and a forced
+// non-resumable one (disconnect first to clear it) per failure mode - see
+// the resumable local in Run's reconnect switch for why that's the
+// finest-grained distinction discordgo's voice client exposes, short of a
+// real close-code-based branch.
+type ConnectionSupervisor struct {
+	gs            *GuildSession
+	zombieTimeout time.Duration
+
+	// disconnected carries an immediate wake-up from receiveLoop when
+	// OpusRecv closes on its own (the real Discord-side disconnect
+	// signal), so Run reconnects right away instead of waiting out
+	// zombieTimeout. Buffered 1: at most one outstanding signal matters.
+	disconnected chan struct{}
+
+	mu          sync.Mutex
+	lastTraffic time.Time
+}
+
+// NewConnectionSupervisor returns a ConnectionSupervisor for gs, with its
+// zombie timeout taken from VOICE_ZOMBIE_TIMEOUT_SEC (or
+// defaultZombieTimeout).
+func NewConnectionSupervisor(gs *GuildSession) *ConnectionSupervisor {
+	return &ConnectionSupervisor{gs: gs, zombieTimeout: zombieTimeoutFromEnv(), lastTraffic: time.Now(), disconnected: make(chan struct{}, 1)}
+}
+
+// signalDisconnected wakes Run immediately instead of waiting for the next
+// tick or the zombie timeout. Call when receiveLoop's OpusRecv channel
+// closes on its own, outside of a deliberate move()/shutdown().
+func (cs *ConnectionSupervisor) signalDisconnected() {
+	select {
+	case cs.disconnected <- struct{}{}:
+	default:
+	}
+}
+
+// recordTraffic marks now as the last time this session saw any Opus
+// traffic. Call on every packet receiveLoop hands off, so the zombie
+// timer only fires on genuine silence, not just a quiet speaker.
+func (cs *ConnectionSupervisor) recordTraffic() {
+	cs.mu.Lock()
+	cs.lastTraffic = time.Now()
+	cs.mu.Unlock()
+}
+
+func (cs *ConnectionSupervisor) sinceLastTraffic() time.Duration {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	return time.Since(cs.lastTraffic)
+}
+
+// Run watches gs for a scheduled reconnect (set by HandleVoiceServerUpdate)
+// or a zombied connection (no traffic for longer than zombieTimeout) until
+// ctx is cancelled, reconnecting with full-jitter exponential backoff
+// (fullJitterBackoff with a 1s base and 30s cap) on either condition.
+func (cs *ConnectionSupervisor) Run(ctx context.Context) {
+	gs := cs.gs
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	const baseBackoff = time.Second
+	const maxBackoff = 30 * time.Second
+	attempt := 0
+	for {
+		var gone bool
+		select {
+		case <-ctx.Done():
+			return
+		case <-cs.disconnected:
+			gone = true
+		case <-ticker.C:
+		}
+
+		gs.mu.Lock()
+		staleDue := !gs.staleAfter.IsZero() && time.Now().After(gs.staleAfter)
+		channelID := gs.channelID
+		closed := gs.closed
+		gs.mu.Unlock()
+		if closed {
+			return
+		}
+
+		zombied := !gone && cs.sinceLastTraffic() > cs.zombieTimeout
+		if !gone && !staleDue && !zombied {
+			attempt = 0
+			continue
+		}
+
+		// resumable distinguishes the two reconnect strategies available
+		// below: attempt gs.connect() with the existing *discordgo.VoiceConnection
+		// left in place (discordgo's ChannelVoiceJoin reuses it and its
+		// still-set session ID/token/endpoint, so its voice-gateway Identify
+		// carries the prior session - the closest thing to a resume
+		// discordgo's voice client exposes), versus forcing oldVC.Disconnect()
+		// first, which clears that state so the next connect() is a genuine
+		// cold rejoin. discordgo doesn't surface the voice websocket's close
+		// code (or implement the voice gateway's own Op 7 Resume at all -
+		// see vendor/github.com/bwmarrin/discordgo/voice.go), so there's no
+		// way to branch on invalid-session codes specifically; this
+		// retain-vs-clear split is the finest-grained distinction available
+		// without forking that dependency, and resumable is reported as a
+		// metrics label so this limitation is visible rather than silent.
+		var resumable bool
+		switch {
+		case gone:
+			// OpusRecv closing on its own doesn't tell us whether Discord
+			// invalidated the session or the socket just dropped, so this
+			// attempts a resumable reconnect first; if the retained session
+			// is no longer valid, Discord's voice gateway answers with a
+			// fresh READY instead of erroring, so there's no failure mode
+			// from guessing wrong here.
+			resumable = true
+			logging.Warn("voice connection closed unexpectedly, reconnecting", "guild_id", gs.GuildID, "channel_id", channelID, "resumable", resumable)
+			observeZombieDetected()
+			gs.Processor.flushExpiredAccums()
+		case zombied:
+			// No traffic at all for zombieTimeout despite OpusRecv staying
+			// open means the socket's gone bad without ever signaling a
+			// close - the one case worth forcing a cold rejoin rather than
+			// trusting a connection that never told us it was dead.
+			resumable = false
+			logging.Warn("voice session zombied, forcing reconnect", "guild_id", gs.GuildID, "channel_id", channelID, "silence", cs.sinceLastTraffic(), "resumable", resumable)
+			observeZombieDetected()
+			gs.Processor.flushExpiredAccums()
+			gs.mu.Lock()
+			oldVC := gs.vc
+			gs.mu.Unlock()
+			if oldVC != nil {
+				_ = oldVC.Disconnect()
+			}
+		default:
+			// A VoiceServerUpdate (region migration) hands us a fresh
+			// endpoint/token for the same session, so this is the case
+			// discordgo's reuse-the-existing-VoiceConnection path is built
+			// for - rejoining resumes rather than starting over.
+			resumable = true
+			logging.Warn("voice server update observed, reconnecting guild voice session", "guild_id", gs.GuildID, "channel_id", channelID, "resumable", resumable)
+		}
+
+		observeReconnectAttempt(resumable)
+		if err := gs.connect(); err != nil {
+			wait := fullJitterBackoff(baseBackoff, attempt, maxBackoff)
+			attempt++
+			logging.Warn("guild session reconnect failed, will retry", "guild_id", gs.GuildID, "err", err, "backoff", wait)
+			time.Sleep(wait)
+			continue
+		}
+		gs.Processor.SeedVoiceChannelMembers(gs.mgr.dg, gs.GuildID, channelID)
+		cs.recordTraffic()
+		attempt = 0
+		gs.mu.Lock()
+		gs.staleAfter = time.Time{}
+		gs.mu.Unlock()
+	}
+}
@@ -0,0 +1,154 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/discord-voice-lab/internal/logging"
+	"github.com/discord-voice-lab/internal/voice/llm"
+)
+
+// conversationStoreFromEnv builds the llm.ConversationStore backing
+// multi-turn memory across wake-phrase-triggered utterances.
+// CONVERSATION_STORE_BACKEND selects "memory" (default, lost on restart) or
+// "file" (persisted under saveAudioDir, see llm.FileConversationStore); with
+// saveAudioDir unset the latter still keeps history in memory but writes
+// nothing to disk, so it too is lost on restart. summarizer compresses turns
+// evicted once a key's history exceeds CONVERSATION_MAX_MESSAGES - pass nil
+// to just drop them instead.
+func conversationStoreFromEnv(saveAudioDir string, summarizer llm.Summarizer) llm.ConversationStore {
+	cfg := llm.ConversationConfig{
+		MaxMessages: 20,
+		TTL:         30 * time.Minute,
+		Summarizer:  summarizer,
+	}
+	if v := os.Getenv("CONVERSATION_MAX_MESSAGES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.MaxMessages = n
+		}
+	}
+	if v := os.Getenv("CONVERSATION_TTL_S"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.TTL = time.Duration(n) * time.Second
+		}
+	}
+	if strings.ToLower(strings.TrimSpace(os.Getenv("CONVERSATION_STORE_BACKEND"))) == "file" {
+		return llm.NewFileConversationStore(cfg, dispatcherQueuePath(saveAudioDir, "conversations.json"))
+	}
+	return llm.NewMemoryConversationStore(cfg)
+}
+
+// conversationSummarizer returns an llm.Summarizer that asks provider to
+// compress turns into a short recap, used as the system message standing in
+// for them once a conversation's history grows past CONVERSATION_MAX_MESSAGES.
+// provider == nil (no registered backend to ask) disables summarization:
+// conversationStoreFromEnv's caller should pass a nil Summarizer instead of
+// one that would only ever fail.
+func conversationSummarizer(provider llm.Provider) llm.Summarizer {
+	if provider == nil {
+		return nil
+	}
+	return func(ctx context.Context, turns []llm.StoredMessage) (llm.StoredMessage, error) {
+		var transcript strings.Builder
+		for _, t := range turns {
+			fmt.Fprintf(&transcript, "%s: %s\n", t.Role, t.Content)
+		}
+		req := llm.Request{
+			Messages: []llm.Message{
+				{Role: "system", Content: "Summarize the following conversation turns into a short recap a later turn can use as context. Be concise; keep names, decisions, and open questions."},
+				{Role: "user", Content: transcript.String()},
+			},
+		}
+		reply, err := provider.Generate(ctx, req)
+		if err != nil {
+			return llm.StoredMessage{}, err
+		}
+		return llm.StoredMessage{
+			Message:   llm.Message{Role: "system", Content: "Earlier conversation (summarized): " + strings.TrimSpace(reply.Content)},
+			Timestamp: time.Now(),
+		}, nil
+	}
+}
+
+// conversationKey scopes uid's history to the guild this Processor is
+// handling (set via SetEventRouter), matching conversation memory to the
+// same (guild, user) pair the orchestrator's own system-message tag
+// already identifies a turn by.
+func (p *Processor) conversationKey(uid string) llm.ConversationKey {
+	return llm.ConversationKey{GuildID: p.eventGuildID, UserID: uid}
+}
+
+// appendUserTurn records userContent as the latest user turn for uid, called
+// once per enqueued utterance (enqueueOrchestratorForward) rather than per
+// dispatch attempt, so a Dispatcher retry or fallback-chain entry reads the
+// same history instead of appending the turn again.
+func (p *Processor) appendUserTurn(uid, correlationID, userContent string) {
+	if p.conversationStore == nil {
+		return
+	}
+	msg := llm.StoredMessage{
+		Message:       llm.Message{Role: "user", Content: userContent},
+		Timestamp:     time.Now(),
+		CorrelationID: correlationID,
+	}
+	if err := p.conversationStore.Append(p.ctx, p.conversationKey(uid), msg); err != nil {
+		// Logged at Warn, not Debug: conversationMessages still appends
+		// userContent itself for this turn when the fetched window's last
+		// entry doesn't carry this correlationID, but a failed Append here
+		// leaves this utterance out of every later turn's history - worth
+		// surfacing by default, not just in verbose logs.
+		logging.Warn("conversation store: append user turn failed", "err", err, "correlation_id", correlationID)
+	}
+}
+
+// appendAssistantTurn records the orchestrator's reply for uid once a
+// fallback-chain attempt succeeds, so the next utterance's window includes
+// it.
+func (p *Processor) appendAssistantTurn(uid, correlationID, content string) {
+	if p.conversationStore == nil || strings.TrimSpace(content) == "" {
+		return
+	}
+	msg := llm.StoredMessage{
+		Message:       llm.Message{Role: "assistant", Content: content},
+		Timestamp:     time.Now(),
+		CorrelationID: correlationID,
+	}
+	if err := p.conversationStore.Append(p.ctx, p.conversationKey(uid), msg); err != nil {
+		logging.Debug("conversation store: append assistant turn failed", "err", err, "correlation_id", correlationID)
+	}
+}
+
+// conversationMessages returns uid's trimmed history (oldest first) as
+// []llm.Message, guaranteed to end with a user turn carrying userContent:
+// normally that's already the window's last entry, appended at enqueue time
+// by appendUserTurn, but if that Append silently failed (or the store
+// lookup here does), or this is uid's first turn, the window won't reflect
+// it yet - detected by checking the window's last entry's CorrelationID
+// rather than trusting "window non-empty" alone, so a returning user's
+// history doesn't mask a dropped latest utterance. Callers needing the raw
+// turns (to build a different wire shape than llm.Message, e.g. the
+// "openai" raw path's map[string]string) should use this rather than
+// re-deriving the same fallback logic themselves.
+func (p *Processor) conversationMessages(ctx context.Context, uid, correlationID, userContent string) []llm.Message {
+	var window []llm.StoredMessage
+	if p.conversationStore != nil {
+		w, err := p.conversationStore.Window(ctx, p.conversationKey(uid))
+		if err != nil {
+			logging.Warn("conversation store: window lookup failed", "err", err, "user_id", uid, "correlation_id", correlationID)
+		} else {
+			window = w
+		}
+	}
+	messages := make([]llm.Message, 0, len(window)+1)
+	for _, turn := range window {
+		messages = append(messages, turn.Message)
+	}
+	if len(window) == 0 || window[len(window)-1].CorrelationID != correlationID {
+		messages = append(messages, llm.Message{Role: "user", Content: userContent})
+	}
+	return messages
+}
@@ -23,7 +23,7 @@ func (p *Processor) handleOpusPacket(pkt opusPacket) {
 	n, err := p.dec.Decode(pkt.data, pcm)
 	if err != nil {
 		atomic.AddInt64(&p.decodeErrCount, 1)
-		logging.Errorw("opus decode error", "ssrc", ssrc, "err", err)
+		logging.Error("opus decode error", "ssrc", ssrc, "err", err)
 		return
 	}
 	samples := make([]int16, n)
@@ -116,7 +116,7 @@ func (p *Processor) flushAccum(ssrc uint32) {
 		}
 	}
 	if uid == "" {
-		logging.Warnw("dropping audio chunk with unknown user; not sending to STT", "ssrc", ssrc, "correlation_id", corrID)
+		logging.Warn("dropping audio chunk with unknown user; not sending to STT", "ssrc", ssrc, "correlation_id", corrID)
 		return
 	}
 	_ = p.sendPCMToWhisper(ssrc, pcmBytes.Bytes(), corrID, createdAt, uid, uname)
@@ -1,71 +1,234 @@
 package voice
 
 import (
-	"sync"
+	"context"
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/discord-voice-lab/internal/logging"
 )
 
+// discordResolver looks up human-friendly names for Discord IDs, backed by a
+// bounded LRU cache per kind (user/guild/channel) with negative caching for
+// hot misses, an optional on-disk snapshot so the cache survives restarts,
+// and discordgo event handlers that proactively invalidate stale entries.
 type discordResolver struct {
-	s  *discordgo.Session
-	mu sync.Mutex
-	// simple caches for users/guilds/channels: id -> (value, expiry)
-	userCache    map[string]cacheEntry
-	guildCache   map[string]cacheEntry
-	channelCache map[string]cacheEntry
+	s *discordgo.Session
+
+	userCache    *resolverLRU
+	guildCache   *resolverLRU
+	channelCache *resolverLRU
+
+	persistPath   string // "" disables persistence
+	flushInterval time.Duration
 }
 
-type cacheEntry struct {
-	val    string
-	expiry time.Time
+// cacheTTL controls how long a positive cache entry is valid.
+var cacheTTL = 5 * time.Minute
+
+// negativeCacheTTL controls how long a cached miss (e.g. a 404 from
+// Discord's REST API) is valid, short enough that a since-created
+// guild/channel/user shows up quickly but long enough that a hot 404 doesn't
+// spam the REST API every call.
+var negativeCacheTTL = 30 * time.Second
+
+// resolverCacheSnapshot is the on-disk shape of resolver_cache.json: one
+// unexpired-entries map per kind.
+type resolverCacheSnapshot struct {
+	User    map[string]resolverCacheEntry `json:"user"`
+	Guild   map[string]resolverCacheEntry `json:"guild"`
+	Channel map[string]resolverCacheEntry `json:"channel"`
 }
 
+// NewDiscordResolver builds a discordResolver backed by s. Cache sizing and
+// persistence are controlled by environment variables, matching how other
+// standalone components in this package (e.g. userRateLimiterFromEnv) are
+// configured:
+//
+//   - RESOLVER_CACHE_MAX_ENTRIES: max entries per kind (default 2000, the
+//     same cap applied to all three kinds since none of them are expected to
+//     dominate).
+//   - RESOLVER_CACHE_FLUSH_INTERVAL_S: how often Run persists a snapshot to
+//     disk (default 60s). Only takes effect when SAVE_AUDIO_DIR is set, same
+//     gating every other on-disk feature in this package uses.
+//
+// If a prior snapshot exists at SAVE_AUDIO_DIR/resolver_cache.json it's
+// loaded immediately so warm caches survive a restart.
 func NewDiscordResolver(s *discordgo.Session) *discordResolver {
-	return &discordResolver{
-		s:            s,
-		userCache:    make(map[string]cacheEntry),
-		guildCache:   make(map[string]cacheEntry),
-		channelCache: make(map[string]cacheEntry),
+	maxEntries := 2000
+	if v := os.Getenv("RESOLVER_CACHE_MAX_ENTRIES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			maxEntries = n
+		}
+	}
+	flushInterval := 60 * time.Second
+	if v := os.Getenv("RESOLVER_CACHE_FLUSH_INTERVAL_S"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			flushInterval = time.Duration(n) * time.Second
+		}
+	}
+
+	d := &discordResolver{
+		s:             s,
+		userCache:     newResolverLRU("user", maxEntries, cacheTTL, negativeCacheTTL),
+		guildCache:    newResolverLRU("guild", maxEntries, cacheTTL, negativeCacheTTL),
+		channelCache:  newResolverLRU("channel", maxEntries, cacheTTL, negativeCacheTTL),
+		flushInterval: flushInterval,
+	}
+	if dir := getSaveAudioDir(); dir != "" {
+		d.persistPath = strings.TrimRight(dir, "/") + "/resolver_cache.json"
+		d.load()
 	}
+	if s != nil {
+		d.registerHandlers()
+	}
+	return d
 }
 
-// cacheTTL controls how long a cached name is valid.
-var cacheTTL = 5 * time.Minute
+// registerHandlers subscribes to discordgo's GuildUpdate/ChannelUpdate/
+// UserUpdate events so a renamed guild, channel or user is invalidated (and,
+// where the event payload already carries the new name, refreshed) without
+// waiting for its TTL to lapse.
+func (d *discordResolver) registerHandlers() {
+	d.s.AddHandler(func(s *discordgo.Session, e *discordgo.GuildUpdate) {
+		if e == nil || e.Guild == nil {
+			return
+		}
+		d.guildCache.set(e.Guild.ID, e.Guild.Name, false)
+	})
+	d.s.AddHandler(func(s *discordgo.Session, e *discordgo.ChannelUpdate) {
+		if e == nil || e.Channel == nil {
+			return
+		}
+		d.channelCache.set(e.Channel.ID, e.Channel.Name, false)
+	})
+	d.s.AddHandler(func(s *discordgo.Session, e *discordgo.UserUpdate) {
+		if e == nil || e.User == nil {
+			return
+		}
+		d.userCache.set(e.User.ID, e.User.Username, false)
+	})
+	// Deletes can't be refreshed with a new name the way updates can, just
+	// invalidated so a later lookup (if the ID is ever reused, or the delete
+	// turns out to be a rename-via-recreate) isn't served a stale name.
+	d.s.AddHandler(func(s *discordgo.Session, e *discordgo.GuildDelete) {
+		if e == nil || e.Guild == nil {
+			return
+		}
+		d.Invalidate(e.Guild.ID)
+	})
+	d.s.AddHandler(func(s *discordgo.Session, e *discordgo.ChannelDelete) {
+		if e == nil || e.Channel == nil {
+			return
+		}
+		d.Invalidate(e.Channel.ID)
+	})
+}
 
-func (d *discordResolver) lookupCache(m map[string]cacheEntry, id string) (string, bool) {
-	if id == "" {
-		return "", false
+// Run persists a cache snapshot every flushInterval until ctx is canceled,
+// then persists once more on the way out. A no-op if persistence is
+// disabled (SAVE_AUDIO_DIR unset). Intended to be started with
+// `go resolver.Run(ctx)` by whatever owns the resolver's lifecycle, the same
+// convention outbound.Dispatcher.Run(ctx) uses.
+func (d *discordResolver) Run(ctx context.Context) {
+	if d.persistPath == "" {
+		return
 	}
-	if e, ok := m[id]; ok {
-		if time.Now().Before(e.expiry) {
-			return e.val, true
+	ticker := time.NewTicker(d.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			d.persist()
+			return
+		case <-ticker.C:
+			d.persist()
 		}
-		delete(m, id)
 	}
-	return "", false
 }
 
-func (d *discordResolver) setCache(m map[string]cacheEntry, id, val string) {
-	m[id] = cacheEntry{val: val, expiry: time.Now().Add(cacheTTL)}
+// Invalidate removes id from whichever cache(s) currently hold it. id isn't
+// tagged by kind, so all three are checked; a miss in two of them is cheap
+// compared to forcing callers to track which kind an ID belongs to.
+func (d *discordResolver) Invalidate(id string) {
+	if id == "" {
+		return
+	}
+	d.userCache.invalidate(id)
+	d.guildCache.invalidate(id)
+	d.channelCache.invalidate(id)
+}
+
+func (d *discordResolver) load() {
+	b, err := os.ReadFile(d.persistPath)
+	if err != nil {
+		return
+	}
+	var snap resolverCacheSnapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		logging.Warn("discord resolver: failed to parse cache snapshot", "path", d.persistPath, "err", err)
+		return
+	}
+	d.userCache.restore(snap.User)
+	d.guildCache.restore(snap.Guild)
+	d.channelCache.restore(snap.Channel)
+}
+
+func (d *discordResolver) persist() {
+	snap := resolverCacheSnapshot{
+		User:    d.userCache.snapshot(),
+		Guild:   d.guildCache.snapshot(),
+		Channel: d.channelCache.snapshot(),
+	}
+	b, err := json.Marshal(snap)
+	if err != nil {
+		logging.Warn("discord resolver: failed to marshal cache snapshot", "err", err)
+		return
+	}
+	tmp := d.persistPath + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		logging.Warn("discord resolver: failed to write cache snapshot", "path", tmp, "err", err)
+		return
+	}
+	if err := os.Rename(tmp, d.persistPath); err != nil {
+		logging.Warn("discord resolver: failed to rename cache snapshot", "tmp", tmp, "path", d.persistPath, "err", err)
+		_ = os.Remove(tmp)
+	}
+}
+
+// isNotFoundErr reports whether err represents Discord's REST API
+// affirmatively saying an ID doesn't exist (HTTP 404), as opposed to a
+// transient failure (network blip, rate limit, 5xx) that's worth retrying
+// on the very next call rather than negative-caching for negativeCacheTTL.
+func isNotFoundErr(err error) bool {
+	if err == nil {
+		return false
+	}
+	restErr, ok := err.(*discordgo.RESTError)
+	return ok && restErr.Response != nil && restErr.Response.StatusCode == 404
 }
 
 func (d *discordResolver) UserName(userID string) string {
 	if d.s == nil || userID == "" {
 		return ""
 	}
-	d.mu.Lock()
-	if v, ok := d.lookupCache(d.userCache, userID); ok {
-		d.mu.Unlock()
+	if v, negative, ok := d.userCache.get(userID); ok {
+		if negative {
+			return ""
+		}
 		return v
 	}
-	d.mu.Unlock()
-	if u, err := d.s.User(userID); err == nil && u != nil {
-		name := u.Username
-		d.mu.Lock()
-		d.setCache(d.userCache, userID, name)
-		d.mu.Unlock()
-		return name
+	u, err := d.s.User(userID)
+	if err == nil && u != nil {
+		d.userCache.set(userID, u.Username, false)
+		return u.Username
+	}
+	if isNotFoundErr(err) {
+		d.userCache.set(userID, "", true)
 	}
 	return ""
 }
@@ -74,27 +237,25 @@ func (d *discordResolver) GuildName(guildID string) string {
 	if d.s == nil || guildID == "" {
 		return ""
 	}
-	d.mu.Lock()
-	if v, ok := d.lookupCache(d.guildCache, guildID); ok {
-		d.mu.Unlock()
+	if v, negative, ok := d.guildCache.get(guildID); ok {
+		if negative {
+			return ""
+		}
 		return v
 	}
-	d.mu.Unlock()
 	if d.s.State != nil {
 		if g, err := d.s.State.Guild(guildID); err == nil && g != nil {
-			name := g.Name
-			d.mu.Lock()
-			d.setCache(d.guildCache, guildID, name)
-			d.mu.Unlock()
-			return name
+			d.guildCache.set(guildID, g.Name, false)
+			return g.Name
 		}
 	}
-	if g, err := d.s.Guild(guildID); err == nil && g != nil {
-		name := g.Name
-		d.mu.Lock()
-		d.setCache(d.guildCache, guildID, name)
-		d.mu.Unlock()
-		return name
+	g, err := d.s.Guild(guildID)
+	if err == nil && g != nil {
+		d.guildCache.set(guildID, g.Name, false)
+		return g.Name
+	}
+	if isNotFoundErr(err) {
+		d.guildCache.set(guildID, "", true)
 	}
 	return ""
 }
@@ -103,27 +264,25 @@ func (d *discordResolver) ChannelName(channelID string) string {
 	if d.s == nil || channelID == "" {
 		return ""
 	}
-	d.mu.Lock()
-	if v, ok := d.lookupCache(d.channelCache, channelID); ok {
-		d.mu.Unlock()
+	if v, negative, ok := d.channelCache.get(channelID); ok {
+		if negative {
+			return ""
+		}
 		return v
 	}
-	d.mu.Unlock()
 	if d.s.State != nil {
 		if c, err := d.s.State.Channel(channelID); err == nil && c != nil {
-			name := c.Name
-			d.mu.Lock()
-			d.setCache(d.channelCache, channelID, name)
-			d.mu.Unlock()
-			return name
+			d.channelCache.set(channelID, c.Name, false)
+			return c.Name
 		}
 	}
-	if c, err := d.s.Channel(channelID); err == nil && c != nil {
-		name := c.Name
-		d.mu.Lock()
-		d.setCache(d.channelCache, channelID, name)
-		d.mu.Unlock()
-		return name
+	c, err := d.s.Channel(channelID)
+	if err == nil && c != nil {
+		d.channelCache.set(channelID, c.Name, false)
+		return c.Name
+	}
+	if isNotFoundErr(err) {
+		d.channelCache.set(channelID, "", true)
 	}
 	return ""
 }
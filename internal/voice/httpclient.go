@@ -0,0 +1,178 @@
+package voice
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/discord-voice-lab/internal/logging"
+	"github.com/discord-voice-lab/internal/voice/outbound"
+)
+
+// postBreakers holds one outbound.CircuitBreaker per destination URL, keyed
+// so that every PostWithRetries caller in the package - sendOrchestratorJob's
+// raw "openai" request and the MCP tool-call loop alike - shares the same
+// failure signal for a given host instead of each keeping its own view of
+// whether that host is healthy.
+var (
+	postBreakersMu sync.Mutex
+	postBreakers   = map[string]*outbound.CircuitBreaker{}
+)
+
+// postBreakerFor returns the shared breaker for url, creating it with the
+// same tolerances breakerConfigFromEnv defaults to (50% failure ratio over
+// at least 5 samples, 30s cooldown) on first use.
+func postBreakerFor(url string) *outbound.CircuitBreaker {
+	postBreakersMu.Lock()
+	defer postBreakersMu.Unlock()
+	b, ok := postBreakers[url]
+	if !ok {
+		b = outbound.NewCircuitBreaker(0.5, 5, 30*time.Second)
+		postBreakers[url] = b
+	}
+	return b
+}
+
+// PostStats is one destination URL's circuit breaker state.
+type PostStats struct {
+	URL   string `json:"url"`
+	State string `json:"state"`
+}
+
+// Stats returns the current breaker state for every URL PostWithRetries has
+// been called against in this process, for admin/debug inspection.
+func Stats() []PostStats {
+	postBreakersMu.Lock()
+	defer postBreakersMu.Unlock()
+	out := make([]PostStats, 0, len(postBreakers))
+	for url, b := range postBreakers {
+		out = append(out, PostStats{URL: url, State: b.State().String()})
+	}
+	return out
+}
+
+// isPermanentStatus reports whether status indicates a request that retrying
+// unmodified won't fix: any 4xx except 408 (request timeout), 425 (too
+// early) and 429 (rate limited), all three of which are worth another try.
+func isPermanentStatus(status int) bool {
+	if status < 400 || status >= 500 {
+		return false
+	}
+	switch status {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests:
+		return false
+	}
+	return true
+}
+
+// postBaseBackoff and postMaxBackoff bound PostWithRetries' full-jitter
+// sleep between attempts.
+const (
+	postBaseBackoff = 200 * time.Millisecond
+	postMaxBackoff  = 10 * time.Second
+)
+
+// fullJitterBackoff implements the "full jitter" strategy from
+// https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/:
+// sleep = rand(0, min(max, base*2^attempt)). Same approach
+// outbound.fullJitterBackoff takes for queued job retries, reimplemented
+// here rather than exported from outbound since the two operate on
+// different attempt counters (in-process retry loop vs. across Dispatcher
+// ticks) and have no other reason to share call sites.
+func fullJitterBackoff(base time.Duration, attempt int, max time.Duration) time.Duration {
+	d := base * time.Duration(uint64(1)<<uint(attempt))
+	if d <= 0 || d > max {
+		d = max
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// PostWithRetries posts JSON to url with error classification, full-jitter
+// backoff, and a per-URL circuit breaker (see postBreakerFor) shared across
+// every call site in this package. A permanent client error (4xx other than
+// 408/425/429) is returned immediately without retrying or affecting the
+// breaker - retrying the same request won't help, and it isn't evidence the
+// destination host is unhealthy. A transient failure (network error,
+// timeout, 5xx, 408/425/429) is retried with backoff, up to attempts tries in
+// all, and counts against the breaker; an already-open breaker fails the call before
+// a request is ever attempted. Caller must close resp.Body on success.
+//
+// ctx bounds every attempt in addition to timeoutMs, so a caller canceling
+// ctx (e.g. during shutdown) aborts an in-flight attempt immediately instead
+// of waiting out the full per-attempt timeout. Pass context.Background() if
+// the call has no narrower context to bound it with.
+//
+// headers is set on every attempt after Content-Type/Authorization, so it
+// can't be used to override either of those; pass nil when a caller has
+// nothing extra to send (every pre-existing call site does).
+func PostWithRetries(ctx context.Context, client *http.Client, url string, body []byte, authToken string, timeoutMs int, attempts int, correlationID string, headers map[string]string) (*http.Response, error) {
+	if attempts <= 0 {
+		attempts = 1
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	breaker := postBreakerFor(url)
+	if !breaker.Allow() {
+		return nil, fmt.Errorf("postWithRetries: circuit open for %s", url)
+	}
+
+	var lastErr error
+	for i := 0; i < attempts; i++ {
+		ctxReq, cancelReq := context.WithTimeout(ctx, time.Duration(timeoutMs)*time.Millisecond)
+		req, rerr := http.NewRequestWithContext(ctxReq, "POST", url, bytes.NewReader(body))
+		if rerr != nil {
+			cancelReq()
+			return nil, rerr
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if authToken != "" {
+			req.Header.Set("Authorization", "Bearer "+authToken)
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+
+		c := client
+		if c == nil {
+			c = &http.Client{Timeout: time.Duration(timeoutMs) * time.Millisecond}
+		}
+		resp, err := c.Do(req)
+		cancelReq()
+
+		if err != nil {
+			logging.Debug("postWithRetries: POST attempt failed", "attempt", i+1, "err", err, "correlation_id", correlationID)
+			lastErr = err
+			if i == attempts-1 {
+				breaker.RecordResult(false)
+				return nil, err
+			}
+			time.Sleep(fullJitterBackoff(postBaseBackoff, i, postMaxBackoff))
+			continue
+		}
+
+		if isPermanentStatus(resp.StatusCode) {
+			return resp, nil
+		}
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout ||
+			resp.StatusCode == http.StatusTooEarly || resp.StatusCode == http.StatusTooManyRequests {
+			if i == attempts-1 {
+				breaker.RecordResult(false)
+				return resp, nil
+			}
+			logging.Debug("postWithRetries: POST attempt returned retryable status", "attempt", i+1, "status", resp.StatusCode, "correlation_id", correlationID)
+			resp.Body.Close()
+			lastErr = fmt.Errorf("postWithRetries: status %d", resp.StatusCode)
+			time.Sleep(fullJitterBackoff(postBaseBackoff, i, postMaxBackoff))
+			continue
+		}
+
+		breaker.RecordResult(true)
+		return resp, nil
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,252 @@
+package voice
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/discord-voice-lab/internal/logging"
+)
+
+// Clock abstracts time.Now so Limiter's token buckets can be driven by a
+// fake clock in tests instead of wall-clock time.
+type Clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// LimiterConfig configures Limiter's token buckets and coalescing queue.
+type LimiterConfig struct {
+	// PerUserRPS/PerUserBurst size the token bucket keyed on (userID, ssrc).
+	// A zero PerUserRPS disables per-user dispatch limiting.
+	PerUserRPS   float64
+	PerUserBurst float64
+	// GlobalRPS/GlobalBurst size the single bucket shared across every
+	// (userID, ssrc) pair, capping total STT dispatch QPS regardless of how
+	// many users are talking at once. A zero GlobalRPS disables it.
+	GlobalRPS   float64
+	GlobalBurst float64
+	// QueueCap bounds how many coalesced dispatches are held per user while
+	// either bucket is exhausted; once a user's queue exceeds it, the
+	// oldest queued dispatch is dropped (with a warn log) to make room.
+	// Zero disables coalescing: an exhausted dispatch is dropped immediately
+	// instead of queued.
+	QueueCap int
+}
+
+// LimiterConfigFromEnv builds a LimiterConfig from STT_DISPATCH_RPS,
+// STT_DISPATCH_BURST, STT_DISPATCH_GLOBAL_RPS, STT_DISPATCH_GLOBAL_BURST and
+// STT_DISPATCH_QUEUE_CAP. Burst values default to their RPS counterpart when
+// unset, matching userRateLimiterFromEnv's STT_USER_* convention.
+func LimiterConfigFromEnv() LimiterConfig {
+	cfg := LimiterConfig{}
+	if v := os.Getenv("STT_DISPATCH_RPS"); v != "" {
+		if n, err := strconv.ParseFloat(v, 64); err == nil && n >= 0 {
+			cfg.PerUserRPS = n
+		}
+	}
+	cfg.PerUserBurst = cfg.PerUserRPS
+	if v := os.Getenv("STT_DISPATCH_BURST"); v != "" {
+		if n, err := strconv.ParseFloat(v, 64); err == nil && n >= 0 {
+			cfg.PerUserBurst = n
+		}
+	}
+	if v := os.Getenv("STT_DISPATCH_GLOBAL_RPS"); v != "" {
+		if n, err := strconv.ParseFloat(v, 64); err == nil && n >= 0 {
+			cfg.GlobalRPS = n
+		}
+	}
+	cfg.GlobalBurst = cfg.GlobalRPS
+	if v := os.Getenv("STT_DISPATCH_GLOBAL_BURST"); v != "" {
+		if n, err := strconv.ParseFloat(v, 64); err == nil && n >= 0 {
+			cfg.GlobalBurst = n
+		}
+	}
+	cfg.QueueCap = 20
+	if v := os.Getenv("STT_DISPATCH_QUEUE_CAP"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.QueueCap = n
+		}
+	}
+	return cfg
+}
+
+type limiterKey struct {
+	userID string
+	ssrc   uint32
+}
+
+// refill tops up b's tokens based on elapsed time since last, at rps tokens
+// per second, capped at burst. tokenBucket itself is declared in
+// ratelimit.go and shared between userRateLimiter and Limiter.
+func (b *tokenBucket) refill(now time.Time, rps, burst float64) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens += elapsed * rps
+		if b.tokens > burst {
+			b.tokens = burst
+		}
+		b.last = now
+	}
+}
+
+// queuedDispatch is one coalesced flushAccum call waiting for Limiter
+// tokens to free up; send performs the actual sendPCMToWhisper call with
+// everything it needs already captured in its closure.
+type queuedDispatch struct {
+	ssrc  uint32
+	bytes int
+	send  func()
+}
+
+// Limiter token-bucket rate limits STT dispatch per (userID, ssrc), plus a
+// single global bucket capping total dispatch QPS across all users, so a
+// noisy user or a stuck VAD condition can't flood the STT backend. Unlike
+// userRateLimiter (which just drops over-quota requests), Limiter coalesces:
+// a dispatch that arrives while tokens are exhausted is held in a bounded
+// per-user queue and released by DrainAll once tokens are available, so a
+// burst doesn't lose speech mid-utterance. A nil *Limiter is valid and
+// behaves as if rate limiting were disabled.
+type Limiter struct {
+	cfg   LimiterConfig
+	clock Clock
+
+	mu      sync.Mutex
+	buckets map[limiterKey]*tokenBucket
+	global  *tokenBucket
+	queues  map[string][]queuedDispatch // keyed by userID, FIFO per user
+}
+
+// NewLimiter returns a Limiter configured by cfg. clock is used for all
+// token-bucket timing; pass nil to use the real wall clock (tests inject a
+// fake Clock to exercise refill/expiry deterministically).
+func NewLimiter(cfg LimiterConfig, clock Clock) *Limiter {
+	if clock == nil {
+		clock = realClock{}
+	}
+	l := &Limiter{
+		cfg:     cfg,
+		clock:   clock,
+		buckets: make(map[limiterKey]*tokenBucket),
+		queues:  make(map[string][]queuedDispatch),
+	}
+	if cfg.GlobalRPS > 0 {
+		l.global = &tokenBucket{tokens: cfg.GlobalBurst, last: clock.Now()}
+	}
+	return l
+}
+
+// Submit applies the per-user and global token buckets to a dispatch of
+// nBytes PCM bytes for (userID, ssrc). If tokens are available it consumes
+// them and calls send immediately. Otherwise it coalesces: send is queued
+// and invoked later by DrainAll once tokens free up, unless userID's queue
+// is already at QueueCap, in which case the oldest queued send is dropped
+// (never invoked, with a warn log) to make room - losing the start of a
+// long utterance is preferable to losing all of it.
+func (l *Limiter) Submit(userID string, ssrc uint32, nBytes int, send func()) {
+	if l == nil {
+		send()
+		return
+	}
+
+	l.mu.Lock()
+	if l.allowLocked(userID, ssrc) {
+		l.mu.Unlock()
+		send()
+		return
+	}
+
+	sttRatelimitedTotal.Inc()
+	if l.cfg.QueueCap <= 0 {
+		l.mu.Unlock()
+		logging.Warn("stt dispatch dropped: rate limited and coalescing disabled", "user_id", userID, "ssrc", ssrc, "bytes", nBytes)
+		return
+	}
+
+	q := l.queues[userID]
+	if len(q) >= l.cfg.QueueCap {
+		dropped := q[0]
+		q = q[1:]
+		logging.Warn("stt dispatch queue full, dropping oldest coalesced chunk", "user_id", userID, "ssrc", dropped.ssrc, "bytes_dropped", dropped.bytes, "queue_cap", l.cfg.QueueCap)
+	}
+	q = append(q, queuedDispatch{ssrc: ssrc, bytes: nBytes, send: send})
+	l.queues[userID] = q
+	sttCoalescedBytes.Add(float64(nBytes))
+	l.mu.Unlock()
+}
+
+// allowLocked reports whether a dispatch for (userID, ssrc) may proceed
+// right now, consuming one token from both the per-user and global buckets
+// if so. l.mu must be held.
+func (l *Limiter) allowLocked(userID string, ssrc uint32) bool {
+	now := l.clock.Now()
+
+	var userBucket *tokenBucket
+	if l.cfg.PerUserRPS > 0 {
+		key := limiterKey{userID: userID, ssrc: ssrc}
+		userBucket = l.buckets[key]
+		if userBucket == nil {
+			userBucket = &tokenBucket{tokens: l.cfg.PerUserBurst, last: now}
+			l.buckets[key] = userBucket
+		}
+		userBucket.refill(now, l.cfg.PerUserRPS, l.cfg.PerUserBurst)
+		if userBucket.tokens < 1 {
+			return false
+		}
+	}
+	if l.global != nil {
+		l.global.refill(now, l.cfg.GlobalRPS, l.cfg.GlobalBurst)
+		if l.global.tokens < 1 {
+			return false
+		}
+	}
+
+	// Only spend tokens once both buckets have cleared, so a per-user pass
+	// immediately followed by a global fail doesn't burn a per-user token
+	// for a dispatch that didn't actually happen.
+	if userBucket != nil {
+		userBucket.tokens--
+	}
+	if l.global != nil {
+		l.global.tokens--
+	}
+	return true
+}
+
+// DrainAll releases as many queued dispatches as current tokens allow,
+// across every user with a non-empty queue. Callers should invoke it
+// periodically (Processor does so from its flushExpiredAccums ticker) so
+// coalesced audio is sent out as soon as capacity frees up rather than only
+// on the next Submit for that user.
+func (l *Limiter) DrainAll() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	var ready []func()
+	for userID, q := range l.queues {
+		i := 0
+		for i < len(q) && l.allowLocked(userID, q[i].ssrc) {
+			ready = append(ready, q[i].send)
+			i++
+		}
+		if i == len(q) {
+			delete(l.queues, userID)
+		} else if i > 0 {
+			l.queues[userID] = q[i:]
+		}
+	}
+	l.mu.Unlock()
+
+	// Invoke off the caller's goroutine (DrainAll is called from Processor's
+	// 100ms flush ticker) so a backlog of queued sends - each a blocking
+	// sendPCMToWhisper call with its own retry/backoff - can't starve the
+	// next tick's flushExpiredAccums.
+	for _, send := range ready {
+		go send()
+	}
+}
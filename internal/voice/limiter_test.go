@@ -0,0 +1,146 @@
+package voice
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually-advanced Clock, letting Limiter's token-bucket
+// refill logic be exercised deterministically instead of depending on real
+// elapsed wall-clock time between test statements.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock(start time.Time) *fakeClock {
+	return &fakeClock{now: start}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// TestLimiterSubmitConsumesBurstThenCoalesces verifies that Submit dispatches
+// immediately while burst tokens remain, then queues (rather than dropping)
+// once the bucket is exhausted, and that the queued send only fires once
+// DrainAll runs after the fake clock advances enough for a token to refill.
+func TestLimiterSubmitConsumesBurstThenCoalesces(t *testing.T) {
+	clock := newFakeClock(time.Unix(0, 0))
+	l := NewLimiter(LimiterConfig{PerUserRPS: 1, PerUserBurst: 1, QueueCap: 5}, clock)
+
+	var sent int32
+	send := func() { atomic.AddInt32(&sent, 1) }
+
+	// First dispatch consumes the single burst token immediately.
+	l.Submit("user-1", 1, 10, send)
+	if got := atomic.LoadInt32(&sent); got != 1 {
+		t.Fatalf("want first dispatch sent immediately, got sent=%d", got)
+	}
+
+	// Second dispatch arrives with no tokens left: it must coalesce, not
+	// send and not drop.
+	l.Submit("user-1", 1, 10, send)
+	if got := atomic.LoadInt32(&sent); got != 1 {
+		t.Fatalf("want second dispatch queued (not sent) while bucket empty, got sent=%d", got)
+	}
+
+	// DrainAll before the clock advances should release nothing: no tokens
+	// have refilled yet.
+	l.DrainAll()
+	if got := atomic.LoadInt32(&sent); got != 1 {
+		t.Fatalf("want DrainAll to release nothing before refill, got sent=%d", got)
+	}
+
+	// Advancing the fake clock by a full second refills exactly one token
+	// at PerUserRPS=1, which DrainAll should now hand to the queued send.
+	// DrainAll runs each released send on its own goroutine, so wait for it.
+	clock.Advance(time.Second)
+	l.DrainAll()
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&sent) < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := atomic.LoadInt32(&sent); got != 2 {
+		t.Fatalf("want queued dispatch released after refill, got sent=%d", got)
+	}
+}
+
+// TestLimiterQueueCapDropsOldest verifies that once a user's coalescing
+// queue is at QueueCap, Submit drops the oldest queued dispatch to make
+// room rather than growing the queue or dropping the newest one.
+func TestLimiterQueueCapDropsOldest(t *testing.T) {
+	clock := newFakeClock(time.Unix(0, 0))
+	// PerUserBurst=0 so the very first Submit already has no tokens,
+	// forcing every call to queue.
+	l := NewLimiter(LimiterConfig{PerUserRPS: 1, PerUserBurst: 0, QueueCap: 2}, clock)
+
+	var released []int
+	submit := func(id int) {
+		l.Submit("user-1", 1, id, func() { released = append(released, id) })
+	}
+
+	submit(1)
+	submit(2)
+	submit(3) // queue is at cap (2); this should drop dispatch 1, not 3.
+
+	l.mu.Lock()
+	q := l.queues["user-1"]
+	l.mu.Unlock()
+	if len(q) != 2 {
+		t.Fatalf("want queue capped at 2, got %d", len(q))
+	}
+	if q[0].bytes != 2 || q[1].bytes != 3 {
+		t.Fatalf("want oldest (id=1) dropped and [2,3] retained, got bytes=[%d,%d]", q[0].bytes, q[1].bytes)
+	}
+}
+
+// TestLimiterGlobalBucketGatesAcrossUsers verifies the shared global bucket
+// caps total dispatch QPS even when each individual user still has
+// available per-user tokens.
+func TestLimiterGlobalBucketGatesAcrossUsers(t *testing.T) {
+	clock := newFakeClock(time.Unix(0, 0))
+	l := NewLimiter(LimiterConfig{
+		PerUserRPS: 100, PerUserBurst: 100, // effectively unlimited per-user
+		GlobalRPS: 1, GlobalBurst: 1,
+		QueueCap: 5,
+	}, clock)
+
+	var sent int
+	send := func() { sent++ }
+
+	l.Submit("user-1", 1, 10, send)
+	if sent != 1 {
+		t.Fatalf("want first dispatch to consume the sole global token, got sent=%d", sent)
+	}
+
+	// A different user, with plenty of per-user tokens, should still be
+	// blocked by the exhausted global bucket.
+	l.Submit("user-2", 2, 10, send)
+	if sent != 1 {
+		t.Fatalf("want second user's dispatch blocked by global bucket, got sent=%d", sent)
+	}
+}
+
+// TestLimiterNilIsDisabled verifies a nil *Limiter (the "rate limiting
+// disabled" state used throughout Processor) sends immediately and ignores
+// DrainAll.
+func TestLimiterNilIsDisabled(t *testing.T) {
+	var l *Limiter
+	var sent bool
+	l.Submit("user-1", 1, 10, func() { sent = true })
+	if !sent {
+		t.Fatalf("want nil Limiter to send immediately")
+	}
+	l.DrainAll() // must not panic
+}
@@ -0,0 +1,111 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// anthropicDefaultVersion is the anthropic-version header value used when
+// ANTHROPIC_VERSION isn't set; bump alongside any Messages API schema change
+// this provider relies on.
+const anthropicDefaultVersion = "2023-06-01"
+
+// AnthropicProvider generates a reply via Anthropic's Messages API
+// (content[].text), which splits the leading "system" message out of the
+// messages array into its own top-level field rather than accepting a
+// system-role message.
+type AnthropicProvider struct {
+	Config  Config
+	Client  *http.Client
+	Version string
+}
+
+// NewAnthropicProvider builds an AnthropicProvider. client == nil uses a
+// Config.timeout()-bounded default client; version == "" uses
+// anthropicDefaultVersion.
+func NewAnthropicProvider(cfg Config, client *http.Client, version string) *AnthropicProvider {
+	if client == nil {
+		client = &http.Client{Timeout: cfg.timeout()}
+	}
+	if version == "" {
+		version = anthropicDefaultVersion
+	}
+	return &AnthropicProvider{Config: cfg, Client: client, Version: version}
+}
+
+func (p *AnthropicProvider) Name() string { return "anthropic" }
+
+func (p *AnthropicProvider) Generate(ctx context.Context, req Request) (Reply, error) {
+	model := req.Model
+	if model == "" {
+		model = p.Config.Model
+	}
+	var system string
+	var turns []Message
+	for _, m := range req.Messages {
+		if m.Role == "system" && system == "" {
+			system = m.Content
+			continue
+		}
+		turns = append(turns, m)
+	}
+	payload := map[string]interface{}{
+		"model":      model,
+		"max_tokens": 1024,
+		"messages":   chatMessages(turns),
+	}
+	if system != "" {
+		payload["system"] = system
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return Reply{}, fmt.Errorf("anthropic: marshal request: %w", err)
+	}
+	url := p.Config.URL
+	if url == "" {
+		url = "https://api.anthropic.com/v1/messages"
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(b))
+	if err != nil {
+		return Reply{}, fmt.Errorf("anthropic: new request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("anthropic-version", p.Version)
+	if p.Config.APIKey != "" {
+		httpReq.Header.Set("x-api-key", p.Config.APIKey)
+	}
+	resp, err := p.Client.Do(httpReq)
+	if err != nil {
+		return Reply{}, fmt.Errorf("anthropic: request failed: %w: %w", err, ErrTransient)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Reply{}, fmt.Errorf("anthropic: read response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return Reply{}, fmt.Errorf("anthropic: status %d: %s: %w", resp.StatusCode, truncate(body, 500), classifyStatus(resp.StatusCode))
+	}
+	var out struct {
+		Model   string `json:"model"`
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return Reply{}, fmt.Errorf("anthropic: unmarshal response: %w", err)
+	}
+	var text strings.Builder
+	for _, c := range out.Content {
+		if c.Type == "text" {
+			text.WriteString(c.Text)
+		}
+	}
+	return Reply{Content: text.String(), Model: out.Model}, nil
+}
@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// AzureOpenAIProvider generates a reply via an Azure OpenAI deployment. It
+// shares OpenAI's chat-completions request/response schema but authenticates
+// with an "api-key" header instead of a Bearer token, and the deployment
+// (which stands in for "model") is baked into Config.URL rather than sent in
+// the request body.
+type AzureOpenAIProvider struct {
+	Config Config
+	Client *http.Client
+}
+
+// NewAzureOpenAIProvider builds an AzureOpenAIProvider. client == nil uses a
+// Config.timeout()-bounded default client.
+func NewAzureOpenAIProvider(cfg Config, client *http.Client) *AzureOpenAIProvider {
+	if client == nil {
+		client = &http.Client{Timeout: cfg.timeout()}
+	}
+	return &AzureOpenAIProvider{Config: cfg, Client: client}
+}
+
+func (p *AzureOpenAIProvider) Name() string { return "azureopenai" }
+
+func (p *AzureOpenAIProvider) Generate(ctx context.Context, req Request) (Reply, error) {
+	payload := map[string]interface{}{
+		"messages": chatMessages(req.Messages),
+		"stream":   false,
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return Reply{}, fmt.Errorf("azureopenai: marshal request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.Config.URL, bytes.NewReader(b))
+	if err != nil {
+		return Reply{}, fmt.Errorf("azureopenai: new request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.Config.APIKey != "" {
+		httpReq.Header.Set("api-key", p.Config.APIKey)
+	}
+	resp, err := p.Client.Do(httpReq)
+	if err != nil {
+		return Reply{}, fmt.Errorf("azureopenai: request failed: %w: %w", err, ErrTransient)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Reply{}, fmt.Errorf("azureopenai: read response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return Reply{}, fmt.Errorf("azureopenai: status %d: %s: %w", resp.StatusCode, truncate(body, 500), classifyStatus(resp.StatusCode))
+	}
+	var out struct {
+		Model   string `json:"model"`
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return Reply{}, fmt.Errorf("azureopenai: unmarshal response: %w", err)
+	}
+	if len(out.Choices) == 0 {
+		return Reply{}, fmt.Errorf("azureopenai: response had no choices")
+	}
+	return Reply{Content: out.Choices[0].Message.Content, Model: out.Model}, nil
+}
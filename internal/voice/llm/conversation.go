@@ -0,0 +1,69 @@
+package llm
+
+import (
+	"context"
+	"time"
+)
+
+// ConversationKey identifies one multi-turn conversation a ConversationStore
+// tracks history for: the Discord guild and user the turns belong to. UserID
+// is the same SSRC-mapped Discord user ID voice.Processor already threads
+// through Request.CorrelationID-adjacent logging elsewhere; GuildID may be
+// empty for a Processor that isn't tracking one (SetEventRouter was never
+// called), in which case UserID alone still scopes the conversation.
+type ConversationKey struct {
+	GuildID string
+	UserID  string
+}
+
+// StoredMessage is one turn as persisted by a ConversationStore: a Message
+// plus the bookkeeping a store needs to enforce its TTL and to log/debug a
+// history entry back to the request that produced it. Request.Messages
+// stays a plain []Message - the wire shape every backend's chatMessages
+// marshals - so this wraps rather than extends it.
+type StoredMessage struct {
+	Message
+	Timestamp     time.Time
+	CorrelationID string
+}
+
+// Summarizer compresses an ordered slice of the oldest turns being evicted
+// from a window into a single "system" StoredMessage standing in for them,
+// so a long-running conversation's context keeps shrinking back down
+// instead of growing without bound. Implementations typically call a
+// Provider.Generate with a "summarize this" prompt - which can take a
+// while, so a ConversationStore must not call it while holding a lock other
+// Append/Window calls need. A nil Summarizer means overflow turns are
+// simply dropped instead of compressed.
+type Summarizer func(ctx context.Context, turns []StoredMessage) (StoredMessage, error)
+
+// ConversationConfig bounds how much history a ConversationStore keeps for
+// any one ConversationKey.
+type ConversationConfig struct {
+	// MaxMessages is the most turns a key keeps after each Append, oldest
+	// first. 0 means unlimited. Once a key's history grows past this, the
+	// oldest turns are removed from it and handed to Summarizer (if set,
+	// async) or dropped outright (if not). A summary is prepended to the kept
+	// turns once Summarizer completes, so Window can then return one entry
+	// more than MaxMessages; until then it returns the kept turns alone.
+	MaxMessages int
+	// TTL evicts a turn once it's older than this, independent of
+	// MaxMessages. 0 means turns never expire by age.
+	TTL        time.Duration
+	Summarizer Summarizer
+}
+
+// ConversationStore persists per-user chat history across turns so
+// voice.Processor can send the orchestrator real multi-message context
+// instead of a single fresh two-message prompt every utterance. Append and
+// Window are expected to be safe for concurrent use by multiple goroutines
+// (one per in-flight conversation turn).
+type ConversationStore interface {
+	// Append adds msg to key's history.
+	Append(ctx context.Context, key ConversationKey, msg StoredMessage) error
+	// Window returns key's history, oldest first, trimmed to the store's
+	// ConversationConfig (and summarized, if configured).
+	Window(ctx context.Context, key ConversationKey) ([]StoredMessage, error)
+	// Reset discards key's entire history.
+	Reset(ctx context.Context, key ConversationKey) error
+}
@@ -0,0 +1,126 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// FileConversationStore is the durable ConversationStore: history survives a
+// process restart, persisted as a single JSON snapshot file. Swapping in a
+// real embedded database later only means a new ConversationStore
+// implementation; the interface and voice.Processor's use of it don't
+// change.
+//
+// One gap from that stand-in: MemoryConversationStore.summarizeAsync writes
+// a completed summary directly into the shared in-memory history, with no
+// way to reach back into this wrapper's persist() afterward. A process
+// restart between a summarization completing and this key's next Append
+// loses the summary (falling back to the dropped-overflow behavior on
+// reload, not data corruption) - acceptable for now since it only affects
+// how much old context survives a restart, not correctness of what's kept.
+type FileConversationStore struct {
+	mem *MemoryConversationStore
+
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileConversationStore returns a FileConversationStore bounded by cfg
+// and persisting to path, loading any existing snapshot so history survives
+// a restart. path == "" disables persistence (history still works, just
+// in-memory only for the process's lifetime).
+func NewFileConversationStore(cfg ConversationConfig, path string) *FileConversationStore {
+	s := &FileConversationStore{
+		mem:  NewMemoryConversationStore(cfg),
+		path: path,
+	}
+	if path == "" {
+		return s
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return s
+	}
+	var entries []fileSnapshotEntry
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return s
+	}
+	for _, e := range entries {
+		// Trim on load the same way Append does (TTL, then MaxMessages),
+		// since the config loaded under may differ from whatever wrote
+		// this snapshot (a lowered CONVERSATION_MAX_MESSAGES/CONVERSATION_TTL_S
+		// between deploys, or a snapshot from before the process was last
+		// down for longer than TTL). Overflow turns are dropped rather than
+		// summarized: summarizing would mean a Provider.Generate call
+		// (i.e. a network round trip) inline in this constructor, which
+		// every other caller in this package expects to be cheap and
+		// synchronous.
+		kept, _ := s.mem.capByCount(s.mem.trimByAge(e.Turns))
+		s.mem.history[e.Key] = kept
+	}
+	return s
+}
+
+// fileSnapshotEntry is one ConversationKey's history as persisted. A plain
+// map[ConversationKey][]StoredMessage can't round-trip through
+// encoding/json (struct map keys aren't supported), so the snapshot is a
+// slice of (key, turns) pairs instead.
+type fileSnapshotEntry struct {
+	Key   ConversationKey `json:"key"`
+	Turns []StoredMessage `json:"turns"`
+}
+
+// Append adds msg to key's history and persists the updated snapshot.
+func (s *FileConversationStore) Append(ctx context.Context, key ConversationKey, msg StoredMessage) error {
+	if err := s.mem.Append(ctx, key, msg); err != nil {
+		return err
+	}
+	return s.persist()
+}
+
+// Window returns key's history as currently trimmed.
+func (s *FileConversationStore) Window(ctx context.Context, key ConversationKey) ([]StoredMessage, error) {
+	return s.mem.Window(ctx, key)
+}
+
+// Reset discards key's history and persists the updated snapshot.
+func (s *FileConversationStore) Reset(ctx context.Context, key ConversationKey) error {
+	if err := s.mem.Reset(ctx, key); err != nil {
+		return err
+	}
+	return s.persist()
+}
+
+// persist writes the full history snapshot to s.path via the tmp+rename
+// pattern used elsewhere in this module (sidecar.go, sidecarIndex) so a
+// crash mid-write can't leave a truncated snapshot behind.
+func (s *FileConversationStore) persist() error {
+	if s.path == "" {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.mem.mu.Lock()
+	entries := make([]fileSnapshotEntry, 0, len(s.mem.history))
+	for key, turns := range s.mem.history {
+		entries = append(entries, fileSnapshotEntry{Key: key, Turns: turns})
+	}
+	s.mem.mu.Unlock()
+
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("conversation store: marshal snapshot: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return fmt.Errorf("conversation store: write tmp: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("conversation store: rename tmp: %w", err)
+	}
+	return nil
+}
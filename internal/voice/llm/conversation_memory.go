@@ -0,0 +1,111 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryConversationStore is the default ConversationStore: per-key history
+// lives only in this process's memory and is lost on restart, same
+// trade-off the rest of this package's caller (voice.Processor) already
+// accepts for in-flight state like the wake-phrase window. NewMemoryConversationStore
+// is the constructor; the zero value is not usable (history map is nil).
+type MemoryConversationStore struct {
+	cfg ConversationConfig
+
+	mu      sync.Mutex
+	history map[ConversationKey][]StoredMessage
+}
+
+// NewMemoryConversationStore returns a MemoryConversationStore bounded by
+// cfg (zero value: unlimited turns, no TTL, no summarization).
+func NewMemoryConversationStore(cfg ConversationConfig) *MemoryConversationStore {
+	return &MemoryConversationStore{
+		cfg:     cfg,
+		history: make(map[ConversationKey][]StoredMessage),
+	}
+}
+
+// Append adds msg to key's history, then applies cfg's TTL/MaxMessages
+// trimming so a conversation that's never read back via Window still can't
+// grow key's history without bound. Any turns trimmed off for exceeding
+// MaxMessages are handed to cfg.Summarizer, if configured, on a background
+// goroutine rather than inline: Summarizer typically makes a network call
+// to an LLM, which must not run while s.mu is held or it would serialize
+// every other key's Append/Window behind it.
+func (s *MemoryConversationStore) Append(ctx context.Context, key ConversationKey, msg StoredMessage) error {
+	s.mu.Lock()
+	turns := s.trimByAge(append(s.history[key], msg))
+	kept, overflow := s.capByCount(turns)
+	s.history[key] = kept
+	s.mu.Unlock()
+
+	if len(overflow) > 0 && s.cfg.Summarizer != nil {
+		go s.summarizeAsync(key, overflow)
+	}
+	return nil
+}
+
+// summarizeAsync runs cfg.Summarizer over overflow and prepends the result
+// to key's current history. Uses context.Background() rather than the
+// triggering Append's ctx, which may already be canceled (e.g. a job's
+// context, done once that job returns) by the time this goroutine runs. A
+// Summarizer failure just means overflow stays dropped, same as having no
+// Summarizer at all - logged by the caller that constructed it, not here,
+// since this package has no logger of its own (see provider.go's doc
+// comment on staying pure).
+func (s *MemoryConversationStore) summarizeAsync(key ConversationKey, overflow []StoredMessage) {
+	summary, err := s.cfg.Summarizer(context.Background(), overflow)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history[key] = append([]StoredMessage{summary}, s.history[key]...)
+}
+
+// Window returns key's history as currently trimmed.
+func (s *MemoryConversationStore) Window(ctx context.Context, key ConversationKey) ([]StoredMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	turns := s.history[key]
+	out := make([]StoredMessage, len(turns))
+	copy(out, turns)
+	return out, nil
+}
+
+// Reset discards key's history entirely.
+func (s *MemoryConversationStore) Reset(ctx context.Context, key ConversationKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.history, key)
+	return nil
+}
+
+// trimByAge drops turns older than cfg.TTL (a no-op if TTL is 0). Called
+// with s.mu held.
+func (s *MemoryConversationStore) trimByAge(turns []StoredMessage) []StoredMessage {
+	if s.cfg.TTL <= 0 {
+		return turns
+	}
+	cutoff := time.Now().Add(-s.cfg.TTL)
+	kept := turns[:0:0]
+	for _, t := range turns {
+		if t.Timestamp.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
+
+// capByCount splits turns at cfg.MaxMessages (a no-op, no overflow, if 0 or
+// under the cap), oldest-first: kept is what Window should return now,
+// overflow is what Append should hand to cfg.Summarizer. Called with s.mu
+// held.
+func (s *MemoryConversationStore) capByCount(turns []StoredMessage) (kept, overflow []StoredMessage) {
+	if s.cfg.MaxMessages <= 0 || len(turns) <= s.cfg.MaxMessages {
+		return turns, nil
+	}
+	return turns[len(turns)-s.cfg.MaxMessages:], turns[:len(turns)-s.cfg.MaxMessages]
+}
@@ -0,0 +1,133 @@
+package llm
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestMemoryConversationStoreCapsByCount verifies Window returns at most
+// MaxMessages turns, oldest-first, once a key's history grows past the cap.
+func TestMemoryConversationStoreCapsByCount(t *testing.T) {
+	s := NewMemoryConversationStore(ConversationConfig{MaxMessages: 2})
+	key := ConversationKey{GuildID: "g1", UserID: "u1"}
+
+	for i, content := range []string{"first", "second", "third"} {
+		msg := StoredMessage{Message: Message{Role: "user", Content: content}, Timestamp: time.Now()}
+		if err := s.Append(context.Background(), key, msg); err != nil {
+			t.Fatalf("Append %d: %v", i, err)
+		}
+	}
+
+	window, err := s.Window(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Window: %v", err)
+	}
+	if len(window) != 2 {
+		t.Fatalf("want window capped at 2, got %d", len(window))
+	}
+	if window[0].Content != "second" || window[1].Content != "third" {
+		t.Fatalf("want oldest turn evicted and [second, third] retained, got [%s, %s]", window[0].Content, window[1].Content)
+	}
+}
+
+// TestMemoryConversationStoreSummarizesOverflow verifies turns evicted for
+// exceeding MaxMessages are handed to the configured Summarizer, and its
+// result is prepended to the key's history once the async summarization
+// completes.
+func TestMemoryConversationStoreSummarizesOverflow(t *testing.T) {
+	summarized := make(chan []StoredMessage, 1)
+	summarizer := func(ctx context.Context, turns []StoredMessage) (StoredMessage, error) {
+		summarized <- turns
+		return StoredMessage{Message: Message{Role: "system", Content: "recap"}, Timestamp: time.Now()}, nil
+	}
+	s := NewMemoryConversationStore(ConversationConfig{MaxMessages: 1, Summarizer: summarizer})
+	key := ConversationKey{GuildID: "g1", UserID: "u1"}
+
+	first := StoredMessage{Message: Message{Role: "user", Content: "first"}, Timestamp: time.Now()}
+	if err := s.Append(context.Background(), key, first); err != nil {
+		t.Fatalf("Append first: %v", err)
+	}
+	second := StoredMessage{Message: Message{Role: "user", Content: "second"}, Timestamp: time.Now()}
+	if err := s.Append(context.Background(), key, second); err != nil {
+		t.Fatalf("Append second: %v", err)
+	}
+
+	select {
+	case overflow := <-summarized:
+		if len(overflow) != 1 || overflow[0].Content != "first" {
+			t.Fatalf("want summarizer called with the evicted [first] turn, got %v", overflow)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("summarizer was never invoked")
+	}
+
+	// summarizeAsync races Append's caller; poll until the prepended summary
+	// shows up rather than asserting on a fixed sleep.
+	deadline := time.Now().Add(time.Second)
+	for {
+		window, err := s.Window(context.Background(), key)
+		if err != nil {
+			t.Fatalf("Window: %v", err)
+		}
+		if len(window) == 2 && window[0].Role == "system" {
+			if window[0].Content != "recap" || window[1].Content != "second" {
+				t.Fatalf("want [recap, second], got [%s, %s]", window[0].Content, window[1].Content)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("summary was never prepended to history, window=%v", window)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestMemoryConversationStoreTrimsByAge verifies a turn older than cfg.TTL is
+// dropped on the next Append, independent of MaxMessages.
+func TestMemoryConversationStoreTrimsByAge(t *testing.T) {
+	s := NewMemoryConversationStore(ConversationConfig{TTL: 10 * time.Millisecond})
+	key := ConversationKey{GuildID: "g1", UserID: "u1"}
+
+	stale := StoredMessage{Message: Message{Role: "user", Content: "stale"}, Timestamp: time.Now()}
+	if err := s.Append(context.Background(), key, stale); err != nil {
+		t.Fatalf("Append stale: %v", err)
+	}
+
+	time.Sleep(15 * time.Millisecond)
+
+	fresh := StoredMessage{Message: Message{Role: "user", Content: "fresh"}, Timestamp: time.Now()}
+	if err := s.Append(context.Background(), key, fresh); err != nil {
+		t.Fatalf("Append fresh: %v", err)
+	}
+
+	window, err := s.Window(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Window: %v", err)
+	}
+	if len(window) != 1 || window[0].Content != "fresh" {
+		t.Fatalf("want only [fresh] left after TTL eviction, got %v", window)
+	}
+}
+
+// TestMemoryConversationStoreReset verifies Reset discards a key's entire
+// history.
+func TestMemoryConversationStoreReset(t *testing.T) {
+	s := NewMemoryConversationStore(ConversationConfig{})
+	key := ConversationKey{GuildID: "g1", UserID: "u1"}
+
+	msg := StoredMessage{Message: Message{Role: "user", Content: "hi"}, Timestamp: time.Now()}
+	if err := s.Append(context.Background(), key, msg); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if err := s.Reset(context.Background(), key); err != nil {
+		t.Fatalf("Reset: %v", err)
+	}
+	window, err := s.Window(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Window: %v", err)
+	}
+	if len(window) != 0 {
+		t.Fatalf("want empty window after Reset, got %v", window)
+	}
+}
@@ -0,0 +1,92 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// OllamaProvider generates a reply via Ollama's /api/chat endpoint, which
+// streams one JSON object per line ({"message":{"content":"..."},"done":bool})
+// rather than OpenAI's SSE "data:" framing. Generate reads the whole stream
+// and concatenates each line's message.content before returning, since the
+// Provider interface is request/response; per-delta TTS overlap is only
+// wired up for the OpenAI SSE path in voice.Processor today.
+type OllamaProvider struct {
+	Config Config
+	Client *http.Client
+}
+
+// NewOllamaProvider builds an OllamaProvider. client == nil uses a
+// Config.timeout()-bounded default client.
+func NewOllamaProvider(cfg Config, client *http.Client) *OllamaProvider {
+	if client == nil {
+		client = &http.Client{Timeout: cfg.timeout()}
+	}
+	return &OllamaProvider{Config: cfg, Client: client}
+}
+
+func (p *OllamaProvider) Name() string { return "ollama" }
+
+func (p *OllamaProvider) Generate(ctx context.Context, req Request) (Reply, error) {
+	model := req.Model
+	if model == "" {
+		model = p.Config.Model
+	}
+	payload := map[string]interface{}{
+		"model":    model,
+		"messages": chatMessages(req.Messages),
+		"stream":   true,
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return Reply{}, fmt.Errorf("ollama: marshal request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.Config.URL, bytes.NewReader(b))
+	if err != nil {
+		return Reply{}, fmt.Errorf("ollama: new request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.Config.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.Config.APIKey)
+	}
+	resp, err := p.Client.Do(httpReq)
+	if err != nil {
+		return Reply{}, fmt.Errorf("ollama: request failed: %w: %w", err, ErrTransient)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return Reply{}, fmt.Errorf("ollama: status %d: %w", resp.StatusCode, classifyStatus(resp.StatusCode))
+	}
+
+	var content strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var chunk struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+			Done bool `json:"done"`
+		}
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			continue
+		}
+		content.WriteString(chunk.Message.Content)
+		if chunk.Done {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Reply{}, fmt.Errorf("ollama: read stream: %w", err)
+	}
+	return Reply{Content: content.String(), Model: model}, nil
+}
@@ -0,0 +1,202 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// OpenAIProvider generates a reply via an OpenAI-compatible
+// /v1/chat/completions endpoint. It implements StreamingProvider in
+// addition to Provider: GenerateStream sends `"stream": true` and parses the
+// `text/event-stream` response, so callers that want partial output as it's
+// generated don't have to wait for Generate's fully-buffered Reply.
+type OpenAIProvider struct {
+	Config Config
+	Client *http.Client
+}
+
+// NewOpenAIProvider builds an OpenAIProvider. client == nil uses a
+// Config.timeout()-bounded default client.
+func NewOpenAIProvider(cfg Config, client *http.Client) *OpenAIProvider {
+	if client == nil {
+		client = &http.Client{Timeout: cfg.timeout()}
+	}
+	return &OpenAIProvider{Config: cfg, Client: client}
+}
+
+func (p *OpenAIProvider) Name() string { return "openai" }
+
+// isRetryableStatus reports whether status is worth a single retry against
+// Config.FallbackModel: 429 (rate limited) or any 5xx, both plausibly caused
+// by the requested model specifically being overloaded or unavailable
+// rather than the request itself. A permanent 4xx (bad request, auth,
+// unknown model) would fail identically under a different model, so those
+// return false.
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// classifyStatus maps status to ErrPermanent/ErrTransient using
+// isRetryableStatus, for backends to wrap their status errors with (%w) so
+// callers can decide whether a fallback model/provider is worth trying.
+func classifyStatus(status int) error {
+	if isRetryableStatus(status) {
+		return ErrTransient
+	}
+	return ErrPermanent
+}
+
+// doRequest posts req to Config.URL with model (the "model" field is left
+// out when model == ""; doRequestWithFallback resolves req.Model/Config.Model
+// before calling this) and the given stream flag. On a non-2xx
+// response it drains and closes the body itself and returns the status
+// alongside the error, so callers can decide whether it's worth a
+// fallback-model retry; on success the caller owns resp.Body.
+func (p *OpenAIProvider) doRequest(ctx context.Context, req Request, model string, stream bool) (*http.Response, int, error) {
+	payload := map[string]interface{}{
+		"messages": chatMessages(req.Messages),
+		"stream":   stream,
+	}
+	if model != "" {
+		payload["model"] = model
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, 0, fmt.Errorf("openai: marshal request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.Config.URL, bytes.NewReader(b))
+	if err != nil {
+		return nil, 0, fmt.Errorf("openai: new request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.Config.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.Config.APIKey)
+	}
+	resp, err := p.Client.Do(httpReq)
+	if err != nil {
+		return nil, 0, fmt.Errorf("openai: request failed: %w: %w", err, ErrTransient)
+	}
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, resp.StatusCode, fmt.Errorf("openai: status %d: %s: %w", resp.StatusCode, truncate(body, 500), classifyStatus(resp.StatusCode))
+	}
+	return resp, resp.StatusCode, nil
+}
+
+// doRequestWithFallback calls doRequest with model (or Config.Model), and
+// once more with Config.FallbackModel if the first attempt failed with a
+// retryable status and a distinct fallback model is configured.
+func (p *OpenAIProvider) doRequestWithFallback(ctx context.Context, req Request, stream bool) (*http.Response, error) {
+	model := req.Model
+	if model == "" {
+		model = p.Config.Model
+	}
+	resp, status, err := p.doRequest(ctx, req, model, stream)
+	if err != nil && isRetryableStatus(status) && p.Config.FallbackModel != "" && p.Config.FallbackModel != model {
+		resp, _, err = p.doRequest(ctx, req, p.Config.FallbackModel, stream)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (p *OpenAIProvider) Generate(ctx context.Context, req Request) (Reply, error) {
+	resp, err := p.doRequestWithFallback(ctx, req, false)
+	if err != nil {
+		return Reply{}, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Reply{}, fmt.Errorf("openai: read response: %w", err)
+	}
+	var out struct {
+		Model   string `json:"model"`
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return Reply{}, fmt.Errorf("openai: unmarshal response: %w", err)
+	}
+	if len(out.Choices) == 0 {
+		return Reply{}, fmt.Errorf("openai: response had no choices")
+	}
+	return Reply{Content: out.Choices[0].Message.Content, Model: out.Model}, nil
+}
+
+// GenerateStream sends `"stream": true` and returns a Stream that decodes
+// incremental choices[0].delta.content chunks off the `data: ` framed
+// text/event-stream response, reporting io.EOF once it sees `data: [DONE]`.
+func (p *OpenAIProvider) GenerateStream(ctx context.Context, req Request) (*Stream, error) {
+	resp, err := p.doRequestWithFallback(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	done := false
+	recv := func() (Delta, error) {
+		if done {
+			return Delta{}, io.EOF
+		}
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || !strings.HasPrefix(line, "data:") {
+				continue
+			}
+			payload := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			if payload == "[DONE]" {
+				done = true
+				return Delta{}, io.EOF
+			}
+			var chunk struct {
+				Choices []struct {
+					Delta struct {
+						Content string `json:"content"`
+					} `json:"delta"`
+				} `json:"choices"`
+			}
+			if err := json.Unmarshal([]byte(payload), &chunk); err != nil {
+				continue
+			}
+			if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+				continue
+			}
+			return Delta{Content: chunk.Choices[0].Delta.Content}, nil
+		}
+		done = true
+		if serr := scanner.Err(); serr != nil {
+			return Delta{}, fmt.Errorf("openai: stream read: %w: %w", serr, ErrTransient)
+		}
+		return Delta{}, io.EOF
+	}
+	return &Stream{closer: resp.Body, recv: recv}, nil
+}
+
+// chatMessages converts Message to the {"role", "content"} shape every
+// backend in this package's requests use.
+func chatMessages(msgs []Message) []map[string]string {
+	out := make([]map[string]string, len(msgs))
+	for i, m := range msgs {
+		out[i] = map[string]string{"role": m.Role, "content": m.Content}
+	}
+	return out
+}
+
+func truncate(b []byte, n int) string {
+	if len(b) <= n {
+		return string(b)
+	}
+	return string(b[:n]) + "..."
+}
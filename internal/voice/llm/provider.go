@@ -0,0 +1,112 @@
+// Package llm defines a pluggable chat-completion backend abstraction used
+// by voice.Processor to generate a reply for a wake-phrase-triggered
+// transcript. Each backend implementation is responsible only for turning a
+// Request into a Reply; retry/backoff, sidecar persistence, and TTS handoff
+// stay in the caller so backends remain pure, same split as internal/voice/stt.
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrPermanent and ErrTransient classify a Generate/GenerateStream error for
+// callers deciding whether retrying - with the same model, a FallbackModel,
+// or a different provider entirely - is worth attempting. Backends should
+// wrap the error they return with one of these via fmt.Errorf("...: %w",
+// ...); an error that doesn't wrap either (e.g. a local marshal failure) is
+// treated as transient by errors.Is(err, ErrPermanent) == false, the same
+// "assume retryable unless proven otherwise" default this package's HTTP
+// status classification (isRetryableStatus) already uses.
+var (
+	ErrPermanent = errors.New("llm: permanent error")
+	ErrTransient = errors.New("llm: transient error")
+)
+
+// Message is one chat turn, using the role vocabulary ("system", "user",
+// "assistant") common to all the backends implemented here.
+type Message struct {
+	Role    string
+	Content string
+}
+
+// Request carries everything a backend needs to generate a reply.
+// CorrelationID is passed through for logging/tracing only.
+type Request struct {
+	Messages      []Message
+	Model         string
+	CorrelationID string
+}
+
+// Reply is the normalized output of a Generate call.
+type Reply struct {
+	Content string
+	Model   string
+}
+
+// Provider generates a chat reply. Implementations should not block longer
+// than necessary and should honor ctx cancellation.
+type Provider interface {
+	Name() string
+	Generate(ctx context.Context, req Request) (Reply, error)
+}
+
+// Delta is one incremental piece of a streamed reply.
+type Delta struct {
+	Content string
+}
+
+// Stream iterates the chunks of a streamed Generate call, started by
+// StreamingProvider.GenerateStream. Recv returns io.EOF once the backend
+// signals the reply is complete (e.g. OpenAI's `data: [DONE]`). Callers
+// should Close the stream when done with it, whether or not it was read to
+// EOF, to release the underlying connection.
+type Stream struct {
+	closer io.Closer
+	recv   func() (Delta, error)
+}
+
+// Recv returns the next Delta, or io.EOF when the stream is exhausted.
+func (s *Stream) Recv() (Delta, error) { return s.recv() }
+
+// Close releases the stream's underlying connection.
+func (s *Stream) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// StreamingProvider is implemented by backends whose wire protocol supports
+// delivering a reply incrementally (OpenAI-style Server-Sent Events) rather
+// than only as a single buffered response. Callers that want partial output
+// as it's generated - voice.Processor, to start TTS synthesis on each
+// sentence before the rest of the reply has arrived - should type-assert a
+// Provider to this instead of assuming every backend supports it:
+// anthropic/ollama/azureopenai don't implement it yet.
+type StreamingProvider interface {
+	GenerateStream(ctx context.Context, req Request) (*Stream, error)
+}
+
+// Config holds the subset of environment-driven settings shared across
+// backends. Individual backends may read additional env vars of their own.
+type Config struct {
+	URL    string
+	APIKey string
+	Model  string
+	// FallbackModel, if set, is retried once - in place of Model - when the
+	// initial attempt fails with a retryable status (429 or 5xx) before any
+	// reply bytes have been emitted. Only honored by backends that document
+	// support for it (currently OpenAIProvider).
+	FallbackModel string
+	TimeoutMs     int
+}
+
+func (c Config) timeout() time.Duration {
+	if c.TimeoutMs <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(c.TimeoutMs) * time.Millisecond
+}
@@ -0,0 +1,100 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// UserTokenStore resolves a per-Discord-user bearer token for orchestrator
+// requests, so a downstream orchestrator can apply per-user quotas/ACLs and
+// log real caller identity instead of a single shared service-account token.
+// Token returns ok=false (not an error) when the store simply has no
+// override configured for userID - not every user needs one, and callers
+// are expected to fall back to a global default in that case (see
+// FallbackUserTokenStore).
+type UserTokenStore interface {
+	Token(ctx context.Context, userID string) (token string, ok bool)
+}
+
+// EnvUserTokenStore resolves userID's token from the environment variable
+// Prefix + a sanitized form of userID (any character outside [A-Za-z0-9_]
+// replaced with one "_"), e.g. Prefix "ORCH_TOKEN_" resolves Discord user ID
+// "123456789012345678" from ORCH_TOKEN_123456789012345678.
+type EnvUserTokenStore struct {
+	Prefix string
+}
+
+// Token looks up s.Prefix+sanitizeEnvKey(userID) in the environment.
+func (s EnvUserTokenStore) Token(ctx context.Context, userID string) (string, bool) {
+	v := os.Getenv(s.Prefix + sanitizeEnvKey(userID))
+	return v, v != ""
+}
+
+// sanitizeEnvKey makes userID safe to splice into an environment variable
+// name: Discord user IDs are digits already, but this also covers any other
+// ID scheme a UserTokenStore caller might key by.
+func sanitizeEnvKey(userID string) string {
+	var b strings.Builder
+	for _, r := range userID {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// FileUserTokenStore resolves tokens from a JSON object of {userID: token}
+// loaded once at construction. Like FileConversationStore, this stands in
+// for a real embedded database the build has no way to vendor here; a
+// process restart is needed to pick up an edited file.
+type FileUserTokenStore struct {
+	tokens map[string]string
+}
+
+// NewFileUserTokenStore loads path (a JSON object mapping Discord user ID to
+// bearer token) into a FileUserTokenStore.
+func NewFileUserTokenStore(path string) (*FileUserTokenStore, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("user token store: read %s: %w", path, err)
+	}
+	var tokens map[string]string
+	if err := json.Unmarshal(b, &tokens); err != nil {
+		return nil, fmt.Errorf("user token store: unmarshal %s: %w", path, err)
+	}
+	return &FileUserTokenStore{tokens: tokens}, nil
+}
+
+// Token looks up userID in the loaded snapshot.
+func (s *FileUserTokenStore) Token(ctx context.Context, userID string) (string, bool) {
+	v, ok := s.tokens[userID]
+	return v, ok && v != ""
+}
+
+// FallbackUserTokenStore tries Stores in order and returns the first token
+// found, falling through to Default (typically the global ORCH_AUTH_TOKEN)
+// if none of them has one for userID.
+type FallbackUserTokenStore struct {
+	Stores  []UserTokenStore
+	Default string
+}
+
+// Token tries each of s.Stores in order before falling back to s.Default;
+// ok is false only when every store came up empty and Default == "".
+func (s FallbackUserTokenStore) Token(ctx context.Context, userID string) (string, bool) {
+	for _, store := range s.Stores {
+		if store == nil {
+			continue
+		}
+		if tok, ok := store.Token(ctx, userID); ok {
+			return tok, true
+		}
+	}
+	return s.Default, s.Default != ""
+}
@@ -0,0 +1,97 @@
+package voice
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/discord-voice-lab/internal/voice/llm"
+)
+
+// llmProviderRegistryFromEnv builds the set of llm.Provider backends a
+// Processor can route a wake-phrase-triggered transcript to, keyed by
+// provider name. ORCHESTRATOR_PROVIDER (if set to one of "ollama",
+// "anthropic" or "azureopenai"; other names are ignored) is always
+// included, under its own name, alongside "openai" (the default, built from the existing
+// ORCHESTRATOR_URL/ORCH_AUTH_TOKEN/ORCHESTRATOR_MODEL env vars every prior
+// chunk already relies on) so routing overrides always have a fallback to
+// land on. ORCHESTRATOR_FALLBACK_MODEL, if set, is the single retry model
+// llm.OpenAIProvider falls back to on a 429/5xx before any reply bytes have
+// arrived; the other backends don't support it yet. Every name listed in
+// ORCHESTRATOR_FALLBACK_PROVIDERS (see llmFallbackChainFromEnv) is also
+// registered here even if it isn't ORCHESTRATOR_PROVIDER, so the chain has
+// something to fall through to.
+func llmProviderRegistryFromEnv() map[string]llm.Provider {
+	cfg := llm.Config{
+		URL:           os.Getenv("ORCHESTRATOR_URL"),
+		APIKey:        os.Getenv("ORCH_AUTH_TOKEN"),
+		Model:         os.Getenv("ORCHESTRATOR_MODEL"),
+		FallbackModel: os.Getenv("ORCHESTRATOR_FALLBACK_MODEL"),
+		TimeoutMs:     30000,
+	}
+	if v := os.Getenv("ORCHESTRATOR_TIMEOUT_MS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.TimeoutMs = n
+		}
+	}
+	reg := map[string]llm.Provider{
+		"openai": llm.NewOpenAIProvider(cfg, nil),
+	}
+	wanted := map[string]bool{}
+	if v := strings.ToLower(strings.TrimSpace(os.Getenv("ORCHESTRATOR_PROVIDER"))); v != "" {
+		wanted[v] = true
+	}
+	for _, name := range llmFallbackChainFromEnv() {
+		wanted[name] = true
+	}
+	for name := range wanted {
+		switch name {
+		case "", "openai":
+			// already registered above
+		case "ollama":
+			reg["ollama"] = llm.NewOllamaProvider(cfg, nil)
+		case "anthropic":
+			reg["anthropic"] = llm.NewAnthropicProvider(cfg, nil, os.Getenv("ANTHROPIC_VERSION"))
+		case "azureopenai":
+			azureCfg := cfg
+			if k := os.Getenv("AZURE_OPENAI_API_KEY"); k != "" {
+				azureCfg.APIKey = k
+			}
+			reg["azureopenai"] = llm.NewAzureOpenAIProvider(azureCfg, nil)
+		}
+	}
+	return reg
+}
+
+// llmFallbackChainFromEnv reads ORCHESTRATOR_FALLBACK_PROVIDERS (comma-
+// separated provider registry names, e.g. "ollama,anthropic") naming
+// llm.Provider entries Processor.llmFallbackChain should try, in order,
+// when the llmRouter-resolved provider's call fails with any error.
+func llmFallbackChainFromEnv() []string {
+	raw := strings.TrimSpace(os.Getenv("ORCHESTRATOR_FALLBACK_PROVIDERS"))
+	if raw == "" {
+		return nil
+	}
+	parts := strings.Split(raw, ",")
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		name := strings.ToLower(strings.TrimSpace(p))
+		if name != "" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// defaultLLMProviderFromEnv reports which registry key llmRouter should fall
+// back to when no per-user override applies: ORCHESTRATOR_PROVIDER if it
+// named a known backend, else "openai" to match pre-chunk4-2 behavior.
+func defaultLLMProviderFromEnv() string {
+	name := strings.ToLower(strings.TrimSpace(os.Getenv("ORCHESTRATOR_PROVIDER")))
+	switch name {
+	case "ollama", "anthropic", "azureopenai":
+		return name
+	default:
+		return "openai"
+	}
+}
@@ -0,0 +1,45 @@
+package voice
+
+import (
+	"sync"
+	"time"
+
+	"github.com/discord-voice-lab/internal/voice/outbound"
+)
+
+// providerBreakers and providerLimiters hold one outbound.CircuitBreaker and
+// outbound.TokenBucket per llm.Provider registry name, mirroring
+// postBreakers/postBreakerFor in httpclient.go - same rationale, applied to
+// generateViaProvider's calls instead of the raw "openai" PostWithRetries
+// path, so a fallback-chain entry (e.g. a local Ollama instance) gets its
+// own admission control instead of inheriting whatever state the primary
+// provider left its shared breaker/limiter in. Keyed by registry name
+// rather than endpoint URL since that's what every other per-provider
+// concept in this package (llmRouter, llmFallbackChain, the sidecar's
+// orchestrator_provider field) already keys off of.
+var (
+	providerGuardsMu sync.Mutex
+	providerBreakers = map[string]*outbound.CircuitBreaker{}
+	providerLimiters = map[string]*outbound.TokenBucket{}
+)
+
+// providerGuardFor returns the shared breaker and limiter for providerName,
+// creating them on first use with the same tolerant defaults
+// postBreakerFor/limiterConfigFromEnv default to elsewhere in this package
+// (50% failure ratio over at least 5 samples, 30s cooldown; 5 req/s with a
+// burst of 10).
+func providerGuardFor(providerName string) (*outbound.CircuitBreaker, *outbound.TokenBucket) {
+	providerGuardsMu.Lock()
+	defer providerGuardsMu.Unlock()
+	b, ok := providerBreakers[providerName]
+	if !ok {
+		b = outbound.NewCircuitBreaker(0.5, 5, 30*time.Second)
+		providerBreakers[providerName] = b
+	}
+	l, ok := providerLimiters[providerName]
+	if !ok {
+		l = outbound.NewTokenBucket(10, 5)
+		providerLimiters[providerName] = l
+	}
+	return b, l
+}
@@ -0,0 +1,230 @@
+package voice
+
+import "math"
+
+// loudness implements the pieces of ITU-R BS.1770-4 / EBU R128 this package
+// needs to normalize a flushed accumulator before it's sent to STT: K-weighted
+// integrated loudness (LUFS), true-peak estimation, and the gain required to
+// hit a target level without exceeding a true-peak ceiling. It operates on
+// mono 16-bit PCM at a fixed sample rate (we only ever measure 48kHz Discord
+// audio), so the biquad coefficients below are derived for fs=48000 and are
+// not re-derived per call.
+
+// kWeightingFilter applies BS.1770's two-stage K-weighting (a high-shelf
+// "head" filter approximating the response of a human head, followed by an
+// RLB high-pass filter) to samples, returning a new float64 slice so the
+// caller's int16 samples are left untouched for the separate true-peak pass.
+func kWeightingFilter(samples []int16, sampleRate int) []float64 {
+	// Coefficients from BS.1770-4 Annex 1, valid at fs=48000. Other sample
+	// rates fall back to an unweighted pass-through rather than silently
+	// applying the wrong filter shape.
+	if sampleRate != 48000 {
+		out := make([]float64, len(samples))
+		for i, s := range samples {
+			out[i] = float64(s)
+		}
+		return out
+	}
+
+	// Stage 1: high-shelf "head" filter.
+	const (
+		b0_1 = 1.53512485958697
+		b1_1 = -2.69169618940638
+		b2_1 = 1.19839281085285
+		a1_1 = -1.69065929318241
+		a2_1 = 0.73248077421585
+	)
+	// Stage 2: RLB high-pass filter.
+	const (
+		b0_2 = 1.0
+		b1_2 = -2.0
+		b2_2 = 1.0
+		a1_2 = -1.99004745483398
+		a2_2 = 0.99007225036621
+	)
+
+	n := len(samples)
+	stage1 := make([]float64, n)
+	var x1, x2, y1, y2 float64
+	for i, s := range samples {
+		x0 := float64(s)
+		y0 := b0_1*x0 + b1_1*x1 + b2_1*x2 - a1_1*y1 - a2_1*y2
+		stage1[i] = y0
+		x2, x1 = x1, x0
+		y2, y1 = y1, y0
+	}
+
+	stage2 := make([]float64, n)
+	x1, x2, y1, y2 = 0, 0, 0, 0
+	for i, s := range stage1 {
+		x0 := s
+		y0 := b0_2*x0 + b1_2*x1 + b2_2*x2 - a1_2*y1 - a2_2*y2
+		stage2[i] = y0
+		x2, x1 = x1, x0
+		y2, y1 = y1, y0
+	}
+	return stage2
+}
+
+// blockLoudness returns the mean-square energy of weighted samples in
+// [start, start+blockLen), or -1 if the block doesn't fully fit.
+func blockLoudness(weighted []float64, start, blockLen int) float64 {
+	if start+blockLen > len(weighted) {
+		return -1
+	}
+	var sum float64
+	for i := start; i < start+blockLen; i++ {
+		sum += weighted[i] * weighted[i]
+	}
+	return sum / float64(blockLen)
+}
+
+// meanSquareToLUFS converts a K-weighted mean-square value to LUFS.
+// -0.691 is BS.1770's calibration offset for a single (mono) channel.
+func meanSquareToLUFS(meanSquare float64) float64 {
+	if meanSquare <= 0 {
+		return math.Inf(-1)
+	}
+	return -0.691 + 10*math.Log10(meanSquare)
+}
+
+// integratedLUFS measures BS.1770/R128 integrated (program) loudness: 400ms
+// momentary blocks at 75% overlap (100ms step), an absolute gate at -70
+// LUFS, then a relative gate at (mean of the absolute-gated blocks - 10 LU).
+func integratedLUFS(samples []int16, sampleRate int) float64 {
+	if len(samples) == 0 {
+		return math.Inf(-1)
+	}
+	weighted := kWeightingFilter(samples, sampleRate)
+
+	blockLen := sampleRate * 400 / 1000
+	step := sampleRate * 100 / 1000
+	if blockLen <= 0 || step <= 0 || len(weighted) < blockLen {
+		return meanSquareToLUFS(blockLoudness(weighted, 0, len(weighted)))
+	}
+
+	var blocks []float64
+	for start := 0; ; start += step {
+		ms := blockLoudness(weighted, start, blockLen)
+		if ms < 0 {
+			break
+		}
+		blocks = append(blocks, ms)
+	}
+	if len(blocks) == 0 {
+		return math.Inf(-1)
+	}
+
+	// Absolute gate: discard blocks quieter than -70 LUFS.
+	gated := make([]float64, 0, len(blocks))
+	for _, ms := range blocks {
+		if meanSquareToLUFS(ms) > -70 {
+			gated = append(gated, ms)
+		}
+	}
+	if len(gated) == 0 {
+		return math.Inf(-1)
+	}
+
+	// Relative gate: discard blocks quieter than (absolute-gated mean - 10 LU).
+	var sum float64
+	for _, ms := range gated {
+		sum += ms
+	}
+	relativeThreshold := meanSquareToLUFS(sum/float64(len(gated))) - 10
+
+	var finalSum float64
+	finalCount := 0
+	for _, ms := range gated {
+		if meanSquareToLUFS(ms) > relativeThreshold {
+			finalSum += ms
+			finalCount++
+		}
+	}
+	if finalCount == 0 {
+		return math.Inf(-1)
+	}
+	return meanSquareToLUFS(finalSum / float64(finalCount))
+}
+
+// estimateTruePeakDBTP approximates ITU-R BS.1770's true-peak measurement by
+// 4x-oversampling samples with simple linear interpolation (a cheaper stand-in
+// for the spec's recommended polyphase FIR resampler) and returning the
+// resulting peak in dBTP (0 dBTP == full scale).
+func estimateTruePeakDBTP(samples []int16) float64 {
+	if len(samples) == 0 {
+		return math.Inf(-1)
+	}
+	const oversample = 4
+	peak := 0.0
+	for i := 0; i < len(samples); i++ {
+		cur := float64(samples[i])
+		if v := math.Abs(cur); v > peak {
+			peak = v
+		}
+		if i+1 >= len(samples) {
+			continue
+		}
+		next := float64(samples[i+1])
+		for k := 1; k < oversample; k++ {
+			frac := float64(k) / float64(oversample)
+			interp := cur + (next-cur)*frac
+			if v := math.Abs(interp); v > peak {
+				peak = v
+			}
+		}
+	}
+	if peak <= 0 {
+		return math.Inf(-1)
+	}
+	return 20 * math.Log10(peak/32768.0)
+}
+
+// loudnessNormResult records the measurement and gain applied by
+// normalizeLoudness, so callers can persist it to a sidecar for later tuning.
+type loudnessNormResult struct {
+	MeasuredLUFS  float64
+	AppliedGainDB float64
+	TruePeakDBTP  float64
+}
+
+// normalizeLoudness measures samples' integrated loudness and true peak,
+// computes the linear gain needed to reach targetLUFS, clamps that gain so
+// the resulting true peak does not exceed maxTruePeakDBTP, and applies it to
+// samples in place. The returned TruePeakDBTP is the peak after the applied
+// gain (i.e. what was actually sent), not the pre-gain measurement.
+func normalizeLoudness(samples []int16, sampleRate int, targetLUFS, maxTruePeakDBTP float64) loudnessNormResult {
+	measured := integratedLUFS(samples, sampleRate)
+	prePeak := estimateTruePeakDBTP(samples)
+
+	gainDB := 0.0
+	if !math.IsInf(measured, -1) {
+		gainDB = targetLUFS - measured
+	}
+	// Clamp so post-gain true peak doesn't exceed the ceiling.
+	if !math.IsInf(prePeak, -1) {
+		maxGainForPeak := maxTruePeakDBTP - prePeak
+		if gainDB > maxGainForPeak {
+			gainDB = maxGainForPeak
+		}
+	}
+
+	gainLinear := math.Pow(10, gainDB/20)
+	if gainLinear != 1 {
+		for i, s := range samples {
+			v := float64(s) * gainLinear
+			if v > 32767 {
+				v = 32767
+			} else if v < -32768 {
+				v = -32768
+			}
+			samples[i] = int16(v)
+		}
+	}
+
+	return loudnessNormResult{
+		MeasuredLUFS:  measured,
+		AppliedGainDB: gainDB,
+		TruePeakDBTP:  estimateTruePeakDBTP(samples),
+	}
+}
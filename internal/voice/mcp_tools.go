@@ -0,0 +1,227 @@
+package voice
+
+// mcp_tools.go wires an optional mcp.ToolRegistry into the "openai" raw
+// orchestrator path (sendOrchestratorRawRequest): llm.Provider/llm.Request
+// (generateViaProvider's path) has no Tools field yet, so a fallback-chain
+// name other than "openai" still gets the plain conversation-only prompt
+// this package has always sent it.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/discord-voice-lab/internal/logging"
+	"github.com/discord-voice-lab/internal/mcp"
+)
+
+// mcpToolRegistryFromEnv connects to every websocket MCP server named in
+// MCP_SERVER_URLS (comma-separated) and returns a mcp.ToolRegistry populated
+// with their tools, or nil if the env var is unset - the zero-config
+// default, so a Processor with no MCP servers configured behaves exactly as
+// it did before this feature existed. A server that fails to connect or
+// list tools is logged and skipped rather than failing Processor
+// construction: a single misconfigured MCP server shouldn't keep the bot
+// from joining voice channels at all.
+//
+// ctx is passed straight through to ConnectWebSocket - not wrapped in an
+// extra per-connect timeout - because ConnectWebSocket's keepalive ping
+// goroutine runs for as long as that same ctx stays alive; wrapping it in a
+// context.WithTimeout canceled right after this function returns would kill
+// keepalive the moment the timeout lapsed (or immediately, if canceled
+// eagerly), not just bound the dial. The dial itself is still bounded by
+// gorilla/websocket's own default 45s handshake timeout, so a hung MCP
+// server can't block Processor construction forever.
+func mcpToolRegistryFromEnv(ctx context.Context) *mcp.ToolRegistry {
+	raw := strings.TrimSpace(os.Getenv("MCP_SERVER_URLS"))
+	if raw == "" {
+		return nil
+	}
+	registry := mcp.NewToolRegistry()
+	for _, url := range strings.Split(raw, ",") {
+		url = strings.TrimSpace(url)
+		if url == "" {
+			continue
+		}
+		w := mcp.NewClientWrapper("discord-voice-lab", "1.0.0")
+		if err := w.ConnectWebSocket(ctx, url); err != nil {
+			logging.Warn("mcp: failed to connect tool server", "url", url, "err", err)
+			continue
+		}
+		if err := registry.Discover(ctx, w); err != nil {
+			logging.Warn("mcp: failed to list tools", "url", url, "err", err)
+			_ = w.Close()
+			continue
+		}
+		// Re-run Discover whenever w's supervisor reconnects (see
+		// mcp.ClientWrapper.OnReconnect), so a tool set change made while
+		// this server was unreachable is picked up instead of staying
+		// frozen at whatever Discover saw just now.
+		serverURL := url
+		w.OnReconnect(func(ctx context.Context, w *mcp.ClientWrapper) {
+			if err := registry.Discover(ctx, w); err != nil {
+				logging.Warn("mcp: failed to re-list tools after reconnect", "url", serverURL, "err", err)
+			}
+		})
+	}
+	if registry.Len() == 0 {
+		return nil
+	}
+	return registry
+}
+
+// mcpToolMaxDepthFromEnv bounds runToolCallLoop's re-invocation count via
+// MCP_TOOL_MAX_DEPTH (default 4): enough for a handful of chained tool calls
+// without letting a model that keeps calling tools loop the orchestrator
+// request forever.
+func mcpToolMaxDepthFromEnv() int {
+	if v := os.Getenv("MCP_TOOL_MAX_DEPTH"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 4
+}
+
+// runToolCallLoop drives the "openai" raw path's tool-calling round trips:
+// POST messages (with p.toolRegistry's tools attached), and as long as the
+// response's message carries tool_calls, dispatch each via
+// p.toolRegistry.CallTool, append the assistant's tool-call message and one
+// role:"tool" message per result (or error text, so the model can recover
+// and try something else), and re-invoke the completion. Stops once a
+// response comes back with no tool_calls (the final reply) or maxDepth
+// round trips are spent, whichever comes first.
+//
+// Always non-streaming (stream:false): a tool_calls response has no content
+// to speak yet, and streaming only the final round trip would mean a second
+// code path just for that one case. handleOrchestratorStreamResponse stays
+// the default for every request with no tools registered.
+//
+// Tool-call/tool-result messages built up across rounds live only in this
+// function's local messages slice, not in p.conversationStore: if a later
+// round trip then fails (network blip, or maxDepth is hit) and
+// sendOrchestratorJob retries the whole job, any tool call the model
+// already made successfully gets issued again with no memory of the first
+// attempt. Acceptable for now the same way this package already accepts
+// similar retry-duplication risk elsewhere (e.g. a retried job replaying a
+// TTS job's synthesizeOnce); a tool with non-idempotent side effects is the
+// MCP server's own problem to guard (e.g. via an idempotency key in its
+// arguments), not something this loop currently provides.
+func (p *Processor) runToolCallLoop(ctx context.Context, url string, messages []map[string]interface{}, authToken, correlationID string, headers map[string]string) (string, error) {
+	tools := p.toolRegistry.ChatTools()
+	maxDepth := mcpToolMaxDepthFromEnv()
+	for depth := 0; depth < maxDepth; depth++ {
+		respMsg, err := p.postChatCompletion(ctx, url, messages, tools, authToken, correlationID, headers)
+		if err != nil {
+			return "", err
+		}
+		toolCalls, _ := respMsg["tool_calls"].([]interface{})
+		if len(toolCalls) == 0 {
+			content, _ := respMsg["content"].(string)
+			return strings.TrimSpace(content), nil
+		}
+		messages = append(messages, respMsg)
+		for _, tc := range toolCalls {
+			tcMap, ok := tc.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			messages = append(messages, p.dispatchToolCall(ctx, tcMap, correlationID))
+		}
+	}
+	return "", fmt.Errorf("mcp: tool call loop exceeded max depth %d", maxDepth)
+}
+
+// postChatCompletion performs one non-streaming chat completion round trip
+// against the "openai" raw orchestrator endpoint and returns the parsed
+// choices[0].message object, so runToolCallLoop can inspect tool_calls or
+// content without re-parsing. Shares PostWithRetries's per-URL circuit
+// breaker with the rest of the "openai" raw path. headers carries the
+// caller's orchestratorOriginHeaders through unchanged.
+func (p *Processor) postChatCompletion(ctx context.Context, url string, messages []map[string]interface{}, tools []map[string]interface{}, authToken, correlationID string, headers map[string]string) (map[string]interface{}, error) {
+	chatPayload := map[string]interface{}{
+		"model":          os.Getenv("ORCHESTRATOR_MODEL"),
+		"messages":       messages,
+		"tools":          tools,
+		"correlation_id": correlationID,
+		"stream":         false,
+	}
+	if chatPayload["model"] == "" || chatPayload["model"] == nil {
+		delete(chatPayload, "model")
+	}
+	b, err := json.Marshal(chatPayload)
+	if err != nil {
+		return nil, fmt.Errorf("mcp: marshal chat payload: %w", err)
+	}
+	timeoutMs := p.orchestratorTimeoutMS
+	if timeoutMs <= 0 {
+		timeoutMs = 30000
+	}
+	resp, err := PostWithRetries(ctx, nil, url, b, authToken, timeoutMs, 1, correlationID, headers)
+	if err != nil {
+		return nil, fmt.Errorf("mcp: chat completion request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("mcp: chat completion status %d", resp.StatusCode)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("mcp: unmarshal chat completion response: %w", err)
+	}
+	choices, _ := out["choices"].([]interface{})
+	if len(choices) == 0 {
+		return nil, fmt.Errorf("mcp: chat completion response had no choices")
+	}
+	ch0, _ := choices[0].(map[string]interface{})
+	msg, _ := ch0["message"].(map[string]interface{})
+	if msg == nil {
+		return nil, fmt.Errorf("mcp: chat completion response missing message")
+	}
+	return msg, nil
+}
+
+// dispatchToolCall executes one tool_calls entry from the model's response
+// via p.toolRegistry.CallTool and returns the role:"tool" message to append
+// - on a dispatch failure (unknown tool, a CallTool transport error, or the
+// MCP server's own IsError result) the error text becomes the tool
+// message's content instead of being returned up the chain, so the model
+// sees its own tool call failed and can recover rather than the whole
+// orchestrator request failing.
+func (p *Processor) dispatchToolCall(ctx context.Context, tc map[string]interface{}, correlationID string) map[string]interface{} {
+	id, _ := tc["id"].(string)
+	fn, _ := tc["function"].(map[string]interface{})
+	name, _ := fn["name"].(string)
+	argsJSON, _ := fn["arguments"].(string)
+
+	var args map[string]interface{}
+	if argsJSON != "" {
+		if err := json.Unmarshal([]byte(argsJSON), &args); err != nil {
+			logging.Warn("mcp: tool call arguments not valid JSON", "tool", name, "err", err, "correlation_id", correlationID)
+			return toolResultMessage(id, fmt.Sprintf("error: arguments not valid JSON: %v", err))
+		}
+	}
+
+	result, err := p.toolRegistry.CallTool(ctx, name, args)
+	p.patchSidecar(correlationID, func(sc map[string]interface{}) {
+		calls, _ := sc["mcp_tool_calls"].([]interface{})
+		sc["mcp_tool_calls"] = append(calls, map[string]interface{}{"name": name, "error": err != nil})
+	})
+	if err != nil {
+		logging.Warn("mcp: tool call failed", "tool", name, "err", err, "correlation_id", correlationID)
+		return toolResultMessage(id, fmt.Sprintf("error: %v", err))
+	}
+	return toolResultMessage(id, result)
+}
+
+// toolResultMessage builds the role:"tool" message a chat-completion API
+// expects in reply to one tool_calls entry, matched back to it via
+// toolCallID.
+func toolResultMessage(toolCallID, content string) map[string]interface{} {
+	return map[string]interface{}{"role": "tool", "tool_call_id": toolCallID, "content": content}
+}
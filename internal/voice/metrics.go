@@ -0,0 +1,126 @@
+package voice
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metrics holds the package's Prometheus collectors. They're package-level
+// (rather than fields on Processor) so multiple Processor instances in the
+// same process share one registry, matching how sendCount/sendFailCount
+// were already process-wide counters in spirit.
+var (
+	sttRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "stt_requests_total",
+		Help: "Total STT backend requests, labeled by backend and outcome.",
+	}, []string{"backend", "status"})
+
+	sttLatencyHist = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "stt_latency_ms",
+		Help:    "End-to-end latency of an STT request as observed by the caller, in milliseconds.",
+		Buckets: []float64{50, 100, 250, 500, 1000, 2000, 5000, 10000, 20000},
+	})
+
+	sttServerHist = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "stt_server_ms",
+		Help:    "Server-reported processing time for an STT request, in milliseconds.",
+		Buckets: []float64{50, 100, 250, 500, 1000, 2000, 5000, 10000, 20000},
+	})
+
+	endToEndHist = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "end_to_end_ms",
+		Help:    "Time from accumulator creation to flushed transcript being available, in milliseconds.",
+		Buckets: []float64{100, 250, 500, 1000, 2500, 5000, 10000, 20000, 30000},
+	})
+
+	pcmBytesSent = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "pcm_bytes_sent",
+		Help: "Total raw PCM bytes sent to STT backends.",
+	})
+
+	activeSSRCs = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "active_ssrcs",
+		Help: "Number of SSRCs currently mapped to a Discord user.",
+	})
+
+	accumulatorDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "accumulator_depth",
+		Help: "Number of per-SSRC PCM accumulators currently buffering audio.",
+	})
+
+	opusPacketsReceivedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "opus_packets_received_total",
+		Help: "Total Opus RTP packets received by the Receiver jitter buffer, labeled by SSRC.",
+	}, []string{"ssrc"})
+
+	reconnectAttemptsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "voice_reconnect_attempts_total",
+		Help: "Total voice connection reconnect attempts across all guild sessions, labeled by whether ConnectionSupervisor attempted it as resumable (retaining the prior voice session) or forced a full non-resumable rejoin.",
+	}, []string{"resumable"})
+
+	zombieDetectedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "voice_zombie_detected_total",
+		Help: "Total guild voice sessions ConnectionSupervisor detected as zombied (no Opus traffic within its timeout) and force-reconnected.",
+	})
+
+	resolverCacheHitsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "resolver_cache_hits_total",
+		Help: "Total discordResolver cache hits, labeled by kind (user/guild/channel).",
+	}, []string{"kind"})
+
+	resolverCacheMissesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "resolver_cache_misses_total",
+		Help: "Total discordResolver cache misses, labeled by kind (user/guild/channel).",
+	}, []string{"kind"})
+
+	resolverCacheEvictionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "resolver_cache_evictions_total",
+		Help: "Total discordResolver cache entries evicted for exceeding their kind's size cap.",
+	}, []string{"kind"})
+
+	llmProviderCallsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "llm_provider_calls_total",
+		Help: "Total generateViaProvider attempts, labeled by provider name and outcome (allowed|throttled|breaker_open|success|failure).",
+	}, []string{"provider", "outcome"})
+
+	sttRatelimitedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "stt_ratelimited_total",
+		Help: "Total flushAccum dispatches that found Limiter's per-user or global token bucket exhausted, whether coalesced or dropped.",
+	})
+
+	sttCoalescedBytes = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "stt_coalesced_bytes",
+		Help: "Total PCM bytes queued by Limiter while waiting for dispatch tokens, including bytes later dropped for exceeding the per-user queue cap.",
+	})
+
+	streamFramesDroppedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "stream_frames_dropped_total",
+		Help: "Total PCM frames dropped because a per-accumulator streaming STT pump's frame queue was full, typically meaning the streaming backend can't keep up.",
+	})
+)
+
+// observeOpusPacket records one Opus packet received for ssrc.
+func observeOpusPacket(ssrc uint32) {
+	opusPacketsReceivedTotal.WithLabelValues(strconv.FormatUint(uint64(ssrc), 10)).Inc()
+}
+
+// observeReconnectAttempt records one guild session reconnect attempt,
+// labeled by whether it was attempted as resumable.
+func observeReconnectAttempt(resumable bool) {
+	reconnectAttemptsTotal.WithLabelValues(strconv.FormatBool(resumable)).Inc()
+}
+
+// observeZombieDetected records one ConnectionSupervisor zombie detection.
+func observeZombieDetected() {
+	zombieDetectedTotal.Inc()
+}
+
+// MetricsHandler returns an http.Handler serving the package's collectors in
+// Prometheus text format, suitable for mounting at /metrics.
+func MetricsHandler() http.Handler {
+	return promhttp.Handler()
+}
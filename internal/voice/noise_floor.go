@@ -0,0 +1,164 @@
+package voice
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"time"
+)
+
+// noiseFloorWindow is how far back noiseFloorTracker looks when fitting its
+// regression; an HVAC cycle or a door closing should age out within a few
+// seconds rather than permanently biasing the floor.
+const noiseFloorWindow = 5 * time.Second
+
+// noiseFloorFitInterval bounds how often the regression is recomputed;
+// refitting on every frame would be wasted work since the floor only needs
+// to track slow-moving room conditions, not instantaneous energy.
+const noiseFloorFitInterval = 500 * time.Millisecond
+
+// noiseFloorSample is one non-speech frame's log-RMS, tagged with when it
+// was observed.
+type noiseFloorSample struct {
+	t      time.Time
+	logRMS float64
+}
+
+// noiseFloorTracker maintains a per-SSRC rolling estimate of the ambient
+// noise floor by fitting a linear regression of log(RMS) vs. time over the
+// last noiseFloorWindow of non-speech frames, evaluated at "now". Not safe
+// for concurrent use; Processor owns one per SSRC under its own mutex.
+type noiseFloorTracker struct {
+	ratio, min, max float64
+
+	samples []noiseFloorSample
+	lastFit time.Time
+	floor   float64 // current fitted noise-floor RMS estimate
+}
+
+// newNoiseFloorTracker builds a tracker with the given ratio/min/max (see
+// VAD_RMS_RATIO/VAD_RMS_MIN/VAD_RMS_MAX), seeded with floor as an initial
+// estimate before enough samples have accumulated to fit a regression.
+func newNoiseFloorTracker(ratio, min, max float64, seedFloor float64) *noiseFloorTracker {
+	return &noiseFloorTracker{ratio: ratio, min: min, max: max, floor: seedFloor}
+}
+
+// Observe records one non-speech frame's RMS and, if noiseFloorFitInterval
+// has elapsed since the last fit, refits the regression.
+func (t *noiseFloorTracker) Observe(rms int, now time.Time) {
+	if rms <= 0 {
+		return
+	}
+	cutoff := now.Add(-noiseFloorWindow)
+	t.samples = append(t.samples, noiseFloorSample{t: now, logRMS: math.Log(float64(rms))})
+	i := 0
+	for i < len(t.samples) && t.samples[i].t.Before(cutoff) {
+		i++
+	}
+	if i > 0 {
+		t.samples = t.samples[i:]
+	}
+
+	if now.Sub(t.lastFit) < noiseFloorFitInterval {
+		return
+	}
+	t.lastFit = now
+	t.fit(now)
+}
+
+// fit performs a least-squares linear regression of logRMS against seconds-
+// since-window-start, then evaluates it at "now" to get the current
+// log-floor, converting back to linear RMS.
+func (t *noiseFloorTracker) fit(now time.Time) {
+	n := len(t.samples)
+	if n < 2 {
+		return
+	}
+	base := t.samples[0].t
+	var sumX, sumY, sumXY, sumXX float64
+	for _, s := range t.samples {
+		x := s.t.Sub(base).Seconds()
+		y := s.logRMS
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	fn := float64(n)
+	denom := fn*sumXX - sumX*sumX
+	var slope, intercept float64
+	if denom == 0 {
+		// All samples landed at the same timestamp; fall back to the mean.
+		intercept = sumY / fn
+	} else {
+		slope = (fn*sumXY - sumX*sumY) / denom
+		intercept = (sumY - slope*sumX) / fn
+	}
+	nowX := now.Sub(base).Seconds()
+	logFloor := intercept + slope*nowX
+	t.floor = math.Exp(logFloor)
+}
+
+// Threshold returns the current VAD threshold: the fitted noise floor times
+// ratio, clamped to [min, max] so a pathological fit (e.g. a long stretch of
+// near-silence) can't drive the threshold high enough to silence a speaker,
+// or low enough to let constant background noise through as speech.
+func (t *noiseFloorTracker) Threshold() float64 {
+	th := t.floor * t.ratio
+	if th < t.min {
+		th = t.min
+	}
+	if th > t.max {
+		th = t.max
+	}
+	return th
+}
+
+// Floor returns the tracker's current raw noise-floor estimate (pre-ratio),
+// for debug/inspection.
+func (t *noiseFloorTracker) Floor() float64 { return t.floor }
+
+// noiseFloorState is the debug-endpoint view of one SSRC's adaptive VAD
+// threshold, returned by Processor.NoiseFloorSnapshot.
+type noiseFloorState struct {
+	SSRC      uint32  `json:"ssrc"`
+	Floor     float64 `json:"noise_floor_rms"`
+	Threshold float64 `json:"vad_threshold"`
+}
+
+// noiseFloorTrackerFor returns (creating if necessary) the tracker for ssrc,
+// seeded from p.vadRmsThreshold so a freshly-joined speaker starts at the
+// previous static default rather than 0.
+func (p *Processor) noiseFloorTrackerFor(ssrc uint32) *noiseFloorTracker {
+	p.noiseFloorMu.Lock()
+	defer p.noiseFloorMu.Unlock()
+	t, ok := p.noiseFloors[ssrc]
+	if !ok {
+		t = newNoiseFloorTracker(p.vadRmsRatio, p.vadRmsMin, p.vadRmsMax, float64(p.vadRmsThreshold))
+		p.noiseFloors[ssrc] = t
+	}
+	return t
+}
+
+// NoiseFloorSnapshot returns the current adaptive VAD threshold for every
+// SSRC with an active tracker, for a debug endpoint so operators can confirm
+// it's tracking changing room conditions instead of guessing at
+// VAD_RMS_THRESHOLD.
+func (p *Processor) NoiseFloorSnapshot() []noiseFloorState {
+	p.noiseFloorMu.Lock()
+	defer p.noiseFloorMu.Unlock()
+	out := make([]noiseFloorState, 0, len(p.noiseFloors))
+	for ssrc, t := range p.noiseFloors {
+		out = append(out, noiseFloorState{SSRC: ssrc, Floor: t.Floor(), Threshold: t.Threshold()})
+	}
+	return out
+}
+
+// handleDebugVAD serves NoiseFloorSnapshot as JSON on the METRICS_ADDR mux's
+// /debug/vad route, so operators can confirm the adaptive threshold is
+// tracking changing room conditions instead of guessing at
+// VAD_RMS_THRESHOLD.
+func (p *Processor) handleDebugVAD(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(p.NoiseFloorSnapshot())
+}
@@ -0,0 +1,115 @@
+package voice
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// oggCRCTable is the lookup table for the Ogg bitstream's CRC-32 checksum
+// (RFC 3533): polynomial 0x04c11db7, MSB-first, not reflected, initial
+// value 0. This is a different CRC-32 variant than the IEEE one
+// hash/crc32 provides, so it's computed here rather than reused.
+var oggCRCTable = func() [256]uint32 {
+	var table [256]uint32
+	for i := 0; i < 256; i++ {
+		crc := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if crc&0x80000000 != 0 {
+				crc = (crc << 1) ^ 0x04c11db7
+			} else {
+				crc <<= 1
+			}
+		}
+		table[i] = crc
+	}
+	return table
+}()
+
+func oggCRC32(data []byte) uint32 {
+	var crc uint32
+	for _, b := range data {
+		crc = (crc << 8) ^ oggCRCTable[byte(crc>>24)^b]
+	}
+	return crc
+}
+
+// writeOggPage appends one Ogg page wrapping a single packet to buf, per
+// RFC 3533. Only single-packet pages are needed here (Discord's Opus
+// packets are always well under the 255*255-byte page limit), which keeps
+// the lacing/segment-table logic simple: zero or more 255-byte segments
+// followed by a final segment shorter than 255 (or an explicit zero-length
+// one, if packet is an exact multiple of 255).
+func writeOggPage(buf *bytes.Buffer, headerType byte, granulePos int64, serial, seq uint32, packet []byte) {
+	var segments []byte
+	remaining := len(packet)
+	for remaining >= 255 {
+		segments = append(segments, 255)
+		remaining -= 255
+	}
+	segments = append(segments, byte(remaining))
+
+	page := &bytes.Buffer{}
+	page.WriteString("OggS")
+	page.WriteByte(0) // version
+	page.WriteByte(headerType)
+	binary.Write(page, binary.LittleEndian, granulePos)
+	binary.Write(page, binary.LittleEndian, serial)
+	binary.Write(page, binary.LittleEndian, seq)
+	crcOffset := page.Len()
+	binary.Write(page, binary.LittleEndian, uint32(0)) // checksum placeholder
+	page.WriteByte(byte(len(segments)))
+	page.Write(segments)
+	page.Write(packet)
+
+	raw := page.Bytes()
+	crc := oggCRC32(raw)
+	binary.LittleEndian.PutUint32(raw[crcOffset:crcOffset+4], crc)
+	buf.Write(raw)
+}
+
+// buildOggOpus muxes opusPackets (one Discord-delivered Opus frame each,
+// frameSamples samples per channel at 48kHz) into a minimal Ogg Opus
+// container per RFC 7845: an OpusHead page, an OpusTags page, then one
+// data page per packet with its cumulative granule position, the last
+// page flagged EOS. preSkip is reported as 0 since the original encoder's
+// algorithmic delay isn't known when passing through frames Discord
+// already encoded; a real encode-time value would make the granule
+// position's start-trim hint exact, but 0 decodes correctly, just without
+// trimming the encoder's startup samples.
+func buildOggOpus(opusPackets [][]byte, frameSamples int, channels int) []byte {
+	const serial = 1
+	buf := &bytes.Buffer{}
+	var seq uint32
+
+	head := &bytes.Buffer{}
+	head.WriteString("OpusHead")
+	head.WriteByte(1) // version
+	head.WriteByte(byte(channels))
+	binary.Write(head, binary.LittleEndian, uint16(0))     // pre-skip
+	binary.Write(head, binary.LittleEndian, uint32(48000)) // input sample rate (informational)
+	binary.Write(head, binary.LittleEndian, int16(0))      // output gain
+	head.WriteByte(0)                                      // channel mapping family
+	writeOggPage(buf, 0x02, 0, serial, seq, head.Bytes())
+	seq++
+
+	tags := &bytes.Buffer{}
+	tags.WriteString("OpusTags")
+	vendor := "audio-orchestrator passthrough"
+	binary.Write(tags, binary.LittleEndian, uint32(len(vendor)))
+	tags.WriteString(vendor)
+	binary.Write(tags, binary.LittleEndian, uint32(0)) // no user comments
+	writeOggPage(buf, 0, 0, serial, seq, tags.Bytes())
+	seq++
+
+	var granule int64
+	for i, pkt := range opusPackets {
+		granule += int64(frameSamples)
+		headerType := byte(0)
+		if i == len(opusPackets)-1 {
+			headerType = 0x04 // EOS
+		}
+		writeOggPage(buf, headerType, granule, serial, seq, pkt)
+		seq++
+	}
+	return buf.Bytes()
+}
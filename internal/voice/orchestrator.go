@@ -63,7 +63,7 @@ func (p *Processor) maybeForwardToOrchestrator(ssrc uint32, a *transcriptAgg, te
 				ctxReq, cancelReq := context.WithTimeout(context.Background(), time.Duration(timeoutMs)*time.Millisecond)
 				req, rerr := http.NewRequestWithContext(ctxReq, "POST", orchestratorURL, bytes.NewReader(b))
 				if rerr != nil {
-					logging.Debugw("orchestrator: new request error", "err", rerr, "correlation_id", correlationID)
+					logging.Debug("orchestrator: new request error", "err", rerr, "correlation_id", correlationID)
 					cancelReq()
 					err = rerr
 					break
@@ -76,7 +76,7 @@ func (p *Processor) maybeForwardToOrchestrator(ssrc uint32, a *transcriptAgg, te
 				resp, err = client.Do(req)
 				cancelReq()
 				if err != nil {
-					logging.Debugw("orchestrator: POST attempt failed", "attempt", i+1, "err", err, "correlation_id", correlationID)
+					logging.Debug("orchestrator: POST attempt failed", "attempt", i+1, "err", err, "correlation_id", correlationID)
 					if i < attempts-1 {
 						time.Sleep(time.Duration(200*(1<<i)) * time.Millisecond)
 						continue
@@ -86,24 +86,24 @@ func (p *Processor) maybeForwardToOrchestrator(ssrc uint32, a *transcriptAgg, te
 				break
 			}
 			if resp == nil {
-				logging.Debugw("orchestrator: no response received", "correlation_id", correlationID)
+				logging.Debug("orchestrator: no response received", "correlation_id", correlationID)
 				return
 			}
 			defer resp.Body.Close()
 			body, _ := io.ReadAll(resp.Body)
 			if resp.StatusCode >= 300 {
-				logging.Warnw("orchestrator: returned non-2xx", "status", resp.StatusCode, "correlation_id", correlationID)
+				logging.Warn("orchestrator: returned non-2xx", "status", resp.StatusCode, "correlation_id", correlationID)
 				return
 			}
-			logging.Infow("orchestrator: forwarded transcript", "status", resp.StatusCode, "correlation_id", correlationID)
+			logging.Info("orchestrator: forwarded transcript", "status", resp.StatusCode, "correlation_id", correlationID)
 
 			var orchOut map[string]interface{}
 			if err := json.Unmarshal(body, &orchOut); err == nil {
 				if bstr := strings.TrimSpace(string(body)); bstr != "" {
 					if len(bstr) > 2000 {
-						logging.Debugw("orchestrator: response (truncated)", "correlation_id", correlationID, "body_len", len(bstr))
+						logging.Debug("orchestrator: response (truncated)", "correlation_id", correlationID, "body_len", len(bstr))
 					} else {
-						logging.Debugw("orchestrator: response body", "correlation_id", correlationID, "body", bstr)
+						logging.Debug("orchestrator: response body", "correlation_id", correlationID, "body", bstr)
 					}
 				}
 				if choices, ok := orchOut["choices"].([]interface{}); ok && len(choices) > 0 {
@@ -111,8 +111,8 @@ func (p *Processor) maybeForwardToOrchestrator(ssrc uint32, a *transcriptAgg, te
 						if msg, ok := ch0["message"].(map[string]interface{}); ok {
 							if content, ok := msg["content"].(string); ok && strings.TrimSpace(content) != "" {
 								replyText := strings.TrimSpace(content)
-								logging.Infow("orchestrator: reply received", "correlation_id", correlationID, "reply_len", len(replyText))
-								logging.Debugw("orchestrator: reply text", "correlation_id", correlationID, "reply", replyText)
+								logging.Info("orchestrator: reply received", "correlation_id", correlationID, "reply_len", len(replyText))
+								logging.Debug("orchestrator: reply text", "correlation_id", correlationID, "reply", replyText)
 
 								if p.saveAudioDir != "" && correlationID != "" {
 									if path := p.findSidecarPathForCID(correlationID); path != "" {
@@ -127,12 +127,12 @@ func (p *Processor) maybeForwardToOrchestrator(ssrc uint32, a *transcriptAgg, te
 												nb, _ := json.MarshalIndent(sc, "", "  ")
 												_ = os.WriteFile(path+".tmp", nb, 0o644)
 												_ = os.Rename(path+".tmp", path)
-												logging.Infow("orchestrator: saved reply to sidecar", "path", path, "correlation_id", correlationID)
+												logging.Info("orchestrator: saved reply to sidecar", "path", path, "correlation_id", correlationID)
 											} else {
-												logging.Debugw("orchestrator: failed to unmarshal sidecar JSON", "path", path, "err", uerr, "correlation_id", correlationID)
+												logging.Debug("orchestrator: failed to unmarshal sidecar JSON", "path", path, "err", uerr, "correlation_id", correlationID)
 											}
 										} else {
-											logging.Debugw("orchestrator: failed to read sidecar for cid", "path", path, "err", rerr, "correlation_id", correlationID)
+											logging.Debug("orchestrator: failed to read sidecar for cid", "path", path, "err", rerr, "correlation_id", correlationID)
 										}
 									}
 								}
@@ -150,7 +150,7 @@ func (p *Processor) maybeForwardToOrchestrator(ssrc uint32, a *transcriptAgg, te
 										ctx2, cancel2 := context.WithTimeout(context.Background(), time.Duration(ttsTimeout)*time.Millisecond)
 										req2, rerr := http.NewRequestWithContext(ctx2, "POST", tts, bytes.NewReader(b2))
 										if rerr != nil {
-											logging.Debugw("tts: new request error", "err", rerr, "correlation_id", correlationID)
+											logging.Debug("tts: new request error", "err", rerr, "correlation_id", correlationID)
 											cancel2()
 											terr = rerr
 											break
@@ -165,7 +165,7 @@ func (p *Processor) maybeForwardToOrchestrator(ssrc uint32, a *transcriptAgg, te
 										resp2, terr = client2.Do(req2)
 										cancel2()
 										if terr != nil {
-											logging.Debugw("tts: POST attempt failed", "attempt", ti+1, "err", terr, "correlation_id", correlationID)
+											logging.Debug("tts: POST attempt failed", "attempt", ti+1, "err", terr, "correlation_id", correlationID)
 											if ti < ttsAttempts-1 {
 												time.Sleep(time.Duration(200*(1<<ti)) * time.Millisecond)
 												continue
@@ -175,28 +175,28 @@ func (p *Processor) maybeForwardToOrchestrator(ssrc uint32, a *transcriptAgg, te
 										break
 									}
 									if terr != nil {
-										logging.Debugw("tts: POST failed", "err", terr, "correlation_id", correlationID)
+										logging.Debug("tts: POST failed", "err", terr, "correlation_id", correlationID)
 									} else if resp2 != nil {
 										defer resp2.Body.Close()
 										if resp2.StatusCode >= 300 {
 											_, _ = io.ReadAll(resp2.Body)
-											logging.Warnw("tts: returned non-2xx", "status", resp2.StatusCode, "correlation_id", correlationID)
+											logging.Warn("tts: returned non-2xx", "status", resp2.StatusCode, "correlation_id", correlationID)
 										} else {
 											audioBytes, rerr := io.ReadAll(resp2.Body)
 											if rerr != nil {
-												logging.Debugw("tts: failed to read response body", "err", rerr, "correlation_id", correlationID)
+												logging.Debug("tts: failed to read response body", "err", rerr, "correlation_id", correlationID)
 											} else if p.saveAudioDir != "" {
 												tsTs := time.Now().UTC().Format("20060102T150405.000Z")
 												base := fmt.Sprintf("%s/%s_ssrc%d_tts", strings.TrimRight(p.saveAudioDir, "/"), tsTs, ssrc)
 												fname := base + ".wav"
 												tmp := fname + ".tmp"
 												if err := os.WriteFile(tmp, audioBytes, 0o644); err != nil {
-													logging.Debugw("tts: failed to write tmp file", "err", err, "path", tmp, "correlation_id", correlationID)
+													logging.Debug("tts: failed to write tmp file", "err", err, "path", tmp, "correlation_id", correlationID)
 												} else if err := os.Rename(tmp, fname); err != nil {
-													logging.Debugw("tts: failed to rename tmp file", "err", err, "tmp", tmp, "final", fname, "correlation_id", correlationID)
+													logging.Debug("tts: failed to rename tmp file", "err", err, "tmp", tmp, "final", fname, "correlation_id", correlationID)
 													_ = os.Remove(tmp)
 												} else {
-													logging.Infow("tts: saved audio to disk", "path", fname, "correlation_id", correlationID)
+													logging.Info("tts: saved audio to disk", "path", fname, "correlation_id", correlationID)
 													if p.saveAudioDir != "" && correlationID != "" {
 														if path := p.findSidecarPathForCID(correlationID); path != "" {
 															if sb, rerr := os.ReadFile(path); rerr == nil {
@@ -207,12 +207,12 @@ func (p *Processor) maybeForwardToOrchestrator(ssrc uint32, a *transcriptAgg, te
 																	nb, _ := json.MarshalIndent(sc, "", "  ")
 																	_ = os.WriteFile(path+".tmp", nb, 0o644)
 																	_ = os.Rename(path+".tmp", path)
-																	logging.Infow("tts: saved tts path to sidecar", "path", path, "correlation_id", correlationID)
+																	logging.Info("tts: saved tts path to sidecar", "path", path, "correlation_id", correlationID)
 																} else {
-																	logging.Debugw("tts: failed to unmarshal sidecar JSON", "path", path, "err", uerr, "correlation_id", correlationID)
+																	logging.Debug("tts: failed to unmarshal sidecar JSON", "path", path, "err", uerr, "correlation_id", correlationID)
 																}
 															} else {
-																logging.Debugw("tts: failed to read sidecar for cid", "path", path, "err", rerr, "correlation_id", correlationID)
+																logging.Debug("tts: failed to read sidecar for cid", "path", path, "err", rerr, "correlation_id", correlationID)
 															}
 														}
 													}
@@ -0,0 +1,369 @@
+package voice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/discord-voice-lab/internal/logging"
+	"github.com/discord-voice-lab/internal/voice/llm"
+	"github.com/discord-voice-lab/internal/voice/outbound"
+)
+
+// errPermanentOrchestratorJob marks the raw "openai" path's failure (see
+// sendOrchestratorRawRequest) as not worth retrying against the same
+// backend later via the Dispatcher's own backoff; sendOrchestratorJob still
+// tries the next name in Processor.llmFallbackChain. Mirrors
+// llm.ErrPermanent's role for the llm.Provider-routed path; isJobErrPermanent
+// checks both so sendOrchestratorJob's fallback loop treats them the same
+// regardless of which path a given name in the chain takes.
+var errPermanentOrchestratorJob = errors.New("orchestrator job: permanent error")
+
+// isJobErrPermanent reports whether err - from either the raw "openai" SSE
+// path or an llm.Provider - is permanent, so that sendOrchestratorJob drops
+// the job (outbound.ErrDrop) when every name tried failed this way: true
+// for llm.ErrPermanent or errPermanentOrchestratorJob, false otherwise,
+// matching the "retryable
+// unless proven otherwise" default every other error classification in this
+// package already uses.
+func isJobErrPermanent(err error) bool {
+	return errors.Is(err, llm.ErrPermanent) || errors.Is(err, errPermanentOrchestratorJob)
+}
+
+const (
+	orchestratorJobKind = "orchestrator_forward"
+	ttsJobKind          = "tts_synthesize"
+
+	// conversationalJobStaleAfter bounds how long a durably-queued
+	// orchestrator/TTS job will keep being retried. Both carry a live voice
+	// conversation turn; replaying a stale turn's reply (and its TTS audio)
+	// minutes after the user moved on is worse than dropping it, so jobs
+	// older than this are dropped instead of requeued.
+	conversationalJobStaleAfter = 60 * time.Second
+)
+
+// isStaleJob reports whether job was created more than maxAge ago.
+func isStaleJob(job outbound.Job, maxAge time.Duration) bool {
+	return time.Since(time.Unix(job.CreatedAtUnix, 0)) > maxAge
+}
+
+// dispatcherQueuePath returns a path under saveAudioDir for a Dispatcher's
+// durable queue file, or "" (in-memory only, no restart survival) when
+// saveAudioDir isn't configured.
+func dispatcherQueuePath(saveAudioDir, name string) string {
+	if saveAudioDir == "" {
+		return ""
+	}
+	return filepath.Join(saveAudioDir, "."+name)
+}
+
+// breakerConfigFromEnv reads an outbound.BreakerConfig from the three named
+// env vars, falling back to defaults tolerant enough not to trip on a
+// handful of errors: 50% failure ratio over at least 5 samples, 30s cooldown.
+func breakerConfigFromEnv(failureRatioVar, minSamplesVar, coolDownVar string) outbound.BreakerConfig {
+	cfg := outbound.BreakerConfig{FailureRatio: 0.5, MinSamples: 5, CoolDown: 30 * time.Second}
+	if v := os.Getenv(failureRatioVar); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 && f <= 1 {
+			cfg.FailureRatio = f
+		}
+	}
+	if v := os.Getenv(minSamplesVar); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.MinSamples = n
+		}
+	}
+	if v := os.Getenv(coolDownVar); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.CoolDown = time.Duration(n) * time.Second
+		}
+	}
+	return cfg
+}
+
+// limiterConfigFromEnv reads an outbound.LimiterConfig from the two named
+// env vars, defaulting to a generous 5 req/s with burst of 10 so the limiter
+// only bites under genuine overload.
+func limiterConfigFromEnv(capacityVar, refillVar string) outbound.LimiterConfig {
+	cfg := outbound.LimiterConfig{Capacity: 10, RefillPerSec: 5}
+	if v := os.Getenv(capacityVar); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 {
+			cfg.Capacity = f
+		}
+	}
+	if v := os.Getenv(refillVar); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 {
+			cfg.RefillPerSec = f
+		}
+	}
+	return cfg
+}
+
+// orchestratorJobPayload is the JSON persisted for one orchestratorJobKind
+// job; it carries everything sendOrchestratorJob needs to rebuild the POST
+// after a process restart. No auth token here: it would otherwise be
+// persisted in plaintext to the queue file and served back by HandleInspect
+// on the METRICS_ADDR debug mux. sendOrchestratorJob resolves it fresh via
+// p.orchestratorAuthToken(payload.UID) at send time instead, same as every
+// other secret read in this package.
+type orchestratorJobPayload struct {
+	URL           string `json:"url"`
+	UID           string `json:"uid"`
+	SSRC          uint32 `json:"ssrc"`
+	UserContent   string `json:"user_content"`
+	CorrelationID string `json:"correlation_id"`
+}
+
+// enqueueOrchestratorForward replaces the old fire-and-forget goroutine with
+// an inline sleep-retry loop: the job is durably queued and
+// p.orchestratorDispatcher.Run owns retry/backoff/circuit-breaking from
+// here on, so a crash mid-retry no longer drops the forward silently.
+//
+// The user turn is appended to p.conversationStore here, once per
+// utterance, rather than inside sendOrchestratorJob: that handler runs once
+// per Dispatcher attempt (and once per fallback-chain name within an
+// attempt), and would otherwise append the same turn again on every retry.
+func (p *Processor) enqueueOrchestratorForward(orchestratorURL, uid string, ssrc uint32, userContent, correlationID string) {
+	p.appendUserTurn(uid, correlationID, userContent)
+	payload := orchestratorJobPayload{
+		URL:           orchestratorURL,
+		UID:           uid,
+		SSRC:          ssrc,
+		UserContent:   userContent,
+		CorrelationID: correlationID,
+	}
+	if err := p.orchestratorDispatcher.Enqueue(orchestratorJobKind, orchestratorURL, payload); err != nil {
+		logging.Warn("orchestrator: failed to enqueue forward job", "err", err, "correlation_id", correlationID)
+	}
+}
+
+// sendOrchestratorJob is the outbound.Handler for orchestratorJobKind: a
+// single-attempt dispatch (Dispatcher itself owns retry across ticks). The
+// llmRouter-resolved name plus p.llmFallbackChain form the ordered list of
+// names to try this attempt - e.g. a cloud provider primary falling back to
+// a local Ollama instance. Every name is tried in order regardless of how
+// the previous one failed: a permanent error (isJobErrPermanent) only means
+// retrying that same backend won't help, not that the next one wouldn't -
+// an expired API key against the primary says nothing about whether a local
+// Ollama fallback would succeed. The job is only dropped (outbound.ErrDrop)
+// if every name tried failed permanently; if any failed transiently, the
+// whole job is left for the Dispatcher to retry even if a later name in the
+// chain then failed permanently. "openai" is handled via sendOrchestratorRawRequest's
+// raw PostWithRetries call (so it keeps sharing a per-URL circuit breaker
+// with the MCP tool-call loop and speaking this endpoint's exact request
+// shape); every other
+// name goes through generateViaProvider and an llm.Provider. Because
+// "openai" is just another name in the list, it participates in the
+// fallback chain whether it's the primary or a fallback entry.
+func (p *Processor) sendOrchestratorJob(ctx context.Context, job outbound.Job) error {
+	var payload orchestratorJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("orchestrator job: unmarshal payload: %w", err)
+	}
+	if isStaleJob(job, conversationalJobStaleAfter) {
+		logging.Warn("orchestrator: dropping stale queued forward job", "correlation_id", payload.CorrelationID, "attempts", job.Attempts)
+		return outbound.ErrDrop
+	}
+	authToken := p.orchestratorAuthToken(payload.UID)
+
+	primary := p.llmRouter.resolve(payload.UID, func(n string) bool { _, ok := p.llmProviders[n]; return ok })
+	names := append([]string{primary}, p.llmFallbackChain...)
+
+	var lastErr error
+	anyTransient := false
+	for i, name := range names {
+		// A multi-name chain can itself eat into conversationalJobStaleAfter's
+		// budget (each failed attempt pays its own timeout); re-check before
+		// every attempt after the first so a turn that's gone stale mid-chain
+		// still gets dropped instead of finally succeeding against a stale
+		// conversation.
+		if i > 0 && isStaleJob(job, conversationalJobStaleAfter) {
+			logging.Warn("orchestrator: turn went stale mid-fallback, stopping chain", "correlation_id", payload.CorrelationID, "attempts", job.Attempts, "stopped_at", name)
+			break
+		}
+		var stepErr error
+		if name == "openai" {
+			stepErr = p.sendOrchestratorRawRequest(ctx, payload, authToken)
+		} else if provider, ok := p.llmProviders[name]; ok {
+			stepErr = p.generateViaProvider(ctx, provider, name, payload.UID, payload.SSRC, payload.UserContent, payload.CorrelationID, authToken)
+		} else {
+			logging.Warn("orchestrator: fallback chain names an unregistered provider, skipping", "provider", name, "correlation_id", payload.CorrelationID)
+			continue
+		}
+		if stepErr == nil {
+			return nil
+		}
+		lastErr = stepErr
+		if !isJobErrPermanent(stepErr) {
+			anyTransient = true
+		}
+		if i < len(names)-1 {
+			logging.Info("orchestrator: provider failed, trying next fallback", "provider", name, "correlation_id", payload.CorrelationID)
+		}
+	}
+	if lastErr == nil {
+		// Every name in the list was unregistered (shouldn't happen - the
+		// router only resolves to a name p.llmProviders/this handler knows
+		// about), or the chain stopped on staleness before any attempt ran;
+		// nothing to report or retry.
+		return nil
+	}
+	if anyTransient {
+		// At least one name in the chain failed in a way worth retrying -
+		// e.g. the primary was merely rate-limited, even if a later
+		// fallback then failed permanently (a misconfigured API key) -
+		// so leave this job for the Dispatcher to requeue instead of
+		// dropping it based solely on how the last name in the chain
+		// happened to fail.
+		return lastErr
+	}
+	return outbound.ErrDrop
+}
+
+// sendOrchestratorRawRequest is the "openai" name's handling within
+// sendOrchestratorJob's fallback loop: a single-attempt POST of payload's
+// chat turn, still routed through PostWithRetries (sharing its per-URL
+// circuit breaker with the MCP tool-call loop) rather than an llm.Provider,
+// since this
+// endpoint's exact request shape (a bare correlation_id field, no llm.Config
+// indirection) isn't one of the llm package's backends yet.
+//
+// When p.toolRegistry has any tools registered (MCP_SERVER_URLS
+// configured), the request is instead handed to runToolCallLoop, which
+// attaches those tools to the payload and exchanges non-streaming round
+// trips with the orchestrator until it stops calling them - see
+// mcp_tools.go. With no tools registered this function behaves exactly as
+// before.
+func (p *Processor) sendOrchestratorRawRequest(ctx context.Context, payload orchestratorJobPayload, authToken string) error {
+	messages := []map[string]interface{}{
+		{"role": "system", "content": fmt.Sprintf("source: discord-voice-lab; user_id: %s; ssrc: %d; correlation_id: %s", payload.UID, payload.SSRC, payload.CorrelationID)},
+	}
+	for _, turn := range p.conversationMessages(ctx, payload.UID, payload.CorrelationID, payload.UserContent) {
+		messages = append(messages, map[string]interface{}{"role": turn.Role, "content": turn.Content})
+	}
+	guildID, channelID := p.originIDs()
+	headers := orchestratorOriginHeaders(guildID, channelID, payload.UID)
+
+	if p.toolRegistry != nil && p.toolRegistry.Len() > 0 {
+		replyText, err := p.runToolCallLoop(ctx, payload.URL, messages, authToken, payload.CorrelationID, headers)
+		if err != nil {
+			return fmt.Errorf("orchestrator job: tool call loop: %w", err)
+		}
+		p.patchSidecar(payload.CorrelationID, func(sc map[string]interface{}) {
+			sc["orchestrator_reply"] = replyText
+			sc["orchestrator_response_received_utc"] = time.Now().UTC().Format(time.RFC3339Nano)
+		})
+		p.appendAssistantTurn(payload.UID, payload.CorrelationID, replyText)
+		p.enqueueTTSSynthesis(replyText, payload.UID, payload.SSRC, payload.CorrelationID)
+		return nil
+	}
+
+	chatPayload := map[string]interface{}{
+		"model":          os.Getenv("ORCHESTRATOR_MODEL"),
+		"messages":       messages,
+		"correlation_id": payload.CorrelationID,
+		"stream":         true,
+	}
+	if chatPayload["model"] == "" || chatPayload["model"] == nil {
+		delete(chatPayload, "model")
+	}
+	b, err := json.Marshal(chatPayload)
+	if err != nil {
+		return fmt.Errorf("orchestrator job: marshal chat payload: %w", err)
+	}
+
+	timeoutMs := p.orchestratorTimeoutMS
+	if timeoutMs <= 0 {
+		timeoutMs = 30000
+	}
+	// A single attempt here: Dispatcher itself already owns retry/backoff
+	// across ticks for this job (job.Attempts, d.maxAttempts). Still routed
+	// through PostWithRetries rather than a raw client.Do so this POST shares
+	// its per-URL circuit breaker with runToolCallLoop's POSTs to the same
+	// URL - a failure seen by one trips the breaker for both. ctx is threaded through so canceling it (shutdown)
+	// aborts the in-flight request instead of waiting out timeoutMs.
+	resp, err := PostWithRetries(ctx, nil, payload.URL, b, authToken, timeoutMs, 1, payload.CorrelationID, headers)
+	if err != nil {
+		return fmt.Errorf("orchestrator job: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		logging.Warn("orchestrator: returned non-2xx", "status", resp.StatusCode, "correlation_id", payload.CorrelationID, "body_len", len(body))
+		if isPermanentStatus(resp.StatusCode) {
+			// Client errors (bad request, auth, etc.) won't be fixed by
+			// retrying the identical payload later; drop rather than queue
+			// a stale conversational turn for retry. Our own malformed
+			// request, not the endpoint being down, so don't count it
+			// against the breaker either. 408/425/429 are excluded from
+			// isPermanentStatus, so those fall through to the Dispatcher's
+			// own backoff/retry (or the next fallback name) instead of
+			// being classified permanent here.
+			return fmt.Errorf("orchestrator job: status %d: %w", resp.StatusCode, errPermanentOrchestratorJob)
+		}
+		return fmt.Errorf("orchestrator job: status %d", resp.StatusCode)
+	}
+	logging.Info("orchestrator: forwarded transcript", "status", resp.StatusCode, "correlation_id", payload.CorrelationID)
+
+	if strings.Contains(resp.Header.Get("Content-Type"), "text/event-stream") {
+		p.handleOrchestratorStreamResponse(resp, payload.SSRC, payload.UID, payload.CorrelationID, authToken)
+	} else {
+		body, _ := io.ReadAll(resp.Body)
+		p.handleOrchestratorJSONResponse(body, payload.SSRC, payload.UID, payload.CorrelationID, authToken)
+	}
+	return nil
+}
+
+// ttsJobPayload is the JSON persisted for one ttsJobKind job. No auth token
+// here: tts.Provider already carries its own API key in its Config (set at
+// registry-construction time from TTS_AUTH_TOKEN/ELEVENLABS_API_KEY/etc.),
+// so there's nothing to persist.
+type ttsJobPayload struct {
+	Text          string `json:"text"`
+	UID           string `json:"uid"`
+	SSRC          uint32 `json:"ssrc"`
+	CorrelationID string `json:"correlation_id"`
+}
+
+// enqueueTTSSynthesis durably queues a whole-reply TTS synthesis job for use
+// by handleOrchestratorJSONResponse and generateViaProvider, whose replies
+// arrive all at once rather than sentence-by-sentence. The per-sentence
+// streaming path stays on synthesizeTTSAndSave's synchronous retry.
+func (p *Processor) enqueueTTSSynthesis(text, uid string, ssrc uint32, correlationID string) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	payload := ttsJobPayload{Text: text, UID: uid, SSRC: ssrc, CorrelationID: correlationID}
+	if err := p.ttsDispatcher.Enqueue(ttsJobKind, "tts", payload); err != nil {
+		logging.Warn("tts: failed to enqueue synthesis job", "err", err, "correlation_id", correlationID)
+	}
+}
+
+// sendTTSJob is the outbound.Handler for ttsJobKind: a single synthesizeOnce
+// attempt per dispatch tick, Dispatcher itself owning retry/backoff.
+func (p *Processor) sendTTSJob(ctx context.Context, job outbound.Job) error {
+	var payload ttsJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("tts job: unmarshal payload: %w", err)
+	}
+	if isStaleJob(job, conversationalJobStaleAfter) {
+		logging.Warn("tts: dropping stale queued synthesis job", "correlation_id", payload.CorrelationID, "attempts", job.Attempts)
+		return outbound.ErrDrop
+	}
+	wavPath, ok, err := p.synthesizeOnce(ctx, payload.Text, payload.UID, payload.SSRC, payload.CorrelationID)
+	if err != nil {
+		return fmt.Errorf("tts job: %w", err)
+	}
+	if !ok {
+		// Not an error (e.g. no TTS backend configured); nothing to retry.
+		return nil
+	}
+	p.appendTTSWavPath(payload.CorrelationID, wavPath)
+	return nil
+}
@@ -0,0 +1,455 @@
+package voice
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/discord-voice-lab/internal/logging"
+	"github.com/discord-voice-lab/internal/voice/llm"
+	"github.com/discord-voice-lab/internal/voice/tts"
+)
+
+// streamSentenceSplitter accumulates orchestrator SSE deltas and yields
+// complete sentences as soon as they're available, so handleOrchestratorStreamResponse
+// can start TTS synthesis on each sentence while the LLM is still generating
+// the rest of the reply instead of waiting for [DONE].
+type streamSentenceSplitter struct {
+	buf strings.Builder
+}
+
+// Feed appends delta to the buffer and returns any sentences it completed:
+// ended by ., !, ?, or \n, or forced out once the buffer reaches ~120
+// characters without having seen any terminating punctuation yet.
+func (s *streamSentenceSplitter) Feed(delta string) []string {
+	var out []string
+	for _, r := range delta {
+		s.buf.WriteRune(r)
+		if r == '.' || r == '!' || r == '?' || r == '\n' || s.buf.Len() >= 120 {
+			if sentence := strings.TrimSpace(s.buf.String()); sentence != "" {
+				out = append(out, sentence)
+			}
+			s.buf.Reset()
+		}
+	}
+	return out
+}
+
+// Flush returns (and clears) whatever's left in the buffer once the stream
+// ends, since the final fragment may not end on punctuation.
+func (s *streamSentenceSplitter) Flush() string {
+	sentence := strings.TrimSpace(s.buf.String())
+	s.buf.Reset()
+	return sentence
+}
+
+// handleOrchestratorStreamResponse reads an OpenAI-style `stream: true` chat
+// completions response (data: framed JSON chunks, terminated by
+// `data: [DONE]`), accumulating choices[0].delta.content into the full
+// reply while feeding each delta to a streamSentenceSplitter. Completed
+// sentences are handed to a background worker that synthesizes and saves
+// TTS audio for each one as it's produced, so audio generation overlaps
+// the LLM's remaining output instead of waiting for the full reply.
+func (p *Processor) handleOrchestratorStreamResponse(resp *http.Response, ssrc uint32, uid, correlationID, authToken string) {
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	next := func() (string, error) {
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || !strings.HasPrefix(line, "data:") {
+				continue
+			}
+			payload := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			if payload == "[DONE]" {
+				return "", io.EOF
+			}
+			var chunk map[string]interface{}
+			if err := json.Unmarshal([]byte(payload), &chunk); err != nil {
+				logging.Debug("orchestrator: failed to unmarshal stream chunk", "err", err, "correlation_id", correlationID)
+				continue
+			}
+			choices, _ := chunk["choices"].([]interface{})
+			if len(choices) == 0 {
+				continue
+			}
+			ch0, _ := choices[0].(map[string]interface{})
+			delta, _ := ch0["delta"].(map[string]interface{})
+			content, _ := delta["content"].(string)
+			if content == "" {
+				continue
+			}
+			return content, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return "", fmt.Errorf("orchestrator: stream read: %w", err)
+		}
+		return "", io.EOF
+	}
+
+	// streamErr is only used to gate conversation-history persistence below;
+	// it's otherwise ignored (not returned or retried) since this is the
+	// "openai" raw-HTTP path, which (like handleOrchestratorJSONResponse's
+	// own parse failures) has no fallback left to try once the initial 2xx
+	// response is in hand - a mid-stream read failure just means a
+	// truncated reply, already logged above.
+	reply, deltaCount, streamErr := p.streamDeltasToTTS(next, uid, ssrc, correlationID, authToken)
+	logging.Info("orchestrator: stream reply complete", "correlation_id", correlationID, "reply_len", len(reply), "stream_deltas", deltaCount)
+	p.patchSidecar(correlationID, func(sc map[string]interface{}) {
+		if reply != "" {
+			sc["orchestrator_reply"] = reply
+		}
+		sc["orchestrator_response_received_utc"] = time.Now().UTC().Format(time.RFC3339Nano)
+		sc["stream_deltas"] = deltaCount
+	})
+	if streamErr == nil {
+		// A truncated reply (non-nil streamErr) isn't saved as the
+		// assistant's turn - same reasoning generateViaProvider's streaming
+		// branch already applies - since a half-finished sentence fed back
+		// as context on the next turn would confuse the conversation more
+		// than an occasional missing turn does.
+		p.appendAssistantTurn(uid, correlationID, reply)
+	}
+}
+
+// streamDeltasToTTS drains next - called repeatedly until it returns io.EOF
+// or another error - into a streamSentenceSplitter, synthesizing and saving
+// TTS audio for each completed sentence as soon as it's available rather
+// than waiting for the full reply. Shared by handleOrchestratorStreamResponse
+// (next reads the default OpenAI-style SSE forwarding) and generateViaProvider
+// (next reads an llm.Stream) so both overlap TTS generation with the rest of
+// the reply the same way. Returns the full reply text gathered so far, the
+// number of deltas received, and next's terminal error - nil for a clean
+// io.EOF, non-nil (and already logged here) for anything else, so a caller
+// that can act on it (generateViaProvider, for its fallback-chain decision)
+// doesn't have to also watch the log for a partial/truncated reply.
+func (p *Processor) streamDeltasToTTS(next func() (string, error), uid string, ssrc uint32, correlationID, authToken string) (string, int, error) {
+	sentenceCh := make(chan string, 16)
+	ttsDone := make(chan struct{})
+	go func() {
+		defer close(ttsDone)
+		for sentence := range sentenceCh {
+			if wavPath, ok := p.synthesizeTTSAndSave(sentence, uid, ssrc, correlationID, authToken); ok {
+				p.appendTTSWavPath(correlationID, wavPath)
+			}
+		}
+	}()
+
+	splitter := &streamSentenceSplitter{}
+	var replyText strings.Builder
+	deltaCount := 0
+	var streamErr error
+	for {
+		content, err := next()
+		if err != nil {
+			if err != io.EOF {
+				streamErr = err
+				logging.Warn("orchestrator: stream ended with error", "err", err, "correlation_id", correlationID, "stream_deltas", deltaCount)
+			}
+			break
+		}
+		deltaCount++
+		replyText.WriteString(content)
+		for _, sentence := range splitter.Feed(content) {
+			sentenceCh <- sentence
+		}
+	}
+	if tail := splitter.Flush(); tail != "" {
+		sentenceCh <- tail
+	}
+	close(sentenceCh)
+	<-ttsDone
+	return strings.TrimSpace(replyText.String()), deltaCount, streamErr
+}
+
+// handleOrchestratorJSONResponse parses a non-streaming OpenAI-style chat
+// completions response (choices[0].message.content) and, same as the
+// streaming path, synthesizes+saves TTS audio for the whole reply. This is
+// the fallback used when the orchestrator ignores `stream: true` and
+// responds with application/json instead of text/event-stream.
+func (p *Processor) handleOrchestratorJSONResponse(body []byte, ssrc uint32, uid, correlationID, authToken string) {
+	if bstr := strings.TrimSpace(string(body)); bstr != "" {
+		if len(bstr) > 2000 {
+			logging.Debug("orchestrator: response (truncated)", "correlation_id", correlationID, "body_len", len(bstr))
+		} else {
+			logging.Debug("orchestrator: response body", "correlation_id", correlationID, "body", bstr)
+		}
+	}
+	var orchOut map[string]interface{}
+	if err := json.Unmarshal(body, &orchOut); err != nil {
+		logging.Debug("orchestrator: failed to unmarshal response", "err", err, "correlation_id", correlationID)
+		return
+	}
+	choices, ok := orchOut["choices"].([]interface{})
+	if !ok || len(choices) == 0 {
+		return
+	}
+	ch0, ok := choices[0].(map[string]interface{})
+	if !ok {
+		return
+	}
+	msg, ok := ch0["message"].(map[string]interface{})
+	if !ok {
+		return
+	}
+	content, ok := msg["content"].(string)
+	if !ok || strings.TrimSpace(content) == "" {
+		return
+	}
+	replyText := strings.TrimSpace(content)
+	logging.Info("orchestrator: reply received", "correlation_id", correlationID, "reply_len", len(replyText))
+	logging.Debug("orchestrator: reply text", "correlation_id", correlationID, "reply", replyText)
+
+	p.patchSidecar(correlationID, func(sc map[string]interface{}) {
+		sc["orchestrator_reply"] = replyText
+		sc["orchestrator_response_received_utc"] = time.Now().UTC().Format(time.RFC3339Nano)
+		if procMs, ok := orchOut["processing_ms"].(float64); ok {
+			sc["orchestrator_processing_ms"] = int(procMs)
+		}
+	})
+
+	p.appendAssistantTurn(uid, correlationID, replyText)
+	p.enqueueTTSSynthesis(replyText, uid, ssrc, correlationID)
+}
+
+// generateViaProvider handles a non-"openai" name in sendOrchestratorJob's
+// fallback loop. If provider also implements llm.StreamingProvider, it
+// streams the reply and overlaps TTS generation with the rest of it, same
+// as handleOrchestratorStreamResponse does for the "openai" raw path;
+// otherwise it falls back to a single buffered Generate call and
+// synthesizes TTS for the whole reply once it's back.
+//
+// req.Messages is p.conversationMessages(ctx, uid, correlationID,
+// userContent) - uid's prior turns, including this one - prefixed by the
+// per-request system tag, so the provider sees real conversation history
+// instead of just this utterance; a successful reply is appended back via
+// appendAssistantTurn before returning so the next turn's window includes
+// it.
+//
+// ctx is the job's own context (shutdown-aware, no per-call deadline of its
+// own) and is used as-is for the streaming branch: GenerateStream's request
+// and every subsequent stream.Recv() share its lifetime, so a slow TTS
+// backend applying backpressure to the per-sentence synthesis goroutine
+// (see streamDeltasToTTS) can't make p.orchestratorTimeoutMS cancel an
+// otherwise-healthy LLM connection mid-reply. The non-streaming branch's
+// single network round trip is still bounded by orchestratorTimeoutMS,
+// same as before.
+//
+// The returned error is provider.Generate/GenerateStream's own (unwrapped,
+// so errors.Is(err, llm.ErrPermanent) still works for the caller's
+// fallback-chain decision) - it's also already logged here, since a
+// fallback attempt succeeding shouldn't erase the record that the previous
+// one failed.
+//
+// Every call is gated by providerGuardFor(name)'s breaker/limiter pair
+// before anything reaches the network, and the breaker is fed the outcome
+// afterward - the same admission control PostWithRetries already applies to
+// the "openai" raw path, extended to cover this function's llm.Provider
+// backends too (and kept per-name rather than shared across them, so one
+// unhealthy fallback can't throttle the others).
+func (p *Processor) generateViaProvider(ctx context.Context, provider llm.Provider, name, uid string, ssrc uint32, userContent, correlationID, authToken string) error {
+	breaker, limiter := providerGuardFor(name)
+	if !breaker.Allow() {
+		llmProviderCallsTotal.WithLabelValues(name, "breaker_open").Inc()
+		return fmt.Errorf("orchestrator: provider %s: circuit open", name)
+	}
+	if !limiter.Allow() {
+		llmProviderCallsTotal.WithLabelValues(name, "throttled").Inc()
+		return fmt.Errorf("orchestrator: provider %s: rate limited", name)
+	}
+	llmProviderCallsTotal.WithLabelValues(name, "allowed").Inc()
+
+	messages := []llm.Message{
+		{Role: "system", Content: fmt.Sprintf("source: discord-voice-lab; user_id: %s; ssrc: %d; correlation_id: %s", uid, ssrc, correlationID)},
+	}
+	messages = append(messages, p.conversationMessages(ctx, uid, correlationID, userContent)...)
+	req := llm.Request{
+		Messages:      messages,
+		CorrelationID: correlationID,
+	}
+
+	if sp, ok := provider.(llm.StreamingProvider); ok {
+		stream, err := sp.GenerateStream(ctx, req)
+		if err != nil {
+			breaker.RecordResult(false)
+			llmProviderCallsTotal.WithLabelValues(name, "failure").Inc()
+			logging.Warn("orchestrator: provider stream failed", "provider", name, "err", err, "correlation_id", correlationID)
+			return err
+		}
+		defer stream.Close()
+		next := func() (string, error) {
+			delta, err := stream.Recv()
+			return delta.Content, err
+		}
+		replyText, deltaCount, streamErr := p.streamDeltasToTTS(next, uid, ssrc, correlationID, authToken)
+		breaker.RecordResult(streamErr == nil)
+		if streamErr == nil {
+			llmProviderCallsTotal.WithLabelValues(name, "success").Inc()
+		} else {
+			llmProviderCallsTotal.WithLabelValues(name, "failure").Inc()
+		}
+		logging.Info("orchestrator: provider stream reply complete", "provider", name, "correlation_id", correlationID, "reply_len", len(replyText), "stream_deltas", deltaCount)
+		p.patchSidecar(correlationID, func(sc map[string]interface{}) {
+			if replyText != "" {
+				sc["orchestrator_reply"] = replyText
+			}
+			sc["orchestrator_provider"] = name
+			sc["orchestrator_response_received_utc"] = time.Now().UTC().Format(time.RFC3339Nano)
+			sc["stream_deltas"] = deltaCount
+		})
+		if streamErr == nil {
+			p.appendAssistantTurn(uid, correlationID, replyText)
+		}
+		// streamErr (already logged by streamDeltasToTTS) is returned rather
+		// than swallowed here, unlike the "openai" raw path: this name is
+		// part of sendOrchestratorJob's fallback chain, so a mid-stream
+		// failure needs to reach the caller to decide whether the next name
+		// is worth trying.
+		return streamErr
+	}
+
+	timeoutMs := p.orchestratorTimeoutMS
+	if timeoutMs <= 0 {
+		timeoutMs = 30000
+	}
+	genCtx, cancel := context.WithTimeout(ctx, time.Duration(timeoutMs)*time.Millisecond)
+	defer cancel()
+	reply, err := provider.Generate(genCtx, req)
+	breaker.RecordResult(err == nil)
+	if err != nil {
+		llmProviderCallsTotal.WithLabelValues(name, "failure").Inc()
+		logging.Warn("orchestrator: provider generate failed", "provider", name, "err", err, "correlation_id", correlationID)
+		return err
+	}
+	llmProviderCallsTotal.WithLabelValues(name, "success").Inc()
+	replyText := strings.TrimSpace(reply.Content)
+	logging.Info("orchestrator: provider reply received", "provider", name, "correlation_id", correlationID, "reply_len", len(replyText))
+	p.patchSidecar(correlationID, func(sc map[string]interface{}) {
+		sc["orchestrator_reply"] = replyText
+		sc["orchestrator_provider"] = name
+		sc["orchestrator_response_received_utc"] = time.Now().UTC().Format(time.RFC3339Nano)
+	})
+	if replyText == "" {
+		return nil
+	}
+	p.appendAssistantTurn(uid, correlationID, replyText)
+	p.enqueueTTSSynthesis(replyText, uid, ssrc, correlationID)
+	return nil
+}
+
+// synthesizeTTSAndSave synthesizes text via the ttsRouter-selected
+// tts.Provider (default "piper", which POSTs TTS_URL just as the original
+// inline TTS forwarding did) and saves the returned audio under
+// saveAudioDir, using the extension tts.ExtensionForMIME derives from the
+// provider's reported MIME type. Used only by the per-sentence streaming
+// path (streamDeltasToTTS, shared by handleOrchestratorStreamResponse and
+// generateViaProvider's streaming branch), which needs the wavPath back
+// synchronously to overlap playback with the rest of the reply; the
+// whole-reply paths go through enqueueTTSSynthesis/sendTTSJob instead, see
+// orchestrator_dispatch.go.
+func (p *Processor) synthesizeTTSAndSave(text, uid string, ssrc uint32, correlationID, authToken string) (string, bool) {
+	if strings.TrimSpace(text) == "" {
+		return "", false
+	}
+	ttsTimeout := 10000
+	if p.orchestratorTimeoutMS > 0 {
+		ttsTimeout = p.orchestratorTimeoutMS
+	}
+	ttsAttempts := 2
+	for ti := 0; ti < ttsAttempts; ti++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(ttsTimeout)*time.Millisecond)
+		wavPath, ok, err := p.synthesizeOnce(ctx, text, uid, ssrc, correlationID)
+		cancel()
+		if err == nil {
+			return wavPath, ok
+		}
+		logging.Debug("tts: synthesize attempt failed", "attempt", ti+1, "err", err, "correlation_id", correlationID)
+		if ti < ttsAttempts-1 {
+			time.Sleep(time.Duration(200*(1<<ti)) * time.Millisecond)
+		}
+	}
+	return "", false
+}
+
+// synthesizeOnce performs a single tts.Provider.Synthesize attempt (no
+// retry of its own) and, on success, saves the audio under saveAudioDir.
+// Shared by synthesizeTTSAndSave's retry loop and sendTTSJob, which relies
+// on Dispatcher-owned retry across ticks instead of an inline sleep loop.
+func (p *Processor) synthesizeOnce(ctx context.Context, text, uid string, ssrc uint32, correlationID string) (string, bool, error) {
+	name := p.ttsRouter.resolve(uid, func(n string) bool { _, ok := p.ttsProviders[n]; return ok })
+	provider, ok := p.ttsProviders[name]
+	if !ok {
+		return "", false, nil
+	}
+	if name == "piper" && os.Getenv("TTS_URL") == "" {
+		// No TTS backend configured at all; preserve the pre-chunk4-2 no-op.
+		return "", false, nil
+	}
+	audioBytes, mimeType, err := provider.Synthesize(ctx, text, "")
+	if err != nil {
+		return "", false, fmt.Errorf("tts: synthesize via %s: %w", name, err)
+	}
+	if p.saveAudioDir == "" {
+		return "", false, nil
+	}
+	fname, err := writeAudioFileAtomically(p.saveAudioDir, ssrc, tts.ExtensionForMIME(mimeType), audioBytes)
+	if err != nil {
+		return "", false, fmt.Errorf("tts: save audio: %w", err)
+	}
+	logging.Info("tts: saved audio to disk", "provider", name, "path", fname, "correlation_id", correlationID)
+	return fname, true, nil
+}
+
+// appendTTSWavPath records wavPath into the sidecar's tts_wav_paths array
+// (rather than a single tts_wav_path field) so a streamed reply's
+// sentence-by-sentence audio files are all recoverable, in the order they
+// were synthesized.
+func (p *Processor) appendTTSWavPath(correlationID, wavPath string) {
+	p.patchSidecar(correlationID, func(sc map[string]interface{}) {
+		var paths []interface{}
+		if existing, ok := sc["tts_wav_paths"].([]interface{}); ok {
+			paths = existing
+		}
+		sc["tts_wav_paths"] = append(paths, wavPath)
+		sc["tts_saved_utc"] = time.Now().UTC().Format(time.RFC3339Nano)
+	})
+}
+
+// patchSidecar loads the sidecar JSON for correlationID, applies mutate,
+// and writes it back with the package's usual tmp+rename pattern. It's
+// best-effort (same as every other sidecar update in this package): a
+// missing sidecar, a read error, or a write error just means the update is
+// dropped, not propagated as a caller-visible failure.
+func (p *Processor) patchSidecar(correlationID string, mutate func(map[string]interface{})) {
+	if p.saveAudioDir == "" || correlationID == "" {
+		return
+	}
+	path := p.findSidecarPathForCID(correlationID)
+	if path == "" {
+		return
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		logging.Debug("sidecar: failed to read for cid", "path", path, "err", err, "correlation_id", correlationID)
+		return
+	}
+	var sc map[string]interface{}
+	if err := json.Unmarshal(b, &sc); err != nil {
+		logging.Debug("sidecar: failed to unmarshal", "path", path, "err", err, "correlation_id", correlationID)
+		return
+	}
+	mutate(sc)
+	nb, err := json.MarshalIndent(sc, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.WriteFile(path+".tmp", nb, 0o644); err != nil {
+		return
+	}
+	_ = os.Rename(path+".tmp", path)
+}
@@ -0,0 +1,27 @@
+package outbound
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// HandleInspect serves the dispatcher's current queue as JSON, for mounting
+// on a debug/admin mux (see voice.Processor's METRICS_ADDR mux).
+func (d *Dispatcher) HandleInspect(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(d.Snapshot())
+}
+
+// HandleDrain triggers an immediate DrainNow pass (rather than waiting for
+// the next poll tick) and reports how many jobs succeeded/failed, for
+// mounting on a debug/admin mux. Only responds to POST to avoid an
+// unintended drain from e.g. a browser prefetch or health-checker GET.
+func (d *Dispatcher) HandleDrain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST required", http.StatusMethodNotAllowed)
+		return
+	}
+	succeeded, failed := d.DrainNow(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]int{"succeeded": succeeded, "failed": failed})
+}
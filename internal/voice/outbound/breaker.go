@@ -0,0 +1,118 @@
+package outbound
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerState is one of the three classic circuit-breaker states.
+type BreakerState int
+
+const (
+	StateClosed BreakerState = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreaker trips to open once at least minSamples of the most recent
+// windowSize outcomes have a failure ratio at or above failureRatio, and
+// stays open for coolDown before allowing a single half-open probe through.
+// A failed probe reopens it for another coolDown; a successful probe closes
+// it and resets the window.
+type CircuitBreaker struct {
+	mu           sync.Mutex
+	state        BreakerState
+	failureRatio float64
+	minSamples   int
+	windowSize   int
+	window       []bool // true = success, oldest first
+	openedAt     time.Time
+	coolDown     time.Duration
+}
+
+// NewCircuitBreaker builds a CircuitBreaker. failureRatio is a fraction in
+// [0,1]; minSamples is how many outcomes must be observed before the ratio
+// is evaluated at all (so one early failure doesn't trip it).
+func NewCircuitBreaker(failureRatio float64, minSamples int, coolDown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		failureRatio: failureRatio,
+		minSamples:   minSamples,
+		windowSize:   minSamples * 4,
+		coolDown:     coolDown,
+	}
+}
+
+// Allow reports whether a new attempt should proceed: always true when
+// closed, true for exactly one half-open probe per coolDown window, false
+// otherwise.
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case StateClosed:
+		return true
+	case StateOpen:
+		if time.Since(b.openedAt) >= b.coolDown {
+			b.state = StateHalfOpen
+			return true
+		}
+		return false
+	case StateHalfOpen:
+		// Only one probe in flight at a time; subsequent callers wait for
+		// its result (recorded via RecordResult) to move state again.
+		return false
+	default:
+		return true
+	}
+}
+
+// RecordResult feeds an attempt's outcome back into the breaker.
+func (b *CircuitBreaker) RecordResult(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateHalfOpen {
+		if success {
+			b.state = StateClosed
+			b.window = nil
+		} else {
+			b.state = StateOpen
+			b.openedAt = time.Now()
+		}
+		return
+	}
+	b.window = append(b.window, success)
+	if len(b.window) > b.windowSize {
+		b.window = b.window[len(b.window)-b.windowSize:]
+	}
+	if len(b.window) < b.minSamples {
+		return
+	}
+	failures := 0
+	for _, ok := range b.window {
+		if !ok {
+			failures++
+		}
+	}
+	if float64(failures)/float64(len(b.window)) >= b.failureRatio {
+		b.state = StateOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// State returns the breaker's current state for metrics/admin reporting.
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
@@ -0,0 +1,81 @@
+package outbound
+
+import (
+	"testing"
+	"time"
+)
+
+// TestCircuitBreakerTripsAfterMinSamples verifies the breaker stays closed
+// until minSamples outcomes have been recorded, even if every one of them
+// failed, and only opens once the window's failure ratio crosses
+// failureRatio.
+func TestCircuitBreakerTripsAfterMinSamples(t *testing.T) {
+	b := NewCircuitBreaker(0.5, 3, time.Minute)
+
+	b.RecordResult(false)
+	b.RecordResult(false)
+	if got := b.State(); got != StateClosed {
+		t.Fatalf("want StateClosed before minSamples reached, got %s", got)
+	}
+
+	b.RecordResult(false)
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("want StateOpen once minSamples failures exceed failureRatio, got %s", got)
+	}
+	if b.Allow() {
+		t.Fatalf("want Allow to refuse while open and within coolDown")
+	}
+}
+
+// TestCircuitBreakerHalfOpenProbeRecovers verifies an open breaker allows
+// exactly one probe after coolDown, and a successful probe closes it and
+// resets the failure window.
+func TestCircuitBreakerHalfOpenProbeRecovers(t *testing.T) {
+	b := NewCircuitBreaker(0.5, 2, 10*time.Millisecond)
+
+	b.RecordResult(false)
+	b.RecordResult(false)
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("want StateOpen after tripping, got %s", got)
+	}
+
+	time.Sleep(15 * time.Millisecond)
+	if !b.Allow() {
+		t.Fatalf("want Allow to grant the half-open probe once coolDown elapses")
+	}
+	if got := b.State(); got != StateHalfOpen {
+		t.Fatalf("want StateHalfOpen once the probe is granted, got %s", got)
+	}
+	if b.Allow() {
+		t.Fatalf("want a second concurrent Allow to be refused while a probe is outstanding")
+	}
+
+	b.RecordResult(true)
+	if got := b.State(); got != StateClosed {
+		t.Fatalf("want StateClosed after a successful probe, got %s", got)
+	}
+	if !b.Allow() {
+		t.Fatalf("want Allow to grant freely once closed again")
+	}
+}
+
+// TestCircuitBreakerHalfOpenProbeReopens verifies a failed half-open probe
+// reopens the breaker for another full coolDown rather than closing it.
+func TestCircuitBreakerHalfOpenProbeReopens(t *testing.T) {
+	b := NewCircuitBreaker(0.5, 2, 10*time.Millisecond)
+
+	b.RecordResult(false)
+	b.RecordResult(false)
+	time.Sleep(15 * time.Millisecond)
+	if !b.Allow() {
+		t.Fatalf("want the half-open probe to be granted")
+	}
+
+	b.RecordResult(false)
+	if got := b.State(); got != StateOpen {
+		t.Fatalf("want StateOpen after a failed probe, got %s", got)
+	}
+	if b.Allow() {
+		t.Fatalf("want Allow to refuse immediately after reopening, before the new coolDown elapses")
+	}
+}
@@ -0,0 +1,344 @@
+// Package outbound provides a durable, retrying dispatcher for outbound
+// requests (orchestrator forwarding, TTS synthesis) that previously lived as
+// fire-and-forget goroutines with an inline exponential-sleep retry loop.
+// Jobs are persisted to a JSON file (the same tmp+rename pattern used
+// throughout internal/voice) so pending work survives a process restart,
+// rather than being silently dropped on shutdown.
+package outbound
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/discord-voice-lab/internal/logging"
+)
+
+// ErrDrop is a sentinel a Handler can return to have its job removed from
+// the queue without being recorded as a circuit-breaker success or failure
+// and without counting against Attempts — for a job a handler has decided is
+// no longer worth sending (e.g. it went stale), rather than one that failed
+// to send.
+var ErrDrop = errors.New("outbound: drop job")
+
+// Job is one durable unit of outbound work. Payload is handler-defined
+// (e.g. the JSON-encoded request a particular Handler knows how to send);
+// Dispatcher itself never inspects it.
+type Job struct {
+	ID              string          `json:"id"`
+	Kind            string          `json:"kind"`
+	Endpoint        string          `json:"endpoint"` // circuit breaker / rate limiter key
+	Payload         json.RawMessage `json:"payload"`
+	Attempts        int             `json:"attempts"`
+	CreatedAtUnix   int64           `json:"created_at_unix"`
+	NextAttemptUnix int64           `json:"next_attempt_unix"`
+}
+
+// Handler performs the actual send for one job's Kind, returning an error to
+// have Dispatcher retry it later (with backoff) rather than drop it.
+type Handler func(ctx context.Context, job Job) error
+
+// BreakerConfig configures every per-endpoint CircuitBreaker Dispatcher
+// creates lazily as new endpoints are seen.
+type BreakerConfig struct {
+	FailureRatio float64
+	MinSamples   int
+	CoolDown     time.Duration
+}
+
+// LimiterConfig configures every per-endpoint TokenBucket Dispatcher creates
+// lazily as new endpoints are seen.
+type LimiterConfig struct {
+	Capacity     float64
+	RefillPerSec float64
+}
+
+// Dispatcher is a durable, per-endpoint circuit-broken, rate-limited retry
+// queue. Jobs are pushed with Enqueue and drained by a background loop
+// started with Run; RegisterHandler wires Kind -> send logic before Run
+// starts processing anything of that Kind.
+type Dispatcher struct {
+	mu          sync.Mutex
+	runMu       sync.Mutex // serializes processDue against itself (Run's ticker vs. an admin DrainNow)
+	queuePath   string
+	jobs        []Job
+	handlers    map[string]Handler
+	breakers    map[string]*CircuitBreaker
+	limiters    map[string]*TokenBucket
+	breakerCfg  BreakerConfig
+	limiterCfg  LimiterConfig
+	maxAttempts int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
+	pollEvery   time.Duration
+}
+
+// NewDispatcher builds a Dispatcher persisting to queuePath, loading any
+// jobs already queued there from a prior run. queuePath == "" disables
+// persistence (jobs still queue and retry in-memory for this process's
+// lifetime, but won't survive a restart).
+func NewDispatcher(queuePath string, breakerCfg BreakerConfig, limiterCfg LimiterConfig) *Dispatcher {
+	d := &Dispatcher{
+		queuePath:   queuePath,
+		handlers:    make(map[string]Handler),
+		breakers:    make(map[string]*CircuitBreaker),
+		limiters:    make(map[string]*TokenBucket),
+		breakerCfg:  breakerCfg,
+		limiterCfg:  limiterCfg,
+		maxAttempts: 20,
+		baseBackoff: 500 * time.Millisecond,
+		maxBackoff:  5 * time.Minute,
+		pollEvery:   2 * time.Second,
+	}
+	if queuePath == "" {
+		return d
+	}
+	if b, err := os.ReadFile(queuePath); err == nil {
+		var jobs []Job
+		if err := json.Unmarshal(b, &jobs); err == nil {
+			d.jobs = jobs
+		}
+	}
+	return d
+}
+
+// RegisterHandler wires kind's send logic. Not safe to call concurrently
+// with Run; register everything before calling Run.
+func (d *Dispatcher) RegisterHandler(kind string, h Handler) {
+	d.handlers[kind] = h
+}
+
+// Enqueue persists a new job of kind, keyed for breaker/limiter purposes by
+// endpoint, and appends it to the in-memory queue for the next Run tick to
+// pick up.
+func (d *Dispatcher) Enqueue(kind, endpoint string, payload interface{}) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	job := Job{
+		ID:            uuid.NewString(),
+		Kind:          kind,
+		Endpoint:      endpoint,
+		Payload:       b,
+		CreatedAtUnix: time.Now().Unix(),
+	}
+	d.mu.Lock()
+	d.jobs = append(d.jobs, job)
+	d.persistLocked()
+	depth := len(d.jobs)
+	d.mu.Unlock()
+	queueDepthGauge.WithLabelValues(kind).Set(float64(depth))
+	return nil
+}
+
+// Run processes due jobs every pollEvery until ctx is canceled. Intended to
+// be started once per Dispatcher in its own goroutine.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.pollEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.processDue(ctx)
+		}
+	}
+}
+
+// DrainNow immediately attempts every currently-due job, ignoring the poll
+// ticker, for use by an admin "drain the queue now" action.
+func (d *Dispatcher) DrainNow(ctx context.Context) (succeeded, failed int) {
+	return d.processDue(ctx)
+}
+
+// processDue dispatches every currently-due job concurrently (one goroutine
+// per job) rather than one at a time: a handler like sendOrchestratorJob can
+// block for the duration of a full LLM reply, and running due jobs
+// sequentially here would serialize every user's conversation behind
+// whichever one happens to be in flight, which is exactly what launching a
+// goroutine per forward (the code this package replaces) was there to avoid.
+// Per-endpoint breakers/limiters stay the serialization point that matters;
+// everything else runs in parallel. runMu serializes whole processDue calls
+// against each other (Run's poll ticker vs. an admin DrainNow), since without
+// it two overlapping calls could both see the same due job still queued and
+// dispatch its handler twice.
+func (d *Dispatcher) processDue(ctx context.Context) (succeeded, failed int) {
+	d.runMu.Lock()
+	defer d.runMu.Unlock()
+
+	now := time.Now().Unix()
+	d.mu.Lock()
+	due := make([]Job, 0, len(d.jobs))
+	for _, j := range d.jobs {
+		if j.NextAttemptUnix <= now {
+			due = append(due, j)
+		}
+	}
+	d.mu.Unlock()
+
+	var wg sync.WaitGroup
+	var resultsMu sync.Mutex
+	remove := make(map[string]bool)
+	updated := make(map[string]Job)
+
+	for _, job := range due {
+		job := job
+		d.mu.Lock()
+		handler := d.handlers[job.Kind]
+		d.mu.Unlock()
+		if handler == nil {
+			continue
+		}
+		breaker := d.breakerFor(job.Endpoint)
+		limiter := d.limiterFor(job.Endpoint)
+
+		if !breaker.Allow() {
+			dispatchAttemptsTotal.WithLabelValues(job.Kind, "breaker_open").Inc()
+			continue
+		}
+		if !limiter.Allow() {
+			dispatchAttemptsTotal.WithLabelValues(job.Kind, "rate_limited").Inc()
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := handler(ctx, job)
+
+			resultsMu.Lock()
+			defer resultsMu.Unlock()
+			if errors.Is(err, ErrDrop) {
+				// Handler decided this job isn't worth sending (e.g. it went
+				// stale); not a signal about the endpoint's health, so don't
+				// feed it to the breaker.
+				dispatchAttemptsTotal.WithLabelValues(job.Kind, "dropped").Inc()
+				remove[job.ID] = true
+				return
+			}
+			breaker.RecordResult(err == nil)
+			breakerStateGauge.WithLabelValues(job.Endpoint).Set(breakerStateMetricValue(breaker.State()))
+			if err == nil {
+				dispatchAttemptsTotal.WithLabelValues(job.Kind, "success").Inc()
+				remove[job.ID] = true
+				succeeded++
+				return
+			}
+			dispatchAttemptsTotal.WithLabelValues(job.Kind, "failure").Inc()
+			failed++
+			job.Attempts++
+			if job.Attempts >= d.maxAttempts {
+				logging.Warn("outbound: job exceeded max attempts, dropping", "kind", job.Kind, "endpoint", job.Endpoint, "attempts", job.Attempts)
+				remove[job.ID] = true
+				return
+			}
+			job.NextAttemptUnix = time.Now().Add(fullJitterBackoff(d.baseBackoff, job.Attempts, d.maxBackoff)).Unix()
+			updated[job.ID] = job
+		}()
+	}
+	wg.Wait()
+
+	if len(remove) > 0 || len(updated) > 0 {
+		d.mu.Lock()
+		kept := d.jobs[:0]
+		for _, j := range d.jobs {
+			if remove[j.ID] {
+				continue
+			}
+			if u, ok := updated[j.ID]; ok {
+				j = u
+			}
+			kept = append(kept, j)
+		}
+		d.jobs = kept
+		d.persistLocked()
+		d.mu.Unlock()
+	}
+	d.reportQueueDepth()
+	return succeeded, failed
+}
+
+func (d *Dispatcher) reportQueueDepth() {
+	d.mu.Lock()
+	byKind := make(map[string]int)
+	for _, j := range d.jobs {
+		byKind[j.Kind]++
+	}
+	d.mu.Unlock()
+	for kind, n := range byKind {
+		queueDepthGauge.WithLabelValues(kind).Set(float64(n))
+	}
+}
+
+// Snapshot returns a copy of the currently queued jobs, for admin
+// inspection.
+func (d *Dispatcher) Snapshot() []Job {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := make([]Job, len(d.jobs))
+	copy(out, d.jobs)
+	return out
+}
+
+func (d *Dispatcher) breakerFor(endpoint string) *CircuitBreaker {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	b, ok := d.breakers[endpoint]
+	if !ok {
+		b = NewCircuitBreaker(d.breakerCfg.FailureRatio, d.breakerCfg.MinSamples, d.breakerCfg.CoolDown)
+		d.breakers[endpoint] = b
+	}
+	return b
+}
+
+func (d *Dispatcher) limiterFor(endpoint string) *TokenBucket {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	l, ok := d.limiters[endpoint]
+	if !ok {
+		l = NewTokenBucket(d.limiterCfg.Capacity, d.limiterCfg.RefillPerSec)
+		d.limiters[endpoint] = l
+	}
+	return l
+}
+
+// persistLocked writes d.jobs to d.queuePath with the package's usual
+// tmp+rename pattern. Caller must hold d.mu.
+func (d *Dispatcher) persistLocked() {
+	if d.queuePath == "" {
+		return
+	}
+	b, err := json.Marshal(d.jobs)
+	if err != nil {
+		logging.Error("outbound: marshal queue failed", "err", err)
+		return
+	}
+	tmp := d.queuePath + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		logging.Error("outbound: write queue tmp failed", "tmp", tmp, "err", err)
+		return
+	}
+	if err := os.Rename(tmp, d.queuePath); err != nil {
+		logging.Error("outbound: rename queue failed", "tmp", tmp, "path", d.queuePath, "err", err)
+		_ = os.Remove(tmp)
+	}
+}
+
+// fullJitterBackoff implements the "full jitter" backoff from
+// https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/:
+// sleep = rand(0, min(max, base * 2^attempt)).
+func fullJitterBackoff(base time.Duration, attempt int, max time.Duration) time.Duration {
+	d := base * time.Duration(uint64(1)<<uint(attempt))
+	if d <= 0 || d > max {
+		d = max
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
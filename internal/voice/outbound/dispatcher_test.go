@@ -0,0 +1,96 @@
+package outbound
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func newTestDispatcher() *Dispatcher {
+	return NewDispatcher("",
+		BreakerConfig{FailureRatio: 0.5, MinSamples: 1, CoolDown: time.Minute},
+		LimiterConfig{Capacity: 1, RefillPerSec: 0},
+	)
+}
+
+// TestProcessDueSkipsJobsOnOpenBreaker verifies a job for an endpoint whose
+// breaker has already tripped open is left queued rather than dispatched -
+// the breaker, not the handler, decides whether the endpoint gets traffic.
+func TestProcessDueSkipsJobsOnOpenBreaker(t *testing.T) {
+	d := newTestDispatcher()
+	called := false
+	d.RegisterHandler("test", func(ctx context.Context, job Job) error {
+		called = true
+		return nil
+	})
+	if err := d.Enqueue("test", "endpoint-a", map[string]string{"k": "v"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	// Trip the breaker before any job is ever processed through it.
+	d.breakerFor("endpoint-a").RecordResult(false)
+
+	succeeded, failed := d.DrainNow(context.Background())
+	if called {
+		t.Error("handler ran despite the breaker being open")
+	}
+	if succeeded != 0 || failed != 0 {
+		t.Errorf("DrainNow = (%d, %d), want (0, 0): a breaker-skipped job is neither a success nor a failure", succeeded, failed)
+	}
+	if got := len(d.Snapshot()); got != 1 {
+		t.Errorf("queue len = %d, want 1 (job stays queued for the next attempt)", got)
+	}
+}
+
+// TestProcessDueSkipsJobsOverRateLimit verifies a job for an endpoint whose
+// limiter has no tokens left is left queued rather than dispatched.
+func TestProcessDueSkipsJobsOverRateLimit(t *testing.T) {
+	d := newTestDispatcher()
+	calls := 0
+	d.RegisterHandler("test", func(ctx context.Context, job Job) error {
+		calls++
+		return nil
+	})
+	if err := d.Enqueue("test", "endpoint-a", 1); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if err := d.Enqueue("test", "endpoint-a", 2); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	// Capacity 1, no refill: only the first of the two due jobs should
+	// dispatch this round.
+	succeeded, _ := d.DrainNow(context.Background())
+	if calls != 1 {
+		t.Errorf("handler called %d times, want exactly 1 (limiter allows only one token)", calls)
+	}
+	if succeeded != 1 {
+		t.Errorf("succeeded = %d, want 1", succeeded)
+	}
+	if got := len(d.Snapshot()); got != 1 {
+		t.Errorf("queue len = %d, want 1 (the rate-limited job stays queued)", got)
+	}
+}
+
+// TestProcessDueDropsJobOnErrDrop verifies a handler returning ErrDrop
+// removes the job without recording a breaker outcome or counting as a
+// dispatch failure.
+func TestProcessDueDropsJobOnErrDrop(t *testing.T) {
+	d := newTestDispatcher()
+	d.RegisterHandler("test", func(ctx context.Context, job Job) error {
+		return ErrDrop
+	})
+	if err := d.Enqueue("test", "endpoint-a", 1); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+
+	succeeded, failed := d.DrainNow(context.Background())
+	if succeeded != 0 || failed != 0 {
+		t.Errorf("DrainNow = (%d, %d), want (0, 0) for a dropped job", succeeded, failed)
+	}
+	if got := len(d.Snapshot()); got != 0 {
+		t.Errorf("queue len = %d, want 0 (dropped job removed)", got)
+	}
+	if got := d.breakerFor("endpoint-a").State(); got != StateClosed {
+		t.Errorf("breaker state = %s, want StateClosed (ErrDrop must not count as a breaker outcome)", got)
+	}
+}
@@ -0,0 +1,36 @@
+package outbound
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// These are package-level, same rationale as internal/voice/metrics.go: one
+// shared registry across every Dispatcher instance in the process.
+var (
+	dispatchAttemptsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "outbound_dispatch_attempts_total",
+		Help: "Total Dispatcher job attempts, labeled by job kind and outcome (success|failure|dropped|breaker_open|rate_limited).",
+	}, []string{"kind", "outcome"})
+
+	breakerStateGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "outbound_breaker_state",
+		Help: "Current circuit breaker state per endpoint: 0=closed, 1=half-open, 2=open.",
+	}, []string{"endpoint"})
+
+	queueDepthGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "outbound_queue_depth",
+		Help: "Number of pending jobs in a Dispatcher's durable queue, labeled by job kind.",
+	}, []string{"kind"})
+)
+
+func breakerStateMetricValue(s BreakerState) float64 {
+	switch s {
+	case StateHalfOpen:
+		return 1
+	case StateOpen:
+		return 2
+	default:
+		return 0
+	}
+}
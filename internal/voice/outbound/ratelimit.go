@@ -0,0 +1,39 @@
+package outbound
+
+import (
+	"sync"
+	"time"
+)
+
+// TokenBucket is a simple token-bucket rate limiter: capacity tokens,
+// refilling at refillPerSec, consumed one at a time by Allow.
+type TokenBucket struct {
+	mu           sync.Mutex
+	capacity     float64
+	tokens       float64
+	refillPerSec float64
+	last         time.Time
+}
+
+// NewTokenBucket builds a TokenBucket starting full.
+func NewTokenBucket(capacity, refillPerSec float64) *TokenBucket {
+	return &TokenBucket{capacity: capacity, tokens: capacity, refillPerSec: refillPerSec, last: time.Now()}
+}
+
+// Allow consumes one token and reports whether one was available.
+func (t *TokenBucket) Allow() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	elapsed := now.Sub(t.last).Seconds()
+	t.last = now
+	t.tokens += elapsed * t.refillPerSec
+	if t.tokens > t.capacity {
+		t.tokens = t.capacity
+	}
+	if t.tokens < 1 {
+		return false
+	}
+	t.tokens--
+	return true
+}
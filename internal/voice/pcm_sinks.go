@@ -0,0 +1,190 @@
+package voice
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/discord-voice-lab/internal/voice/vad"
+)
+
+// WAVFileSink writes one WAV file per speaker per utterance under Dir,
+// accumulating PCM in memory and flushing it on Close (called by Receiver
+// when a speaking-stop transition finalizes the stream).
+type WAVFileSink struct {
+	Dir string
+
+	mu  sync.Mutex
+	buf map[uint32]*bytes.Buffer
+}
+
+// NewWAVFileSink returns a sink writing under dir, creating it if needed.
+func NewWAVFileSink(dir string) *WAVFileSink {
+	return &WAVFileSink{Dir: dir, buf: make(map[uint32]*bytes.Buffer)}
+}
+
+func (s *WAVFileSink) WritePCM(ssrc uint32, userID string, frame []int16, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.buf[ssrc]
+	if !ok {
+		b = &bytes.Buffer{}
+		s.buf[ssrc] = b
+	}
+	for _, sample := range frame {
+		_ = binary.Write(b, binary.LittleEndian, sample)
+	}
+	return nil
+}
+
+func (s *WAVFileSink) Close(ssrc uint32) error {
+	s.mu.Lock()
+	b, ok := s.buf[ssrc]
+	delete(s.buf, ssrc)
+	s.mu.Unlock()
+	if !ok || b.Len() == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return fmt.Errorf("create wav sink dir: %w", err)
+	}
+	ts := time.Now().UTC().Format("20060102T150405.000Z")
+	fname := filepath.Join(s.Dir, fmt.Sprintf("%s_ssrc%d.wav", ts, ssrc))
+	wav := buildWAV(b.Bytes(), 48000, 1, 16)
+	tmp := fname + ".tmp"
+	if err := os.WriteFile(tmp, wav, 0o644); err != nil {
+		return fmt.Errorf("write wav tmp: %w", err)
+	}
+	if err := os.Rename(tmp, fname); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("rename wav tmp: %w", err)
+	}
+	return nil
+}
+
+// VADGatedBufferSink accumulates PCM per SSRC while vad.Backend considers it
+// speech, discarding frames the gate considers silence; this is the
+// in-memory buffer downstream ASR can poll with Drain instead of
+// re-running VAD over raw, un-gated audio.
+type VADGatedBufferSink struct {
+	backend vad.Backend
+	cfg     vad.Config
+
+	mu   sync.Mutex
+	gate map[uint32]*vad.Gate
+	buf  map[uint32][]int16
+}
+
+// NewVADGatedBufferSink builds a sink gating frames with backend/cfg (see
+// vad.FromEnv).
+func NewVADGatedBufferSink(backend vad.Backend, cfg vad.Config) *VADGatedBufferSink {
+	return &VADGatedBufferSink{
+		backend: backend,
+		cfg:     cfg,
+		gate:    make(map[uint32]*vad.Gate),
+		buf:     make(map[uint32][]int16),
+	}
+}
+
+func (s *VADGatedBufferSink) WritePCM(ssrc uint32, userID string, frame []int16, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g, ok := s.gate[ssrc]
+	if !ok {
+		g = vad.NewGate(s.cfg)
+		s.gate[ssrc] = g
+	}
+	var isSpeech bool
+	if s.backend != nil {
+		isSpeech, _ = s.backend.IsSpeech(frame, 48000)
+	}
+	frameMs := (len(frame) * 1000) / 48000
+	g.Observe(isSpeech, frameMs)
+	if g.Active() {
+		s.buf[ssrc] = append(s.buf[ssrc], frame...)
+	}
+	return nil
+}
+
+// Drain returns and clears the buffered speech PCM for ssrc.
+func (s *VADGatedBufferSink) Drain(ssrc uint32) []int16 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := s.buf[ssrc]
+	delete(s.buf, ssrc)
+	return out
+}
+
+func (s *VADGatedBufferSink) Close(ssrc uint32) error {
+	s.mu.Lock()
+	delete(s.gate, ssrc)
+	delete(s.buf, ssrc)
+	s.mu.Unlock()
+	return nil
+}
+
+// PCMFrame is one frame delivered to a ChannelFanoutSink subscriber.
+type PCMFrame struct {
+	SSRC   uint32
+	UserID string
+	Frame  []int16
+	At     time.Time
+}
+
+// ChannelFanoutSink pushes every frame onto a buffered channel, intended for
+// tests that want to assert on raw Receiver output without a file or VAD
+// gate in the way. Frames are dropped (not blocked) if the channel is full.
+type ChannelFanoutSink struct {
+	Frames chan PCMFrame
+}
+
+// NewChannelFanoutSink creates a sink with a channel of the given buffer size.
+func NewChannelFanoutSink(bufSize int) *ChannelFanoutSink {
+	if bufSize <= 0 {
+		bufSize = 64
+	}
+	return &ChannelFanoutSink{Frames: make(chan PCMFrame, bufSize)}
+}
+
+func (s *ChannelFanoutSink) WritePCM(ssrc uint32, userID string, frame []int16, at time.Time) error {
+	cp := make([]int16, len(frame))
+	copy(cp, frame)
+	select {
+	case s.Frames <- PCMFrame{SSRC: ssrc, UserID: userID, Frame: cp, At: at}:
+	default:
+	}
+	return nil
+}
+
+func (s *ChannelFanoutSink) Close(ssrc uint32) error { return nil }
+
+// ProcessorAccumSink feeds a Receiver's jitter-corrected, strict-cadence PCM
+// directly into Processor's existing accumulation/VAD/STT pipeline via
+// appendAccum, so a jitter buffer in front of decode no longer means a
+// second, disconnected copy of that pipeline. voiceProb is always nil here;
+// the rnnoise VAD mode (see Processor.denoiseFrame) only applies to the
+// legacy ProcessOpusFrame path today. The original Opus packet is also
+// unavailable here (Receiver decodes before a sink ever sees a frame), so
+// STT_INPUT_CODEC=opus/ogg passthrough only applies to that legacy path too.
+type ProcessorAccumSink struct {
+	proc *Processor
+}
+
+// NewProcessorAccumSink wires a sink that appends decoded frames onto proc's
+// per-SSRC accumulators.
+func NewProcessorAccumSink(proc *Processor) *ProcessorAccumSink {
+	return &ProcessorAccumSink{proc: proc}
+}
+
+func (s *ProcessorAccumSink) WritePCM(ssrc uint32, userID string, frame []int16, at time.Time) error {
+	samples := make([]int16, len(frame))
+	copy(samples, frame)
+	s.proc.appendAccum(ssrc, samples, "", nil, nil)
+	return nil
+}
+
+func (s *ProcessorAccumSink) Close(ssrc uint32) error { return nil }
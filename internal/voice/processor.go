@@ -6,7 +6,6 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
-	"io"
 	"math"
 	"net/http"
 	"net/url"
@@ -21,6 +20,14 @@ import (
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/discord-voice-lab/internal/logging"
+	"github.com/discord-voice-lab/internal/mcp"
+	"github.com/discord-voice-lab/internal/orchestrator"
+	"github.com/discord-voice-lab/internal/voice/acl"
+	"github.com/discord-voice-lab/internal/voice/llm"
+	"github.com/discord-voice-lab/internal/voice/outbound"
+	"github.com/discord-voice-lab/internal/voice/stt"
+	"github.com/discord-voice-lab/internal/voice/tts"
+	"github.com/discord-voice-lab/internal/voice/vad"
 	"github.com/google/uuid"
 	"github.com/hraban/opus"
 )
@@ -54,6 +61,66 @@ type Processor struct {
 	maxAccumMs   int // maximum accumulation duration per chunk
 	// simple RMS-based VAD: if computed RMS < vadRmsThreshold we drop the chunk
 	vadRmsThreshold int
+	// vadBackend classifies individual frames as speech/non-speech; vadCfg
+	// tunes the Gate built per-accumulator from it. Configured via
+	// VAD_BACKEND/VAD_AGGRESSIVENESS/VAD_MIN_SPEECH_MS/VAD_HANGOVER_MS.
+	vadBackend vad.Backend
+	vadCfg     vad.Config
+	// vadMode selects the VAD front-end: "rms" (default) runs vadBackend/
+	// vadRmsThreshold against raw decoded PCM; "rnnoise" denoises each frame
+	// per-SSRC first in handleOpusPacket and gates appendAccum on the
+	// resulting voice_prob instead. Configured via VAD_MODE.
+	vadMode string
+	// denoiserModelPath is passed to each per-SSRC RNNoiseDenoiser; unused
+	// by the current energy-floor approximation but kept so a real
+	// RNNoise binding can be dropped in later without a config change.
+	denoiserModelPath string
+	denoiserCfg       vad.DenoiserConfig
+	// energyGateCfg tunes energyGate when vadMode == "energy_hysteresis".
+	// Configured via VAD_ENERGY_ENTER_RMS/VAD_ENERGY_EXIT_RMS/
+	// VAD_ENERGY_HANGOVER_MS.
+	energyGateCfg vad.EnergyGateConfig
+	// denoisers holds one RNNoiseDenoiser per SSRC, guarded by denoiserMu,
+	// so each speaker's noise floor is tracked independently. Only used
+	// when vadMode == "rnnoise".
+	denoiserMu sync.Mutex
+	denoisers  map[uint32]*vad.RNNoiseDenoiser
+	// targetLUFS and maxTruePeakDBTP configure the EBU R128 loudness
+	// normalization flushAccum applies before sending PCM to STT. Configured
+	// via STT_TARGET_LUFS/STT_MAX_TRUE_PEAK_DBTP.
+	targetLUFS      float64
+	maxTruePeakDBTP float64
+	// sttInputCodec selects what sendPCMToWhisper POSTs: "wav" (default)
+	// re-encodes the accumulator's decoded PCM into a WAV container;
+	// "opus"/"ogg" instead mux the original Opus packets straight into an
+	// Ogg Opus container, skipping the PCM->WAV re-encode entirely.
+	// Configured via STT_INPUT_CODEC.
+	sttInputCodec string
+	// stabilityMs is how long (ms) a streaming STT partial's token prefix
+	// must stay unchanged before addAggregatedTranscript commits it, per
+	// stabilityTracker. Configured via STT_STABILITY_MS; irrelevant to the
+	// blocking whisper path, which only ever calls addAggregatedTranscript
+	// with isFinal=true.
+	stabilityMs int
+	// noiseFloors holds one rolling noise-floor regression per SSRC,
+	// replacing the single global vadRmsThreshold with a per-speaker
+	// adaptive threshold when vadRmsThreshold (legacy RMS VAD) is the
+	// active mode. vadRmsRatio/vadRmsMin/vadRmsMax configure it via
+	// VAD_RMS_RATIO/VAD_RMS_MIN/VAD_RMS_MAX.
+	noiseFloorMu sync.Mutex
+	noiseFloors  map[uint32]*noiseFloorTracker
+	vadRmsRatio  float64
+	vadRmsMin    float64
+	vadRmsMax    float64
+	// quota gates per-user STT request rate and monthly audio-seconds
+	// budget. Configured via STT_USER_RPS/STT_USER_BURST/STT_USER_SECONDS_CAP.
+	quota *userRateLimiter
+	// dispatch rate limits flushAccum's sendPCMToWhisper call itself, keyed
+	// on (userID, ssrc) plus a global bucket, coalescing into a bounded
+	// per-user queue on exhaustion instead of dropping like quota does.
+	// Configured via STT_DISPATCH_RPS/STT_DISPATCH_BURST/
+	// STT_DISPATCH_GLOBAL_RPS/STT_DISPATCH_GLOBAL_BURST/STT_DISPATCH_QUEUE_CAP.
+	dispatch *Limiter
 	// monitoring counters
 	enqueueCount   int64 // total frames enqueued
 	dropQueueCount int64 // frames dropped due to full queue
@@ -78,14 +145,149 @@ type Processor struct {
 	// optional directory to save raw/wav audio for troubleshooting. If empty,
 	// audio is not saved to disk.
 	saveAudioDir string
+	// sidecarIdx answers findSidecarPathForCID in O(1) instead of scanning
+	// saveAudioDir; see sidecar_index.go.
+	sidecarIdx *sidecarIndex
+	// transcriptSink delivers finalized transcripts to TRANSCRIPT_SINK (or
+	// legacy TEXT_FORWARD_URL); nil when neither is configured. See
+	// transcript_sink.go.
+	transcriptSink *retryingSink
+	// history retains recent finalized transcripts and fans them out to
+	// subscribers; it backs the voice.list_sessions/get_transcript/
+	// subscribe_transcripts MCP tools.
+	history *transcriptHistory
+	// partials fans out interim streaming-STT transcripts to live-caption
+	// subscribers; unlike history it keeps no backlog since a partial is
+	// immediately superseded by the next one or the eventual final.
+	partials *partialFanout
+	// eventRouter, when set via SetEventRouter, receives an
+	// orchestrator.EventTranscript for every finalized transcript so bridges
+	// (Slack, stdout, a generic HTTP sink, ...) can subscribe without this
+	// package knowing about any of them. eventGuildID tags those events.
+	eventRouter  *orchestrator.EventRouter
+	eventGuildID string
+	// eventChannelID is the voice channel this Processor's GuildSession is
+	// currently connected to, set via SetChannelID (session_manager.go's
+	// Join/move). Used to tag forwarded orchestrator requests with their
+	// Discord origin - see orchestratorOriginHeaders.
+	eventChannelID string
+
+	// speaker is Processor's outbound sibling, streaming TTS/orchestrator
+	// replies back into the same voice connection as 20ms Opus frames. Set
+	// up in NewProcessorWithResolver; EnqueuePlayback is a no-op if Speaker
+	// creation failed (e.g. no libopus encoder available).
+	speaker *Speaker
+	// wakeMu guards wakePhrases and wakePhrasePhonetics. These were
+	// originally set once at construction and read lock-free, but
+	// SetWakePhrases (backing the voice.set_wake_phrases MCP tool) can now
+	// rewrite them while the audio pipeline is concurrently reading via
+	// hasWakePhrase/detectWakePhraseWords.
+	wakeMu sync.RWMutex
 	// wake phrases that must prefix a transcript to allow forwarding to orchestrator
 	wakePhrases []string
 	// wakePhraseWindowS controls how many seconds from the start of an
 	// accumulation we consider the wake phrase to be valid (Option C).
 	wakePhraseWindowS int
+	// wakeMaxEditDistance is the per-token Levenshtein distance still
+	// accepted as a wake-phrase word match, so a single misrecognized
+	// phoneme doesn't suppress the wake (see detectWakePhraseWords).
+	wakeMaxEditDistance int
+	// wakeMinConfidence is the minimum mean STT word confidence across a
+	// matched wake-phrase window required to accept it; below this, a
+	// fuzzy match is treated as noise rather than a real wake.
+	wakeMinConfidence float64
+	// wakePhraseMatch selects how hasWakePhrase's text-based matcher (the
+	// fallback path used when no STT word timestamps are available; see
+	// detectWakePhraseWords for the word-timestamp path) compares candidate
+	// words against wakePhrases: "exact" (default, case/punctuation-folded
+	// equality), "fuzzy" (summed Levenshtein distance), or "phonetic"
+	// (Double Metaphone code equality).
+	wakePhraseMatch string
+	// wakePhrasePhonetics holds, for each entry of wakePhrases, the
+	// per-token Double Metaphone codes, precomputed whenever wakePhrases is
+	// set (at construction and in SetWakePhrases) rather than per
+	// transcript. Only populated/consulted
+	// when wakePhraseMatch == "phonetic".
+	wakePhrasePhonetics [][]metaphoneCode
 	// timeouts (ms) for external services, configurable via env
 	whisperTimeoutMS      int
 	orchestratorTimeoutMS int
+
+	// streamingSTT is set when STT_STREAM_URL is configured; when non-nil it
+	// is preferred over the blocking sendPCMToWhisper HTTP path.
+	streamingSTT StreamingSTTClient
+	// sttBackend selects the pluggable STT implementation (whisper, deepgram,
+	// vosk) chosen via STT_BACKEND. sendPCMToWhisper remains the default
+	// code path today; callers migrating to the pluggable path can invoke
+	// p.sttBackend.Transcribe directly.
+	sttBackend stt.Backend
+	// onPartialTranscript, if set, is invoked with low-latency partial
+	// transcripts from the streaming STT backend.
+	onPartialTranscript OnPartialTranscript
+	// llmProviders holds the pluggable chat-completion backends (openai,
+	// and whichever one ORCHESTRATOR_PROVIDER additionally names) llmRouter
+	// may select among. The ORCHESTRATOR_URL forwarding goroutine in
+	// forwardToOrchestrator remains the default code path for OpenAI's SSE
+	// streaming; llmProviders/llmRouter back the non-streaming providers
+	// (ollama, anthropic, azureopenai) chosen via ORCHESTRATOR_PROVIDER.
+	llmProviders map[string]llm.Provider
+	llmRouter    *providerRouter
+	// llmFallbackChain names registry entries - llmProviders keys or "openai"
+	// - to try, in order, after the llmRouter-resolved primary name when
+	// sendOrchestratorJob's attempt fails with any error, permanent or not
+	// (see isJobErrPermanent) - e.g. a cloud provider primary falling back to a
+	// local Ollama instance. Entries absent from llmProviders (other than
+	// "openai", always handled via the raw HTTP path) are skipped. Empty by
+	// default (no cross-provider fallback), set via
+	// ORCHESTRATOR_FALLBACK_PROVIDERS.
+	llmFallbackChain []string
+	// conversationStore persists role-tagged chat turns per (guild, user)
+	// so sendOrchestratorJob/generateViaProvider send the orchestrator real
+	// multi-turn context instead of a fresh single-utterance prompt every
+	// time; see conversation_store.go. Always non-nil (defaults to an
+	// in-memory store) so the fallback-to-single-turn path in
+	// conversationMessages is only ever a lookup-failure safety net, not the
+	// default behavior.
+	conversationStore llm.ConversationStore
+	// toolRegistry holds the MCP tools discovered from every MCP_SERVER_URLS
+	// entry, or nil if that env var is unset. Only sendOrchestratorRawRequest
+	// (the "openai" raw path) consults it - see mcp_tools.go.
+	toolRegistry *mcp.ToolRegistry
+	// userTokenStore resolves the per-Discord-user bearer token
+	// sendOrchestratorJob sends instead of a single shared ORCH_AUTH_TOKEN;
+	// see user_tokens.go. Always non-nil (userTokenStoreFromEnv's
+	// zero-config default falls through to ORCH_AUTH_TOKEN for every user).
+	userTokenStore llm.UserTokenStore
+	// ttsProviders holds the pluggable TTS backends (piper, and whichever
+	// one TTS_PROVIDER additionally names) ttsRouter may select among, same
+	// relationship to synthesizeTTSAndSave's TTS_URL default as llmProviders
+	// has to ORCHESTRATOR_URL.
+	ttsProviders map[string]tts.Provider
+	ttsRouter    *providerRouter
+	// orchestratorDispatcher durably retries orchestrator-forward POSTs
+	// (with a circuit breaker and rate limiter per endpoint) instead of the
+	// fire-and-forget goroutine with an inline sleep-retry loop this used to
+	// be; see orchestrator_dispatch.go.
+	orchestratorDispatcher *outbound.Dispatcher
+	// ttsDispatcher durably retries whole-reply (non-streaming) TTS
+	// synthesis jobs the same way. The per-sentence streaming TTS path in
+	// handleOrchestratorStreamResponse stays on synthesizeTTSAndSave's
+	// synchronous inline retry, since queuing a sentence for later delivery
+	// would break the live sentence-by-sentence playback it exists for.
+	ttsDispatcher *outbound.Dispatcher
+	// aclManager gates which users' transcripts flushAgg forwards to the
+	// orchestrator/TTS pipeline, loaded from ACL_FILE (see acl.Manager). A
+	// Manager with no file configured allows everything.
+	aclManager *acl.Manager
+}
+
+// SetOnPartialTranscript registers a callback invoked with partial (not yet
+// final) transcripts from the streaming STT backend, if one is configured
+// via STT_STREAM_URL. Passing nil disables the callback.
+func (p *Processor) SetOnPartialTranscript(cb OnPartialTranscript) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onPartialTranscript = cb
 }
 
 type opusPacket struct {
@@ -115,6 +317,49 @@ type pcmAccum struct {
 	// race where speaking updates arrive after accumulator creation.
 	userID   string
 	username string
+	// denoiserGate gates on the RNNoise approximation's per-frame voice_prob
+	// instead of vadGate's speech/non-speech decision. Only populated when
+	// vadMode == "rnnoise"; reset along with the rest of the accumulator on
+	// each new utterance.
+	denoiserGate *vad.DenoiserGate
+	// vadGate turns p.vadBackend's per-frame speech/non-speech decisions
+	// into start/stop utterance edges (see vad.Gate); populated lazily the
+	// first time vadBackend is in use for this accumulator.
+	vadGate *vad.Gate
+	// energyGate implements vadMode == "energy_hysteresis": a two-threshold
+	// (enter/exit) amplitude hangover detector, populated lazily the first
+	// time that mode is active for this accumulator.
+	energyGate *vad.EnergyGate
+	// vadStartUTC/vadStopUTC record when vadGate last fired EventStart/
+	// EventStop, for sidecar/debug visibility into the true end-of-utterance
+	// decision rather than just the raw RMS-above/below transitions.
+	vadStartUTC string
+	vadStopUTC  string
+	// vadEnded is set when vadGate fires EventStop, letting
+	// flushExpiredAccums flush on that true end-of-utterance signal instead
+	// of waiting out silenceTimeoutMs.
+	vadEnded bool
+	// vadSpeechFrames/vadSilenceFrames/vadProbSum/vadProbFrames accumulate
+	// per-flush VAD statistics (frame counts, probability sum) recorded in
+	// the sidecar JSON so operators can see how confidently vadBackend
+	// judged this utterance, not just its start/stop timestamps.
+	vadSpeechFrames  int
+	vadSilenceFrames int
+	vadProbSum       float64
+	vadProbFrames    int
+	// opusPackets holds this accumulator's original Opus packets (one per
+	// appendAccum call that provided one), for the STT_INPUT_CODEC=opus/ogg
+	// passthrough path in flushAccum. Only populated on the direct
+	// ProcessOpusFrame path; the jitter-buffer (Receiver) path decodes
+	// before appendAccum sees a frame, so it has no original packet to keep.
+	opusPackets [][]byte
+	// streamFrames, once non-nil, is this accumulator's queue of raw frames
+	// awaiting push to the streaming STT backend by its pump goroutine.
+	// flushAccum closes it to signal the pump to push whatever's left,
+	// close out the session (delivering the final transcript), and exit -
+	// instead of dispatching the accumulated PCM over the blocking
+	// sendPCMToWhisper HTTP path.
+	streamFrames chan []int16
 }
 
 // transcriptAgg holds an aggregated transcript for an SSRC and timestamp of last update
@@ -132,7 +377,25 @@ type transcriptAgg struct {
 	// when a wake phrase is detected so the flusher can forward only the
 	// intended user utterance.
 	wakeStripped string
-	createdAt    time.Time
+	// wakeConfidence/wakeStartMs/wakeEndMs are set when the wake phrase was
+	// located via word-level timestamps (detectWakePhraseWords) rather than
+	// the flattened-text fallback; all zero when the fallback was used or
+	// no wake phrase has been detected yet.
+	wakeConfidence float64
+	wakeStartMs    int
+	wakeEndMs      int
+	createdAt      time.Time
+	// finalizedText accumulates the finalized portion of text across
+	// possibly-multiple STT results aggregated within aggMs of each other;
+	// text is finalizedText plus whatever the in-flight utterance's
+	// stability-tracked rendering (see stability) currently contributes.
+	finalizedText string
+	// stability commits a streaming STT session's partial results once
+	// their prefix has held stable for stabilityMs, so repeated partials
+	// replace the mutable tail instead of piling up as separate
+	// space-joined fragments. It is reset whenever a final result for the
+	// current utterance arrives.
+	stability *stabilityTracker
 }
 
 func NewProcessor() (*Processor, error) {
@@ -159,16 +422,20 @@ func NewProcessorWithResolver(parent context.Context, resolver NameResolver) (*P
 	ctx, cancel := context.WithCancel(parent)
 
 	p := &Processor{
-		ssrcMap:    make(map[uint32]string),
-		allowlist:  make(map[string]struct{}),
-		dec:        dec,
-		httpClient: nil,
-		resolver:   resolver,
-		ctx:        ctx,
-		cancel:     cancel,
-		opusCh:     make(chan opusPacket, 32),
-		accums:     make(map[uint32]*pcmAccum),
-		aggs:       make(map[uint32]*transcriptAgg),
+		ssrcMap:     make(map[uint32]string),
+		allowlist:   make(map[string]struct{}),
+		dec:         dec,
+		httpClient:  nil,
+		resolver:    resolver,
+		ctx:         ctx,
+		cancel:      cancel,
+		opusCh:      make(chan opusPacket, 32),
+		accums:      make(map[uint32]*pcmAccum),
+		denoisers:   make(map[uint32]*vad.RNNoiseDenoiser),
+		noiseFloors: make(map[uint32]*noiseFloorTracker),
+		aggs:        make(map[uint32]*transcriptAgg),
+		history:     &transcriptHistory{},
+		partials:    &partialFanout{},
 		// read the container-local save path; fall back to legacy SAVE_AUDIO_DIR
 		// but only enable saving when SAVE_AUDIO_ENABLED is set to "true"
 		saveAudioDir: func() string {
@@ -203,6 +470,63 @@ func NewProcessorWithResolver(parent context.Context, resolver NameResolver) (*P
 		}(),
 	}
 
+	p.sidecarIdx = newSidecarIndex(p.saveAudioDir)
+
+	p.transcriptSink = transcriptSinkFromEnv(p.saveAudioDir)
+	if p.transcriptSink != nil {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.transcriptSink.startRetryLoop(p.ctx)
+		}()
+	}
+
+	p.llmProviders = llmProviderRegistryFromEnv()
+	p.llmRouter = newProviderRouter(defaultLLMProviderFromEnv(), "ORCHESTRATOR_ROUTING_JSON")
+	p.llmFallbackChain = llmFallbackChainFromEnv()
+	p.conversationStore = conversationStoreFromEnv(p.saveAudioDir, conversationSummarizer(p.llmProviders["openai"]))
+	p.toolRegistry = mcpToolRegistryFromEnv(p.ctx)
+	p.userTokenStore = userTokenStoreFromEnv()
+	p.ttsProviders = ttsProviderRegistryFromEnv()
+	p.ttsRouter = newProviderRouter(defaultTTSProviderFromEnv(), "TTS_ROUTING_JSON")
+
+	p.orchestratorDispatcher = outbound.NewDispatcher(
+		dispatcherQueuePath(p.saveAudioDir, "orchestrator_queue.json"),
+		breakerConfigFromEnv("ORCH_BREAKER_FAILURE_RATIO", "ORCH_BREAKER_MIN_SAMPLES", "ORCH_BREAKER_COOLDOWN_S"),
+		limiterConfigFromEnv("ORCH_RATE_LIMIT_CAPACITY", "ORCH_RATE_LIMIT_PER_SEC"),
+	)
+	p.orchestratorDispatcher.RegisterHandler(orchestratorJobKind, p.sendOrchestratorJob)
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.orchestratorDispatcher.Run(p.ctx)
+	}()
+
+	p.ttsDispatcher = outbound.NewDispatcher(
+		dispatcherQueuePath(p.saveAudioDir, "tts_queue.json"),
+		breakerConfigFromEnv("TTS_BREAKER_FAILURE_RATIO", "TTS_BREAKER_MIN_SAMPLES", "TTS_BREAKER_COOLDOWN_S"),
+		limiterConfigFromEnv("TTS_RATE_LIMIT_CAPACITY", "TTS_RATE_LIMIT_PER_SEC"),
+	)
+	p.ttsDispatcher.RegisterHandler(ttsJobKind, p.sendTTSJob)
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.ttsDispatcher.Run(p.ctx)
+	}()
+
+	p.aclManager = acl.NewManager(os.Getenv("ACL_FILE"))
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.aclManager.StartReloadLoop(p.ctx, 2*time.Second)
+	}()
+
+	if sp, err := NewSpeaker(); err != nil {
+		logging.Warn("Processor: outbound Speaker unavailable, EnqueuePlayback will be a no-op", "err", err)
+	} else {
+		p.speaker = sp
+	}
+
 	// Configure timeouts and wake phrase window from environment (ms/sec).
 	// Defaults: 30s for whisper/orch, 3s window for wake phrase
 	p.whisperTimeoutMS = 30000
@@ -223,10 +547,46 @@ func NewProcessorWithResolver(parent context.Context, resolver NameResolver) (*P
 			p.wakePhraseWindowS = n
 		}
 	}
+	p.wakeMaxEditDistance = 1
+	if v := os.Getenv("WAKE_PHRASE_MAX_EDIT_DISTANCE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			p.wakeMaxEditDistance = n
+		}
+	}
+	p.wakeMinConfidence = 0.5
+	if v := os.Getenv("WAKE_PHRASE_MIN_CONFIDENCE"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 && f <= 1 {
+			p.wakeMinConfidence = f
+		}
+	}
+	p.wakePhraseMatch = "exact"
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("WAKE_PHRASE_MATCH_MODE"))) {
+	case "fuzzy":
+		p.wakePhraseMatch = "fuzzy"
+	case "phonetic":
+		p.wakePhraseMatch = "phonetic"
+		p.wakePhrasePhonetics = wakePhrasePhoneticsFor(p.wakePhrases)
+	}
 
 	// assign http client with whisper timeout
 	p.httpClient = &http.Client{Timeout: time.Duration(p.whisperTimeoutMS) * time.Millisecond}
 
+	p.sttBackend = sttBackendFromEnv(os.Getenv("WHISPER_URL"), stt.Config{TimeoutMs: p.whisperTimeoutMS})
+
+	// If STT_STREAM_URL is configured, prefer the streaming backend over the
+	// blocking HTTP whisper path. Partial transcripts are forwarded to
+	// onPartialTranscript if a caller has registered one.
+	if url := streamingSTTURL(); url != "" {
+		p.streamingSTT = NewStreamingSTTClient(p, url, func(ssrc uint32, correlationID string, partial string) {
+			p.mu.Lock()
+			cb := p.onPartialTranscript
+			p.mu.Unlock()
+			if cb != nil {
+				cb(ssrc, correlationID, partial)
+			}
+		})
+	}
+
 	// Retention settings for saved audio (optional)
 	retHours := 72
 	if v := os.Getenv("SAVE_AUDIO_RETENTION_HOURS"); v != "" {
@@ -382,6 +742,70 @@ func NewProcessorWithResolver(parent context.Context, resolver NameResolver) (*P
 			p.vadRmsThreshold = n
 		}
 	}
+	p.vadBackend, p.vadCfg = vad.FromEnv(p.vadRmsThreshold)
+
+	// VAD_MODE=rnnoise swaps the RMS/Backend gate above for a per-SSRC
+	// RNNoise-style denoiser + probability gate; anything else (including
+	// unset) keeps the existing vadBackend/vadRmsThreshold path untouched.
+	p.vadMode = strings.ToLower(strings.TrimSpace(os.Getenv("VAD_MODE")))
+	p.denoiserModelPath = strings.TrimSpace(os.Getenv("VAD_RNNOISE_MODEL"))
+	p.denoiserCfg = vad.DenoiserConfigFromEnv()
+	p.energyGateCfg = vad.EnergyGateConfigFromEnv()
+
+	// Loudness normalization target/ceiling for flushed accumulators before
+	// they're sent to STT. Defaults match EBU R128 program loudness and the
+	// -1 dBTP true-peak ceiling common broadcast delivery specs use.
+	p.targetLUFS = -23
+	if v := os.Getenv("STT_TARGET_LUFS"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			p.targetLUFS = f
+		}
+	}
+	p.maxTruePeakDBTP = -1
+	if v := os.Getenv("STT_MAX_TRUE_PEAK_DBTP"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			p.maxTruePeakDBTP = f
+		}
+	}
+
+	p.sttInputCodec = strings.ToLower(strings.TrimSpace(os.Getenv("STT_INPUT_CODEC")))
+	if p.sttInputCodec == "" {
+		p.sttInputCodec = "wav"
+	}
+
+	// Stability window for committing streaming STT partials; see
+	// stabilityTracker.
+	p.stabilityMs = 600
+	if v := os.Getenv("STT_STABILITY_MS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			p.stabilityMs = n
+		}
+	}
+
+	// Adaptive per-SSRC noise floor: effective threshold is noiseFloor*ratio,
+	// clamped to [min, max] so a pathological fit can't silence a speaker
+	// entirely or let constant noise through as speech.
+	p.vadRmsRatio = 3.0
+	if v := os.Getenv("VAD_RMS_RATIO"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 {
+			p.vadRmsRatio = f
+		}
+	}
+	p.vadRmsMin = 50
+	if v := os.Getenv("VAD_RMS_MIN"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 {
+			p.vadRmsMin = f
+		}
+	}
+	p.vadRmsMax = 400
+	if v := os.Getenv("VAD_RMS_MAX"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 {
+			p.vadRmsMax = f
+		}
+	}
+
+	p.quota = userRateLimiterFromEnv(p.saveAudioDir)
+	p.dispatch = NewLimiter(LimiterConfigFromEnv(), nil)
 
 	// Whether to flush as soon as minFlushMs is reached. When false, we will
 	// only flush when maxAccumMs is reached or when an inactivity timeout
@@ -430,6 +854,7 @@ func NewProcessorWithResolver(parent context.Context, resolver NameResolver) (*P
 				return
 			case <-ticker.C:
 				p.flushExpiredAccums()
+				p.dispatch.DrainAll()
 			}
 		}
 	}()
@@ -476,6 +901,55 @@ func NewProcessorWithResolver(parent context.Context, resolver NameResolver) (*P
 		}
 	}()
 
+	// Periodically prune sidecarIdx entries whose WAV was deleted out from
+	// under it, same cadence family as the other background flush loops.
+	if p.saveAudioDir != "" {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			ticker := time.NewTicker(time.Minute)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-p.ctx.Done():
+					return
+				case <-ticker.C:
+					if n := p.sidecarIdx.Compact(); n > 0 {
+						logging.Info("sidecar index: compacted", "removed", n)
+					}
+				}
+			}
+		}()
+	}
+
+	// Expose Prometheus metrics when METRICS_ADDR is configured (e.g. ":9100").
+	if addr := os.Getenv("METRICS_ADDR"); addr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", MetricsHandler())
+		mux.HandleFunc("/debug/vad", p.handleDebugVAD)
+		mux.HandleFunc("/debug/sidecars", p.handleDebugSidecars)
+		mux.HandleFunc("/debug/outbound/orchestrator", p.orchestratorDispatcher.HandleInspect)
+		mux.HandleFunc("/debug/outbound/orchestrator/drain", p.orchestratorDispatcher.HandleDrain)
+		mux.HandleFunc("/debug/outbound/tts", p.ttsDispatcher.HandleInspect)
+		mux.HandleFunc("/debug/outbound/tts/drain", p.ttsDispatcher.HandleDrain)
+		srv := &http.Server{Addr: addr, Handler: mux}
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logging.Error("metrics server exited", "addr", addr, "err", err)
+			}
+		}()
+		go func() {
+			<-p.ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = srv.Shutdown(shutdownCtx)
+		}()
+	}
+
+	InitTracing()
+
 	return p, nil
 }
 
@@ -494,19 +968,81 @@ func (p *Processor) SetAllowedUsers(ids []string) {
 		p.allowlist[id] = struct{}{}
 	}
 	// log configured allowlist size
-	logging.Infow("Processor: SetAllowedUsers", "count", len(p.allowlist))
+	logging.Info("Processor: SetAllowedUsers", "count", len(p.allowlist))
+}
+
+// SetEventRouter wires an orchestrator.EventRouter into the processor so
+// finalized transcripts are published as orchestrator.EventTranscript
+// events, tagged with guildID. Passing a nil router disables publishing.
+func (p *Processor) SetEventRouter(router *orchestrator.EventRouter, guildID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.eventRouter = router
+	p.eventGuildID = guildID
+}
+
+// SetChannelID records the voice channel this Processor's GuildSession is
+// currently connected to (session_manager.go's Join/move), so forwarded
+// orchestrator requests can be tagged with it - see orchestratorOriginHeaders.
+func (p *Processor) SetChannelID(channelID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.eventChannelID = channelID
+}
+
+// originIDs returns the guild/channel this Processor is currently attached
+// to, read under p.mu since SetEventRouter/SetChannelID (session_manager.go's
+// Join/move) can update them from another goroutine - e.g. a channel move
+// racing an in-flight orchestrator request - while this is read.
+func (p *Processor) originIDs() (guildID, channelID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.eventGuildID, p.eventChannelID
 }
 
 func (p *Processor) Close() error {
-	logging.Infow("Processor: Close called")
+	logging.Info("Processor: Close called")
 	// stop background workers
 	p.cancel()
 	// close channel to unblock worker if it's waiting
 	close(p.opusCh)
 	p.wg.Wait()
+	if p.speaker != nil {
+		p.speaker.Close()
+	}
+	if p.toolRegistry != nil {
+		if err := p.toolRegistry.Close(); err != nil {
+			logging.Warn("Processor: closing MCP tool registry failed", "err", err)
+		}
+	}
+	if err := p.sidecarIdx.Close(); err != nil {
+		logging.Warn("Processor: closing sidecar index failed", "err", err)
+	}
 	return nil
 }
 
+// SetVoiceConnection points Processor's outbound Speaker at vc, so
+// EnqueuePlayback streams into it. GuildSession calls this once per
+// (re)connect, mirroring how ProcessOpusFrame already consumes vc.OpusRecv
+// on the inbound side.
+func (p *Processor) SetVoiceConnection(vc *discordgo.VoiceConnection) {
+	if p.speaker != nil {
+		p.speaker.SetVoiceConnection(vc)
+	}
+}
+
+// EnqueuePlayback queues pcm (48kHz mono PCM) to be spoken back into this
+// session's voice connection, e.g. a TTS reply to the wake phrase
+// correlationID detected earlier in the same utterance's pipeline. It is a
+// no-op if the Speaker couldn't be created or no VoiceConnection has been
+// registered yet via SetVoiceConnection.
+func (p *Processor) EnqueuePlayback(correlationID string, pcm []int16) {
+	if p.speaker == nil {
+		return
+	}
+	p.speaker.EnqueuePlayback(correlationID, pcm)
+}
+
 // SeedVoiceChannelMembers enumerates the session state's voice states for
 // the given guild and channel and populates an internal userID->display
 // name cache. This helps provide immediate names for participants when the
@@ -564,9 +1100,9 @@ func (p *Processor) HandleVoiceState(s *discordgo.Session, vs *discordgo.VoiceSt
 	// Include human-friendly names when available via resolver (unused after logging removed).
 	if p.resolver != nil {
 		if n := p.resolver.UserName(vs.UserID); n != "" {
-			logging.Debugw("Processor: VoiceState update", logging.UserFields(vs.UserID, n)...)
+			logging.Debug("Processor: VoiceState update", logging.User(vs.UserID, n))
 		} else {
-			logging.Debugw("Processor: VoiceState update", "user_id", vs.UserID)
+			logging.Debug("Processor: VoiceState update", "user_id", vs.UserID)
 		}
 	}
 }
@@ -577,6 +1113,7 @@ func (p *Processor) HandleSpeakingUpdate(s *discordgo.Session, su *discordgo.Voi
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	p.ssrcMap[uint32(su.SSRC)] = su.UserID
+	activeSSRCs.Set(float64(len(p.ssrcMap)))
 	// Backfill any existing accumulator's user info to avoid unknown user in sidecars
 	p.accumMu.Lock()
 	if a, ok := p.accums[uint32(su.SSRC)]; ok {
@@ -594,7 +1131,7 @@ func (p *Processor) HandleSpeakingUpdate(s *discordgo.Session, su *discordgo.Voi
 	p.accumMu.Unlock()
 	// resolver lookup retained for potential future use
 	// Log mapping at info level so operator can see when SSRCs are associated
-	logging.Infow("Processor: HandleSpeakingUpdate: mapped SSRC -> user", "ssrc", su.SSRC, "user_id", su.UserID)
+	logging.Info("Processor: HandleSpeakingUpdate: mapped SSRC -> user", "ssrc", su.SSRC, "user_id", su.UserID)
 }
 
 // This function would be called by the discord voice receive loop with raw opus frames.
@@ -644,10 +1181,10 @@ func (p *Processor) ProcessOpusFrame(ssrc uint32, opusPayload []byte) {
 			// Use the pre-captured user info when creating the accumulator so
 			// it contains the correct mapping even if a speaking update races in.
 			p.accums[ssrc] = &pcmAccum{samples: nil, last: time.Now(), correlationID: outgoingCID, createdAt: time.Now(), userID: preUid, username: preUname}
-			logging.Debugw("generated correlation id for new accumulator", "user_id", preUid, "user_name", preUname, "ssrc", ssrc, "correlation_id", outgoingCID)
+			logging.Debug("generated correlation id for new accumulator", "user_id", preUid, "user_name", preUname, "ssrc", ssrc, "correlation_id", outgoingCID)
 		} else {
 			p.accums[ssrc].correlationID = outgoingCID
-			logging.Debugw("assigned correlation id to existing accumulator", "ssrc", ssrc, "correlation_id", outgoingCID)
+			logging.Debug("assigned correlation id to existing accumulator", "ssrc", ssrc, "correlation_id", outgoingCID)
 		}
 	}
 	p.accumMu.Unlock()
@@ -658,13 +1195,13 @@ func (p *Processor) ProcessOpusFrame(ssrc uint32, opusPayload []byte) {
 		// increment enqueue counter and log enqueue for diagnostics
 		atomic.AddInt64(&p.enqueueCount, 1)
 		if outgoingCID != "" {
-			logging.Debugw("opus frame enqueued", "ssrc", ssrc, "correlation_id", outgoingCID)
+			logging.Debug("opus frame enqueued", "ssrc", ssrc, "correlation_id", outgoingCID)
 		} else {
-			logging.Debugw("opus frame enqueued", "ssrc", ssrc)
+			logging.Debug("opus frame enqueued", "ssrc", ssrc)
 		}
 	default:
 		atomic.AddInt64(&p.dropQueueCount, 1)
-		logging.Warnw("dropping opus frame; queue full", "ssrc", ssrc)
+		logging.Warn("dropping opus frame; queue full", "ssrc", ssrc)
 	}
 }
 
@@ -679,7 +1216,7 @@ func (p *Processor) handleOpusPacket(pkt opusPacket) {
 	n, err := p.dec.Decode(opusPayload, pcm)
 	if err != nil {
 		atomic.AddInt64(&p.decodeErrCount, 1)
-		logging.Errorw("opus decode error", "ssrc", ssrc, "err", err)
+		logging.Error("opus decode error", "ssrc", ssrc, "err", err)
 		return
 	}
 	// assemble raw PCM bytes (little-endian int16)
@@ -692,7 +1229,15 @@ func (p *Processor) handleOpusPacket(pkt opusPacket) {
 	// when the accumulator reaches a minimum duration or when it times out.
 	samples := make([]int16, n)
 	copy(samples, pcm[:n])
-	cid := p.appendAccum(ssrc, samples, pkt.correlationID)
+
+	// In rnnoise mode, denoise in place and let the resulting voice_prob
+	// drive the accumulator's VAD instead of raw-PCM RMS/vadBackend.
+	var voiceProb *float64
+	if p.vadMode == "rnnoise" {
+		vp := p.denoiseFrame(ssrc, samples)
+		voiceProb = &vp
+	}
+	cid := p.appendAccum(ssrc, samples, pkt.correlationID, voiceProb, opusPayload)
 	// Log the correlation id associated with this accumulated chunk so it's
 	// visible early in the pipeline while frames are still arriving.
 	if cid != "" {
@@ -702,6 +1247,36 @@ func (p *Processor) handleOpusPacket(pkt opusPacket) {
 	}
 }
 
+// denoiseFrame runs the per-SSRC RNNoise-mode denoiser over frame in its
+// native 480-sample sub-frames, denoising frame in place and returning the
+// average voice_prob across the sub-frames. Only called when
+// p.vadMode == "rnnoise".
+func (p *Processor) denoiseFrame(ssrc uint32, frame []int16) float64 {
+	p.denoiserMu.Lock()
+	d, ok := p.denoisers[ssrc]
+	if !ok {
+		d, _ = vad.NewRNNoiseDenoiser(p.denoiserModelPath)
+		p.denoisers[ssrc] = d
+	}
+	p.denoiserMu.Unlock()
+
+	const subFrame = 480
+	var sum float64
+	n := 0
+	for i := 0; i < len(frame); i += subFrame {
+		end := i + subFrame
+		if end > len(frame) {
+			end = len(frame)
+		}
+		sum += d.ProcessFrame(frame[i:end])
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
 // appendAccum adds decoded samples to the per-SSRC accumulator.
 // appendAccum adds decoded samples to the per-SSRC accumulator and returns
 // the accumulator's correlation ID (if any). The returned correlation ID is
@@ -710,7 +1285,12 @@ func (p *Processor) handleOpusPacket(pkt opusPacket) {
 // appendAccum adds decoded samples to the per-SSRC accumulator and returns
 // the accumulator's correlation ID (if any). If an incomingCID is provided
 // it will be preferred when creating or populating the accumulator.
-func (p *Processor) appendAccum(ssrc uint32, samples []int16, incomingCID string) string {
+// voiceProb is the rnnoise-mode denoiser's voice_prob for this frame (nil
+// when vadMode != "rnnoise"), taking priority over vadBackend/
+// vadRmsThreshold when present. opusPayload, if non-nil, is this frame's
+// original encoded Opus packet, kept for the STT_INPUT_CODEC=opus/ogg
+// passthrough path in flushAccum.
+func (p *Processor) appendAccum(ssrc uint32, samples []int16, incomingCID string, voiceProb *float64, opusPayload []byte) string {
 	p.accumMu.Lock()
 	defer p.accumMu.Unlock()
 	a, ok := p.accums[ssrc]
@@ -742,8 +1322,9 @@ func (p *Processor) appendAccum(ssrc uint32, samples []int16, incomingCID string
 			}
 		}
 		p.accums[ssrc] = a
+		accumulatorDepth.Set(float64(len(p.accums)))
 		// Log accumulator creation and captured user mapping (may be empty)
-		logging.Debugw("appendAccum: created accumulator", "ssrc", ssrc, "user_id", uid, "user_name", uname, "correlation_id", a.correlationID)
+		logging.Debug("appendAccum: created accumulator", "ssrc", ssrc, "user_id", uid, "user_name", uname, "correlation_id", a.correlationID)
 	}
 	// If accumulator exists but lacks an ID, populate it from incomingCID.
 	if a.correlationID == "" {
@@ -754,12 +1335,105 @@ func (p *Processor) appendAccum(ssrc uint32, samples []int16, incomingCID string
 			a.correlationID = uuid.NewString()
 		}
 		// Log correlation id assignment (if it was previously empty)
-		logging.Debugw("appendAccum: assigned correlation id", "ssrc", ssrc, "correlation_id", a.correlationID, "user_id", a.userID)
+		logging.Debug("appendAccum: assigned correlation id", "ssrc", ssrc, "correlation_id", a.correlationID, "user_id", a.userID)
 	}
 	a.samples = append(a.samples, samples...)
+	if opusPayload != nil {
+		a.opusPackets = append(a.opusPackets, append([]byte(nil), opusPayload...))
+	}
 	a.last = time.Now()
-	// Compute RMS for the newly appended samples to update lastAboveRms.
-	if p.vadRmsThreshold > 0 && len(samples) > 0 {
+
+	// Stream this frame to the configured StreamingSTTClient as it arrives,
+	// rather than waiting for flushAccum's batch dispatch, so partial
+	// transcripts are available while the user is still talking. Started
+	// unconditionally on the first frame (not gated on a resolved user):
+	// flushAccum skips its batch fallback for any accumulator that streamed,
+	// so gating this on a.userID would silently drop whatever arrived before
+	// HandleSpeakingUpdate resolved the speaker - same as a.samples, this
+	// accumulates from frame one and the user mapping is sorted out at
+	// finalization by addAggregatedTranscript/flushAccum, same as today's
+	// batch path. Note that unlike the batch path's vadRmsThreshold check, a
+	// frame already pushed to the streaming backend can't be un-sent if the
+	// whole utterance later turns out to be near-silence; that's an
+	// inherent tradeoff of real-time streaming.
+	//
+	// The actual dial (OpenSession) and websocket writes (PushPCM) happen on
+	// a dedicated per-accumulator pump goroutine (startStreamPump), not
+	// here, so a slow or unreachable streaming backend can't stall this
+	// function's caller - the single goroutine that dispatches every SSRC's
+	// opus frames.
+	if p.streamingSTT != nil && len(samples) > 0 {
+		a.startStreamPump(p, ssrc)
+		select {
+		case a.streamFrames <- append([]int16(nil), samples...):
+		default:
+			streamFramesDroppedTotal.Inc()
+			logging.Debug("streaming stt: frame queue full, dropping frame", "ssrc", ssrc, "correlation_id", a.correlationID)
+		}
+	}
+	// Run the configured VAD backend on this frame and feed its
+	// speech/non-speech decision through a per-accumulator Gate, which
+	// requires VAD_MIN_SPEECH_MS of speech before reporting an utterance
+	// started and VAD_HANGOVER_MS of silence before reporting it stopped.
+	// lastAboveRms still drives flushExpiredAccums' silence timeout below.
+	if voiceProb != nil && len(samples) > 0 {
+		if a.denoiserGate == nil {
+			a.denoiserGate = vad.NewDenoiserGate(p.denoiserCfg)
+		}
+		if a.denoiserGate.Observe(*voiceProb) {
+			a.lastAboveRms = time.Now()
+		}
+	} else if p.vadMode == "energy_hysteresis" && len(samples) > 0 {
+		if a.energyGate == nil {
+			a.energyGate = vad.NewEnergyGate(p.energyGateCfg)
+		}
+		frameMs := (len(samples) * 1000) / 48000
+		event, prob := a.energyGate.Observe(samples, frameMs)
+		if a.energyGate.Active() {
+			a.vadSpeechFrames++
+		} else {
+			a.vadSilenceFrames++
+		}
+		a.vadProbSum += prob
+		a.vadProbFrames++
+		switch event {
+		case vad.EventStart:
+			a.vadStartUTC = time.Now().UTC().Format(time.RFC3339Nano)
+			logging.Info("vad: speech started", "ssrc", ssrc, "correlation_id", a.correlationID)
+		case vad.EventStop:
+			a.vadStopUTC = time.Now().UTC().Format(time.RFC3339Nano)
+			a.vadEnded = true
+			logging.Info("vad: speech stopped", "ssrc", ssrc, "correlation_id", a.correlationID)
+		}
+		if a.energyGate.Active() {
+			a.lastAboveRms = time.Now()
+		}
+	} else if p.vadBackend != nil && len(samples) > 0 {
+		if a.vadGate == nil {
+			a.vadGate = vad.NewGate(p.vadCfg)
+		}
+		isSpeech, prob := p.vadBackend.IsSpeech(samples, 48000)
+		if isSpeech {
+			a.vadSpeechFrames++
+		} else {
+			a.vadSilenceFrames++
+		}
+		a.vadProbSum += prob
+		a.vadProbFrames++
+		frameMs := (len(samples) * 1000) / 48000
+		switch a.vadGate.Observe(isSpeech, frameMs) {
+		case vad.EventStart:
+			a.vadStartUTC = time.Now().UTC().Format(time.RFC3339Nano)
+			logging.Info("vad: speech started", "ssrc", ssrc, "correlation_id", a.correlationID)
+		case vad.EventStop:
+			a.vadStopUTC = time.Now().UTC().Format(time.RFC3339Nano)
+			a.vadEnded = true
+			logging.Info("vad: speech stopped", "ssrc", ssrc, "correlation_id", a.correlationID)
+		}
+		if isSpeech {
+			a.lastAboveRms = time.Now()
+		}
+	} else if p.vadRmsThreshold > 0 && len(samples) > 0 {
 		var sumSq int64
 		for _, s := range samples {
 			v := int64(s)
@@ -767,8 +1441,17 @@ func (p *Processor) appendAccum(ssrc uint32, samples []int16, incomingCID string
 		}
 		meanSq := sumSq / int64(len(samples))
 		rms := int(math.Sqrt(float64(meanSq)))
-		if rms >= p.vadRmsThreshold {
-			a.lastAboveRms = time.Now()
+		// The static vadRmsThreshold seeds each SSRC's tracker, but the
+		// effective gate is the per-SSRC adaptive threshold so a whisperer
+		// and a shouter sharing a channel (or a room whose HVAC cycles)
+		// each get a threshold tracking their own recent noise floor.
+		tracker := p.noiseFloorTrackerFor(ssrc)
+		now := time.Now()
+		threshold := tracker.Threshold()
+		if float64(rms) >= threshold {
+			a.lastAboveRms = now
+		} else {
+			tracker.Observe(rms, now)
 		}
 	}
 
@@ -785,6 +1468,38 @@ func (p *Processor) appendAccum(ssrc uint32, samples []int16, incomingCID string
 	return a.correlationID
 }
 
+// startStreamPump lazily starts this accumulator's streaming pump goroutine,
+// which owns dialing p.streamingSTT, pushing every frame sent on
+// a.streamFrames, and closing out the session (once flushAccum closes the
+// channel) - all off the caller's goroutine. Callers must hold p.accumMu.
+func (a *pcmAccum) startStreamPump(p *Processor, ssrc uint32) {
+	if a.streamFrames != nil {
+		return
+	}
+	// Buffered for ~1s of 20ms frames so a brief dial delay doesn't drop the
+	// very start of an utterance.
+	a.streamFrames = make(chan []int16, 50)
+	go func() {
+		sess, err := p.streamingSTT.OpenSession(p.ctx, ssrc, a.correlationID)
+		if err != nil {
+			logging.Warn("streaming stt: open session failed, falling back to batch flush", "ssrc", ssrc, "correlation_id", a.correlationID, "err", err)
+			// Drain without pushing so a backlog that accumulated while the
+			// dial was in flight doesn't block flushAccum's close(a.streamFrames).
+			for range a.streamFrames {
+			}
+			return
+		}
+		for frame := range a.streamFrames {
+			if err := sess.PushPCM(frame); err != nil {
+				logging.Warn("streaming stt: push pcm failed", "ssrc", ssrc, "correlation_id", a.correlationID, "err", err)
+			}
+		}
+		if err := sess.Close(); err != nil {
+			logging.Warn("streaming stt: close session failed", "ssrc", ssrc, "correlation_id", a.correlationID, "err", err)
+		}
+	}()
+}
+
 // flushAccum flushes an accumulator by sending its PCM to the STT service.
 // It removes the accumulator entry.
 func (p *Processor) flushAccum(ssrc uint32) {
@@ -794,15 +1509,45 @@ func (p *Processor) flushAccum(ssrc uint32) {
 		p.accumMu.Unlock()
 		return
 	}
+	flushCtx, flushSpan := tracer.Start(withCorrelationID(p.ctx, a.correlationID), "utterance.flush")
+	defer flushSpan.End()
 	samples := a.samples
 	// capture correlationID, createdAt and captured user info from accumulator (may be empty)
 	corrID := a.correlationID
 	createdAt := a.createdAt
 	uid := a.userID
 	uname := a.username
+	vadStartUTC := a.vadStartUTC
+	vadStopUTC := a.vadStopUTC
+	vadSpeechFrames := a.vadSpeechFrames
+	vadSilenceFrames := a.vadSilenceFrames
+	vadMeanProb := 0.0
+	if a.vadProbFrames > 0 {
+		vadMeanProb = a.vadProbSum / float64(a.vadProbFrames)
+	}
+	opusPackets := a.opusPackets
+	streamFrames := a.streamFrames
 	delete(p.accums, ssrc)
+	accumulatorDepth.Set(float64(len(p.accums)))
 	p.accumMu.Unlock()
 
+	// Closing a.streamFrames tells the accumulator's pump goroutine
+	// (startStreamPump) to push whatever's left, close out the streaming
+	// session - which delivers its final transcript via
+	// addAggregatedTranscript internally, see wsStreamingSession.Close - and
+	// exit. This must happen regardless of which path below this function
+	// takes, including early drops, and is safe even if the pump's dial
+	// never completed (it drains instead of pushing in that case).
+	if streamFrames != nil {
+		defer close(streamFrames)
+	}
+
+	// Normalize loudness in place before WAV encoding so both the STT
+	// request and the saved debug WAV see the same, level-matched audio.
+	_, normSpan := tracer.Start(flushCtx, "loudness.normalize")
+	loudnessResult := normalizeLoudness(samples, 48000, p.targetLUFS, p.maxTruePeakDBTP)
+	normSpan.End()
+
 	// Convert samples to bytes and send
 	pcmBytes := &bytes.Buffer{}
 	for _, s := range samples {
@@ -840,10 +1585,11 @@ func (p *Processor) flushAccum(ssrc uint32) {
 		createdAt := a.createdAt
 		uid := a.userID
 		uname := a.username
-		go func(ssrc uint32, pcm []byte, cid string, durationMs int, rmsVal int, vadDropped bool, createdAt time.Time, uid string, uname string) {
+		guildID, channelID := p.originIDs()
+		go func(ssrc uint32, pcm []byte, cid string, durationMs int, rmsVal int, vadDropped bool, createdAt time.Time, uid string, uname string, vadStartUTC string, vadStopUTC string, vadSpeechFrames int, vadSilenceFrames int, vadMeanProb float64, loudness loudnessNormResult, guildID string, channelID string) {
 			// ensure dir exists
 			if err := os.MkdirAll(p.saveAudioDir, 0o755); err != nil {
-				logging.Errorw("failed to create save audio dir", "dir", p.saveAudioDir, "err", err)
+				logging.Error("failed to create save audio dir", "dir", p.saveAudioDir, "err", err)
 				return
 			}
 			// prefer accumulator-captured user info to avoid races
@@ -864,11 +1610,11 @@ func (p *Processor) flushAccum(ssrc uint32) {
 			wav := buildWAV(pcm, 48000, 1, 16)
 			tmp := fname + ".tmp"
 			if err := os.WriteFile(tmp, wav, 0o644); err != nil {
-				logging.Errorw("failed to write wav tmp file", "tmp", tmp, "err", err)
+				logging.Error("failed to write wav tmp file", "tmp", tmp, "err", err)
 				return
 			}
 			if err := os.Rename(tmp, fname); err != nil {
-				logging.Errorw("failed to rename wav tmp", "tmp", tmp, "fname", fname, "err", err)
+				logging.Error("failed to rename wav tmp", "tmp", tmp, "fname", fname, "err", err)
 				_ = os.Remove(tmp)
 				return
 			}
@@ -877,6 +1623,8 @@ func (p *Processor) flushAccum(ssrc uint32) {
 				"ssrc":           ssrc,
 				"user_id":        uid,
 				"username":       username,
+				"guild_id":       guildID,
+				"channel_id":     channelID,
 				// include the wav file path so consumers can locate the audio without a separate index
 				"wav_path":      fname,
 				"timestamp_utc": ts,
@@ -885,13 +1633,37 @@ func (p *Processor) flushAccum(ssrc uint32) {
 				"vad_dropped":   vadDropped,
 				// placeholder timing fields filled after STT response
 				"accum_created_utc": createdAt.UTC().Format(time.RFC3339Nano),
+				"vad_start_utc":     vadStartUTC,
+				"vad_stop_utc":      vadStopUTC,
+				// per-utterance VAD statistics, recorded so operators can see how
+				// confidently vadBackend judged this utterance, not just its
+				// start/stop timestamps.
+				"vad_speech_frames":  vadSpeechFrames,
+				"vad_silence_frames": vadSilenceFrames,
+				"vad_mean_prob":      vadMeanProb,
+				// loudness normalization diagnostics, recorded so operators can
+				// tune vadRmsThreshold and debug transcription quality.
+				"measured_lufs":   loudness.MeasuredLUFS,
+				"applied_gain_db": loudness.AppliedGainDB,
+				"true_peak_dbtp":  loudness.TruePeakDBTP,
 			}
 			sidecarBytes, _ := json.MarshalIndent(sidecar, "", "  ")
 			if err := os.WriteFile(base+".json.tmp", sidecarBytes, 0o644); err == nil {
 				_ = os.Rename(base+".json.tmp", base+".json")
+				p.sidecarIdx.Put(cid, sidecarIndexEntry{
+					Path:          base + ".json",
+					SSRC:          ssrc,
+					UserID:        uid,
+					GuildID:       guildID,
+					ChannelID:     channelID,
+					DurationMS:    int64(durationMs),
+					CreatedAtUTC:  createdAt.UTC().Format(time.RFC3339Nano),
+					IndexedAtUnix: time.Now().Unix(),
+					ModUnix:       time.Now().Unix(),
+				})
 			}
-			logging.Infow("saved audio to disk", "json", base+".json", "wav", fname, "ssrc", ssrc, "correlation_id", cid)
-		}(ssrc, pcmBytes.Bytes(), cid, durationMs, rmsVal, vadDropped, createdAt, uid, uname)
+			logging.Info("saved audio to disk", "json", base+".json", "wav", fname, "ssrc", ssrc, "correlation_id", cid)
+		}(ssrc, pcmBytes.Bytes(), cid, durationMs, rmsVal, vadDropped, createdAt, uid, uname, vadStartUTC, vadStopUTC, vadSpeechFrames, vadSilenceFrames, vadMeanProb, loudnessResult, guildID, channelID)
 	}
 	// If accumulator didn't capture a user mapping, wait a short window for
 	// a late speaking update to arrive (common when the bot joins after
@@ -924,8 +1696,8 @@ func (p *Processor) flushAccum(ssrc uint32) {
 			rms := int(math.Sqrt(float64(meanSq)))
 			if rms < p.vadRmsThreshold {
 				atomic.AddInt64(&p.vadDropCount, 1)
-				logging.Debugw("VAD dropped near-silence chunk", "ssrc", ssrc, "rms", rms)
-				logging.Debugw("VAD drop details", "ssrc", ssrc, "samples", len(samples), "duration_ms", (len(samples)*1000)/48000)
+				logging.Debug("VAD dropped near-silence chunk", "ssrc", ssrc, "rms", rms)
+				logging.Debug("VAD drop details", "ssrc", ssrc, "samples", len(samples), "duration_ms", (len(samples)*1000)/48000)
 				return
 			}
 		}
@@ -940,14 +1712,31 @@ func (p *Processor) flushAccum(ssrc uint32) {
 			durationMs = (len(samples) * 1000) / 48000
 		}
 		atomic.AddInt64(&p.sendFailCount, 1)
-		logging.Warnw("dropping audio chunk with unknown user; not sending to STT", "ssrc", ssrc, "correlation_id", cid, "duration_ms", durationMs)
+		logging.Warn("dropping audio chunk with unknown user; not sending to STT", "ssrc", ssrc, "correlation_id", cid, "duration_ms", durationMs)
 		return
 	}
 
-	if err := p.sendPCMToWhisper(ssrc, pcmBytes.Bytes(), cid, createdAt, uid, uname); err != nil {
-		// logging removed: send to whisper failed
+	// A streaming pump already sent every frame as it arrived and, via the
+	// deferred close(streamFrames) above, delivers its own final transcript
+	// once its pump goroutine closes out the session - the blocking batch
+	// dispatch below is the fallback path for when STT_STREAM_URL isn't
+	// configured.
+	if streamFrames != nil {
 		return
 	}
+
+	pcmData := pcmBytes.Bytes()
+	p.dispatch.Submit(uid, ssrc, len(pcmData), func() {
+		// Submit may run this closure immediately or, if rate limited, much
+		// later from DrainAll - by then flushSpan has already ended via the
+		// defer above, so start a fresh span off the correlation ID instead
+		// of reusing the now-closed flushCtx as a parent.
+		dispatchCtx, dispatchSpan := tracer.Start(withCorrelationID(p.ctx, cid), "utterance.dispatch")
+		defer dispatchSpan.End()
+		if err := p.sendPCMToWhisper(dispatchCtx, ssrc, pcmData, opusPackets, cid, createdAt, uid, uname); err != nil {
+			// logging removed: send to whisper failed
+		}
+	})
 }
 
 // flushExpiredAccums checks accumulators and flushes ones that have been
@@ -963,6 +1752,12 @@ func (p *Processor) flushExpiredAccums() {
 			toFlush = append(toFlush, ssrc)
 			continue
 		}
+		// vadEnded means vadGate already fired a true end-of-utterance edge;
+		// flush now instead of waiting out silenceTimeoutMs on top of it.
+		if a.vadEnded {
+			toFlush = append(toFlush, ssrc)
+			continue
+		}
 		// If there's recent speech (lastAboveRms), wait for a silence period
 		// before flushing. Otherwise, if inactivity exceeded flushTimeout,
 		// flush as before.
@@ -982,15 +1777,28 @@ func (p *Processor) flushExpiredAccums() {
 	}
 }
 
-// sendPCMToWhisper wraps raw PCM16LE into a WAV and POSTs it to WHISPER_URL.
-// It retries up to 3 times with exponential backoff for transient errors.
-func (p *Processor) sendPCMToWhisper(ssrc uint32, pcmBytes []byte, correlationID string, accumCreatedAt time.Time, capturedUserID string, capturedUsername string) error {
+// sendPCMToWhisper POSTs the utterance to WHISPER_URL, making up to 3
+// attempts with exponential backoff for transient errors. By default it wraps
+// raw PCM16LE into a WAV; when STT_INPUT_CODEC is "opus" or "ogg" and
+// opusPackets is non-empty, it instead muxes the original Opus packets into
+// an Ogg Opus container and sends that, skipping the PCM->WAV re-encode
+// (see buildOggOpus).
+func (p *Processor) sendPCMToWhisper(ctx context.Context, ssrc uint32, pcmBytes []byte, opusPackets [][]byte, correlationID string, accumCreatedAt time.Time, capturedUserID string, capturedUsername string) error {
 	whisper := os.Getenv("WHISPER_URL")
 	if whisper == "" {
-		logging.Warnw("WHISPER_URL not set, dropping audio", "ssrc", ssrc, "correlation_id", correlationID)
+		logging.Warn("WHISPER_URL not set, dropping audio", "ssrc", ssrc, "correlation_id", correlationID)
 		return fmt.Errorf("WHISPER_URL not set")
 	}
 
+	if p.quota != nil {
+		durationMs := (len(pcmBytes) / 2 * 1000) / 48000
+		if !p.quota.allow(capturedUserID, durationMs) {
+			logging.Warn("dropping audio chunk: user rate limit or quota exceeded", "ssrc", ssrc, "user_id", capturedUserID, "correlation_id", correlationID)
+			p.markSidecarSkipped(correlationID, "rate_limit")
+			return nil
+		}
+	}
+
 	// Build base whisper URL and optionally add query params to control
 	// server-side transcription knobs. This keeps compatibility while
 	// allowing runtime tuning via environment variables.
@@ -1025,22 +1833,38 @@ func (p *Processor) sendPCMToWhisper(ssrc uint32, pcmBytes []byte, correlationID
 		whisperURL = u.String()
 	}
 
-	// Build a mono WAV (48kHz, 16-bit) from decoded PCM so transcription
-	// servers receive a standard audio container.
-	wav := buildWAV(pcmBytes, 48000, 1, 16)
+	// Build the request body: by default a mono WAV (48kHz, 16-bit) from
+	// decoded PCM, or an Ogg Opus container passing the original Opus
+	// packets straight through when configured and available.
+	codec := "wav"
+	var body []byte
+	contentType := "audio/wav"
+	if (p.sttInputCodec == "opus" || p.sttInputCodec == "ogg") && len(opusPackets) > 0 {
+		_, encodeSpan := tracer.Start(ctx, "oggopus.mux")
+		body = buildOggOpus(opusPackets, 48000/50, 1)
+		encodeSpan.End()
+		codec = "ogg_opus"
+		contentType = "audio/ogg; codecs=opus"
+	} else {
+		_, encodeSpan := tracer.Start(ctx, "wav.encode")
+		body = buildWAV(pcmBytes, 48000, 1, 16)
+		encodeSpan.End()
+	}
 
 	// Attempt up to 3 tries with exponential backoff on transient errors.
 	var lastErr error
 	for attempt := 0; attempt < 3; attempt++ {
+		reqSpanCtx, reqSpan := tracer.Start(ctx, "stt.request")
 		// Use configured whisper timeout for per-request context
-		reqCtx, cancel := context.WithTimeout(p.ctx, time.Duration(p.whisperTimeoutMS)*time.Millisecond)
-		req, err := http.NewRequestWithContext(reqCtx, "POST", whisperURL, bytes.NewReader(wav))
+		reqCtx, cancel := context.WithTimeout(reqSpanCtx, time.Duration(p.whisperTimeoutMS)*time.Millisecond)
+		req, err := http.NewRequestWithContext(reqCtx, "POST", whisperURL, bytes.NewReader(body))
 		if err != nil {
 			cancel()
+			reqSpan.End()
 			lastErr = err
 			break
 		}
-		req.Header.Set("Content-Type", "audio/wav")
+		req.Header.Set("Content-Type", contentType)
 		if correlationID != "" {
 			req.Header.Set("X-Correlation-ID", correlationID)
 		}
@@ -1059,17 +1883,20 @@ func (p *Processor) sendPCMToWhisper(ssrc uint32, pcmBytes []byte, correlationID
 				uid = p.ssrcMap[ssrc]
 				p.mu.Unlock()
 			}
-			logging.Debugw("sending audio to whisper", "ssrc", ssrc, "url", whisperURL, "correlation_id", correlationID, "bytes", len(pcmBytes), "samples", samples, "duration_ms", durationMs, "user_id", uid)
+			logging.Debug("sending audio to whisper", "ssrc", ssrc, "url", whisperURL, "correlation_id", correlationID, "bytes", len(pcmBytes), "samples", samples, "duration_ms", durationMs, "user_id", uid)
 		} else {
-			logging.Debugw("sending audio to whisper", "ssrc", ssrc, "url", whisperURL, "correlation_id", correlationID)
+			logging.Debug("sending audio to whisper", "ssrc", ssrc, "url", whisperURL, "correlation_id", correlationID)
 		}
 
+		pcmBytesSent.Add(float64(len(body)))
 		resp, err := p.httpClient.Do(req)
 		cancel()
 		if err != nil {
 			atomic.AddInt64(&p.sendFailCount, 1)
+			sttRequestsTotal.WithLabelValues("whisper", "error").Inc()
 			lastErr = err
-			logging.Warnw("HTTP send error to whisper", "ssrc", ssrc, "err", err, "attempt", attempt)
+			logging.Warn("HTTP send error to whisper", "ssrc", ssrc, "err", err, "attempt", attempt)
+			reqSpan.End()
 			// transient network error -> retry
 			backoff := time.Duration(1<<attempt) * time.Second
 			time.Sleep(backoff)
@@ -1081,8 +1908,10 @@ func (p *Processor) sendPCMToWhisper(ssrc uint32, pcmBytes []byte, correlationID
 
 		if resp.StatusCode >= 500 {
 			atomic.AddInt64(&p.sendFailCount, 1)
+			sttRequestsTotal.WithLabelValues("whisper", "server_error").Inc()
 			lastErr = fmt.Errorf("server error status=%d", resp.StatusCode)
-			logging.Warnw("STT server error", "ssrc", ssrc, "status", resp.StatusCode, "attempt", attempt)
+			logging.Warn("STT server error", "ssrc", ssrc, "status", resp.StatusCode, "attempt", attempt)
+			reqSpan.End()
 			backoff := time.Duration(1<<attempt) * time.Second
 			time.Sleep(backoff)
 			continue
@@ -1090,9 +1919,11 @@ func (p *Processor) sendPCMToWhisper(ssrc uint32, pcmBytes []byte, correlationID
 
 		var out map[string]interface{}
 		if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+			reqSpan.End()
 			lastErr = err
 			return err
 		}
+		reqSpan.End()
 
 		// compute client-observed STT latency and attempt to extract server processing time
 		sttLatencyMs := int(respReceivedTs.Sub(sendTs).Milliseconds())
@@ -1141,6 +1972,12 @@ func (p *Processor) sendPCMToWhisper(ssrc uint32, pcmBytes []byte, correlationID
 		// Successful response - log transcript and timing for tracing (username resolved below)
 		// Successful response - log transcript if present and return nil.
 		atomic.AddInt64(&p.sendCount, 1)
+		sttRequestsTotal.WithLabelValues("whisper", "ok").Inc()
+		sttLatencyHist.Observe(float64(sttLatencyMs))
+		sttServerHist.Observe(float64(sttServerMs))
+		if endToEndMs > 0 {
+			endToEndHist.Observe(float64(endToEndMs))
+		}
 		// Prefer the accumulator-captured username when available to avoid
 		// races where the SSRC->user mapping changed between accumulation and send.
 		uid := capturedUserID
@@ -1161,7 +1998,7 @@ func (p *Processor) sendPCMToWhisper(ssrc uint32, pcmBytes []byte, correlationID
 			username = "unknown"
 		}
 		// Log STT response with resolved username and raw user id
-		logging.Infow("STT response received", "ssrc", ssrc, "user", username, "user_id", uid, "correlation_id", correlationID, "status", resp.StatusCode, "stt_latency_ms", sttLatencyMs, "stt_server_ms", sttServerMs, "end_to_end_ms", endToEndMs)
+		logging.Info("STT response received", "ssrc", ssrc, "user", username, "user_id", uid, "correlation_id", correlationID, "status", resp.StatusCode, "stt_latency_ms", sttLatencyMs, "stt_server_ms", sttServerMs, "end_to_end_ms", endToEndMs)
 		transcript := ""
 		if t, ok := out["text"].(string); ok {
 			// Trim whitespace the STT service may include (leading/trailing).
@@ -1169,54 +2006,43 @@ func (p *Processor) sendPCMToWhisper(ssrc uint32, pcmBytes []byte, correlationID
 		}
 		// Log STT result and timing for tracing
 		// logging removed: STT response received
-		// Optionally forward recognized text to another service for downstream
-		// integrations. This is a best-effort POST; failures are logged but do
-		// not affect the main transcription success path.
-		if fw := os.Getenv("TEXT_FORWARD_URL"); fw != "" && transcript != "" {
-			go func(forwardURL string, uid string, ssrc uint32, text string, cid string, sendTs, respTs time.Time, sttLatencyMs, sttServerMs, endToEndMs int) {
-				payload := map[string]interface{}{
-					"user_id":                   uid,
-					"ssrc":                      ssrc,
-					"transcript":                text,
-					"correlation_id":            cid,
-					"stt_request_sent_utc":      sendTs.UTC().Format(time.RFC3339Nano),
-					"stt_response_received_utc": respTs.UTC().Format(time.RFC3339Nano),
-					"stt_latency_ms":            sttLatencyMs,
-					"stt_server_ms":             sttServerMs,
-					"end_to_end_ms":             endToEndMs,
-				}
-				b, _ := json.Marshal(payload)
-				req, err := http.NewRequestWithContext(context.Background(), "POST", forwardURL, bytes.NewReader(b))
-				if err != nil {
-					// logging removed: text forward new request error
-					return
-				}
-				req.Header.Set("Content-Type", "application/json")
-				// Do not reuse processor httpClient to avoid interfering with timeouts
-				c := &http.Client{Timeout: 5 * time.Second}
-				resp, err := c.Do(req)
-				if err != nil {
-					// logging removed: text forward POST failed
-					return
-				}
-				defer resp.Body.Close()
-				if resp.StatusCode >= 300 {
-					// logging removed: text forward returned non-2xx
-				} else {
-					// logging removed: forwarded transcript
-				}
-			}(fw, uid, ssrc, transcript, correlationID, sendTs, respReceivedTs, sttLatencyMs, sttServerMs, endToEndMs)
+		// Optionally publish the recognized text to the configured
+		// TranscriptSink for downstream integrations. Delivery failures are
+		// queued to the sink's WAL and retried in the background rather than
+		// dropped, unlike the old fire-and-forget POST this replaced.
+		if p.transcriptSink != nil && transcript != "" {
+			wakeDetected, _ := p.hasWakePhrase(transcript)
+			go func(ev TranscriptEvent) {
+				_, fwSpan := tracer.Start(ctx, "transcript.sink.send")
+				defer fwSpan.End()
+				_ = p.transcriptSink.Send(ctx, ev)
+			}(TranscriptEvent{
+				UserID:        uid,
+				SSRC:          ssrc,
+				Seq:           p.transcriptSink.nextSeq(ssrc),
+				CorrelationID: correlationID,
+				Transcript:    transcript,
+				IsFinal:       true,
+				WakeDetected:  wakeDetected,
+				STTLatencyMs:  sttLatencyMs,
+				STTServerMs:   sttServerMs,
+				EndToEndMs:    endToEndMs,
+				EventSentUTC:  time.Now().UTC().Format(time.RFC3339Nano),
+			})
 		}
 
 		// Best-effort: update sidecar JSON with timing fields for offline analysis.
 		if p.saveAudioDir != "" && correlationID != "" {
+			_, sidecarSpan := tracer.Start(ctx, "sidecar.write")
+			defer sidecarSpan.End()
 			if path := p.findSidecarPathForCID(correlationID); path != "" {
 				b, err := os.ReadFile(path)
 				if err != nil {
-					logging.Warnw("failed to read sidecar for cid", "path", path, "err", err)
+					logging.Warn("failed to read sidecar for cid", "path", path, "err", err)
 				} else {
 					var sc map[string]interface{}
 					if uerr := json.Unmarshal(b, &sc); uerr == nil {
+						sc["stt_input_codec"] = codec
 						sc["stt_request_sent_utc"] = sendTs.UTC().Format(time.RFC3339Nano)
 						sc["stt_response_received_utc"] = respReceivedTs.UTC().Format(time.RFC3339Nano)
 						sc["stt_latency_ms"] = sttLatencyMs
@@ -1240,7 +2066,7 @@ func (p *Processor) sendPCMToWhisper(ssrc uint32, pcmBytes []byte, correlationID
 						_ = os.WriteFile(path+".tmp", nb, 0o644)
 						_ = os.Rename(path+".tmp", path)
 					} else {
-						logging.Debugw("failed to unmarshal sidecar JSON", "path", path, "err", uerr)
+						logging.Debug("failed to unmarshal sidecar JSON", "path", path, "err", uerr)
 					}
 				}
 			}
@@ -1250,35 +2076,136 @@ func (p *Processor) sendPCMToWhisper(ssrc uint32, pcmBytes []byte, correlationID
 		// aggregator/flusher can forward only the post-wake content.
 		_, strippedText := p.hasWakePhrase(transcript)
 		if transcript != "" {
-			p.addAggregatedTranscript(ssrc, username, transcript, correlationID, accumCreatedAt, strippedText)
+			p.addAggregatedTranscript(ssrc, username, transcript, correlationID, accumCreatedAt, strippedText, true, out["segments"])
+			p.recordWakeDetailsInSidecar(correlationID, ssrc)
 		}
 		return nil
 	}
 	return lastErr
 }
 
+// patchSidecarJSON reads the sidecar JSON at path, applies mutate to its
+// decoded form, and writes it back atomically (tmp+rename). Best-effort: any
+// read/decode/write error just aborts the patch, same as every other
+// sidecar update in this file. Shared by recordWakeDetailsInSidecar and
+// recordACLDecisionInSidecar so the read-mutate-write sequence only needs
+// fixing in one place.
+func patchSidecarJSON(path string, mutate func(sc map[string]interface{})) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var sc map[string]interface{}
+	if err := json.Unmarshal(b, &sc); err != nil {
+		return
+	}
+	mutate(sc)
+	nb, err := json.MarshalIndent(sc, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.WriteFile(path+".tmp", nb, 0o644); err == nil {
+		_ = os.Rename(path+".tmp", path)
+	}
+}
+
+// recordWakeDetailsInSidecar patches the sidecar JSON for correlationID with
+// the wake-phrase detection result addAggregatedTranscript just computed for
+// ssrc's aggregator, so wake_confidence/wake_start_ms/wake_end_ms are
+// available for offline tuning of wakeMaxEditDistance/wakeMinConfidence
+// alongside the rest of the utterance's timing fields. Best-effort, same as
+// the other sidecar updates in this file.
+func (p *Processor) recordWakeDetailsInSidecar(correlationID string, ssrc uint32) {
+	if p.saveAudioDir == "" || correlationID == "" {
+		return
+	}
+	p.aggMu.Lock()
+	a, ok := p.aggs[ssrc]
+	var wakeDetected bool
+	var wakeConfidence float64
+	var wakeStartMs, wakeEndMs int
+	if ok {
+		wakeDetected = a.wakeDetected
+		wakeConfidence = a.wakeConfidence
+		wakeStartMs = a.wakeStartMs
+		wakeEndMs = a.wakeEndMs
+	}
+	p.aggMu.Unlock()
+	if !ok || !wakeDetected || wakeConfidence == 0 {
+		// wakeConfidence == 0 means the word-level matcher never ran (either
+		// no wake phrase yet, or it matched via the flattened-text fallback,
+		// which has no timing/confidence to report).
+		return
+	}
+	path := p.findSidecarPathForCID(correlationID)
+	if path == "" {
+		return
+	}
+	patchSidecarJSON(path, func(sc map[string]interface{}) {
+		sc["wake_confidence"] = wakeConfidence
+		sc["wake_start_ms"] = wakeStartMs
+		sc["wake_end_ms"] = wakeEndMs
+	})
+}
+
+// recordACLDecisionInSidecar patches the sidecar JSON for correlationID with
+// the acl.Decision flushAgg just evaluated for this utterance, so a
+// post-hoc audit can see why a given transcript was or wasn't forwarded to
+// the orchestrator. Best-effort, same as the other sidecar updates in this
+// file.
+func (p *Processor) recordACLDecisionInSidecar(correlationID string, decision acl.Decision) {
+	if p.saveAudioDir == "" || correlationID == "" {
+		return
+	}
+	path := p.findSidecarPathForCID(correlationID)
+	if path == "" {
+		return
+	}
+	patchSidecarJSON(path, func(sc map[string]interface{}) {
+		sc["acl_decision"] = decision.Allowed
+		sc["acl_rule_id"] = decision.RuleID
+	})
+}
+
 // addAggregatedTranscript appends/inserts a transcript into the per-SSRC
 // aggregation buffer and updates the timestamp. The flusher will emit
 // combined transcripts after aggMs of inactivity.
-func (p *Processor) addAggregatedTranscript(ssrc uint32, username, text string, correlationID string, createdAt time.Time, strippedText string) {
+//
+// isFinal distinguishes a streaming STT session's partial results (text is
+// that session's latest, possibly-revised partial for its current
+// utterance) from a final result (the blocking whisper path always passes
+// true here). Partials are routed through a stabilityTracker so a revised
+// partial replaces the mutable tail of the current utterance instead of
+// being appended as a new space-joined fragment; a final result commits
+// whatever remains and resets the tracker for the next utterance.
+func (p *Processor) addAggregatedTranscript(ssrc uint32, username, text string, correlationID string, createdAt time.Time, strippedText string, isFinal bool, segments interface{}) {
 	p.aggMu.Lock()
 	defer p.aggMu.Unlock()
 	a, ok := p.aggs[ssrc]
 	if !ok {
-		a = &transcriptAgg{text: text, last: time.Now(), correlationID: correlationID, createdAt: createdAt}
-		if strippedText != "" {
-			a.wakeDetected = true
-			a.wakeStripped = strippedText
-		}
+		a = &transcriptAgg{last: time.Now(), correlationID: correlationID, createdAt: createdAt, stability: newStabilityTracker(defaultStabilityWindowN, p.stabilityMs)}
 		p.aggs[ssrc] = a
-		return
 	}
-	// Append with a space separator if existing text is non-empty
-	if a.text != "" {
-		a.text = strings.TrimSpace(a.text) + " " + strings.TrimSpace(text)
+
+	if isFinal {
+		finalUtterance := strings.TrimSpace(a.stability.Final(text))
+		a.stability = newStabilityTracker(defaultStabilityWindowN, p.stabilityMs)
+		if a.finalizedText != "" {
+			a.finalizedText = strings.TrimSpace(a.finalizedText) + " " + finalUtterance
+		} else {
+			a.finalizedText = finalUtterance
+		}
+		a.text = a.finalizedText
 	} else {
-		a.text = strings.TrimSpace(text)
+		committed, tail := a.stability.Update(text, time.Now())
+		rendering := strings.TrimSpace(committed + " " + tail)
+		if a.finalizedText != "" {
+			a.text = strings.TrimSpace(a.finalizedText) + " " + rendering
+		} else {
+			a.text = rendering
+		}
 	}
+
 	a.last = time.Now()
 	// If correlationID not set on existing agg, set it when provided
 	if a.correlationID == "" && correlationID != "" {
@@ -1287,11 +2214,26 @@ func (p *Processor) addAggregatedTranscript(ssrc uint32, username, text string,
 	if a.createdAt.IsZero() && !createdAt.IsZero() {
 		a.createdAt = createdAt
 	}
-	// preserve true once set: do not clear an existing wakeDetected flag
-	if strippedText != "" {
-		a.wakeDetected = true
-		// prefer the first seen stripped text
-		if a.wakeStripped == "" {
+	// Word-level detection is preferred over the flattened-text strippedText
+	// fallback the caller computed via hasWakePhrase: it can't misfire on a
+	// homophone mid-utterance and gives an exact sample offset for the
+	// post-wake content. Preserve the first match seen within this
+	// aggregation window, same as the prior wakeDetected/wakeStripped
+	// semantics.
+	if !a.wakeDetected {
+		if isFinal {
+			if words := parseSegmentWords(segments); len(words) > 0 {
+				if matched, m := p.detectWakePhraseWords(words); matched {
+					a.wakeDetected = true
+					a.wakeStripped = m.StrippedText
+					a.wakeConfidence = m.Confidence
+					a.wakeStartMs = m.StartMs
+					a.wakeEndMs = m.EndMs
+				}
+			}
+		}
+		if !a.wakeDetected && strippedText != "" {
+			a.wakeDetected = true
 			a.wakeStripped = strippedText
 		}
 	}
@@ -1342,34 +2284,38 @@ func (p *Processor) flushAgg(ssrc uint32) {
 	if username == "" {
 		username = "unknown"
 	}
-	// Also forward to TEXT_FORWARD_URL if configured (reuse same payload logic)
-	if fw := os.Getenv("TEXT_FORWARD_URL"); fw != "" {
-		go func(forwardURL string, uid string, ssrc uint32, text string) {
-			payload := map[string]interface{}{
-				"user_id":    uid,
-				"ssrc":       ssrc,
-				"transcript": text,
-			}
-			b, _ := json.Marshal(payload)
-			req, err := http.NewRequestWithContext(context.Background(), "POST", forwardURL, bytes.NewReader(b))
-			if err != nil {
-				// logging removed: text forward new request error
-				return
-			}
-			req.Header.Set("Content-Type", "application/json")
-			c := &http.Client{Timeout: 5 * time.Second}
-			resp, err := c.Do(req)
-			if err != nil {
-				// logging removed: text forward POST failed
-				return
-			}
-			defer resp.Body.Close()
-			if resp.StatusCode >= 300 {
-				// logging removed: text forward returned non-2xx
-			} else {
-				// logging removed: forwarded transcript
-			}
-		}(fw, uid, ssrc, strings.TrimSpace(text))
+	if p.history != nil {
+		p.history.record(TranscriptEntry{SSRC: ssrc, UserID: uid, Text: strings.TrimSpace(text), CorrelationID: corrID, At: time.Now()})
+	}
+	if p.eventRouter != nil {
+		guildID, channelID := p.originIDs()
+		p.eventRouter.Publish(orchestrator.Event{
+			Type:          orchestrator.EventTranscript,
+			GuildID:       guildID,
+			ChannelID:     channelID,
+			UserID:        uid,
+			Username:      username,
+			SSRC:          ssrc,
+			Text:          strings.TrimSpace(text),
+			CorrelationID: corrID,
+			At:            time.Now(),
+		})
+	}
+	// Also publish to the configured TranscriptSink (reuse same event schema
+	// as the per-request forward in sendPCMToWhisper).
+	if p.transcriptSink != nil {
+		go func(ev TranscriptEvent) {
+			_ = p.transcriptSink.Send(p.ctx, ev)
+		}(TranscriptEvent{
+			UserID:        uid,
+			SSRC:          ssrc,
+			Seq:           p.transcriptSink.nextSeq(ssrc),
+			CorrelationID: corrID,
+			Transcript:    strings.TrimSpace(text),
+			IsFinal:       true,
+			WakeDetected:  a.wakeDetected,
+			EventSentUTC:  time.Now().UTC().Format(time.RFC3339Nano),
+		})
 	}
 
 	// Forward aggregated transcript to an optional orchestrator / LLM service
@@ -1390,218 +2336,30 @@ func (p *Processor) flushAgg(ssrc uint32) {
 			m, stripped = p.hasWakePhrase(text)
 			matched = m
 		}
+		// Consult the ACL before the wake-phrase gate below, not after: its
+		// wake_required only means anything for an utterance that didn't
+		// match a wake phrase, so checking it here is what lets an
+		// allow-listed-but-wake_required rule actually fire (and be
+		// recorded) instead of every unmatched utterance bailing out on the
+		// plain "not matching wake phrase" return before the ACL ever runs.
+		decision := p.aclManager.Decide(uid, ssrc, matched)
+		if p.aclManager.Configured() {
+			p.recordACLDecisionInSidecar(corrID, decision)
+		}
+		if !decision.Allowed {
+			logging.Info("orchestrator: skipped by ACL", "correlation_id", corrID, "user_id", uid, "ssrc", ssrc, "rule_id", decision.RuleID)
+			return
+		}
 		if !matched {
 			// not matching wake phrase; skip orchestrator/TTS forwarding
 			return
-		} else {
-			// use stripped text for the user content
-			go func(orchestratorURL string, authToken string, uid string, ssrc uint32, text string, correlationID string) {
-				// Build an OpenAI-compatible chat request. Include a short system message
-				// with metadata so the orchestrator can use it if desired.
-				userContent := stripped
-				if userContent == "" {
-					userContent = strings.TrimSpace(text)
-				}
-				chatPayload := map[string]interface{}{
-					"model": os.Getenv("ORCHESTRATOR_MODEL"),
-					"messages": []map[string]string{
-						{"role": "system", "content": fmt.Sprintf("source: discord-voice-lab; user_id: %s; ssrc: %d; correlation_id: %s", uid, ssrc, correlationID)},
-						{"role": "user", "content": userContent},
-					},
-					// include correlation_id in top-level payload for easier downstream tracing
-					"correlation_id": correlationID,
-				}
-				// If model is empty, remove it to let the server pick a default
-				if chatPayload["model"] == "" || chatPayload["model"] == nil {
-					delete(chatPayload, "model")
-				}
-				b, _ := json.Marshal(chatPayload)
-				// Use configured orchestrator timeout (fallback to 30s) and retry a few times
-				timeoutMs := p.orchestratorTimeoutMS
-				if timeoutMs <= 0 {
-					timeoutMs = 30000
-				}
-				attempts := 3
-				var resp *http.Response
-				var err error
-				for i := 0; i < attempts; i++ {
-					ctxReq, cancelReq := context.WithTimeout(context.Background(), time.Duration(timeoutMs)*time.Millisecond)
-					req, rerr := http.NewRequestWithContext(ctxReq, "POST", orchestratorURL, bytes.NewReader(b))
-					if rerr != nil {
-						logging.Debugw("orchestrator: new request error", "err", rerr, "correlation_id", correlationID)
-						cancelReq()
-						err = rerr
-						break
-					}
-					req.Header.Set("Content-Type", "application/json")
-					if authToken != "" {
-						req.Header.Set("Authorization", "Bearer "+authToken)
-					}
-					client := &http.Client{Timeout: time.Duration(timeoutMs) * time.Millisecond}
-					resp, err = client.Do(req)
-					cancelReq()
-					if err != nil {
-						logging.Debugw("orchestrator: POST attempt failed", "attempt", i+1, "err", err, "correlation_id", correlationID)
-						if i < attempts-1 {
-							time.Sleep(time.Duration(200*(1<<i)) * time.Millisecond)
-							continue
-						}
-						// final failure
-						return
-					}
-					// Received response; stop retrying
-					break
-				}
-				if resp == nil {
-					logging.Debugw("orchestrator: no response received", "correlation_id", correlationID)
-					return
-				}
-				defer resp.Body.Close()
-				body, _ := io.ReadAll(resp.Body)
-				if resp.StatusCode >= 300 {
-					logging.Warnw("orchestrator: returned non-2xx", "status", resp.StatusCode, "correlation_id", correlationID)
-					return
-				}
-				logging.Infow("orchestrator: forwarded transcript", "status", resp.StatusCode, "correlation_id", correlationID)
-
-				// Parse OpenAI-style response: choices[0].message.content
-				var orchOut map[string]interface{}
-				if err := json.Unmarshal(body, &orchOut); err == nil {
-					// Log raw orchestrator response for easier tracing (non-sensitive)
-					if bstr := strings.TrimSpace(string(body)); bstr != "" {
-						// Avoid logging extremely large bodies
-						if len(bstr) > 2000 {
-							logging.Debugw("orchestrator: response (truncated)", "correlation_id", correlationID, "body_len", len(bstr))
-						} else {
-							logging.Debugw("orchestrator: response body", "correlation_id", correlationID, "body", bstr)
-						}
-					}
-					if choices, ok := orchOut["choices"].([]interface{}); ok && len(choices) > 0 {
-						if ch0, ok := choices[0].(map[string]interface{}); ok {
-							if msg, ok := ch0["message"].(map[string]interface{}); ok {
-								if content, ok := msg["content"].(string); ok && strings.TrimSpace(content) != "" {
-									replyText := strings.TrimSpace(content)
-									logging.Infow("orchestrator: reply received", "correlation_id", correlationID, "reply_len", len(replyText))
-									logging.Debugw("orchestrator: reply text", "correlation_id", correlationID, "reply", replyText)
-
-									// Persist orchestrator reply to sidecar JSON (best-effort)
-									if p.saveAudioDir != "" && correlationID != "" {
-										if path := p.findSidecarPathForCID(correlationID); path != "" {
-											if sb, rerr := os.ReadFile(path); rerr == nil {
-												var sc map[string]interface{}
-												if uerr := json.Unmarshal(sb, &sc); uerr == nil {
-													sc["orchestrator_reply"] = replyText
-													sc["orchestrator_response_received_utc"] = time.Now().UTC().Format(time.RFC3339Nano)
-													if procMs, ok := orchOut["processing_ms"].(float64); ok {
-														sc["orchestrator_processing_ms"] = int(procMs)
-													}
-													nb, _ := json.MarshalIndent(sc, "", "  ")
-													_ = os.WriteFile(path+".tmp", nb, 0o644)
-													_ = os.Rename(path+".tmp", path)
-													logging.Infow("orchestrator: saved reply to sidecar", "path", path, "correlation_id", correlationID)
-												} else {
-													logging.Debugw("orchestrator: failed to unmarshal sidecar JSON", "path", path, "err", uerr, "correlation_id", correlationID)
-												}
-											} else {
-												logging.Debugw("orchestrator: failed to read sidecar for cid", "path", path, "err", rerr, "correlation_id", correlationID)
-											}
-										}
-									}
-
-									// If TTS_URL is configured, POST the reply text and save returned audio (with retries)
-									if tts := os.Getenv("TTS_URL"); tts != "" {
-										b2, _ := json.Marshal(map[string]string{"text": replyText})
-										ttsTimeout := 10000
-										if p.orchestratorTimeoutMS > 0 {
-											ttsTimeout = p.orchestratorTimeoutMS
-										}
-										ttsAttempts := 2
-										var resp2 *http.Response
-										var terr error
-										for ti := 0; ti < ttsAttempts; ti++ {
-											ctx2, cancel2 := context.WithTimeout(context.Background(), time.Duration(ttsTimeout)*time.Millisecond)
-											req2, rerr := http.NewRequestWithContext(ctx2, "POST", tts, bytes.NewReader(b2))
-											if rerr != nil {
-												logging.Debugw("tts: new request error", "err", rerr, "correlation_id", correlationID)
-												cancel2()
-												terr = rerr
-												break
-											}
-											req2.Header.Set("Content-Type", "application/json")
-											if tok := os.Getenv("TTS_AUTH_TOKEN"); tok != "" {
-												req2.Header.Set("Authorization", "Bearer "+tok)
-											} else if authToken != "" {
-												req2.Header.Set("Authorization", "Bearer "+authToken)
-											}
-											client2 := &http.Client{Timeout: time.Duration(ttsTimeout) * time.Millisecond}
-											resp2, terr = client2.Do(req2)
-											cancel2()
-											if terr != nil {
-												logging.Debugw("tts: POST attempt failed", "attempt", ti+1, "err", terr, "correlation_id", correlationID)
-												if ti < ttsAttempts-1 {
-													time.Sleep(time.Duration(200*(1<<ti)) * time.Millisecond)
-													continue
-												}
-												break
-											}
-											// got response; stop retrying
-											break
-										}
-										if terr != nil {
-											logging.Debugw("tts: POST failed", "err", terr, "correlation_id", correlationID)
-										} else if resp2 != nil {
-											defer resp2.Body.Close()
-											if resp2.StatusCode >= 300 {
-												_, _ = io.ReadAll(resp2.Body)
-												logging.Warnw("tts: returned non-2xx", "status", resp2.StatusCode, "correlation_id", correlationID)
-											} else {
-												audioBytes, rerr := io.ReadAll(resp2.Body)
-												if rerr != nil {
-													logging.Debugw("tts: failed to read response body", "err", rerr, "correlation_id", correlationID)
-												} else if p.saveAudioDir != "" {
-													tsTs := time.Now().UTC().Format("20060102T150405.000Z")
-													base := fmt.Sprintf("%s/%s_ssrc%d_tts", strings.TrimRight(p.saveAudioDir, "/"), tsTs, ssrc)
-													fname := base + ".wav"
-													tmp := fname + ".tmp"
-													if err := os.WriteFile(tmp, audioBytes, 0o644); err != nil {
-														logging.Debugw("tts: failed to write tmp file", "err", err, "path", tmp, "correlation_id", correlationID)
-													} else if err := os.Rename(tmp, fname); err != nil {
-														logging.Debugw("tts: failed to rename tmp file", "err", err, "tmp", tmp, "final", fname, "correlation_id", correlationID)
-														_ = os.Remove(tmp)
-													} else {
-														logging.Infow("tts: saved audio to disk", "path", fname, "correlation_id", correlationID)
-														// record tts path into sidecar JSON if possible
-														if p.saveAudioDir != "" && correlationID != "" {
-															if path := p.findSidecarPathForCID(correlationID); path != "" {
-																if sb, rerr := os.ReadFile(path); rerr == nil {
-																	var sc map[string]interface{}
-																	if uerr := json.Unmarshal(sb, &sc); uerr == nil {
-																		sc["tts_wav_path"] = fname
-																		sc["tts_saved_utc"] = time.Now().UTC().Format(time.RFC3339Nano)
-																		nb, _ := json.MarshalIndent(sc, "", "  ")
-																		_ = os.WriteFile(path+".tmp", nb, 0o644)
-																		_ = os.Rename(path+".tmp", path)
-																		logging.Infow("tts: saved tts path to sidecar", "path", path, "correlation_id", correlationID)
-																	} else {
-																		logging.Debugw("tts: failed to unmarshal sidecar JSON", "path", path, "err", uerr, "correlation_id", correlationID)
-																	}
-																} else {
-																	logging.Debugw("tts: failed to read sidecar for cid", "path", path, "err", rerr, "correlation_id", correlationID)
-																}
-															}
-														}
-													}
-												}
-											}
-										}
-									}
-								}
-							}
-						}
-					}
-				}
-			}(orch, os.Getenv("ORCH_AUTH_TOKEN"), uid, ssrc, strings.TrimSpace(text), corrID)
 		}
+		// use stripped text for the user content
+		userContent := stripped
+		if userContent == "" {
+			userContent = strings.TrimSpace(text)
+		}
+		p.enqueueOrchestratorForward(orch, uid, ssrc, userContent, corrID)
 	}
 }
 
@@ -1636,115 +2394,152 @@ func buildWAV(pcm []byte, sampleRate, channels, bitsPerSample int) []byte {
 	return buf.Bytes()
 }
 
-// hasWakePhrase checks whether the provided text begins with one of the
-// configured wake phrases (case-insensitive). If a wake phrase is found,
-// it returns (true, strippedText) where strippedText is the text with the
-// wake phrase and any immediate punctuation removed. Otherwise returns
-// (false, "").
+// hasWakePhrase checks whether the provided text contains one of the
+// configured wake phrases within the first wakePhraseWindowS seconds'
+// worth of words (or, if that's 0, strictly as a prefix). How a candidate
+// word is compared against a wake-phrase token is governed by
+// wakePhraseMatch: "exact" (default), "fuzzy" (summed Levenshtein
+// distance), or "phonetic" (Double Metaphone code equality) — see
+// wakeWindowMatches. If a wake phrase is found, it returns (true,
+// strippedText), where strippedText is reconstructed from the original
+// (non-normalized) transcript starting after the matched span, so the
+// user's actual casing and punctuation survive into the forwarded text.
+// Otherwise returns (false, "").
 func (p *Processor) hasWakePhrase(text string) (bool, string) {
 	if text == "" {
 		return false, ""
 	}
-	s := strings.ToLower(strings.TrimSpace(text))
-	// normalize whitespace
+	p.wakeMu.RLock()
+	defer p.wakeMu.RUnlock()
+	origText := strings.TrimLeft(text, " \t\n\r\f\v\"'`~")
+	s := strings.ToLower(origText)
 	s = regexp.MustCompile(`\s+`).ReplaceAllString(s, " ")
-	// trim any leading punctuation
-	s = strings.TrimLeft(s, " \t\n\r\f\v\"'`~")
-	// If wakePhraseWindowS == 0, fallback to strict prefix semantics
+
+	fullWords := strings.Fields(s)
+	origWords := strings.Fields(origText)
+	if len(origWords) != len(fullWords) {
+		// Tokenization drifted (e.g. case-folding changed a rune's byte
+		// length); fall back to the normalized words rather than risk
+		// misaligned stripped output.
+		origWords = fullWords
+	}
+
 	windowS := p.wakePhraseWindowS
-	for _, wp := range p.wakePhrases {
+	for wpIdx, wp := range p.wakePhrases {
 		if wp == "" {
 			continue
 		}
-		// exact match
 		if s == wp {
 			return true, ""
 		}
-		// If windowS == 0 use original prefix-based detection
-		if windowS == 0 {
-			prefixes := []string{wp + " ", wp + ",", wp + ".", wp + "!", wp + "?", wp + ":"}
-			for _, pref := range prefixes {
-				if strings.HasPrefix(s, pref) {
-					stripped := strings.TrimLeft(strings.TrimSpace(s[len(pref):]), " ,.!?;:-\"'`~")
-					return true, stripped
-				}
-			}
+		wpWords := strings.Fields(wp)
+		if len(wpWords) == 0 || len(fullWords) < len(wpWords) {
 			continue
 		}
-		// Window-based heuristic: check whether the wake phrase appears within
-		// the first K words of the transcript. K derived from windowS and a
-		// heuristic speech rate (~3 words/sec). This avoids requiring strict
-		// prefix matching while still limiting false positives.
-		words := strings.Fields(s)
-		k := windowS * 3
-		if k < 3 {
-			k = 3
-		}
-		if len(words) > k {
-			words = words[:k]
+
+		maxStart := len(fullWords) - len(wpWords)
+		searchLimit := maxStart
+		if windowS > 0 {
+			k := windowS * 3
+			if k < 3 {
+				k = 3
+			}
+			if k-len(wpWords) < searchLimit {
+				searchLimit = k - len(wpWords)
+			}
+		} else {
+			// windowS == 0: fall back to strict prefix semantics.
+			searchLimit = 0
 		}
-		// Split wake phrase into words to perform a word-boundary-aware search
-		wpWords := strings.Fields(wp)
-		if len(wpWords) == 0 {
+		if searchLimit < 0 {
 			continue
 		}
-		// helper to normalize a token for comparison (strip surrounding punctuation)
-		normalizeToken := func(tok string) string {
-			return strings.Trim(strings.ToLower(strings.TrimSpace(tok)), " ,.!?;:-\"'`~")
-		}
-		// Search for the wake phrase sequence anywhere inside the head word slice
-		for i := 0; i+len(wpWords) <= len(words); i++ {
-			match := true
-			for j := 0; j < len(wpWords); j++ {
-				if normalizeToken(words[i+j]) != normalizeToken(wpWords[j]) {
-					match = false
-					break
-				}
+
+		for start := 0; start <= searchLimit; start++ {
+			if !p.wakeWindowMatches(wpIdx, wpWords, fullWords, start) {
+				continue
 			}
-			if match {
-				// Build stripped text from the remainder of the entire normalized
-				// transcript (not just the head) starting after the matched words.
-				// Find the index of the first occurrence of this sequence in the
-				// full words list to capture any words beyond the head.
-				fullWords := strings.Fields(strings.TrimSpace(regexp.MustCompile(`\s+`).ReplaceAllString(s, " ")))
-				// locate the matched sequence in fullWords
-				foundIdx := -1
-				for fi := 0; fi+len(wpWords) <= len(fullWords); fi++ {
-					okMatch := true
-					for fj := 0; fj < len(wpWords); fj++ {
-						if normalizeToken(fullWords[fi+fj]) != normalizeToken(wpWords[fj]) {
-							okMatch = false
-							break
-						}
-					}
-					if okMatch {
-						foundIdx = fi
-						break
-					}
-				}
-				stripped := ""
-				if foundIdx >= 0 && foundIdx+len(wpWords) <= len(fullWords) {
-					if foundIdx+len(wpWords) < len(fullWords) {
-						stripped = strings.Join(fullWords[foundIdx+len(wpWords):], " ")
-						stripped = strings.Trim(stripped, " ,.!?;:-\"'`~")
-					}
-				}
-				return true, stripped
+			end := start + len(wpWords)
+			stripped := ""
+			if end < len(origWords) {
+				stripped = strings.Trim(strings.Join(origWords[end:], " "), " ,.!?;:-\"'`~")
 			}
+			return true, stripped
 		}
 	}
 	return false, ""
 }
 
+// wakeWindowMatches reports whether fullWords[start:start+len(wpWords)]
+// matches wakePhrases[wpIdx]'s tokens under the configured wakePhraseMatch
+// mode.
+func (p *Processor) wakeWindowMatches(wpIdx int, wpWords, fullWords []string, start int) bool {
+	switch p.wakePhraseMatch {
+	case "fuzzy":
+		total := 0
+		for j, target := range wpWords {
+			total += levenshtein(normalizeWakeToken(fullWords[start+j]), normalizeWakeToken(target))
+		}
+		threshold := len(strings.Join(wpWords, " ")) / 5
+		if threshold < 1 {
+			threshold = 1
+		}
+		return total <= threshold
+	case "phonetic":
+		codes := p.wakePhrasePhonetics[wpIdx]
+		if len(codes) != len(wpWords) {
+			return false
+		}
+		for j, target := range codes {
+			cp, cs := doubleMetaphone(normalizeWakeToken(fullWords[start+j]))
+			if !metaphoneCodesMatch(cp, cs, target.Primary, target.Secondary) {
+				return false
+			}
+		}
+		return true
+	default: // "exact"
+		for j, target := range wpWords {
+			if normalizeWakeToken(fullWords[start+j]) != normalizeWakeToken(target) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// metaphoneCodesMatch reports whether two words' Double Metaphone codes are
+// close enough to accept: either primary codes agree, or either word's
+// secondary (alternate-pronunciation) code agrees with the other's primary
+// or secondary code.
+func metaphoneCodesMatch(p1, s1, p2, s2 string) bool {
+	if p1 == "" || p2 == "" {
+		return false
+	}
+	if p1 == p2 {
+		return true
+	}
+	if s1 != "" && (s1 == p2 || s1 == s2) {
+		return true
+	}
+	if s2 != "" && s2 == p1 {
+		return true
+	}
+	return false
+}
+
 // findSidecarPathForCID returns the full path to the sidecar JSON for a given
-// correlation id. It first looks for an index file named `cid-<cid>.idx` in
-// the saveAudioDir which contains the exact JSON path. If not found, it
-// falls back to scanning the directory for a filename that contains
-// 'cid<cid>' and ends with .json (legacy behavior).
+// correlation id. It's an O(1) lookup against sidecarIdx (populated as
+// flushAccum saves each sidecar); the directory scan below only runs as a
+// fallback for correlation IDs saved before sidecarIdx existed or whose
+// persisted snapshot was lost, and backfills the index on a hit so it's not
+// repeated.
 func (p *Processor) findSidecarPathForCID(cid string) string {
 	if p.saveAudioDir == "" || cid == "" {
 		return ""
 	}
+	if path := p.sidecarIdx.Get(cid); path != "" {
+		return path
+	}
 	// Scan JSON files in saveAudioDir and try to find a sidecar whose
 	// correlation_id matches. Fall back to filename substring match if
 	// necessary. This avoids relying on a separate index file.
@@ -1759,6 +2554,7 @@ func (p *Processor) findSidecarPathForCID(cid string) string {
 			var sc map[string]interface{}
 			if err := json.Unmarshal(b, &sc); err == nil {
 				if v, ok := sc["correlation_id"].(string); ok && v == cid {
+					p.sidecarIdx.Put(cid, sidecarIndexEntry{Path: path, IndexedAtUnix: time.Now().Unix()})
 					return path
 				}
 			}
@@ -1768,7 +2564,9 @@ func (p *Processor) findSidecarPathForCID(cid string) string {
 	for _, fi := range files {
 		name := fi.Name()
 		if strings.Contains(name, "cid"+cid) && strings.HasSuffix(name, ".json") {
-			return p.saveAudioDir + "/" + name
+			path := p.saveAudioDir + "/" + name
+			p.sidecarIdx.Put(cid, sidecarIndexEntry{Path: path, IndexedAtUnix: time.Now().Unix()})
+			return path
 		}
 	}
 	return ""
@@ -125,5 +125,5 @@ func startBackgroundWorkers(p *Processor) {
 			}
 		}
 	}()
-	logging.Infow("Processor: background workers started")
+	logging.Info("Processor: background workers started")
 }
@@ -1,6 +1,7 @@
 package voice
 
 import (
+	"os"
 	"testing"
 
 	"github.com/bwmarrin/discordgo"
@@ -32,3 +33,43 @@ func TestHandleSpeakingUpdateMapsSSRC(t *testing.T) {
 		t.Fatalf("ssrc mapping mismatch: want=%s got=%s", su.UserID, got)
 	}
 }
+
+// TestHasWakePhraseFuzzyAndPhonetic verifies that the fuzzy and phonetic
+// wakePhraseMatch modes tolerate common ASR mis-transcriptions of a wake
+// phrase, and that the stripped text returned alongside a match preserves
+// the original transcript's casing and punctuation.
+func TestHasWakePhraseFuzzyAndPhonetic(t *testing.T) {
+	cases := []struct {
+		mode      string
+		utterance string
+		stripped  string
+	}{
+		// "compuper" is one Levenshtein edit away from "computer".
+		{"fuzzy", "Hey Compuper, What's the Weather?", "What's the Weather?"},
+		// "kompyooter" is spelled nothing like "computer" but encodes to the
+		// same Double Metaphone primary code.
+		{"phonetic", "Hey Kompyooter, What's the Weather?", "What's the Weather?"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.mode, func(t *testing.T) {
+			os.Setenv("WAKE_PHRASES", "hey computer")
+			os.Setenv("WAKE_PHRASE_MATCH_MODE", tc.mode)
+			defer os.Unsetenv("WAKE_PHRASES")
+			defer os.Unsetenv("WAKE_PHRASE_MATCH_MODE")
+
+			p, err := NewProcessor()
+			if err != nil {
+				t.Fatalf("NewProcessor: %v", err)
+			}
+			defer func() { _ = p.Close() }()
+
+			matched, stripped := p.hasWakePhrase(tc.utterance)
+			if !matched {
+				t.Fatalf("hasWakePhrase(%q) mode=%s: want match, got none", tc.utterance, tc.mode)
+			}
+			if stripped != tc.stripped {
+				t.Fatalf("stripped text mismatch: want=%q got=%q", tc.stripped, stripped)
+			}
+		})
+	}
+}
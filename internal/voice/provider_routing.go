@@ -0,0 +1,59 @@
+package voice
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/discord-voice-lab/internal/logging"
+)
+
+// providerRouter maps a per-request key (here, the Discord user ID) to the
+// registry name of the llm.Provider/tts.Provider that should handle it,
+// falling back to a package-wide default when the key has no override.
+// Routing "per correlation-id", as the originating request names it,
+// resolves here at the moment each correlation ID is minted: a fresh UUID
+// has no identity of its own to key a static config on, so selection keys
+// off the user who triggered it instead, and the chosen provider name is
+// then recorded against that correlation ID in its sidecar for tracing.
+type providerRouter struct {
+	defaultName string
+	overrides   map[string]string // user ID -> provider name
+}
+
+// newProviderRouter builds a providerRouter from def and the parsed
+// contents of an optional JSON object env var (user ID -> provider name),
+// e.g. ORCHESTRATOR_ROUTING_JSON=`{"123456789012345678":"anthropic"}`.
+func newProviderRouter(def, overridesEnvVar string) *providerRouter {
+	r := &providerRouter{defaultName: def, overrides: map[string]string{}}
+	raw := os.Getenv(overridesEnvVar)
+	if raw == "" {
+		return r
+	}
+	var parsed map[string]string
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		logging.Warn("provider routing: failed to parse overrides", "env", overridesEnvVar, "err", err)
+		return r
+	}
+	for uid, name := range parsed {
+		r.overrides[uid] = strings.ToLower(strings.TrimSpace(name))
+	}
+	return r
+}
+
+// resolve returns the provider name for uid: its override if one exists and
+// known reports it as actually registered, else the router's default. A
+// configured override that doesn't match any registered provider name
+// (registry names are always lowercase) is logged rather than silently
+// dropped, so a typo in *_ROUTING_JSON doesn't go unnoticed.
+func (r *providerRouter) resolve(uid string, known func(name string) bool) string {
+	name, ok := r.overrides[uid]
+	if !ok || name == "" {
+		return r.defaultName
+	}
+	if !known(name) {
+		logging.Warn("provider routing: override names an unregistered provider, using default", "user_id", uid, "provider", name, "default", r.defaultName)
+		return r.defaultName
+	}
+	return name
+}
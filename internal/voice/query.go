@@ -0,0 +1,271 @@
+package voice
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/discord-voice-lab/internal/logging"
+	"github.com/discord-voice-lab/internal/voice/tts"
+)
+
+// SessionInfo summarizes one active SSRC->user mapping for external callers
+// (e.g. the voice.list_sessions MCP tool).
+type SessionInfo struct {
+	SSRC     uint32
+	UserID   string
+	Username string
+}
+
+// TranscriptEntry is a single finalized transcript emitted by the flusher,
+// retained in a bounded ring buffer for voice.get_transcript and streamed to
+// voice.subscribe_transcripts subscribers.
+type TranscriptEntry struct {
+	SSRC          uint32
+	UserID        string
+	Text          string
+	CorrelationID string
+	At            time.Time
+}
+
+const maxTranscriptHistory = 500
+
+// transcriptHistory is a small bounded ring buffer plus a fan-out of
+// subscriber channels, guarded by its own mu. It's deliberately separate from
+// the aggMu-protected per-SSRC aggregation state since it's read by MCP
+// tool calls which should never block the audio pipeline.
+type transcriptHistory struct {
+	mu          sync.Mutex
+	entries     []TranscriptEntry
+	subscribers map[chan TranscriptEntry]struct{}
+}
+
+func (h *transcriptHistory) record(e TranscriptEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = append(h.entries, e)
+	if len(h.entries) > maxTranscriptHistory {
+		h.entries = h.entries[len(h.entries)-maxTranscriptHistory:]
+	}
+	for ch := range h.subscribers {
+		select {
+		case ch <- e:
+		default:
+			// slow subscriber; drop rather than block the flusher
+		}
+	}
+}
+
+func (h *transcriptHistory) since(ssrc uint32, since time.Time) []TranscriptEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var out []TranscriptEntry
+	for _, e := range h.entries {
+		if ssrc != 0 && e.SSRC != ssrc {
+			continue
+		}
+		if !since.IsZero() && !e.At.After(since) {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+func (h *transcriptHistory) subscribe() (chan TranscriptEntry, func()) {
+	ch := make(chan TranscriptEntry, 16)
+	h.mu.Lock()
+	if h.subscribers == nil {
+		h.subscribers = make(map[chan TranscriptEntry]struct{})
+	}
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+	cancel := func() {
+		h.mu.Lock()
+		delete(h.subscribers, ch)
+		h.mu.Unlock()
+		close(ch)
+	}
+	return ch, cancel
+}
+
+// ListSessions returns the currently known SSRC->user mappings. Intended for
+// the voice.list_sessions MCP tool.
+func (p *Processor) ListSessions() []SessionInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]SessionInfo, 0, len(p.ssrcMap))
+	for ssrc, uid := range p.ssrcMap {
+		out = append(out, SessionInfo{SSRC: ssrc, UserID: uid, Username: p.userDisplay[uid]})
+	}
+	return out
+}
+
+// GetTranscript returns finalized transcripts recorded since the given time
+// for the given ssrc (or all SSRCs when ssrc is 0). Intended for the
+// voice.get_transcript MCP tool.
+func (p *Processor) GetTranscript(ssrc uint32, since time.Time) []TranscriptEntry {
+	return p.history.since(ssrc, since)
+}
+
+// ResetUserQuota clears userID's STT rate-limit bucket and monthly usage
+// counter, unblocking them ahead of the next calendar month. Intended for
+// the voice.reset_user_quota MCP tool. It is a no-op if rate limiting is
+// unconfigured.
+func (p *Processor) ResetUserQuota(userID string) {
+	if p.quota != nil {
+		p.quota.reset(userID)
+	}
+}
+
+// SubscribeTranscripts returns a channel of newly-finalized transcripts and a
+// cancel function to unsubscribe. Intended for the voice.subscribe_transcripts
+// MCP tool's streaming response.
+func (p *Processor) SubscribeTranscripts() (<-chan TranscriptEntry, func()) {
+	return p.history.subscribe()
+}
+
+// SubscribePartialTranscripts returns a channel of interim streaming-STT
+// transcripts and a cancel function to unsubscribe, for consumers that want
+// live captions ahead of an utterance's eventual final transcript. Only
+// fires when STT_STREAM_URL is configured; otherwise the channel simply never
+// receives anything.
+func (p *Processor) SubscribePartialTranscripts() (<-chan PartialTranscript, func()) {
+	return p.partials.subscribe()
+}
+
+// Speak synthesizes text via the configured ttsRouter-selected tts.Provider
+// (the same one the orchestrator reply path uses) and plays it back into
+// this Processor's voice connection, if any. Intended for the voice.speak
+// MCP tool.
+//
+// channelID is accepted for API parity with Discord's per-channel model,
+// but this Processor (like GuildSession, which owns one Processor per
+// guild) only ever has a single outbound voice connection at a time, set by
+// SetVoiceConnection; Speak does not itself join or switch channels, so
+// channelID is purely informational here, not used to route audio.
+//
+// Playback requires the synthesized audio to be PCM WAV, since that's the
+// only format decodeWAVPCM16 understands and the only one EnqueuePlayback's
+// 48kHz-mono-PCM contract supports without resampling; a provider emitting
+// a different sample rate will still play back, just at the wrong speed,
+// same caveat as using EnqueuePlayback directly. If saveAudioDir is set the
+// synthesized audio is also saved to disk, same as every other TTS path in
+// this package.
+func (p *Processor) Speak(ctx context.Context, text, channelID, correlationID string) error {
+	if strings.TrimSpace(text) == "" {
+		return fmt.Errorf("voice: speak: empty text")
+	}
+	name := p.ttsRouter.resolve("", func(n string) bool { _, ok := p.ttsProviders[n]; return ok })
+	provider, ok := p.ttsProviders[name]
+	if !ok {
+		return fmt.Errorf("voice: speak: no tts provider registered as %q", name)
+	}
+	audioBytes, mimeType, err := provider.Synthesize(ctx, text, "")
+	if err != nil {
+		return fmt.Errorf("voice: speak: synthesize via %s: %w", name, err)
+	}
+	if p.saveAudioDir != "" {
+		ts := time.Now().UTC().Format("20060102T150405.000Z")
+		fname := fmt.Sprintf("%s/%s_speak%s", strings.TrimRight(p.saveAudioDir, "/"), ts, tts.ExtensionForMIME(mimeType))
+		tmp := fname + ".tmp"
+		if err := os.WriteFile(tmp, audioBytes, 0o644); err == nil {
+			_ = os.Rename(tmp, fname)
+		}
+	}
+	samples, sampleRate, channels, err := decodeWAVPCM16(audioBytes)
+	if err != nil {
+		return fmt.Errorf("voice: speak: %w (mime=%s)", err, mimeType)
+	}
+	if sampleRate != 48000 {
+		logging.Warn("voice: speak: tts provider's sample rate doesn't match EnqueuePlayback's 48kHz assumption, playback will be mis-pitched", "provider", name, "sample_rate", sampleRate)
+	}
+	if channelID != "" {
+		// Processor itself has no notion of "which channel" (GuildSession
+		// owns that); it just speaks into whatever VoiceConnection was last
+		// registered via SetVoiceConnection.
+		logging.Debug("voice: speak: channel_id is informational only; speaking into this Processor's current voice connection", "channel_id", channelID)
+	}
+	p.EnqueuePlayback(correlationID, downmixToMono(samples, channels))
+	return nil
+}
+
+// SetWakePhrases replaces the configured wake phrases, recomputing the
+// phonetic encodings if wakePhraseMatch is "phonetic" so the new phrases take
+// effect for that mode too. Intended for the voice.set_wake_phrases MCP
+// tool. Safe to call while the audio pipeline is running: hasWakePhrase and
+// detectWakePhraseWords take wakeMu's read side, this takes its write side.
+func (p *Processor) SetWakePhrases(phrases []string) {
+	normalized := make([]string, 0, len(phrases))
+	for _, wp := range phrases {
+		if s := strings.ToLower(strings.TrimSpace(wp)); s != "" {
+			normalized = append(normalized, s)
+		}
+	}
+	p.wakeMu.Lock()
+	defer p.wakeMu.Unlock()
+	p.wakePhrases = normalized
+	if p.wakePhraseMatch == "phonetic" {
+		p.wakePhrasePhonetics = wakePhrasePhoneticsFor(normalized)
+	}
+}
+
+// ResolveUser returns the human-friendly username for a Discord user ID via
+// the configured NameResolver, or "" if unresolvable or no resolver is
+// configured. Intended for the voice.resolve_user MCP tool.
+func (p *Processor) ResolveUser(userID string) string {
+	if p.resolver == nil {
+		return ""
+	}
+	return p.resolver.UserName(userID)
+}
+
+// TailEvents returns finalized transcripts recorded since the given time,
+// across all SSRCs. It's GetTranscript(0, since) under a name that matches
+// the voice.tail_events MCP tool it backs; transcripts are the only
+// "events" this package currently keeps a queryable history of.
+func (p *Processor) TailEvents(since time.Time) []TranscriptEntry {
+	return p.GetTranscript(0, since)
+}
+
+// TranscribeWAV sends an already-encoded WAV payload to the configured
+// WHISPER_URL and returns the recognized text. It invokes the same endpoint
+// as sendPCMToWhisper but skips accumulation/VAD/aggregation, making it
+// suitable for the voice.transcribe_wav MCP tool where a client supplies a
+// complete utterance out-of-band.
+func (p *Processor) TranscribeWAV(ctx context.Context, wav []byte) (string, error) {
+	whisper := os.Getenv("WHISPER_URL")
+	if whisper == "" {
+		return "", fmt.Errorf("WHISPER_URL not set")
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", whisper, bytes.NewReader(wav))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "audio/wav")
+	client := p.httpClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("whisper returned status %d", resp.StatusCode)
+	}
+	var out struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("decode whisper response: %w", err)
+	}
+	return out.Text, nil
+}
@@ -0,0 +1,181 @@
+package voice
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// userRateLimiter gates STT requests per Discord user with a token bucket
+// (STT_USER_RPS/STT_USER_BURST) plus a monthly cumulative-duration cap
+// (STT_USER_SECONDS_CAP) persisted next to saveAudioDir so it survives
+// restarts. A single spammy user or an SSRC-mapping bug can otherwise
+// hammer (and for hosted backends, bill for) the STT backend indefinitely.
+type userRateLimiter struct {
+	mu         sync.Mutex
+	rps        float64
+	burst      float64
+	secondsCap float64
+	quotaDir   string // persists monthly usage; empty disables persistence
+	buckets    map[string]*tokenBucket
+	usage      map[string]*monthlyUsage
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type monthlyUsage struct {
+	Month       string  `json:"month"`
+	SecondsUsed float64 `json:"seconds_used"`
+}
+
+// userRateLimiterFromEnv builds a userRateLimiter from STT_USER_RPS,
+// STT_USER_BURST and STT_USER_SECONDS_CAP. A zero RPS or seconds cap
+// disables that half of the check. Persistence is only enabled when
+// saveAudioDir is configured, matching how sidecar JSON is already scoped
+// to that directory.
+func userRateLimiterFromEnv(saveAudioDir string) *userRateLimiter {
+	rps := 0.0
+	if v := os.Getenv("STT_USER_RPS"); v != "" {
+		if n, err := strconv.ParseFloat(v, 64); err == nil && n >= 0 {
+			rps = n
+		}
+	}
+	burst := rps
+	if v := os.Getenv("STT_USER_BURST"); v != "" {
+		if n, err := strconv.ParseFloat(v, 64); err == nil && n >= 0 {
+			burst = n
+		}
+	}
+	secondsCap := 0.0
+	if v := os.Getenv("STT_USER_SECONDS_CAP"); v != "" {
+		if n, err := strconv.ParseFloat(v, 64); err == nil && n >= 0 {
+			secondsCap = n
+		}
+	}
+	quotaDir := ""
+	if saveAudioDir != "" && secondsCap > 0 {
+		quotaDir = filepath.Join(saveAudioDir, "quota")
+	}
+	return &userRateLimiter{
+		rps:        rps,
+		burst:      burst,
+		secondsCap: secondsCap,
+		quotaDir:   quotaDir,
+		buckets:    make(map[string]*tokenBucket),
+		usage:      make(map[string]*monthlyUsage),
+	}
+}
+
+// allow reports whether a new STT request for userID may proceed, and if
+// so records durationMs of audio against userID's monthly usage counter.
+// An empty userID (unmapped SSRC) is always allowed since there's no
+// identity to key a bucket on.
+func (l *userRateLimiter) allow(userID string, durationMs int) bool {
+	if userID == "" {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.rps > 0 {
+		now := time.Now()
+		b, ok := l.buckets[userID]
+		if !ok {
+			b = &tokenBucket{tokens: l.burst, last: now}
+			l.buckets[userID] = b
+		}
+		elapsed := now.Sub(b.last).Seconds()
+		b.tokens = math.Min(l.burst, b.tokens+elapsed*l.rps)
+		b.last = now
+		if b.tokens < 1 {
+			return false
+		}
+		b.tokens--
+	}
+
+	if l.secondsCap > 0 {
+		u := l.loadUsage(userID)
+		if u.SecondsUsed >= l.secondsCap {
+			return false
+		}
+		u.SecondsUsed += float64(durationMs) / 1000
+		l.saveUsage(userID, u)
+	}
+	return true
+}
+
+// reset clears userID's token bucket and monthly usage counter (including
+// its on-disk file, if persisted). It backs the voice.reset_user_quota MCP
+// tool for operators unblocking a user ahead of the next calendar month.
+func (l *userRateLimiter) reset(userID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.buckets, userID)
+	delete(l.usage, userID)
+	if l.quotaDir != "" {
+		_ = os.Remove(l.quotaPath(userID))
+	}
+}
+
+func currentMonth() string { return time.Now().UTC().Format("2006-01") }
+
+func (l *userRateLimiter) quotaPath(userID string) string {
+	return filepath.Join(l.quotaDir, "quota_"+sanitizeForFilename(userID)+".json")
+}
+
+// loadUsage returns userID's usage for the current month, reading the
+// on-disk counter on first access and resetting it if the month rolled over.
+func (l *userRateLimiter) loadUsage(userID string) *monthlyUsage {
+	month := currentMonth()
+	if u, ok := l.usage[userID]; ok && u.Month == month {
+		return u
+	}
+	u := &monthlyUsage{Month: month}
+	if l.quotaDir != "" {
+		if b, err := os.ReadFile(l.quotaPath(userID)); err == nil {
+			var onDisk monthlyUsage
+			if json.Unmarshal(b, &onDisk) == nil && onDisk.Month == month {
+				u.SecondsUsed = onDisk.SecondsUsed
+			}
+		}
+	}
+	l.usage[userID] = u
+	return u
+}
+
+func (l *userRateLimiter) saveUsage(userID string, u *monthlyUsage) {
+	l.usage[userID] = u
+	if l.quotaDir == "" {
+		return
+	}
+	if err := os.MkdirAll(l.quotaDir, 0o755); err != nil {
+		return
+	}
+	b, err := json.MarshalIndent(u, "", "  ")
+	if err != nil {
+		return
+	}
+	path := l.quotaPath(userID)
+	_ = os.WriteFile(path+".tmp", b, 0o644)
+	_ = os.Rename(path+".tmp", path)
+}
+
+// sanitizeForFilename guards against path separators in a user ID landing
+// in a filesystem path; Discord user IDs are plain snowflake digit strings
+// in practice, but callers (e.g. the MCP reset tool) pass arbitrary input.
+func sanitizeForFilename(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == '.' {
+			return '_'
+		}
+		return r
+	}, s)
+}
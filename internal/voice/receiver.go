@@ -0,0 +1,274 @@
+package voice
+
+import (
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/discord-voice-lab/internal/logging"
+	"github.com/hraban/opus"
+)
+
+// PCMSink receives decoded, jitter-corrected PCM frames from a Receiver.
+// Frame is 20ms of 48kHz mono signed 16-bit PCM (matching the sample rate
+// Processor's own opus.Decoder already uses; see NewProcessorWithResolver).
+// Close is called when the speaker has gone quiet long enough that the sink
+// should finalize whatever it was accumulating for ssrc (e.g. close a file).
+type PCMSink interface {
+	WritePCM(ssrc uint32, userID string, frame []int16, at time.Time) error
+	Close(ssrc uint32) error
+}
+
+// ReceiverConfig tunes the per-SSRC jitter buffer's playout timing.
+type ReceiverConfig struct {
+	// TargetDepth is how much audio the drain loop waits to buffer before
+	// it starts playing a stream out, absorbing ordinary network jitter
+	// before it ever reaches decode. Configured via JITTER_TARGET_MS.
+	TargetDepth time.Duration
+	// MaxDepth bounds how far pending packets may accumulate ahead of the
+	// playout point; once a stream's buffered span exceeds this, the
+	// pending packet farthest ahead of the playout point (largest forward
+	// sequence distance from nextSeq) is dropped rather than growing the buffer (and
+	// the added latency) without bound. Configured via JITTER_MAX_MS.
+	MaxDepth time.Duration
+	// PLCEnabled selects libopus packet-loss concealment (decoding with a
+	// nil payload) for a missing sequence number instead of inserting a
+	// silence frame. Configured via PLC_ENABLED.
+	PLCEnabled bool
+}
+
+// DefaultReceiverConfig targets 60ms of reorder tolerance before playout
+// starts, up to 200ms of buffering before packets are dropped, with PLC off
+// (silence insertion is the more conservative default for STT, which cares
+// more about not fabricating phonemes than about natural-sounding gaps).
+func DefaultReceiverConfig() ReceiverConfig {
+	return ReceiverConfig{TargetDepth: 60 * time.Millisecond, MaxDepth: 200 * time.Millisecond, PLCEnabled: false}
+}
+
+// ReceiverConfigFromEnv builds a ReceiverConfig from JITTER_TARGET_MS,
+// JITTER_MAX_MS and PLC_ENABLED, falling back to DefaultReceiverConfig()
+// values for anything unset or invalid.
+func ReceiverConfigFromEnv() ReceiverConfig {
+	cfg := DefaultReceiverConfig()
+	if v := os.Getenv("JITTER_TARGET_MS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.TargetDepth = time.Duration(n) * time.Millisecond
+		}
+	}
+	if v := os.Getenv("JITTER_MAX_MS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.MaxDepth = time.Duration(n) * time.Millisecond
+		}
+	}
+	if v := os.Getenv("PLC_ENABLED"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			cfg.PLCEnabled = b
+		}
+	}
+	return cfg
+}
+
+// drainFrameMs is the strict cadence the drain loop plays frames out at,
+// matching Discord's 20ms Opus framing.
+const drainFrameMs = 20
+
+// ssrcStream holds the per-speaker decode, reorder and playout state.
+type ssrcStream struct {
+	dec      *opus.Decoder
+	pending  map[uint16]*discordgo.Packet
+	nextSeq  uint16
+	haveSeq  bool
+	warmedUp bool
+	stop     chan struct{}
+}
+
+// Receiver demuxes vc.OpusRecv by SSRC, buffers each stream in a per-SSRC
+// jitter buffer keyed by RTP sequence number (reordering packets and
+// tolerating up to TargetDepth of network jitter), decodes Opus to PCM per-
+// SSRC (so one speaker's decode state never corrupts another's), and drains
+// each stream from its own goroutine at a strict 20ms cadence so arrival
+// timing never leaks into processing timing. A missing sequence number at
+// playout time is concealed with libopus PLC (PLC_ENABLED=true) or a
+// silence frame, keeping every sink's timeline gap-free.
+type Receiver struct {
+	cfg        ReceiverConfig
+	userLookup func(ssrc uint32) string
+	sinks      []PCMSink
+	// OnPacketLoss, if set, is called whenever the drain loop plays out a
+	// concealed (PLC or silence) frame for a missing sequence number, so a
+	// caller can record it as a trace span event. skipped is always 1 (one
+	// concealed frame per call) but kept as a count for API continuity with
+	// callers that may batch multiple losses together.
+	OnPacketLoss func(ssrc uint32, skipped int)
+
+	mu      sync.Mutex
+	streams map[uint32]*ssrcStream
+}
+
+// NewReceiver creates a Receiver with cfg (DefaultReceiverConfig() if the
+// zero value is passed) delivering frames to sinks. userLookup resolves a
+// user ID for a given SSRC (e.g. Processor.ListSessions-backed); it may be
+// nil, in which case sinks receive an empty userID.
+func NewReceiver(cfg ReceiverConfig, userLookup func(ssrc uint32) string, sinks ...PCMSink) *Receiver {
+	if cfg.TargetDepth <= 0 && cfg.MaxDepth <= 0 {
+		cfg = DefaultReceiverConfig()
+	}
+	return &Receiver{cfg: cfg, userLookup: userLookup, sinks: sinks, streams: make(map[uint32]*ssrcStream)}
+}
+
+// HandlePacket buffers one packet read from vc.OpusRecv into its SSRC's
+// jitter buffer. Decoding and dispatch happen later, on that SSRC's own
+// drain-loop cadence, not here.
+func (r *Receiver) HandlePacket(pkt *discordgo.Packet) {
+	if pkt == nil {
+		return
+	}
+	r.mu.Lock()
+	s, ok := r.streams[pkt.SSRC]
+	if !ok {
+		dec, err := opus.NewDecoder(48000, 1)
+		if err != nil {
+			r.mu.Unlock()
+			logging.Error("receiver: failed to create opus decoder", "ssrc", pkt.SSRC, "err", err)
+			return
+		}
+		s = &ssrcStream{dec: dec, pending: make(map[uint16]*discordgo.Packet), stop: make(chan struct{})}
+		r.streams[pkt.SSRC] = s
+		go r.drainLoop(pkt.SSRC, s.stop)
+	}
+	if !s.haveSeq {
+		s.nextSeq = pkt.Sequence
+		s.haveSeq = true
+	}
+	s.pending[pkt.Sequence] = pkt
+
+	// Bound how far pending can grow ahead of the playout point: once a
+	// stream holds more than MaxDepth worth of packets, drop the stalest
+	// one - the packet with the largest forward distance from nextSeq,
+	// i.e. farthest from playing out next - rather than letting buffering
+	// - and latency - grow without bound under sustained reordering or
+	// loss. Forward distance (not raw sequence number) is what matters
+	// here so this stays correct across uint16 sequence wraparound.
+	maxPackets := int(r.cfg.MaxDepth / (drainFrameMs * time.Millisecond))
+	if maxPackets > 0 {
+		for len(s.pending) > maxPackets {
+			var stalest uint16
+			first := true
+			for seq := range s.pending {
+				if first || seq-s.nextSeq > stalest-s.nextSeq {
+					stalest = seq
+					first = false
+				}
+			}
+			delete(s.pending, stalest)
+		}
+	}
+	r.mu.Unlock()
+}
+
+// drainLoop plays ssrc's jitter buffer out at a strict 20ms cadence until
+// stop is closed by CloseStream.
+func (r *Receiver) drainLoop(ssrc uint32, stop chan struct{}) {
+	ticker := time.NewTicker(drainFrameMs * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			r.tick(ssrc)
+		}
+	}
+}
+
+// tick plays out exactly one frame for ssrc, if its stream has warmed up to
+// TargetDepth. On a hit it decodes the buffered packet; on a miss it
+// conceals the gap with PLC or silence so the 20ms cadence never stalls.
+func (r *Receiver) tick(ssrc uint32) {
+	r.mu.Lock()
+	s, ok := r.streams[ssrc]
+	if !ok {
+		r.mu.Unlock()
+		return
+	}
+	if !s.warmedUp {
+		bufferedMs := time.Duration(len(s.pending)*drainFrameMs) * time.Millisecond
+		if bufferedMs < r.cfg.TargetDepth {
+			r.mu.Unlock()
+			return
+		}
+		s.warmedUp = true
+	}
+
+	pkt, hit := s.pending[s.nextSeq]
+	if hit {
+		delete(s.pending, s.nextSeq)
+	}
+	s.nextSeq++
+	dec := s.dec
+	r.mu.Unlock()
+
+	var frame []int16
+	if hit {
+		pcm := make([]int16, 48000/drainFrameMs)
+		n, err := dec.Decode(pkt.Opus, pcm)
+		if err != nil {
+			logging.Warn("receiver: opus decode error", "ssrc", ssrc, "err", err)
+			return
+		}
+		frame = pcm[:n]
+		observeOpusPacket(ssrc)
+	} else if r.cfg.PLCEnabled {
+		// A nil payload tells libopus to run its packet-loss concealment,
+		// synthesizing a frame that continues the decoder's internal state
+		// instead of leaving a hard gap.
+		pcm := make([]int16, 48000*drainFrameMs/1000)
+		n, err := dec.Decode(nil, pcm)
+		if err != nil {
+			logging.Warn("receiver: opus PLC decode error", "ssrc", ssrc, "err", err)
+			frame = make([]int16, 48000*drainFrameMs/1000)
+		} else {
+			frame = pcm[:n]
+		}
+		if r.OnPacketLoss != nil {
+			r.OnPacketLoss(ssrc, 1)
+		}
+	} else {
+		frame = make([]int16, 48000*drainFrameMs/1000)
+		if r.OnPacketLoss != nil {
+			r.OnPacketLoss(ssrc, 1)
+		}
+	}
+
+	userID := ""
+	if r.userLookup != nil {
+		userID = r.userLookup(ssrc)
+	}
+	now := time.Now()
+	for _, sink := range r.sinks {
+		if err := sink.WritePCM(ssrc, userID, frame, now); err != nil {
+			logging.Warn("receiver: sink write error", "ssrc", ssrc, "err", err)
+		}
+	}
+}
+
+// CloseStream finalizes ssrc's stream (e.g. on a VoiceSpeakingUpdate
+// speaking=false transition), stopping its drain loop, telling every sink
+// to close whatever it was accumulating, and dropping the decoder/jitter
+// state.
+func (r *Receiver) CloseStream(ssrc uint32) {
+	r.mu.Lock()
+	s, ok := r.streams[ssrc]
+	delete(r.streams, ssrc)
+	r.mu.Unlock()
+	if ok {
+		close(s.stop)
+	}
+	for _, sink := range r.sinks {
+		if err := sink.Close(ssrc); err != nil {
+			logging.Warn("receiver: sink close error", "ssrc", ssrc, "err", err)
+		}
+	}
+}
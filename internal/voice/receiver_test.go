@@ -0,0 +1,42 @@
+package voice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// TestHandlePacketOverflowEvictsStalestPacket verifies that once a stream's
+// jitter buffer exceeds MaxDepth, HandlePacket drops the packet with the
+// largest forward distance from nextSeq (farthest from playing out next),
+// not the one closest to nextSeq - evicting the latter would throw away a
+// frame that's about to be played, forcing an avoidable PLC/silence gap.
+func TestHandlePacketOverflowEvictsStalestPacket(t *testing.T) {
+	r := NewReceiver(ReceiverConfig{TargetDepth: 60 * time.Millisecond, MaxDepth: 3 * drainFrameMs * time.Millisecond}, nil)
+
+	const ssrc = 42
+	// nextSeq locks in as 100 on the first packet; feed three more out of
+	// order so the buffer (cap 3) must evict one to make room.
+	seqs := []uint16{100, 103, 102, 101}
+	for _, seq := range seqs {
+		r.HandlePacket(&discordgo.Packet{SSRC: ssrc, Sequence: seq, Opus: []byte{0xf8, 0xff, 0xfe}})
+	}
+
+	r.mu.Lock()
+	s := r.streams[ssrc]
+	_, keptFarthest := s.pending[103]
+	_, keptNearest := s.pending[100]
+	pendingLen := len(s.pending)
+	r.mu.Unlock()
+
+	if pendingLen != 3 {
+		t.Fatalf("pending len = %d, want 3 (one evicted to respect MaxDepth)", pendingLen)
+	}
+	if keptFarthest {
+		t.Error("seq 103 (farthest from nextSeq) was kept; want the stalest packet evicted")
+	}
+	if !keptNearest {
+		t.Error("seq 100 (closest to nextSeq, about to play) was evicted; want it kept")
+	}
+}
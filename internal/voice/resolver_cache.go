@@ -0,0 +1,142 @@
+package voice
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// resolverCacheEntry is one cached lookup result, positive or negative, in
+// the shape persisted to resolver_cache.json.
+type resolverCacheEntry struct {
+	Val      string    `json:"val"`
+	Expiry   time.Time `json:"expiry"`
+	Negative bool      `json:"negative,omitempty"`
+}
+
+// resolverLRU is a bounded, size-capped LRU cache of string lookups for one
+// discordResolver "kind" (user/guild/channel), replacing the unbounded
+// per-kind maps discordResolver used to keep directly. Eviction order is
+// tracked with container/list.
+type resolverLRU struct {
+	mu     sync.Mutex
+	kind   string
+	max    int
+	posTTL time.Duration
+	negTTL time.Duration
+	ll     *list.List
+	items  map[string]*list.Element
+}
+
+type resolverLRUElem struct {
+	key   string
+	entry resolverCacheEntry
+}
+
+func newResolverLRU(kind string, max int, posTTL, negTTL time.Duration) *resolverLRU {
+	return &resolverLRU{
+		kind:   kind,
+		max:    max,
+		posTTL: posTTL,
+		negTTL: negTTL,
+		ll:     list.New(),
+		items:  make(map[string]*list.Element),
+	}
+}
+
+// get returns the cached value for key if present and unexpired, plus
+// whether it's a negative (known-miss) entry. Expired entries are evicted
+// lazily on lookup, same as discordResolver.lookupCache did before this.
+func (c *resolverLRU) get(key string) (val string, negative bool, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, found := c.items[key]
+	if !found {
+		resolverCacheMissesTotal.WithLabelValues(c.kind).Inc()
+		return "", false, false
+	}
+	e := el.Value.(*resolverLRUElem).entry
+	if time.Now().After(e.Expiry) {
+		c.ll.Remove(el)
+		delete(c.items, key)
+		resolverCacheMissesTotal.WithLabelValues(c.kind).Inc()
+		return "", false, false
+	}
+	c.ll.MoveToFront(el)
+	resolverCacheHitsTotal.WithLabelValues(c.kind).Inc()
+	return e.Val, e.Negative, true
+}
+
+// set inserts or overwrites key with val (negative marks a cached miss, e.g.
+// a 404, using negTTL instead of posTTL), evicting the least-recently-used
+// entry if this push would exceed max.
+func (c *resolverLRU) set(key, val string, negative bool) {
+	ttl := c.posTTL
+	if negative {
+		ttl = c.negTTL
+	}
+	entry := resolverCacheEntry{Val: val, Expiry: time.Now().Add(ttl), Negative: negative}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value.(*resolverLRUElem).entry = entry
+		c.ll.MoveToFront(el)
+		return
+	}
+	el := c.ll.PushFront(&resolverLRUElem{key: key, entry: entry})
+	c.items[key] = el
+	if c.max > 0 && c.ll.Len() > c.max {
+		if oldest := c.ll.Back(); oldest != nil {
+			c.ll.Remove(oldest)
+			delete(c.items, oldest.Value.(*resolverLRUElem).key)
+			resolverCacheEvictionsTotal.WithLabelValues(c.kind).Inc()
+		}
+	}
+}
+
+// invalidate removes key outright, used when a GuildUpdate/ChannelUpdate/
+// UserUpdate event tells us it's stale before its TTL would have expired it.
+func (c *resolverLRU) invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.ll.Remove(el)
+		delete(c.items, key)
+	}
+}
+
+// snapshot returns all unexpired entries, for persisting to disk.
+func (c *resolverLRU) snapshot() map[string]resolverCacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	out := make(map[string]resolverCacheEntry, len(c.items))
+	for key, el := range c.items {
+		e := el.Value.(*resolverLRUElem).entry
+		if now.After(e.Expiry) {
+			continue
+		}
+		out[key] = e
+	}
+	return out
+}
+
+// restore seeds the cache from a loaded snapshot, skipping already-expired
+// entries and stopping once max is reached if the snapshot was made with a
+// larger limit. Order among restored entries doesn't matter: none of them
+// have been "used" yet in this process.
+func (c *resolverLRU) restore(entries map[string]resolverCacheEntry) {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, e := range entries {
+		if now.After(e.Expiry) {
+			continue
+		}
+		if c.max > 0 && c.ll.Len() >= c.max {
+			break
+		}
+		el := c.ll.PushFront(&resolverLRUElem{key: key, entry: e})
+		c.items[key] = el
+	}
+}
@@ -13,60 +13,84 @@ import (
 )
 
 // StartSaveAudioCleaner starts a background goroutine that periodically
-// scans dir for sidecar JSON files and their paired wavs, removing entries
-// older than retention and enforcing maxFiles. Caller must call wg.Add(1)
-// before calling this function; the goroutine will call wg.Done() on exit.
-func StartSaveAudioCleaner(ctx context.Context, wg *sync.WaitGroup, dir string, retention time.Duration, interval time.Duration, maxFiles int) {
+// removes sidecar JSON files and their paired wavs older than retention, and
+// enforces maxFiles. Caller must call wg.Add(1) before calling this
+// function; the goroutine will call wg.Done() on exit.
+//
+// idx, if non-nil, is consulted via RangeByModTime instead of re-reading and
+// re-parsing every sidecar JSON in dir each tick; its ModUnix is maintained
+// by flushAccum (see processor.go) and Reconcile, so it stays current
+// without this cleaner having to stat anything itself. idx == nil falls
+// back to the original directory scan, for a caller that only has a bare
+// dir and no shared index (e.g. one running against a legacy saveAudioDir
+// with no sidecarIndex wired up).
+func StartSaveAudioCleaner(ctx context.Context, wg *sync.WaitGroup, dir string, retention time.Duration, interval time.Duration, maxFiles int, idx *sidecarIndex) {
 	go func() {
 		defer wg.Done()
 		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
+		writer := NewAtomicWriter()
 		for {
 			select {
 			case <-ctx.Done():
 				return
 			case <-ticker.C:
-				files, err := os.ReadDir(dir)
-				if err != nil {
-					logging.Debugw("saveaudio: cleanup readDir failed", "err", err)
-					continue
+				if quarantined, err := writer.VerifyAll(dir); err != nil {
+					logging.Debug("saveaudio: manifest verify failed", "dir", dir, "err", err)
+				} else if len(quarantined) > 0 {
+					logging.Warn("saveaudio: quarantined corrupt files before cleanup", "dir", dir, "count", len(quarantined))
 				}
 				type pairInfo struct {
 					jsonPath string
 					wavPath  string
 					mod      time.Time
 				}
-				pairs := make(map[string]*pairInfo)
-				for _, fi := range files {
-					name := fi.Name()
-					if !strings.HasSuffix(name, ".json") {
-						continue
+				var pairList []pairInfo
+				if idx != nil {
+					for _, entry := range idx.RangeByModTime(time.Now()) {
+						pairList = append(pairList, pairInfo{
+							jsonPath: entry.Path,
+							wavPath:  strings.TrimSuffix(entry.Path, ".json") + ".wav",
+							mod:      time.Unix(entry.ModUnix, 0),
+						})
 					}
-					jsonPath := dir + "/" + name
-					b, err := os.ReadFile(jsonPath)
+				} else {
+					files, err := os.ReadDir(dir)
 					if err != nil {
+						logging.Debug("saveaudio: cleanup readDir failed", "err", err)
 						continue
 					}
-					var sc map[string]interface{}
-					if err := json.Unmarshal(b, &sc); err != nil {
-						continue
-					}
-					wavPath := ""
-					if v, ok := sc["wav_path"].(string); ok && v != "" {
-						wavPath = v
-					} else {
-						wavPath = strings.TrimSuffix(jsonPath, ".json") + ".wav"
+					pairs := make(map[string]*pairInfo)
+					for _, fi := range files {
+						name := fi.Name()
+						if !strings.HasSuffix(name, ".json") {
+							continue
+						}
+						jsonPath := dir + "/" + name
+						b, err := os.ReadFile(jsonPath)
+						if err != nil {
+							continue
+						}
+						var sc map[string]interface{}
+						if err := json.Unmarshal(b, &sc); err != nil {
+							continue
+						}
+						wavPath := ""
+						if v, ok := sc["wav_path"].(string); ok && v != "" {
+							wavPath = v
+						} else {
+							wavPath = strings.TrimSuffix(jsonPath, ".json") + ".wav"
+						}
+						st, err := os.Stat(jsonPath)
+						if err != nil {
+							continue
+						}
+						base := strings.TrimSuffix(name, ".json")
+						pairs[base] = &pairInfo{jsonPath: jsonPath, wavPath: wavPath, mod: st.ModTime()}
 					}
-					st, err := os.Stat(jsonPath)
-					if err != nil {
-						continue
+					for _, p := range pairs {
+						pairList = append(pairList, *p)
 					}
-					base := strings.TrimSuffix(name, ".json")
-					pairs[base] = &pairInfo{jsonPath: jsonPath, wavPath: wavPath, mod: st.ModTime()}
-				}
-				var pairList []pairInfo
-				for _, p := range pairs {
-					pairList = append(pairList, *p)
 				}
 				sort.Slice(pairList, func(i, j int) bool { return pairList[i].mod.Before(pairList[j].mod) })
 				cutoff := time.Now().Add(-retention)
@@ -0,0 +1,487 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/discord-voice-lab/internal/logging"
+	"github.com/discord-voice-lab/internal/orchestrator"
+	"github.com/discord-voice-lab/internal/voice/vad"
+	"go.opentelemetry.io/otel/attribute"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// GuildSession owns one guild's voice connection and the Processor decoding
+// audio from it. Each session has its own SSRC<->user map, RTP receive
+// goroutine, and speaking-update handler registration, so multiple guilds
+// never share accumulator/transcript state.
+type GuildSession struct {
+	GuildID   string
+	Processor *Processor
+	// Receiver, when RECEIVER_ENABLED=true, replaces Processor's immediate,
+	// arrival-order decode (ProcessOpusFrame) with a per-SSRC jitter buffer
+	// that reorders packets and drains each stream at a strict 20ms
+	// cadence, so reordered/late RTP no longer reads to Processor's
+	// silence/flush logic as dropped audio. A ProcessorAccumSink still
+	// feeds the jitter-corrected PCM into the same appendAccum pipeline.
+	Receiver *Receiver
+
+	mgr *SessionManager
+
+	supervisor *ConnectionSupervisor
+
+	mu        sync.Mutex
+	channelID string
+	vc        *discordgo.VoiceConnection
+	// staleAfter, when non-zero, marks a time after which supervise should
+	// attempt a reconnect; set by a VoiceServerUpdate observed for this
+	// guild (usually a region migration) so the RTP goroutine doesn't have
+	// to detect the drop itself.
+	staleAfter time.Time
+	closed     bool
+	// moving is true while move() is disconnecting the old VoiceConnection
+	// and dialing the new one, so receiveLoop's own OpusRecv-closed signal
+	// doesn't race move()'s synchronous reconnect with a second one from
+	// ConnectionSupervisor.
+	moving bool
+
+	// span is this session's root trace span, covering the guild session's
+	// whole lifetime; speakingSpans holds one in-progress child span per
+	// SSRC currently speaking. Both are nil until the first connect().
+	span          oteltrace.Span
+	spanCtx       context.Context
+	speakingSpans map[uint32]*speakingSpan
+
+	cancel context.CancelFunc
+}
+
+// speakingSpan tracks one user's in-progress "voice.speaking" child span,
+// started on VoiceSpeakingUpdate{Speaking: true} and ended (with a
+// duration_ms attribute) on the matching Speaking: false.
+type speakingSpan struct {
+	span  oteltrace.Span
+	start time.Time
+}
+
+// SessionManager owns one GuildSession per guild the bot has an active (or
+// reconnecting) voice connection in. It replaces main()'s single
+// package-level *discordgo.VoiceConnection with a map so the bot can join
+// more than one guild/channel at a time.
+type SessionManager struct {
+	dg       *discordgo.Session
+	resolver NameResolver
+	router   *orchestrator.EventRouter
+
+	mu       sync.Mutex
+	sessions map[string]*GuildSession
+}
+
+// NewSessionManager returns a SessionManager bound to dg. resolver (may be
+// nil) is passed through to each guild's Processor for human-friendly names.
+// router (may be nil) receives SpeakingStart/SpeakingStop/VoiceStateUpdate
+// events for each guild session; passing nil disables publishing.
+func NewSessionManager(dg *discordgo.Session, resolver NameResolver, router *orchestrator.EventRouter) *SessionManager {
+	return &SessionManager{dg: dg, resolver: resolver, router: router, sessions: make(map[string]*GuildSession)}
+}
+
+// SessionSummary is a read-only snapshot of one guild session, returned by
+// List().
+type SessionSummary struct {
+	GuildID   string
+	ChannelID string
+	Connected bool
+}
+
+// Join connects to channelID in guildID. If guildID already has a session,
+// it is moved to channelID instead of creating a second connection. ctx is
+// passed through to NewProcessorWithResolver as the parent of the new
+// session's Processor, so it must outlive the session - callers should pass
+// a long-lived context (context.Background() today), not a request-scoped
+// one that gets cancelled once Join returns, or the Processor's background
+// goroutines (dispatchers, ACL reload, transcript sink retries) will be torn
+// down out from under a session that otherwise looks alive. The supervisor
+// goroutine's own lifetime is unaffected either way: it runs off a separate
+// context.WithCancel tied to gs.cancel (set on Leave/Shutdown), not ctx.
+func (m *SessionManager) Join(ctx context.Context, guildID, channelID string) (*GuildSession, error) {
+	m.mu.Lock()
+	existing := m.sessions[guildID]
+	m.mu.Unlock()
+	if existing != nil {
+		return existing, existing.move(channelID)
+	}
+
+	proc, err := NewProcessorWithResolver(ctx, m.resolver)
+	if err != nil {
+		return nil, fmt.Errorf("create processor for guild %s: %w", guildID, err)
+	}
+	gs := &GuildSession{GuildID: guildID, Processor: proc, mgr: m, channelID: channelID}
+	proc.SetChannelID(channelID)
+	if m.router != nil {
+		proc.SetEventRouter(m.router, guildID)
+	}
+	if os.Getenv("RECEIVER_ENABLED") == "true" {
+		gs.Receiver = newReceiverFromEnv(gs)
+	}
+	// Assign the supervisor before connect() starts receiveLoop, so the
+	// very first packets it hands off always see a non-nil gs.supervisor
+	// instead of racing the assignment below.
+	gs.supervisor = NewConnectionSupervisor(gs)
+	if err := gs.connect(); err != nil {
+		_ = proc.Close()
+		return nil, err
+	}
+
+	supervisorCtx, cancel := context.WithCancel(context.Background())
+	gs.cancel = cancel
+
+	m.mu.Lock()
+	m.sessions[guildID] = gs
+	m.mu.Unlock()
+
+	go gs.supervisor.Run(supervisorCtx)
+	return gs, nil
+}
+
+// Leave disconnects and tears down guildID's session, if any.
+func (m *SessionManager) Leave(guildID string) error {
+	m.mu.Lock()
+	gs := m.sessions[guildID]
+	delete(m.sessions, guildID)
+	m.mu.Unlock()
+	if gs == nil {
+		return nil
+	}
+	return gs.shutdown()
+}
+
+// Move relocates guildID's existing session to newChannelID, joining fresh
+// if no session exists yet.
+func (m *SessionManager) Move(ctx context.Context, guildID, newChannelID string) error {
+	m.mu.Lock()
+	gs := m.sessions[guildID]
+	m.mu.Unlock()
+	if gs == nil {
+		_, err := m.Join(ctx, guildID, newChannelID)
+		return err
+	}
+	return gs.move(newChannelID)
+}
+
+// List returns a snapshot of all known sessions.
+func (m *SessionManager) List() []SessionSummary {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]SessionSummary, 0, len(m.sessions))
+	for _, gs := range m.sessions {
+		gs.mu.Lock()
+		out = append(out, SessionSummary{GuildID: gs.GuildID, ChannelID: gs.channelID, Connected: gs.vc != nil})
+		gs.mu.Unlock()
+	}
+	return out
+}
+
+// AnnotateEvent adds a span event named name (with attrs) to guildID's
+// session span, if one exists. It's the non-logging alternative to
+// sugar.Infow for gateway events a Sampler decided not to log in full:
+// the event is still visible in the session's trace instead of vanishing
+// entirely.
+func (m *SessionManager) AnnotateEvent(guildID, name string, attrs ...attribute.KeyValue) {
+	m.mu.Lock()
+	gs := m.sessions[guildID]
+	m.mu.Unlock()
+	if gs == nil {
+		return
+	}
+	gs.mu.Lock()
+	span := gs.span
+	gs.mu.Unlock()
+	if span != nil {
+		span.AddEvent(name, oteltrace.WithAttributes(attrs...))
+	}
+}
+
+// HandleVoiceStateUpdate routes a gateway VoiceStateUpdate to the matching
+// guild session's Processor, if one exists. Register this once at the
+// discordgo.Session level; it replaces calling vp.HandleVoiceState directly
+// against a single global Processor.
+func (m *SessionManager) HandleVoiceStateUpdate(s *discordgo.Session, vs *discordgo.VoiceStateUpdate) {
+	m.mu.Lock()
+	gs := m.sessions[vs.GuildID]
+	m.mu.Unlock()
+	if gs != nil {
+		gs.Processor.HandleVoiceState(s, vs)
+	}
+	if m.router != nil {
+		m.router.Publish(orchestrator.Event{
+			Type:      orchestrator.EventVoiceStateUpdate,
+			GuildID:   vs.GuildID,
+			ChannelID: vs.ChannelID,
+			UserID:    vs.UserID,
+			At:        time.Now(),
+		})
+	}
+}
+
+// HandleVoiceServerUpdate marks guildID's session stale so supervise()
+// attempts a reconnect shortly, covering region migrations where Discord
+// sends a fresh endpoint/token for an existing session.
+func (m *SessionManager) HandleVoiceServerUpdate(_ *discordgo.Session, vsu *discordgo.VoiceServerUpdate) {
+	m.mu.Lock()
+	gs := m.sessions[vsu.GuildID]
+	m.mu.Unlock()
+	if gs == nil {
+		return
+	}
+	gs.mu.Lock()
+	gs.staleAfter = time.Now().Add(5 * time.Second)
+	gs.mu.Unlock()
+	logging.Info("voice server update observed, scheduling reconnect check", "guild_id", vsu.GuildID)
+}
+
+// Shutdown gracefully drains every session (disconnecting its voice
+// connection and closing its Processor), replacing the single-shot
+// vc.Disconnect() main() used to call on SIGTERM.
+func (m *SessionManager) Shutdown() {
+	m.mu.Lock()
+	sessions := make([]*GuildSession, 0, len(m.sessions))
+	for _, gs := range m.sessions {
+		sessions = append(sessions, gs)
+	}
+	m.sessions = make(map[string]*GuildSession)
+	m.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, gs := range sessions {
+		wg.Add(1)
+		go func(gs *GuildSession) {
+			defer wg.Done()
+			if err := gs.shutdown(); err != nil {
+				logging.Warn("error shutting down guild session", "guild_id", gs.GuildID, "err", err)
+			}
+		}(gs)
+	}
+	wg.Wait()
+}
+
+// connect joins gs.channelID in gs.GuildID and wires the speaking-update
+// handler and RTP receive goroutine against the new VoiceConnection.
+func (gs *GuildSession) connect() error {
+	vconn, err := gs.mgr.dg.ChannelVoiceJoin(gs.GuildID, gs.channelID, false, false)
+	if err != nil {
+		return fmt.Errorf("join voice channel guild=%s channel=%s: %w", gs.GuildID, gs.channelID, err)
+	}
+
+	gs.mu.Lock()
+	if gs.span == nil {
+		gs.spanCtx, gs.span = tracer.Start(context.Background(), "voice.session",
+			oteltrace.WithAttributes(attribute.String("guild_id", gs.GuildID)))
+	} else {
+		gs.span.AddEvent("reconnect", oteltrace.WithAttributes(attribute.String("channel_id", gs.channelID)))
+	}
+	gs.mu.Unlock()
+
+	vconn.AddHandler(func(v *discordgo.VoiceConnection, su *discordgo.VoiceSpeakingUpdate) {
+		gs.Processor.HandleSpeakingUpdate(gs.mgr.dg, su)
+		if gs.Receiver != nil && !su.Speaking {
+			gs.Receiver.CloseStream(uint32(su.SSRC))
+		}
+		gs.trackSpeakingSpan(uint32(su.SSRC), su.UserID, su.Speaking)
+		if gs.mgr.router != nil {
+			evtType := orchestrator.EventSpeakingStop
+			if su.Speaking {
+				evtType = orchestrator.EventSpeakingStart
+			}
+			gs.mgr.router.Publish(orchestrator.Event{
+				Type:    evtType,
+				GuildID: gs.GuildID,
+				UserID:  su.UserID,
+				SSRC:    uint32(su.SSRC),
+				At:      time.Now(),
+			})
+		}
+	})
+
+	gs.mu.Lock()
+	gs.vc = vconn
+	gs.mu.Unlock()
+	gs.Processor.SetVoiceConnection(vconn)
+
+	go gs.receiveLoop(vconn)
+	logging.Info("guild session connected", "guild_id", gs.GuildID, "channel_id", gs.channelID)
+	return nil
+}
+
+// trackSpeakingSpan starts (speaking=true) or ends (speaking=false) ssrc's
+// "voice.speaking" child span of the session's root span, tagging it with
+// ssrc/user_id/guild_id and, on end, a duration_ms attribute.
+func (gs *GuildSession) trackSpeakingSpan(ssrc uint32, userID string, speaking bool) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	if gs.speakingSpans == nil {
+		gs.speakingSpans = make(map[uint32]*speakingSpan)
+	}
+	if speaking {
+		if gs.spanCtx == nil {
+			return
+		}
+		_, span := tracer.Start(gs.spanCtx, "voice.speaking", oteltrace.WithAttributes(
+			attribute.Int64("ssrc", int64(ssrc)),
+			attribute.String("user_id", userID),
+			attribute.String("guild_id", gs.GuildID),
+		))
+		gs.speakingSpans[ssrc] = &speakingSpan{span: span, start: time.Now()}
+		return
+	}
+	ss, ok := gs.speakingSpans[ssrc]
+	if !ok {
+		return
+	}
+	delete(gs.speakingSpans, ssrc)
+	ss.span.SetAttributes(attribute.Int64("duration_ms", time.Since(ss.start).Milliseconds()))
+	ss.span.End()
+}
+
+// receiveLoop drains vc.OpusRecv into the session's Processor until the
+// channel closes (on disconnect) or a newer connection replaces vc. If
+// OpusRecv closes on its own - the real signal the voice websocket died,
+// whether from a gateway disconnect, a network blip, or a zombied
+// session - and nothing else (move/shutdown) already replaced or closed
+// this session, it wakes the ConnectionSupervisor immediately instead of
+// leaving the session silent until the next zombie-timeout check.
+func (gs *GuildSession) receiveLoop(vc *discordgo.VoiceConnection) {
+	for pkt := range vc.OpusRecv {
+		gs.mu.Lock()
+		current := gs.vc
+		gs.mu.Unlock()
+		if current != vc {
+			// A reconnect/move already swapped in a newer connection;
+			// stop draining this stale one.
+			return
+		}
+		if gs.supervisor != nil {
+			gs.supervisor.recordTraffic()
+		}
+		if gs.Receiver != nil {
+			// The jitter buffer owns decode timing once enabled: it feeds
+			// appendAccum itself via ProcessorAccumSink, so calling
+			// ProcessOpusFrame here too would double-accumulate every frame.
+			gs.Receiver.HandlePacket(pkt)
+		} else {
+			gs.Processor.ProcessOpusFrame(pkt.SSRC, pkt.Opus)
+		}
+	}
+
+	gs.mu.Lock()
+	stillCurrent := gs.vc == vc && !gs.closed && !gs.moving
+	gs.mu.Unlock()
+	if stillCurrent && gs.supervisor != nil {
+		gs.supervisor.signalDisconnected()
+	}
+}
+
+// newReceiverFromEnv builds a Receiver wired to gs.Processor's SSRC->user
+// mapping. ProcessorAccumSink feeds the jitter-corrected PCM into the same
+// appendAccum pipeline ProcessOpusFrame used to drive directly; a WAV file
+// sink (RECEIVER_SAVE_DIR, if set), a VAD-gated in-memory buffer sink, and
+// an in-memory channel fanout sink for tests/consumers round out the
+// default sink set. Packet loss the jitter buffer conceals is recorded as a
+// span event on gs's session span.
+func newReceiverFromEnv(gs *GuildSession) *Receiver {
+	proc := gs.Processor
+	sinks := []PCMSink{NewProcessorAccumSink(proc)}
+	if dir := os.Getenv("RECEIVER_SAVE_DIR"); dir != "" {
+		sinks = append(sinks, NewWAVFileSink(dir))
+	}
+	backend, cfg := vad.FromEnv(0)
+	sinks = append(sinks, NewVADGatedBufferSink(backend, cfg))
+	sinks = append(sinks, NewChannelFanoutSink(64))
+
+	userLookup := func(ssrc uint32) string {
+		for _, s := range proc.ListSessions() {
+			if s.SSRC == ssrc {
+				return s.UserID
+			}
+		}
+		return ""
+	}
+	r := NewReceiver(ReceiverConfigFromEnv(), userLookup, sinks...)
+	r.OnPacketLoss = func(ssrc uint32, skipped int) {
+		gs.mu.Lock()
+		span := gs.span
+		gs.mu.Unlock()
+		if span != nil {
+			span.AddEvent("rtp.loss", oteltrace.WithAttributes(
+				attribute.Int64("ssrc", int64(ssrc)),
+				attribute.Int("skipped_sequences", skipped),
+			))
+		}
+	}
+	return r
+}
+
+// move relocates the session to a new channel within the same guild.
+func (gs *GuildSession) move(newChannelID string) error {
+	gs.mu.Lock()
+	oldVC := gs.vc
+	gs.channelID = newChannelID
+	gs.moving = true
+	gs.mu.Unlock()
+	defer func() {
+		gs.mu.Lock()
+		gs.moving = false
+		gs.mu.Unlock()
+	}()
+	if gs.Processor != nil {
+		gs.Processor.SetChannelID(newChannelID)
+	}
+	if oldVC != nil {
+		_ = oldVC.Disconnect()
+	}
+	if err := gs.connect(); err != nil {
+		return err
+	}
+	if gs.supervisor != nil {
+		// A quiet channel before the move shouldn't count against the new
+		// connection's zombie timer the moment it lands in the new channel.
+		gs.supervisor.recordTraffic()
+	}
+	return nil
+}
+
+// shutdown disconnects the voice connection and closes the Processor.
+func (gs *GuildSession) shutdown() error {
+	gs.mu.Lock()
+	if gs.closed {
+		gs.mu.Unlock()
+		return nil
+	}
+	gs.closed = true
+	vc := gs.vc
+	cancel := gs.cancel
+	span := gs.span
+	for ssrc, ss := range gs.speakingSpans {
+		ss.span.SetAttributes(attribute.Int64("duration_ms", time.Since(ss.start).Milliseconds()))
+		ss.span.End()
+		delete(gs.speakingSpans, ssrc)
+	}
+	gs.mu.Unlock()
+
+	if span != nil {
+		span.End()
+	}
+	if cancel != nil {
+		cancel()
+	}
+	var err error
+	if vc != nil {
+		err = vc.Disconnect()
+	}
+	if cerr := gs.Processor.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -6,14 +6,19 @@ import (
 	"os"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/discord-voice-lab/internal/logging"
 )
 
 // SidecarManager centralizes finding and updating JSON sidecar files stored
-// in a configured directory. If Dir is empty the manager is a no-op.
+// in a configured directory. If Dir is empty the manager is a no-op. idx, if
+// set via NewSidecarManagerWithIndex, gives FindByCID an O(1) path before it
+// falls back to the directory scan below, and lets MergeUpdatesForCID keep
+// the shared sidecarIndex's ModUnix current after it rewrites the file.
 type SidecarManager struct {
 	Dir string
+	idx *sidecarIndex
 }
 
 func NewSidecarManager(dir string) *SidecarManager {
@@ -23,15 +28,33 @@ func NewSidecarManager(dir string) *SidecarManager {
 	return &SidecarManager{Dir: dir}
 }
 
+// NewSidecarManagerWithIndex is NewSidecarManager plus a shared sidecarIndex,
+// so FindByCID/MergeUpdatesForCID consult and maintain the same O(1) index
+// Processor uses via findSidecarPathForCID, instead of each keeping its own
+// view of the directory.
+func NewSidecarManagerWithIndex(dir string, idx *sidecarIndex) *SidecarManager {
+	s := NewSidecarManager(dir)
+	if s != nil {
+		s.idx = idx
+	}
+	return s
+}
+
 // FindByCID returns the full path to the sidecar JSON matching correlation id
-// or an empty string if not found.
+// or an empty string if not found. Checks idx first when set, falling back
+// to the directory scan only on an index miss (e.g. idx not yet reconciled).
 func (s *SidecarManager) FindByCID(cid string) string {
 	if s == nil || s.Dir == "" || cid == "" {
 		return ""
 	}
+	if s.idx != nil {
+		if path := s.idx.Get(cid); path != "" {
+			return path
+		}
+	}
 	files, derr := os.ReadDir(s.Dir)
 	if derr != nil {
-		logging.Warnw("sidecar: failed to list dir", "dir", s.Dir, "err", derr)
+		logging.Warn("sidecar: failed to list dir", "dir", s.Dir, "err", derr)
 		return ""
 	}
 	for _, fi := range files {
@@ -49,7 +72,7 @@ func (s *SidecarManager) FindByCID(cid string) string {
 			}
 		} else {
 			// surface read errors at debug so operators can inspect problematic files
-			logging.Debugw("sidecar: failed to read file while searching by cid", "path", path, "err", err, "correlation_id", cid)
+			logging.Debug("sidecar: failed to read file while searching by cid", "path", path, "err", err, "correlation_id", cid)
 		}
 	}
 	// fallback: filename contains cid
@@ -80,13 +103,13 @@ func (s *SidecarManager) MergeUpdatesForCID(cid string, updates map[string]inter
 		lf := path + ".lock"
 		f, ferr := os.OpenFile(lf, os.O_CREATE|os.O_RDWR, 0o644)
 		if ferr != nil {
-			logging.Warnw("sidecar: failed to open lock file", "lock", lf, "err", ferr, "correlation_id", cid)
+			logging.Warn("sidecar: failed to open lock file", "lock", lf, "err", ferr, "correlation_id", cid)
 			return fmt.Errorf("failed to open lock file %s: %w", lf, ferr)
 		}
 		lockFile = f
 		if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
 			_ = f.Close()
-			logging.Warnw("sidecar: failed to flock lock file", "lock", lf, "err", err, "correlation_id", cid)
+			logging.Warn("sidecar: failed to flock lock file", "lock", lf, "err", err, "correlation_id", cid)
 			return fmt.Errorf("failed to lock file %s: %w", lf, err)
 		}
 		// We hold the lock until we explicitly unlock/close below
@@ -99,12 +122,12 @@ func (s *SidecarManager) MergeUpdatesForCID(cid string, updates map[string]inter
 			_ = syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN)
 			_ = lockFile.Close()
 		}
-		logging.Warnw("sidecar: failed to read sidecar for cid", "path", path, "err", rerr, "correlation_id", cid)
+		logging.Warn("sidecar: failed to read sidecar for cid", "path", path, "err", rerr, "correlation_id", cid)
 		return fmt.Errorf("failed to read sidecar %s: %w", path, rerr)
 	}
 	var sc map[string]interface{}
 	if uerr := json.Unmarshal(sb, &sc); uerr != nil {
-		logging.Warnw("sidecar: failed to unmarshal sidecar JSON", "path", path, "err", uerr, "correlation_id", cid)
+		logging.Warn("sidecar: failed to unmarshal sidecar JSON", "path", path, "err", uerr, "correlation_id", cid)
 		return fmt.Errorf("invalid sidecar JSON %s: %w", path, uerr)
 	}
 	for k, v := range updates {
@@ -116,59 +139,64 @@ func (s *SidecarManager) MergeUpdatesForCID(cid string, updates map[string]inter
 			_ = syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN)
 			_ = lockFile.Close()
 		}
-		logging.Warnw("sidecar: failed to marshal updated JSON", "path", path, "err", merr, "correlation_id", cid)
+		logging.Warn("sidecar: failed to marshal updated JSON", "path", path, "err", merr, "correlation_id", cid)
 		return fmt.Errorf("failed to marshal updated sidecar JSON for %s: %w", path, merr)
 	}
-	tmpPath := path + ".tmp"
-	f, ferr := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
-	if ferr != nil {
+	// Write through AtomicWriter rather than the hand-rolled tmp+fsync+rename
+	// this method used to do inline, so the sidecar JSON also gets a
+	// directory fsync and a MANIFEST entry like every other durable write in
+	// this package, enabling an offline fsck over the save-audio directory.
+	if werr := defaultAtomicWriter.Write(path, nb, 0o644); werr != nil {
 		if lockFile != nil {
 			_ = syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN)
 			_ = lockFile.Close()
 		}
-		logging.Warnw("sidecar: failed to create tmp file", "tmp", tmpPath, "err", ferr, "correlation_id", cid)
-		return fmt.Errorf("failed to create temp file %s: %w", tmpPath, ferr)
-	}
-	// write and fsync to ensure data hits disk before rename
-	if _, werr := f.Write(nb); werr != nil {
-		_ = f.Close()
-		logging.Warnw("sidecar: failed to write tmp file", "tmp", tmpPath, "err", werr, "correlation_id", cid)
-		_ = os.Remove(tmpPath)
-		return fmt.Errorf("failed to write temp file %s: %w", tmpPath, werr)
-	}
-	if serr := f.Sync(); serr != nil {
-		_ = f.Close()
-		if lockFile != nil {
-			_ = syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN)
-			_ = lockFile.Close()
-		}
-		logging.Warnw("sidecar: fsync failed for tmp file", "tmp", tmpPath, "err", serr, "correlation_id", cid)
-		_ = os.Remove(tmpPath)
-		return fmt.Errorf("fsync failed for temp file %s: %w", tmpPath, serr)
+		logging.Warn("sidecar: atomic write failed", "path", path, "err", werr, "correlation_id", cid)
+		return fmt.Errorf("failed to write sidecar %s: %w", path, werr)
 	}
-	if cerr := f.Close(); cerr != nil {
-		if lockFile != nil {
-			_ = syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN)
-			_ = lockFile.Close()
-		}
-		logging.Warnw("sidecar: failed to close tmp file", "tmp", tmpPath, "err", cerr, "correlation_id", cid)
-		_ = os.Remove(tmpPath)
-		return fmt.Errorf("failed to close temp file %s: %w", tmpPath, cerr)
-	}
-	if err := os.Rename(tmpPath, path); err != nil {
-		if lockFile != nil {
-			_ = syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN)
-			_ = lockFile.Close()
-		}
-		logging.Warnw("sidecar: failed to rename tmp file", "tmp", tmpPath, "final", path, "err", err, "correlation_id", cid)
-		_ = os.Remove(tmpPath)
-		return fmt.Errorf("failed to rename temp file %s -> %s: %w", tmpPath, path, err)
-	}
-	// release lock after successful rename
+	// release lock after successful write
 	if lockFile != nil {
 		_ = syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN)
 		_ = lockFile.Close()
 	}
-	logging.Infow("sidecar: saved updates", "path", path, "correlation_id", cid)
+	if s.idx != nil {
+		s.refreshIndexEntry(cid, path, sc)
+	}
+	logging.Info("sidecar: saved updates", "path", path, "correlation_id", cid)
 	return nil
 }
+
+// refreshIndexEntry re-derives an sidecarIndexEntry from sc (the merged
+// sidecar contents MergeUpdatesForCID just wrote to path) and Puts it into
+// s.idx, so the shared index's ModUnix/duration/etc. don't go stale the
+// moment MergeUpdatesForCID rewrites a file out from under it. The file is
+// written first and the index row second, not in one transaction: Put
+// commits the row in its own bbolt transaction, which does not cover the
+// tmp+rename file write, so the order is "file, then index".
+func (s *SidecarManager) refreshIndexEntry(cid, path string, sc map[string]interface{}) {
+	st, err := os.Stat(path)
+	modUnix := time.Now().Unix()
+	if err == nil {
+		modUnix = st.ModTime().Unix()
+	}
+	entry := sidecarIndexEntry{Path: path, IndexedAtUnix: time.Now().Unix(), ModUnix: modUnix}
+	if v, ok := sc["ssrc"].(float64); ok {
+		entry.SSRC = uint32(v)
+	}
+	if v, ok := sc["user_id"].(string); ok {
+		entry.UserID = v
+	}
+	if v, ok := sc["guild_id"].(string); ok {
+		entry.GuildID = v
+	}
+	if v, ok := sc["channel_id"].(string); ok {
+		entry.ChannelID = v
+	}
+	if v, ok := sc["duration_ms"].(float64); ok {
+		entry.DurationMS = int64(v)
+	}
+	if v, ok := sc["accum_created_utc"].(string); ok {
+		entry.CreatedAtUTC = v
+	}
+	s.idx.Put(cid, entry)
+}
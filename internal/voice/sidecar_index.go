@@ -0,0 +1,402 @@
+package voice
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/discord-voice-lab/internal/logging"
+)
+
+// sidecarIndexBucket is the single bbolt bucket sidecarIndex persists its
+// entries map into, keyed by correlation_id.
+var sidecarIndexBucket = []byte("sidecar_index")
+
+// sidecarIndexEntry is what sidecarIndex keeps per correlation_id: just
+// enough to answer findSidecarPathForCID and the /debug/sidecars query
+// without re-reading (or re-scanning) the JSON file itself. GuildID/
+// ChannelID/DurationMS mirror the fields flushAccum now writes into the
+// sidecar JSON itself (see processor.go); ModUnix is the secondary index
+// StartSaveAudioCleaner ranges over instead of re-stat'ing every file.
+type sidecarIndexEntry struct {
+	Path          string `json:"path"`
+	SSRC          uint32 `json:"ssrc"`
+	UserID        string `json:"user_id,omitempty"`
+	GuildID       string `json:"guild_id,omitempty"`
+	ChannelID     string `json:"channel_id,omitempty"`
+	DurationMS    int64  `json:"duration_ms,omitempty"`
+	CreatedAtUTC  string `json:"created_at_utc,omitempty"`
+	IndexedAtUnix int64  `json:"indexed_at_unix"`
+	ModUnix       int64  `json:"mod_unix"`
+}
+
+// sidecarIndex is an in-process, correlation_id-keyed index over the sidecar
+// JSON files under saveAudioDir, replacing the O(files) directory scan
+// findSidecarPathForCID used to do per lookup. entries is the in-memory
+// copy every read (Get/Query/RangeByModTime) serves from; db is its durable
+// backing store (a single bbolt bucket, one key per correlation_id), so a
+// restart reloads entries from db instead of re-deriving them all from
+// Reconcile. db is nil when persistence is disabled (dir == "").
+type sidecarIndex struct {
+	mu      sync.RWMutex
+	entries map[string]sidecarIndexEntry
+	db      *bbolt.DB
+}
+
+// newSidecarIndex builds an index persisting to
+// "<saveAudioDir>/.sidecar_index.db", loading any existing entries so they
+// survive a restart. dir == "" disables persistence (and the whole feature
+// is moot, since saveAudioDir empty means nothing is ever saved).
+//
+// After loading (or failing to open the db), it always runs Reconcile
+// against dir: per-file this is just a stat plus a ModUnix comparison
+// against whatever's already indexed, so it's cheap when the index is
+// current, and it's the only way to catch dir having been changed out from
+// under an up-to-date index (e.g. files swapped 1-for-1 while the process
+// was down) rather than just a missing or empty one.
+func newSidecarIndex(dir string) *sidecarIndex {
+	idx := &sidecarIndex{entries: make(map[string]sidecarIndexEntry)}
+	if dir == "" {
+		return idx
+	}
+	dbPath := strings.TrimRight(dir, "/") + "/.sidecar_index.db"
+	db, err := bbolt.Open(dbPath, 0o644, &bbolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		logging.Error("sidecar index: failed to open db, running unpersisted", "path", dbPath, "err", err)
+	} else {
+		idx.db = db
+		if err := db.Update(func(tx *bbolt.Tx) error {
+			_, err := tx.CreateBucketIfNotExists(sidecarIndexBucket)
+			return err
+		}); err != nil {
+			logging.Error("sidecar index: failed to create bucket, running unpersisted", "path", dbPath, "err", err)
+			_ = db.Close()
+			idx.db = nil
+		} else {
+			_ = db.View(func(tx *bbolt.Tx) error {
+				return tx.Bucket(sidecarIndexBucket).ForEach(func(k, v []byte) error {
+					var entry sidecarIndexEntry
+					if err := json.Unmarshal(v, &entry); err != nil {
+						return nil // skip a corrupt row rather than failing the whole load
+					}
+					idx.entries[string(k)] = entry
+					return nil
+				})
+			})
+		}
+	}
+	if fixed := idx.Reconcile(dir); fixed > 0 {
+		logging.Info("sidecar index: reconciled at boot", "dir", dir, "fixed", fixed, "indexed", len(idx.entries))
+	}
+	return idx
+}
+
+// Close releases the underlying bbolt db, if persistence is enabled. Safe to
+// call on a nil index.
+func (idx *sidecarIndex) Close() error {
+	if idx == nil || idx.db == nil {
+		return nil
+	}
+	return idx.db.Close()
+}
+
+// Put records (or overwrites) the entry for cid and persists it.
+func (idx *sidecarIndex) Put(cid string, entry sidecarIndexEntry) {
+	if cid == "" {
+		return
+	}
+	idx.mu.Lock()
+	idx.entries[cid] = entry
+	idx.mu.Unlock()
+	idx.persistOne(cid, entry)
+}
+
+// putNoPersist is Put without the per-call persistOne, so a caller adding
+// many entries in a loop (Reconcile) can write them all in one persistMany
+// bbolt transaction at the end instead of one transaction per entry.
+func (idx *sidecarIndex) putNoPersist(cid string, entry sidecarIndexEntry) {
+	if cid == "" {
+		return
+	}
+	idx.mu.Lock()
+	idx.entries[cid] = entry
+	idx.mu.Unlock()
+}
+
+// Get returns the sidecar path for cid, matching findSidecarPathForCID's old
+// return contract: "" means not found.
+func (idx *sidecarIndex) Get(cid string) string {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return idx.entries[cid].Path
+}
+
+// Compact drops entries whose WAV file (the sidecar's sibling, same base
+// name with a .wav extension instead of .json) no longer exists on disk,
+// and returns how many were removed. Run periodically so the index doesn't
+// grow unbounded as operators prune old saved audio out from under it.
+func (idx *sidecarIndex) Compact() int {
+	idx.mu.Lock()
+	var dropped []string
+	for cid, entry := range idx.entries {
+		wavPath := strings.TrimSuffix(entry.Path, ".json") + ".wav"
+		if _, err := os.Stat(wavPath); err != nil {
+			delete(idx.entries, cid)
+			dropped = append(dropped, cid)
+		}
+	}
+	idx.mu.Unlock()
+	if len(dropped) > 0 {
+		idx.deleteKeys(dropped)
+	}
+	return len(dropped)
+}
+
+// SidecarFilter bounds a Query call; every field is optional (zero value
+// matches everything). Since/Until bound IndexedAtUnix.
+type SidecarFilter struct {
+	CID       string
+	UserID    string
+	GuildID   string
+	ChannelID string
+	SSRC      uint32
+	Since     time.Time
+	Until     time.Time
+}
+
+// Query returns every entry matching f, across any indexed field, newest
+// first - the general lookup handleDebugSidecars builds a SidecarFilter
+// for directly from its query-string params.
+func (idx *sidecarIndex) Query(f SidecarFilter) []sidecarIndexEntry {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	out := make([]sidecarIndexEntry, 0, len(idx.entries))
+	for c, entry := range idx.entries {
+		if f.CID != "" && c != f.CID {
+			continue
+		}
+		if f.UserID != "" && entry.UserID != f.UserID {
+			continue
+		}
+		if f.GuildID != "" && entry.GuildID != f.GuildID {
+			continue
+		}
+		if f.ChannelID != "" && entry.ChannelID != f.ChannelID {
+			continue
+		}
+		if f.SSRC != 0 && entry.SSRC != f.SSRC {
+			continue
+		}
+		if !f.Since.IsZero() && time.Unix(entry.IndexedAtUnix, 0).Before(f.Since) {
+			continue
+		}
+		if !f.Until.IsZero() && time.Unix(entry.IndexedAtUnix, 0).After(f.Until) {
+			continue
+		}
+		out = append(out, entry)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].IndexedAtUnix > out[j].IndexedAtUnix })
+	return out
+}
+
+// RangeByModTime returns every entry with ModUnix before cutoff, oldest
+// first, so a caller enforcing retention (StartSaveAudioCleaner) can walk
+// expired entries in index order instead of re-stat'ing and re-parsing
+// every sidecar JSON in saveAudioDir each tick.
+func (idx *sidecarIndex) RangeByModTime(cutoff time.Time) []sidecarIndexEntry {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	cut := cutoff.Unix()
+	out := make([]sidecarIndexEntry, 0, len(idx.entries))
+	for _, entry := range idx.entries {
+		if entry.ModUnix < cut {
+			out = append(out, entry)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ModUnix < out[j].ModUnix })
+	return out
+}
+
+// Reconcile walks dir's sidecar JSON files and adds (or refreshes) any whose
+// correlation_id isn't already indexed, or whose indexed ModUnix no longer
+// matches the file's current mtime - the boot-time repair path for an index
+// that's missing entirely (fresh saveAudioDir, or the db failed to open) or
+// gone stale (saveAudioDir touched by another process, or a crash between
+// writing a sidecar and committing its index row). Returns how many entries
+// were added or refreshed. Best-effort: a file that fails to read/parse is
+// skipped rather than failing the whole reconcile.
+//
+// Entries are written via putNoPersist and persisted in one bbolt
+// transaction after the loop, not per-file: a saveAudioDir with thousands of
+// sidecars needing repair would otherwise mean as many individual
+// transactions, turning boot-time reconcile into a needless fsync storm.
+func (idx *sidecarIndex) Reconcile(dir string) int {
+	if dir == "" {
+		return 0
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		logging.Warn("sidecar index: reconcile readdir failed", "dir", dir, "err", err)
+		return 0
+	}
+	fixed := 0
+	touched := make(map[string]sidecarIndexEntry)
+	for _, fi := range files {
+		name := fi.Name()
+		if !strings.HasSuffix(name, ".json") || name == ".sidecar_index.json" {
+			continue
+		}
+		path := strings.TrimRight(dir, "/") + "/" + name
+		info, err := fi.Info()
+		if err != nil {
+			continue
+		}
+		modUnix := info.ModTime().Unix()
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var sc map[string]interface{}
+		if err := json.Unmarshal(b, &sc); err != nil {
+			continue
+		}
+		cid, _ := sc["correlation_id"].(string)
+		if cid == "" {
+			continue
+		}
+
+		idx.mu.RLock()
+		existing, ok := idx.entries[cid]
+		idx.mu.RUnlock()
+		if ok && existing.ModUnix == modUnix {
+			continue
+		}
+
+		entry := sidecarIndexEntry{Path: path, ModUnix: modUnix, IndexedAtUnix: time.Now().Unix()}
+		if v, ok := sc["ssrc"].(float64); ok {
+			entry.SSRC = uint32(v)
+		}
+		if v, ok := sc["user_id"].(string); ok {
+			entry.UserID = v
+		}
+		if v, ok := sc["guild_id"].(string); ok {
+			entry.GuildID = v
+		}
+		if v, ok := sc["channel_id"].(string); ok {
+			entry.ChannelID = v
+		}
+		if v, ok := sc["duration_ms"].(float64); ok {
+			entry.DurationMS = int64(v)
+		}
+		if v, ok := sc["accum_created_utc"].(string); ok {
+			entry.CreatedAtUTC = v
+		}
+		idx.putNoPersist(cid, entry)
+		touched[cid] = entry
+		fixed++
+	}
+	if len(touched) > 0 {
+		idx.persistMany(touched)
+	}
+	return fixed
+}
+
+// persistOne write-throughs a single cid/entry pair to the bbolt bucket. A
+// no-op when persistence is disabled (idx.db == nil).
+func (idx *sidecarIndex) persistOne(cid string, entry sidecarIndexEntry) {
+	if idx.db == nil {
+		return
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		logging.Error("sidecar index: marshal failed", "cid", cid, "err", err)
+		return
+	}
+	if err := idx.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(sidecarIndexBucket).Put([]byte(cid), b)
+	}); err != nil {
+		logging.Error("sidecar index: persist failed", "cid", cid, "err", err)
+	}
+}
+
+// persistMany write-throughs a batch of cid/entry pairs in a single bbolt
+// transaction, used by Reconcile so a boot-time repair of thousands of
+// sidecars costs one fsync instead of one per file.
+func (idx *sidecarIndex) persistMany(entries map[string]sidecarIndexEntry) {
+	if idx.db == nil {
+		return
+	}
+	if err := idx.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(sidecarIndexBucket)
+		for cid, entry := range entries {
+			b, err := json.Marshal(entry)
+			if err != nil {
+				continue
+			}
+			if err := bucket.Put([]byte(cid), b); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		logging.Error("sidecar index: batch persist failed", "count", len(entries), "err", err)
+	}
+}
+
+// deleteKeys removes cids from the bbolt bucket in a single transaction,
+// used by Compact after it's already dropped them from the in-memory map.
+func (idx *sidecarIndex) deleteKeys(cids []string) {
+	if idx.db == nil {
+		return
+	}
+	if err := idx.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(sidecarIndexBucket)
+		for _, cid := range cids {
+			if err := bucket.Delete([]byte(cid)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		logging.Error("sidecar index: batch delete failed", "count", len(cids), "err", err)
+	}
+}
+
+// handleDebugSidecars serves sidecarIndex.Query as JSON on the METRICS_ADDR
+// mux's /debug/sidecars route, filtered by the optional cid/user_id/
+// guild_id/channel_id/ssrc/since/until query params (since/until as
+// RFC3339, ssrc as a plain uint32).
+func (p *Processor) handleDebugSidecars(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var since, until time.Time
+	if v := q.Get("since"); v != "" {
+		since, _ = time.Parse(time.RFC3339, v)
+	}
+	if v := q.Get("until"); v != "" {
+		until, _ = time.Parse(time.RFC3339, v)
+	}
+	var ssrc uint32
+	if v := q.Get("ssrc"); v != "" {
+		if n, err := strconv.ParseUint(v, 10, 32); err == nil {
+			ssrc = uint32(n)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(p.sidecarIdx.Query(SidecarFilter{
+		CID:       q.Get("cid"),
+		UserID:    q.Get("user_id"),
+		GuildID:   q.Get("guild_id"),
+		ChannelID: q.Get("channel_id"),
+		SSRC:      ssrc,
+		Since:     since,
+		Until:     until,
+	}))
+}
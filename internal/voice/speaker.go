@@ -0,0 +1,150 @@
+package voice
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/discord-voice-lab/internal/logging"
+	"github.com/hraban/opus"
+)
+
+const (
+	speakerSampleRate = 48000
+	speakerChannels   = 1
+	// speakerFrameSize is 20ms of audio at 48kHz: the frame size Discord
+	// voice expects per Opus packet, matching the decode side's 20ms
+	// framing in receiver.go/processor.go.
+	speakerFrameSize = speakerSampleRate / 50 // 960 samples
+	speakerFramePace = 20 * time.Millisecond
+)
+
+// Speaker is Processor's outbound sibling: it accepts 48kHz mono PCM (a
+// TTS/orchestrator response) and streams it into a Discord voice
+// connection as 20ms Opus frames, the mirror of Processor's inbound
+// decode path. A send goroutine paces frames with a ticker so encoding or
+// enqueue jitter doesn't pile up as uneven bursts on the wire, and toggles
+// vc.Speaking around bursts of playback.
+type Speaker struct {
+	enc *opus.Encoder
+
+	mu sync.Mutex
+	vc *discordgo.VoiceConnection
+
+	frames chan []int16
+	done   chan struct{}
+}
+
+// NewSpeaker creates a Speaker and starts its send goroutine. Call
+// SetVoiceConnection once a VoiceConnection is available; EnqueuePlayback
+// is a no-op until then.
+func NewSpeaker() (*Speaker, error) {
+	enc, err := opus.NewEncoder(speakerSampleRate, speakerChannels, opus.AppAudio)
+	if err != nil {
+		return nil, fmt.Errorf("create opus encoder: %w", err)
+	}
+	s := &Speaker{enc: enc, frames: make(chan []int16, 128), done: make(chan struct{})}
+	go s.sendLoop()
+	return s, nil
+}
+
+// SetVoiceConnection points the Speaker at vc, replacing any previous
+// connection (e.g. after a reconnect). Passing nil pauses playback until a
+// new connection is set.
+func (s *Speaker) SetVoiceConnection(vc *discordgo.VoiceConnection) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vc = vc
+}
+
+// EnqueuePlayback splits pcm into speakerFrameSize frames (zero-padding a
+// short final frame) and queues them for sendLoop, tagging drops with
+// correlationID so a dropped reply can be traced back to the utterance
+// that triggered it.
+func (s *Speaker) EnqueuePlayback(correlationID string, pcm []int16) {
+	for i := 0; i < len(pcm); i += speakerFrameSize {
+		end := i + speakerFrameSize
+		var frame []int16
+		if end <= len(pcm) {
+			frame = pcm[i:end]
+		} else {
+			frame = make([]int16, speakerFrameSize)
+			copy(frame, pcm[i:])
+		}
+		select {
+		case s.frames <- frame:
+		default:
+			logging.Warn("speaker: playback queue full, dropping frame", "correlation_id", correlationID)
+		}
+	}
+}
+
+// Close stops the send goroutine. Safe to call once.
+func (s *Speaker) Close() {
+	close(s.done)
+}
+
+// sendLoop paces queued frames onto vc.OpusSend at speakerFramePace,
+// toggling vc.Speaking(true) when a burst of playback starts and
+// vc.Speaking(false) once the queue runs dry, following the same
+// streaming/idle transition pattern Processor uses for inbound speaking
+// updates.
+func (s *Speaker) sendLoop() {
+	ticker := time.NewTicker(speakerFramePace)
+	defer ticker.Stop()
+	opusBuf := make([]byte, 4000)
+	speaking := false
+
+	setSpeaking := func(v bool) {
+		s.mu.Lock()
+		vc := s.vc
+		s.mu.Unlock()
+		if vc != nil {
+			_ = vc.Speaking(v)
+		}
+		speaking = v
+	}
+
+	for {
+		select {
+		case <-s.done:
+			if speaking {
+				setSpeaking(false)
+			}
+			return
+		case <-ticker.C:
+			select {
+			case frame, ok := <-s.frames:
+				if !ok {
+					return
+				}
+				s.mu.Lock()
+				vc := s.vc
+				s.mu.Unlock()
+				if vc == nil {
+					continue
+				}
+				if !speaking {
+					setSpeaking(true)
+				}
+				n, err := s.enc.Encode(frame, opusBuf)
+				if err != nil {
+					logging.Warn("speaker: opus encode error", "err", err)
+					continue
+				}
+				out := make([]byte, n)
+				copy(out, opusBuf[:n])
+				select {
+				case vc.OpusSend <- out:
+				default:
+					logging.Warn("speaker: vc.OpusSend full, dropping frame")
+				}
+			default:
+				if speaking {
+					setSpeaking(false)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package voice
+
+import (
+	"strings"
+	"time"
+)
+
+// defaultStabilityWindowN is how many recent partials stabilityTracker
+// considers when computing the longest common prefix. AWS Transcribe's
+// stability model looks at a short rolling window rather than just the
+// latest two results, which keeps a single noisy partial from resetting
+// the candidate prefix's stable-since timer.
+const defaultStabilityWindowN = 3
+
+// stabilityTracker implements the AWS-Transcribe-style "stability-based
+// commit" technique: rather than committing a streaming backend's partial
+// transcript the moment it arrives (which gets overwritten by the next,
+// slightly different partial) or waiting for a single final result (which
+// reintroduces the minFlushMs/maxAccumMs latency-vs-truncation tradeoff),
+// it tracks the longest common prefix across the last commitWindowN
+// partials and commits that prefix once it has held for commitStabilityMs
+// without changing. Everything after the committed prefix is the mutable
+// tail — still subject to revision by the next partial.
+//
+// One tracker is owned per in-flight utterance (see transcriptAgg.stability,
+// created alongside the transcriptAgg and discarded when it flushes), so
+// state never leaks across utterances.
+type stabilityTracker struct {
+	stabilityMs int
+	windowN     int
+
+	history   [][]string // last windowN partials' tokens, oldest first
+	committed []string   // tokens already committed into the aggregated transcript
+
+	candidateLen   int       // longest common prefix length under consideration for commit
+	candidateSince time.Time // when candidateLen was first observed
+}
+
+// newStabilityTracker builds a tracker with the given commit window
+// (commitWindowN partials) and stability duration (commitStabilityMs).
+func newStabilityTracker(commitWindowN, commitStabilityMs int) *stabilityTracker {
+	if commitWindowN < 1 {
+		commitWindowN = 1
+	}
+	return &stabilityTracker{stabilityMs: commitStabilityMs, windowN: commitWindowN}
+}
+
+// Update feeds one partial result and returns the committed text so far and
+// the current mutable tail (tokens of this partial not yet committed).
+func (t *stabilityTracker) Update(text string, now time.Time) (committedText, tailText string) {
+	tokens := strings.Fields(text)
+	t.history = append(t.history, tokens)
+	if len(t.history) > t.windowN {
+		t.history = t.history[len(t.history)-t.windowN:]
+	}
+
+	lcp := longestCommonPrefixLen(t.history)
+	if lcp < len(t.committed) {
+		// A later partial revised something earlier than what we already
+		// committed; that shouldn't happen with well-behaved streaming
+		// backends, but don't let it shrink the committed transcript.
+		lcp = len(t.committed)
+	}
+	if lcp != t.candidateLen {
+		t.candidateLen = lcp
+		t.candidateSince = now
+	}
+	if t.candidateLen > len(t.committed) && now.Sub(t.candidateSince) >= time.Duration(t.stabilityMs)*time.Millisecond {
+		t.committed = append([]string(nil), tokens[:t.candidateLen]...)
+	}
+
+	var tail []string
+	if len(tokens) > len(t.committed) {
+		tail = tokens[len(t.committed):]
+	}
+	return strings.Join(t.committed, " "), strings.Join(tail, " ")
+}
+
+// Final commits every remaining token of text (a streaming backend's final
+// result supersedes whatever was still mutable) and returns the full
+// committed transcript.
+func (t *stabilityTracker) Final(text string) string {
+	tokens := strings.Fields(text)
+	if len(tokens) > len(t.committed) {
+		t.committed = tokens
+	} else if text != "" {
+		// The final result may differ from (not just extend) the committed
+		// prefix; trust it as the authoritative transcript for this
+		// utterance.
+		t.committed = tokens
+	}
+	return strings.Join(t.committed, " ")
+}
+
+// longestCommonPrefixLen returns the length of the longest token prefix
+// shared by every token list in lists. An empty lists slice has no
+// constraint and returns 0.
+func longestCommonPrefixLen(lists [][]string) int {
+	if len(lists) == 0 {
+		return 0
+	}
+	minLen := len(lists[0])
+	for _, l := range lists[1:] {
+		if len(l) < minLen {
+			minLen = len(l)
+		}
+	}
+	for i := 0; i < minLen; i++ {
+		want := lists[0][i]
+		for _, l := range lists[1:] {
+			if l[i] != want {
+				return i
+			}
+		}
+	}
+	return minLen
+}
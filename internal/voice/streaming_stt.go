@@ -0,0 +1,318 @@
+package voice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/discord-voice-lab/internal/logging"
+	"github.com/gorilla/websocket"
+)
+
+// StreamingSTTClient pushes raw PCM frames to a streaming speech-to-text
+// backend and receives incremental partial/final transcript messages.
+// Implementations must be safe for concurrent use by a single SSRC's
+// goroutine (callers serialize PushPCM per session).
+type StreamingSTTClient interface {
+	// OpenSession establishes (or reuses) a streaming session for ssrc and
+	// correlationID, returning a handle used to push frames and close out.
+	OpenSession(ctx context.Context, ssrc uint32, correlationID string) (StreamingSession, error)
+}
+
+// StreamingSession represents one in-flight streaming STT conversation for
+// a single SSRC's accumulated utterance.
+type StreamingSession interface {
+	// PushPCM sends a chunk of little-endian int16 PCM samples (typically a
+	// single 20ms Opus-decoded frame) to the backend.
+	PushPCM(samples []int16) error
+	// Close flushes and tears down the session. Safe to call more than once.
+	Close() error
+}
+
+// streamingTranscriptMsg mirrors the JSON shape emitted by Whisper-family
+// streaming servers: {"type":"partial"|"final","text":"...","segments":[...]}.
+type streamingTranscriptMsg struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	Segments []struct {
+		Text  string  `json:"text"`
+		Start float64 `json:"start"`
+		End   float64 `json:"end"`
+	} `json:"segments,omitempty"`
+}
+
+// OnPartialTranscript is invoked for every "partial" message received on a
+// streaming session, in addition to the session feeding the same partial
+// into addAggregatedTranscript via its stabilityTracker. Callers should
+// still treat partials as revisable; only the text committed once it has
+// been stable for STT_STABILITY_MS (or the eventual "final" message) is
+// safe to treat as settled.
+type OnPartialTranscript func(ssrc uint32, correlationID string, partial string)
+
+// PartialTranscript is one interim (not-yet-final) transcript emitted by a
+// streaming STT session, broadcast to SubscribePartialTranscripts subscribers
+// so consumers that want live captions don't have to poll GetTranscript.
+type PartialTranscript struct {
+	SSRC          uint32
+	Text          string
+	CorrelationID string
+	At            time.Time
+}
+
+// partialFanout broadcasts PartialTranscripts to subscriber channels. It's
+// the same subscribe/unsubscribe shape as transcriptHistory (query.go) minus
+// the ring buffer, since a partial is immediately superseded by the next one
+// or the eventual final and there's nothing worth replaying to a late
+// subscriber.
+type partialFanout struct {
+	mu          sync.Mutex
+	subscribers map[chan PartialTranscript]struct{}
+}
+
+func (f *partialFanout) publish(e PartialTranscript) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for ch := range f.subscribers {
+		select {
+		case ch <- e:
+		default:
+			// slow subscriber; drop rather than block the streaming read loop
+		}
+	}
+}
+
+func (f *partialFanout) subscribe() (chan PartialTranscript, func()) {
+	ch := make(chan PartialTranscript, 16)
+	f.mu.Lock()
+	if f.subscribers == nil {
+		f.subscribers = make(map[chan PartialTranscript]struct{})
+	}
+	f.subscribers[ch] = struct{}{}
+	f.mu.Unlock()
+	cancel := func() {
+		f.mu.Lock()
+		delete(f.subscribers, ch)
+		f.mu.Unlock()
+		close(ch)
+	}
+	return ch, cancel
+}
+
+// wsStreamingSTTClient implements StreamingSTTClient over a persistent
+// WebSocket connection to STT_STREAM_URL, one connection per session,
+// mirroring the dial/upgrade pattern used by the existing /mcp/ws bridge.
+type wsStreamingSTTClient struct {
+	url         string
+	p           *Processor
+	onPartial   OnPartialTranscript
+	dialTimeout time.Duration
+}
+
+// NewStreamingSTTClient returns a StreamingSTTClient that dials url (typically
+// STT_STREAM_URL) for each session. onPartial may be nil.
+func NewStreamingSTTClient(p *Processor, url string, onPartial OnPartialTranscript) StreamingSTTClient {
+	return &wsStreamingSTTClient{url: url, p: p, onPartial: onPartial, dialTimeout: 10 * time.Second}
+}
+
+func (c *wsStreamingSTTClient) OpenSession(ctx context.Context, ssrc uint32, correlationID string) (StreamingSession, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, c.dialTimeout)
+	defer cancel()
+	header := map[string][]string{}
+	if correlationID != "" {
+		header["X-Correlation-ID"] = []string{correlationID}
+	}
+	conn, _, err := websocket.DefaultDialer.DialContext(dialCtx, c.url, header)
+	if err != nil {
+		return nil, fmt.Errorf("streaming stt dial: %w", err)
+	}
+	sess := &wsStreamingSession{
+		conn:          conn,
+		ssrc:          ssrc,
+		correlationID: correlationID,
+		p:             c.p,
+		onPartial:     c.onPartial,
+		done:          make(chan struct{}),
+	}
+	sess.wg.Add(1)
+	go sess.readLoop()
+	return sess, nil
+}
+
+type wsStreamingSession struct {
+	conn          *websocket.Conn
+	ssrc          uint32
+	correlationID string
+	p             *Processor
+	onPartial     OnPartialTranscript
+
+	mu        sync.Mutex
+	closed    bool
+	done      chan struct{}
+	wg        sync.WaitGroup
+	finalText string
+}
+
+func (s *wsStreamingSession) PushPCM(samples []int16) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return fmt.Errorf("streaming session closed")
+	}
+	conn := s.conn
+	s.mu.Unlock()
+	buf := make([]byte, len(samples)*2)
+	for i, v := range samples {
+		buf[i*2] = byte(v)
+		buf[i*2+1] = byte(v >> 8)
+	}
+	return conn.WriteMessage(websocket.BinaryMessage, buf)
+}
+
+func (s *wsStreamingSession) readLoop() {
+	defer s.wg.Done()
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			logging.Debug("streaming stt: read loop ended", "ssrc", s.ssrc, "correlation_id", s.correlationID, "err", err)
+			close(s.done)
+			return
+		}
+		var msg streamingTranscriptMsg
+		if err := json.Unmarshal(data, &msg); err != nil {
+			logging.Debug("streaming stt: failed to decode message", "ssrc", s.ssrc, "correlation_id", s.correlationID, "err", err)
+			continue
+		}
+		switch msg.Type {
+		case "partial":
+			logging.Debug("streaming stt: partial transcript", "ssrc", s.ssrc, "correlation_id", s.correlationID, "text", msg.Text)
+			if s.onPartial != nil {
+				s.onPartial(s.ssrc, s.correlationID, msg.Text)
+			}
+			if s.p != nil {
+				_, stripped := s.p.hasWakePhrase(msg.Text)
+				s.p.addAggregatedTranscript(s.ssrc, "unknown", msg.Text, s.correlationID, time.Now(), stripped, false, nil)
+				if s.p.saveAudioDir != "" {
+					s.p.updateSidecarPartial(s.correlationID, msg.Text)
+				}
+				if s.p.partials != nil {
+					s.p.partials.publish(PartialTranscript{SSRC: s.ssrc, Text: msg.Text, CorrelationID: s.correlationID, At: time.Now()})
+				}
+			}
+		case "final":
+			s.mu.Lock()
+			s.finalText = msg.Text
+			s.mu.Unlock()
+			logging.Info("streaming stt: final transcript", "ssrc", s.ssrc, "correlation_id", s.correlationID, "text", msg.Text)
+		default:
+			logging.Debug("streaming stt: unknown message type", "ssrc", s.ssrc, "correlation_id", s.correlationID, "type", msg.Type)
+		}
+	}
+}
+
+// Close stops the read loop and closes the underlying connection. It
+// returns the last "final" transcript observed, if any, via the Processor's
+// normal aggregation path (addAggregatedTranscript), mirroring how the HTTP
+// whisper backend finalizes an utterance.
+func (s *wsStreamingSession) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	final := s.finalText
+	s.mu.Unlock()
+
+	_ = s.conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"eof"}`))
+	select {
+	case <-s.done:
+	case <-time.After(2 * time.Second):
+	}
+	err := s.conn.Close()
+	if final != "" && s.p != nil {
+		_, stripped := s.p.hasWakePhrase(final)
+		s.p.addAggregatedTranscript(s.ssrc, "unknown", final, s.correlationID, time.Now(), stripped, true, nil)
+	}
+	return err
+}
+
+// sidecarPartialEntry is one entry in a sidecar's stt_partial_timeline,
+// recorded by updateSidecarPartial so offline analysis can replay how a
+// streaming session's transcript evolved rather than seeing only the last
+// partial before the final.
+type sidecarPartialEntry struct {
+	Text string `json:"text"`
+	UTC  string `json:"utc"`
+}
+
+// updateSidecarPartial merges the latest streaming partial transcript into
+// the sidecar JSON for cid, mirroring the read/merge/rename pattern used
+// elsewhere for STT timing fields. It also appends to stt_partial_timeline
+// so the full sequence of partials is available for offline analysis, not
+// just the most recent one.
+func (p *Processor) updateSidecarPartial(cid string, partial string) {
+	path := p.findSidecarPathForCID(cid)
+	if path == "" {
+		return
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var sc map[string]interface{}
+	if err := json.Unmarshal(b, &sc); err != nil {
+		return
+	}
+	entry := sidecarPartialEntry{Text: partial, UTC: time.Now().UTC().Format(time.RFC3339Nano)}
+	var timeline []sidecarPartialEntry
+	if raw, ok := sc["stt_partial_timeline"]; ok {
+		if rb, err := json.Marshal(raw); err == nil {
+			_ = json.Unmarshal(rb, &timeline)
+		}
+	}
+	timeline = append(timeline, entry)
+	sc["stt_partial_timeline"] = timeline
+	sc["stt_last_partial"] = partial
+	sc["stt_last_partial_utc"] = entry.UTC
+	nb, err := json.MarshalIndent(sc, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(path+".tmp", nb, 0o644)
+	_ = os.Rename(path+".tmp", path)
+}
+
+// markSidecarSkipped merges a stt_skipped_reason field into the sidecar
+// JSON for cid, mirroring the read/merge/rename pattern used elsewhere for
+// STT timing fields.
+func (p *Processor) markSidecarSkipped(cid string, reason string) {
+	path := p.findSidecarPathForCID(cid)
+	if path == "" {
+		return
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var sc map[string]interface{}
+	if err := json.Unmarshal(b, &sc); err != nil {
+		return
+	}
+	sc["stt_skipped_reason"] = reason
+	nb, err := json.MarshalIndent(sc, "", "  ")
+	if err != nil {
+		return
+	}
+	_ = os.WriteFile(path+".tmp", nb, 0o644)
+	_ = os.Rename(path+".tmp", path)
+}
+
+// streamingSTTURL returns the configured STT_STREAM_URL, or empty if
+// streaming STT is disabled. When unset, callers should fall back to the
+// blocking sendPCMToWhisper HTTP path.
+func streamingSTTURL() string {
+	return os.Getenv("STT_STREAM_URL")
+}
@@ -0,0 +1,101 @@
+package stt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// DeepgramBackend POSTs raw PCM (as WAV) to Deepgram's prerecorded REST
+// endpoint and maps its response shape into TranscribeResult.
+type DeepgramBackend struct {
+	URL    string // defaults to https://api.deepgram.com/v1/listen
+	APIKey string
+	Client *http.Client
+	Config Config
+}
+
+func NewDeepgramBackend(apiKey string, client *http.Client, cfg Config) *DeepgramBackend {
+	url := os.Getenv("DEEPGRAM_URL")
+	if url == "" {
+		url = "https://api.deepgram.com/v1/listen"
+	}
+	return &DeepgramBackend{URL: url, APIKey: apiKey, Client: client, Config: cfg}
+}
+
+func (b *DeepgramBackend) Name() string { return "deepgram" }
+
+func (b *DeepgramBackend) Transcribe(ctx context.Context, pcm []byte, meta TranscribeMeta) (TranscribeResult, error) {
+	if b.APIKey == "" {
+		return TranscribeResult{}, fmt.Errorf("deepgram backend: DEEPGRAM_API_KEY not set")
+	}
+	sampleRate := meta.SampleRateHz
+	if sampleRate == 0 {
+		sampleRate = 48000
+	}
+	channels := meta.Channels
+	if channels == 0 {
+		channels = 1
+	}
+	wav := buildWAV(pcm, sampleRate, channels, 16)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", b.URL, bytes.NewReader(wav))
+	if err != nil {
+		return TranscribeResult{}, err
+	}
+	req.Header.Set("Content-Type", "audio/wav")
+	req.Header.Set("Authorization", "Token "+b.APIKey)
+	if meta.CorrelationID != "" {
+		req.Header.Set("X-Correlation-ID", meta.CorrelationID)
+	}
+
+	client := b.Client
+	if client == nil {
+		client = &http.Client{Timeout: b.Config.timeout()}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return TranscribeResult{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return TranscribeResult{RawStatus: resp.StatusCode}, fmt.Errorf("deepgram backend: status %d", resp.StatusCode)
+	}
+
+	// Deepgram's prerecorded response schema:
+	// {"results":{"channels":[{"alternatives":[{"transcript":"...","words":[{"word":"...","start":0.1,"end":0.3}]}]}]}}
+	var out struct {
+		Results struct {
+			Channels []struct {
+				Alternatives []struct {
+					Transcript string `json:"transcript"`
+					Words      []struct {
+						Word  string  `json:"word"`
+						Start float64 `json:"start"`
+						End   float64 `json:"end"`
+					} `json:"words"`
+				} `json:"alternatives"`
+			} `json:"channels"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return TranscribeResult{RawStatus: resp.StatusCode}, err
+	}
+
+	text := ""
+	var segs []Segment
+	if len(out.Results.Channels) > 0 && len(out.Results.Channels[0].Alternatives) > 0 {
+		alt := out.Results.Channels[0].Alternatives[0]
+		text = strings.TrimSpace(alt.Transcript)
+		for _, w := range alt.Words {
+			segs = append(segs, Segment{Text: w.Word, Start: w.Start, End: w.End})
+		}
+	}
+
+	return TranscribeResult{Text: text, Segments: segs, RawStatus: resp.StatusCode}, nil
+}
@@ -0,0 +1,58 @@
+// Package stt defines a pluggable speech-to-text backend abstraction used by
+// voice.Processor. Each backend implementation is responsible only for
+// turning PCM audio into text; retry/backoff, sidecar persistence, and
+// TEXT_FORWARD_URL forwarding stay in the caller so backends remain pure.
+package stt
+
+import (
+	"context"
+	"time"
+)
+
+// TranscribeMeta carries request-scoped metadata a backend may use to build
+// its request (headers, query params) or to tag its response.
+type TranscribeMeta struct {
+	SSRC          uint32
+	CorrelationID string
+	UserID        string
+	Username      string
+	SampleRateHz  int
+	Channels      int
+}
+
+// Segment is a single timestamped span of recognized speech, when the
+// backend supports word/segment-level timestamps.
+type Segment struct {
+	Text  string  `json:"text"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// TranscribeResult is the normalized output of a Transcribe call.
+type TranscribeResult struct {
+	Text      string
+	Segments  []Segment
+	ServerMs  int
+	RawStatus int
+}
+
+// Backend transcribes a chunk of little-endian int16 PCM audio. meta.SSRC
+// and meta.CorrelationID are provided for logging/tracing; implementations
+// should not block longer than necessary and should honor ctx cancellation.
+type Backend interface {
+	Name() string
+	Transcribe(ctx context.Context, pcm []byte, meta TranscribeMeta) (TranscribeResult, error)
+}
+
+// Config holds the subset of environment-driven settings shared across
+// backends. Individual backends may read additional env vars of their own.
+type Config struct {
+	TimeoutMs int
+}
+
+func (c Config) timeout() time.Duration {
+	if c.TimeoutMs <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(c.TimeoutMs) * time.Millisecond
+}
@@ -0,0 +1,65 @@
+package stt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// VoskBackend POSTs raw PCM to a local Vosk HTTP server (vosk-server's REST
+// shim). Vosk expects raw little-endian PCM16 at the model's sample rate
+// rather than a WAV container.
+type VoskBackend struct {
+	URL    string
+	Client *http.Client
+	Config Config
+}
+
+func NewVoskBackend(client *http.Client, cfg Config) *VoskBackend {
+	url := os.Getenv("VOSK_URL")
+	return &VoskBackend{URL: url, Client: client, Config: cfg}
+}
+
+func (b *VoskBackend) Name() string { return "vosk" }
+
+func (b *VoskBackend) Transcribe(ctx context.Context, pcm []byte, meta TranscribeMeta) (TranscribeResult, error) {
+	if b.URL == "" {
+		return TranscribeResult{}, fmt.Errorf("vosk backend: VOSK_URL not set")
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", b.URL, bytes.NewReader(pcm))
+	if err != nil {
+		return TranscribeResult{}, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if meta.CorrelationID != "" {
+		req.Header.Set("X-Correlation-ID", meta.CorrelationID)
+	}
+
+	client := b.Client
+	if client == nil {
+		client = &http.Client{Timeout: b.Config.timeout()}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return TranscribeResult{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return TranscribeResult{RawStatus: resp.StatusCode}, fmt.Errorf("vosk backend: status %d", resp.StatusCode)
+	}
+
+	// vosk-server's HTTP shim returns {"text": "..."} for the final result.
+	var out struct {
+		Text string `json:"text"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return TranscribeResult{RawStatus: resp.StatusCode}, err
+	}
+
+	return TranscribeResult{Text: strings.TrimSpace(out.Text), RawStatus: resp.StatusCode}, nil
+}
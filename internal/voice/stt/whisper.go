@@ -0,0 +1,146 @@
+package stt
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// WhisperBackend POSTs a WAV-wrapped PCM payload to a faster-whisper-style
+// HTTP endpoint. This is the original sendPCMToWhisper behavior extracted
+// into the Backend interface.
+type WhisperBackend struct {
+	URL    string
+	Client *http.Client
+	Config Config
+}
+
+func NewWhisperBackend(url string, client *http.Client, cfg Config) *WhisperBackend {
+	return &WhisperBackend{URL: url, Client: client, Config: cfg}
+}
+
+func (b *WhisperBackend) Name() string { return "whisper" }
+
+func (b *WhisperBackend) Transcribe(ctx context.Context, pcm []byte, meta TranscribeMeta) (TranscribeResult, error) {
+	if b.URL == "" {
+		return TranscribeResult{}, fmt.Errorf("whisper backend: URL not configured")
+	}
+	sampleRate := meta.SampleRateHz
+	if sampleRate == 0 {
+		sampleRate = 48000
+	}
+	channels := meta.Channels
+	if channels == 0 {
+		channels = 1
+	}
+
+	endpoint := b.URL
+	if u, err := url.Parse(b.URL); err == nil {
+		q := u.Query()
+		if v := os.Getenv("WHISPER_TRANSLATE"); v != "" {
+			lv := strings.ToLower(strings.TrimSpace(v))
+			if lv == "1" || lv == "true" || lv == "yes" {
+				q.Set("task", "translate")
+			}
+		}
+		if v := os.Getenv("STT_BEAM_SIZE"); v != "" {
+			if _, err := strconv.Atoi(v); err == nil {
+				q.Set("beam_size", v)
+			}
+		}
+		if v := os.Getenv("STT_LANGUAGE"); v != "" {
+			q.Set("language", v)
+		}
+		u.RawQuery = q.Encode()
+		endpoint = u.String()
+	}
+
+	wav := buildWAV(pcm, sampleRate, channels, 16)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(wav))
+	if err != nil {
+		return TranscribeResult{}, err
+	}
+	req.Header.Set("Content-Type", "audio/wav")
+	if meta.CorrelationID != "" {
+		req.Header.Set("X-Correlation-ID", meta.CorrelationID)
+	}
+
+	client := b.Client
+	if client == nil {
+		client = &http.Client{Timeout: b.Config.timeout()}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return TranscribeResult{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return TranscribeResult{RawStatus: resp.StatusCode}, fmt.Errorf("whisper backend: status %d", resp.StatusCode)
+	}
+
+	var out struct {
+		Text     string `json:"text"`
+		Segments []struct {
+			Text  string  `json:"text"`
+			Start float64 `json:"start"`
+			End   float64 `json:"end"`
+		} `json:"segments"`
+		ProcessingMs int `json:"processing_ms"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return TranscribeResult{RawStatus: resp.StatusCode}, err
+	}
+
+	segs := make([]Segment, 0, len(out.Segments))
+	for _, s := range out.Segments {
+		segs = append(segs, Segment{Text: s.Text, Start: s.Start, End: s.End})
+	}
+
+	serverMs := out.ProcessingMs
+	if v := resp.Header.Get("X-Processing-Time-ms"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			serverMs = n
+		}
+	}
+
+	return TranscribeResult{
+		Text:      strings.TrimSpace(out.Text),
+		Segments:  segs,
+		ServerMs:  serverMs,
+		RawStatus: resp.StatusCode,
+	}, nil
+}
+
+// buildWAV creates a RIFF/WAVE header for 16-bit PCM and appends pcm bytes.
+func buildWAV(pcm []byte, sampleRate, channels, bitsPerSample int) []byte {
+	byteRate := uint32(sampleRate * channels * bitsPerSample / 8)
+	blockAlign := uint16(channels * bitsPerSample / 8)
+	dataLen := uint32(len(pcm))
+	riffSize := uint32(4 + (8 + 16) + (8 + dataLen))
+
+	buf := &bytes.Buffer{}
+	buf.WriteString("RIFF")
+	binary.Write(buf, binary.LittleEndian, riffSize)
+	buf.WriteString("WAVE")
+	buf.WriteString("fmt ")
+	binary.Write(buf, binary.LittleEndian, uint32(16))
+	binary.Write(buf, binary.LittleEndian, uint16(1))
+	binary.Write(buf, binary.LittleEndian, uint16(channels))
+	binary.Write(buf, binary.LittleEndian, uint32(sampleRate))
+	binary.Write(buf, binary.LittleEndian, uint32(byteRate))
+	binary.Write(buf, binary.LittleEndian, blockAlign)
+	binary.Write(buf, binary.LittleEndian, uint16(bitsPerSample))
+	buf.WriteString("data")
+	binary.Write(buf, binary.LittleEndian, uint32(dataLen))
+	buf.Write(pcm)
+	return buf.Bytes()
+}
@@ -0,0 +1,24 @@
+package voice
+
+import (
+	"os"
+	"strings"
+
+	"github.com/discord-voice-lab/internal/voice/stt"
+)
+
+// sttBackendFromEnv selects an stt.Backend implementation based on
+// STT_BACKEND (whisper|deepgram|vosk, default whisper). Construction is
+// lazy/best-effort: an unconfigured backend (e.g. missing API key or URL)
+// is still returned so callers see the resulting Transcribe error rather
+// than a nil backend.
+func sttBackendFromEnv(whisperURL string, cfg stt.Config) stt.Backend {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("STT_BACKEND"))) {
+	case "deepgram":
+		return stt.NewDeepgramBackend(os.Getenv("DEEPGRAM_API_KEY"), nil, cfg)
+	case "vosk":
+		return stt.NewVoskBackend(nil, cfg)
+	default:
+		return stt.NewWhisperBackend(whisperURL, nil, cfg)
+	}
+}
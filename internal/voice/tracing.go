@@ -0,0 +1,143 @@
+package voice
+
+import (
+	"context"
+	"crypto/rand"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/discord-voice-lab/internal/logging"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// tracer is the package-wide OpenTelemetry tracer used to instrument the
+// STT pipeline: a root span per flushed utterance with child spans for
+// wav.encode, stt.request, sidecar.write and transcript.sink.send.
+var tracer = otel.Tracer("github.com/discord-voice-lab/internal/voice")
+
+type correlationIDKey struct{}
+
+// withCorrelationID stashes cid in ctx so correlationIDGenerator can derive
+// the root span's trace ID from it when tracer.Start is next called.
+func withCorrelationID(ctx context.Context, cid string) context.Context {
+	if cid == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, correlationIDKey{}, cid)
+}
+
+// randIDGenerator is the plain-random fallback used whenever no correlation
+// ID is available to seed a trace ID.
+type randIDGenerator struct{}
+
+func (randIDGenerator) NewIDs(context.Context) (oteltrace.TraceID, oteltrace.SpanID) {
+	var tid oteltrace.TraceID
+	var sid oteltrace.SpanID
+	_, _ = rand.Read(tid[:])
+	_, _ = rand.Read(sid[:])
+	return tid, sid
+}
+
+func (randIDGenerator) NewSpanID(context.Context, oteltrace.TraceID) oteltrace.SpanID {
+	var sid oteltrace.SpanID
+	_, _ = rand.Read(sid[:])
+	return sid
+}
+
+// correlationIDGenerator derives a root span's trace ID from the
+// X-Correlation-ID UUID stashed in context via withCorrelationID, so traces
+// can be joined with the existing correlation-ID-keyed logs and sidecar
+// JSON on the same value. Spans without a (valid) stashed correlation ID
+// fall back to a random trace ID.
+type correlationIDGenerator struct {
+	fallback sdktrace.IDGenerator
+}
+
+func (g *correlationIDGenerator) NewIDs(ctx context.Context) (oteltrace.TraceID, oteltrace.SpanID) {
+	if cid, _ := ctx.Value(correlationIDKey{}).(string); cid != "" {
+		if u, err := uuid.Parse(cid); err == nil {
+			_, sid := g.fallback.NewIDs(ctx)
+			return oteltrace.TraceID(u), sid
+		}
+	}
+	return g.fallback.NewIDs(ctx)
+}
+
+func (g *correlationIDGenerator) NewSpanID(ctx context.Context, traceID oteltrace.TraceID) oteltrace.SpanID {
+	return g.fallback.NewSpanID(ctx, traceID)
+}
+
+// spanLogExporter is a minimal sdktrace.SpanExporter that logs finished
+// spans through the existing structured logger instead of requiring an
+// OTLP collector. It keeps tracing self-contained like the rest of the
+// package's dependency-light approach; swap in an OTLP exporter via
+// sdktrace.WithBatcher if a collector becomes available.
+type spanLogExporter struct{}
+
+func (spanLogExporter) ExportSpans(_ context.Context, spans []sdktrace.ReadOnlySpan) error {
+	for _, s := range spans {
+		logging.Info("trace span",
+			"trace_id", s.SpanContext().TraceID().String(),
+			"span_id", s.SpanContext().SpanID().String(),
+			"name", s.Name(),
+			"duration_ms", s.EndTime().Sub(s.StartTime()).Milliseconds(),
+		)
+	}
+	return nil
+}
+
+func (spanLogExporter) Shutdown(context.Context) error { return nil }
+
+// tracingOnce guards the global TracerProvider install: InitTracing is
+// called once per guild session's Processor construction (NewProcessorWithResolver),
+// so without this a second guild join would silently replace the first
+// session's exporter/provider.
+var tracingOnce sync.Once
+
+// InitTracing installs a global TracerProvider whose root spans derive
+// their trace ID from the in-flight utterance's correlation ID. If
+// OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set,
+// spans are batched to an OTLP/HTTP collector (using the exporter's own
+// standard env-var configuration for endpoint/headers/protocol); otherwise
+// spans fall back to spanLogExporter, keeping tracing usable with zero
+// external setup. It's safe to call multiple times (only the first call
+// takes effect); callers should defer the returned shutdown func.
+func InitTracing() func(context.Context) error {
+	shutdown := func(context.Context) error { return nil }
+	tracingOnce.Do(func() {
+		opts := []sdktrace.TracerProviderOption{
+			sdktrace.WithIDGenerator(&correlationIDGenerator{fallback: randIDGenerator{}}),
+		}
+		if exp, err := otlpExporterFromEnv(); err != nil {
+			logging.Warn("otel: OTLP exporter init failed, falling back to log exporter", "err", err)
+			opts = append(opts, sdktrace.WithSyncer(spanLogExporter{}))
+		} else if exp != nil {
+			opts = append(opts, sdktrace.WithBatcher(exp))
+		} else {
+			opts = append(opts, sdktrace.WithSyncer(spanLogExporter{}))
+		}
+		tp := sdktrace.NewTracerProvider(opts...)
+		otel.SetTracerProvider(tp)
+		shutdown = tp.Shutdown
+	})
+	return shutdown
+}
+
+// otlpExporterFromEnv builds an OTLP/HTTP span exporter when
+// OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set,
+// letting otlptracehttp read the rest of its configuration (headers,
+// protocol, insecure) from the same standard OTel env vars. Returns a nil
+// exporter (not an error) when neither is set.
+func otlpExporterFromEnv() (sdktrace.SpanExporter, error) {
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return otlptracehttp.New(ctx)
+}
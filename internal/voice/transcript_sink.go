@@ -0,0 +1,446 @@
+package voice
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/discord-voice-lab/internal/logging"
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	sinkSuccessTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "transcript_sink_success_total",
+		Help: "Transcript events successfully delivered, labeled by sink type.",
+	}, []string{"sink"})
+
+	sinkFailedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "transcript_sink_failed_total",
+		Help: "Transcript events that failed delivery and were (or will be) appended to the WAL, labeled by sink type.",
+	}, []string{"sink"})
+
+	walDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "transcript_sink_wal_depth",
+		Help: "Number of transcript events currently queued in the on-disk WAL awaiting retry.",
+	})
+)
+
+// TranscriptEvent is the stable schema published to a TranscriptSink. Seq is
+// monotonically increasing per SSRC (see retryingSink.nextSeq) so downstream
+// consumers can detect gaps caused by a dropped or never-retried event.
+type TranscriptEvent struct {
+	UserID        string `json:"user_id"`
+	SSRC          uint32 `json:"ssrc"`
+	Seq           uint64 `json:"seq"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+	Transcript    string `json:"transcript"`
+	IsFinal       bool   `json:"is_final"`
+	WakeDetected  bool   `json:"wake_detected"`
+	STTLatencyMs  int    `json:"stt_latency_ms,omitempty"`
+	STTServerMs   int    `json:"stt_server_ms,omitempty"`
+	EndToEndMs    int    `json:"end_to_end_ms,omitempty"`
+	EventSentUTC  string `json:"event_sent_utc"`
+}
+
+// TranscriptSink delivers finalized transcript events to downstream
+// consumers; every event the Processor sends has IsFinal set, and partial
+// transcripts are not published. Implementations are expected
+// to be best-effort; retryingSink is what gives the whole pipeline its
+// at-least-once delivery via the WAL.
+type TranscriptSink interface {
+	Name() string
+	Send(ctx context.Context, ev TranscriptEvent) error
+}
+
+// HTTPTranscriptSink POSTs each event as JSON to a fixed URL. This is the
+// original TEXT_FORWARD_URL behavior, now expressed as a TranscriptSink so
+// it can sit behind the same WAL/retry wrapper as the other sinks.
+type HTTPTranscriptSink struct {
+	URL    string
+	client *http.Client
+}
+
+// NewHTTPTranscriptSink builds a sink POSTing to url with its own short
+// timeout, independent of Processor.httpClient (which is tuned for STT
+// backend calls, not this best-effort forward).
+func NewHTTPTranscriptSink(url string) *HTTPTranscriptSink {
+	return &HTTPTranscriptSink{URL: url, client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (s *HTTPTranscriptSink) Name() string { return "http" }
+
+func (s *HTTPTranscriptSink) Send(ctx context.Context, ev TranscriptEvent) error {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("marshal transcript event: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", s.URL, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("build forward request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("forward POST: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("forward POST returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// KafkaTranscriptSink publishes events to a Kafka topic via a Sarama
+// SyncProducer, built once in NewKafkaTranscriptSink and reused for every
+// Send (Sarama's producers are safe for concurrent use and expensive to
+// construct, so there's no per-Send dial).
+type KafkaTranscriptSink struct {
+	Brokers  []string
+	Topic    string
+	producer sarama.SyncProducer
+}
+
+// NewKafkaTranscriptSink dials brokers and returns a Kafka sink publishing
+// to topic, or an error if the SyncProducer can't be constructed (e.g. no
+// broker in brokers is reachable). The caller (transcriptSinkFromEnv) falls
+// back to WAL-only delivery on error, same as every other sink-construction
+// failure in this file.
+func NewKafkaTranscriptSink(brokers []string, topic string) (*KafkaTranscriptSink, error) {
+	cfg := sarama.NewConfig()
+	cfg.Producer.Return.Successes = true
+	cfg.Producer.RequiredAcks = sarama.WaitForAll
+	cfg.Producer.Retry.Max = 3
+	producer, err := sarama.NewSyncProducer(brokers, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("kafka: new sync producer: %w", err)
+	}
+	return &KafkaTranscriptSink{Brokers: brokers, Topic: topic, producer: producer}, nil
+}
+
+func (s *KafkaTranscriptSink) Name() string { return "kafka" }
+
+func (s *KafkaTranscriptSink) Send(ctx context.Context, ev TranscriptEvent) error {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("marshal transcript event: %w", err)
+	}
+	msg := &sarama.ProducerMessage{
+		Topic: s.Topic,
+		Key:   sarama.StringEncoder(ev.UserID),
+		Value: sarama.ByteEncoder(b),
+	}
+	_, _, err = s.producer.SendMessage(msg)
+	if err != nil {
+		return fmt.Errorf("kafka: send message: %w", err)
+	}
+	return nil
+}
+
+// Close releases the underlying SyncProducer. Safe to call on a nil sink.
+func (s *KafkaTranscriptSink) Close() error {
+	if s == nil || s.producer == nil {
+		return nil
+	}
+	return s.producer.Close()
+}
+
+// NATSTranscriptSink publishes events to a NATS JetStream subject.
+type NATSTranscriptSink struct {
+	URL     string
+	Subject string
+	conn    *nats.Conn
+	js      nats.JetStreamContext
+}
+
+// NewNATSTranscriptSink connects to url and returns a sink publishing to
+// subject via JetStream, or an error if the connection or JetStream context
+// can't be established.
+func NewNATSTranscriptSink(url, subject string) (*NATSTranscriptSink, error) {
+	conn, err := nats.Connect(url)
+	if err != nil {
+		return nil, fmt.Errorf("nats: connect: %w", err)
+	}
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("nats: jetstream context: %w", err)
+	}
+	return &NATSTranscriptSink{URL: url, Subject: subject, conn: conn, js: js}, nil
+}
+
+func (s *NATSTranscriptSink) Name() string { return "nats" }
+
+func (s *NATSTranscriptSink) Send(ctx context.Context, ev TranscriptEvent) error {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("marshal transcript event: %w", err)
+	}
+	if _, err := s.js.Publish(s.Subject, b, nats.Context(ctx)); err != nil {
+		return fmt.Errorf("nats: publish: %w", err)
+	}
+	return nil
+}
+
+// Close drains the underlying connection. Safe to call on a nil sink.
+func (s *NATSTranscriptSink) Close() error {
+	if s == nil || s.conn == nil {
+		return nil
+	}
+	return s.conn.Drain()
+}
+
+// WALTranscriptSink appends events as JSON lines to a file, for
+// TRANSCRIPT_SINK=wal deployments that want events durably queued on disk
+// without any live downstream consumer. It's also what retryingSink uses
+// internally as the fallback for any other sink's failures.
+type WALTranscriptSink struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func NewWALTranscriptSink(path string) *WALTranscriptSink {
+	return &WALTranscriptSink{Path: path}
+}
+
+func (s *WALTranscriptSink) Name() string { return "wal" }
+
+func (s *WALTranscriptSink) Send(ctx context.Context, ev TranscriptEvent) error {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("marshal transcript event: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open wal: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("append wal: %w", err)
+	}
+	return nil
+}
+
+// retryingSink wraps a primary TranscriptSink with per-SSRC sequence
+// numbering and a disk-backed WAL: Send tries primary first, and on failure
+// appends the event to walPath instead of dropping it silently, as
+// TEXT_FORWARD_URL's old fire-and-forget behavior did. A background goroutine (see startRetryLoop) periodically
+// retries everything in the WAL against primary with exponential backoff.
+type retryingSink struct {
+	primary TranscriptSink
+	walPath string
+
+	walMu sync.Mutex
+
+	seqMu sync.Mutex
+	seq   map[uint32]uint64
+
+	backoff time.Duration
+}
+
+const (
+	walRetryMinBackoff = 2 * time.Second
+	walRetryMaxBackoff = 2 * time.Minute
+)
+
+func newRetryingSink(primary TranscriptSink, walPath string) *retryingSink {
+	return &retryingSink{
+		primary: primary,
+		walPath: walPath,
+		seq:     make(map[uint32]uint64),
+		backoff: walRetryMinBackoff,
+	}
+}
+
+// nextSeq returns the next monotonically increasing sequence number for
+// ssrc, starting at 1, so a downstream consumer can tell seq 4 was skipped
+// if it only ever sees 3 then 5.
+func (r *retryingSink) nextSeq(ssrc uint32) uint64 {
+	r.seqMu.Lock()
+	defer r.seqMu.Unlock()
+	r.seq[ssrc]++
+	return r.seq[ssrc]
+}
+
+func (r *retryingSink) Send(ctx context.Context, ev TranscriptEvent) error {
+	if err := r.primary.Send(ctx, ev); err != nil {
+		sinkFailedTotal.WithLabelValues(r.primary.Name()).Inc()
+		logging.Warn("transcript sink: primary send failed, queuing to wal", "sink", r.primary.Name(), "ssrc", ev.SSRC, "seq", ev.Seq, "err", err)
+		r.walMu.Lock()
+		walErr := (&WALTranscriptSink{Path: r.walPath}).Send(ctx, ev)
+		r.walMu.Unlock()
+		if walErr != nil {
+			logging.Error("transcript sink: failed to queue event to wal", "path", r.walPath, "err", walErr)
+			return walErr
+		}
+		walDepth.Add(1)
+		return nil
+	}
+	sinkSuccessTotal.WithLabelValues(r.primary.Name()).Inc()
+	return nil
+}
+
+// startRetryLoop runs until ctx is done, periodically draining walPath by
+// retrying every queued event against primary. Entries that still fail stay
+// queued for the next tick; the interval backs off exponentially (capped at
+// walRetryMaxBackoff) while the WAL stays non-empty, and resets to the
+// minimum once a drain succeeds fully, so a flapping downstream doesn't get
+// hammered but a brief outage drains quickly once it recovers.
+func (r *retryingSink) startRetryLoop(ctx context.Context) {
+	timer := time.NewTimer(r.backoff)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			drained := r.drainWAL(ctx)
+			if drained {
+				r.backoff = walRetryMinBackoff
+			} else {
+				r.backoff *= 2
+				if r.backoff > walRetryMaxBackoff {
+					r.backoff = walRetryMaxBackoff
+				}
+			}
+			timer.Reset(r.backoff)
+		}
+	}
+}
+
+// drainWAL reads walPath line by line, retries each event against primary,
+// and rewrites the file (tmp+rename, same atomic pattern every other
+// sidecar/index write in this package uses) containing only the events
+// that still failed. Returns true iff the WAL is now empty.
+func (r *retryingSink) drainWAL(ctx context.Context) bool {
+	// A WAL primary appends to the very file being drained: retrying against
+	// it would re-append every line as it is read, so the queue stays as is.
+	if w, ok := r.primary.(*WALTranscriptSink); ok && w.Path == r.walPath {
+		return true
+	}
+	r.walMu.Lock()
+	defer r.walMu.Unlock()
+
+	f, err := os.Open(r.walPath)
+	if err != nil {
+		walDepth.Set(0)
+		return true
+	}
+	defer f.Close()
+
+	var remaining []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var ev TranscriptEvent
+		if err := json.Unmarshal([]byte(line), &ev); err != nil {
+			logging.Error("transcript sink: dropping unparseable wal line", "err", err)
+			continue
+		}
+		if err := r.primary.Send(ctx, ev); err != nil {
+			remaining = append(remaining, line)
+			continue
+		}
+		sinkSuccessTotal.WithLabelValues(r.primary.Name()).Inc()
+	}
+
+	walDepth.Set(float64(len(remaining)))
+	if len(remaining) == 0 {
+		_ = os.Remove(r.walPath)
+		return true
+	}
+	tmp := r.walPath + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strings.Join(remaining, "\n")+"\n"), 0o644); err != nil {
+		logging.Error("transcript sink: failed to rewrite wal", "err", err)
+		return false
+	}
+	if err := os.Rename(tmp, r.walPath); err != nil {
+		logging.Error("transcript sink: failed to rename wal", "err", err)
+		_ = os.Remove(tmp)
+		return false
+	}
+	return false
+}
+
+// transcriptSinkFromEnv builds the configured TranscriptSink wrapped in a
+// retryingSink, or nil if nothing is configured (preserving the old
+// behavior of simply not forwarding transcripts). TRANSCRIPT_SINK selects
+// the implementation (http|kafka|nats|wal); if unset but TEXT_FORWARD_URL
+// is, that's treated as TRANSCRIPT_SINK=http for backward compatibility
+// with the POST-only behavior this replaces.
+func transcriptSinkFromEnv(saveAudioDir string) *retryingSink {
+	kind := strings.ToLower(strings.TrimSpace(os.Getenv("TRANSCRIPT_SINK")))
+	fw := strings.TrimSpace(os.Getenv("TEXT_FORWARD_URL"))
+	if kind == "" && fw != "" {
+		kind = "http"
+	}
+	if kind == "" {
+		return nil
+	}
+
+	var primary TranscriptSink
+	switch kind {
+	case "http":
+		if fw == "" {
+			logging.Warn("transcript sink: TRANSCRIPT_SINK=http requires TEXT_FORWARD_URL, disabling sink")
+			return nil
+		}
+		primary = NewHTTPTranscriptSink(fw)
+	case "kafka":
+		brokers := strings.Split(os.Getenv("KAFKA_BROKERS"), ",")
+		for i := range brokers {
+			brokers[i] = strings.TrimSpace(brokers[i])
+		}
+		sink, err := NewKafkaTranscriptSink(brokers, os.Getenv("KAFKA_TOPIC"))
+		if err != nil {
+			logging.Warn("transcript sink: kafka producer unavailable, events will be WAL-queued only", "brokers", brokers, "topic", os.Getenv("KAFKA_TOPIC"), "err", err)
+			primary = NewWALTranscriptSink(transcriptWALPath(saveAudioDir))
+		} else {
+			primary = sink
+		}
+	case "nats":
+		sink, err := NewNATSTranscriptSink(os.Getenv("NATS_URL"), os.Getenv("NATS_SUBJECT"))
+		if err != nil {
+			logging.Warn("transcript sink: nats connection unavailable, events will be WAL-queued only", "url", os.Getenv("NATS_URL"), "subject", os.Getenv("NATS_SUBJECT"), "err", err)
+			primary = NewWALTranscriptSink(transcriptWALPath(saveAudioDir))
+		} else {
+			primary = sink
+		}
+	case "wal":
+		primary = NewWALTranscriptSink(transcriptWALPath(saveAudioDir))
+	default:
+		logging.Warn("transcript sink: unknown TRANSCRIPT_SINK, disabling sink", "requested", kind)
+		return nil
+	}
+
+	return newRetryingSink(primary, transcriptWALPath(saveAudioDir))
+}
+
+// transcriptWALPath picks where retryingSink (and TRANSCRIPT_SINK=wal
+// itself) persist queued events: TRANSCRIPT_SINK_WAL_PATH if set, else
+// alongside saved audio, else the working directory.
+func transcriptWALPath(saveAudioDir string) string {
+	if v := strings.TrimSpace(os.Getenv("TRANSCRIPT_SINK_WAL_PATH")); v != "" {
+		return v
+	}
+	if saveAudioDir != "" {
+		return strings.TrimRight(saveAudioDir, "/") + "/.transcript_sink.wal"
+	}
+	return "transcript_sink.wal"
+}
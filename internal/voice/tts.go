@@ -1,6 +1,8 @@
 package voice
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -12,8 +14,19 @@ import (
 	"github.com/discord-voice-lab/internal/logging"
 )
 
-// TTSClient performs text->audio synthesis using an external service and
-// optionally saves results to disk via SidecarManager.
+// streamChunkBytes is the read buffer size SynthesizeStream uses: 20ms of
+// 48kHz mono 16-bit PCM, matching Speaker's speakerFrameSize so a caller can
+// forward each chunk straight into EnqueuePlayback without re-buffering.
+const streamChunkBytes = speakerFrameSize * 2
+
+// TTSClient performs low-latency streaming text->audio synthesis against a
+// raw HTTP TTS endpoint (see SynthesizeStream) and optionally saves results
+// to disk via SidecarManager. Whole-reply (non-streaming) synthesis goes
+// through the tts.Provider registry instead (ttsProviderRegistryFromEnv,
+// routed per-user by Processor.ttsRouter via synthesizeOnce): that registry
+// already covers the plain HTTP/Piper-server backend this type used to
+// duplicate with its own SynthesizeAndSave method, so TTSClient's role is
+// now just the streaming path none of the Provider implementations support.
 type TTSClient struct {
 	URL       string
 	AuthToken string
@@ -23,56 +36,181 @@ type TTSClient struct {
 	TimeoutMs int
 }
 
-// SynthesizeAndSave sends text to the TTS URL, saves returned audio to disk
-// if SaveDir is set, and updates the sidecar via SidecarManager. It returns
-// the saved filename on success or an error.
-func (t *TTSClient) SynthesizeAndSave(text string, ssrc uint32, correlationID string) (string, error) {
+// writeAudioFileAtomically writes data under dir as
+// "<timestamp>_ssrc<ssrc>_tts<ext>" using the tmp+rename pattern every audio
+// write in this package uses, returning the final filename. The one place
+// synthesized audio is written to disk regardless of which TTS backend
+// produced it - TTSClient's direct stream here or a tts.Provider routed
+// through Processor.synthesizeOnce. Sidecar merging is deliberately left to
+// the caller: different synthesis paths update different sidecar shapes (a
+// single tts_wav_path for a one-shot reply vs. an appended tts_wav_paths
+// entry per streamed sentence), so there's no one merge this helper could
+// perform on their behalf. Returns ("", nil) if dir is empty (saving
+// disabled, not itself an error to the caller).
+func writeAudioFileAtomically(dir string, ssrc uint32, ext string, data []byte) (string, error) {
+	if dir == "" {
+		return "", nil
+	}
+	ts := time.Now().UTC().Format("20060102T150405.000Z")
+	fname := fmt.Sprintf("%s/%s_ssrc%d_tts%s", strings.TrimRight(dir, "/"), ts, ssrc, ext)
+	tmp := fname + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return "", fmt.Errorf("write tmp file %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, fname); err != nil {
+		_ = os.Remove(tmp)
+		return "", fmt.Errorf("rename tmp file %s to %s: %w", tmp, fname, err)
+	}
+	return fname, nil
+}
+
+// saveAudioAtomically writes data via writeAudioFileAtomically, then merges
+// tts_wav_path/tts_saved_utc into correlationID's sidecar if sidecar is
+// non-nil. Used by TTSClient's streaming path, whose one-shot-reply sidecar
+// shape matches this merge.
+func saveAudioAtomically(dir string, ssrc uint32, ext string, data []byte, sidecar *SidecarManager, correlationID string) (string, error) {
+	fname, err := writeAudioFileAtomically(dir, ssrc, ext, data)
+	if err != nil {
+		logging.Debug("tts: failed to save audio", "err", err, "correlation_id", correlationID)
+		return "", err
+	}
+	if fname == "" {
+		return "", nil
+	}
+	logging.Info("tts: saved audio to disk", "path", fname, "correlation_id", correlationID)
+	if correlationID != "" && sidecar != nil {
+		_ = sidecar.MergeUpdatesForCID(correlationID, map[string]interface{}{
+			"tts_wav_path":  fname,
+			"tts_saved_utc": time.Now().UTC().Format(time.RFC3339Nano),
+		})
+	}
+	return fname, nil
+}
+
+// SynthesizeStream requests raw PCM (Accept: audio/pcm; rate=48000) and hands
+// back a channel of chunks as they're read off the response body, instead of
+// buffering the whole reply before returning anything, so a caller can start
+// forwarding audio into Speaker.EnqueuePlayback well before the TTS backend
+// has finished generating the rest of the sentence. The full stream is still
+// persisted to disk atomically once it ends, via writeAudioFileAtomically,
+// just deferred to end-of-stream instead of up-front.
+//
+// ctx governs the whole request/stream and is the barge-in hook: canceling
+// it (e.g. because a new utterance was detected mid-playback) closes the
+// response body, stops the stream early, and records a tts_cancelled_utc
+// sidecar update instead of tts_wav_path/tts_saved_utc.
+func (t *TTSClient) SynthesizeStream(ctx context.Context, text string, ssrc uint32, correlationID string) (<-chan []byte, error) {
 	if t == nil || t.URL == "" {
-		return "", fmt.Errorf("tts client not configured")
+		return nil, fmt.Errorf("tts client not configured")
 	}
-	b2, _ := json.Marshal(map[string]string{"text": text})
 	timeout := 10000
 	if t.TimeoutMs > 0 {
 		timeout = t.TimeoutMs
 	}
-	resp, err := PostWithRetries(t.Client, t.URL, b2, t.AuthToken, timeout, 2, correlationID)
+	// streamCtx's deadline bounds the whole request+stream; its cancel
+	// composes naturally with barge-in (ctx is the caller's, cancelled
+	// separately on a new utterance), whichever fires first wins.
+	streamCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Millisecond)
+	b2, _ := json.Marshal(map[string]string{"text": text})
+	req, err := http.NewRequestWithContext(streamCtx, "POST", t.URL, bytes.NewReader(b2))
 	if err != nil {
-		logging.Debugw("tts: POST failed", "err", err, "correlation_id", correlationID)
-		return "", err
+		cancel()
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "audio/pcm; rate=48000")
+	if t.AuthToken != "" {
+		req.Header.Set("Authorization", "Bearer "+t.AuthToken)
+	}
+	client := t.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		cancel()
+		logging.Debug("tts: stream POST failed", "err", err, "correlation_id", correlationID)
+		return nil, err
 	}
-	defer resp.Body.Close()
 	if resp.StatusCode >= 300 {
 		_, _ = io.ReadAll(resp.Body)
-		logging.Warnw("tts: returned non-2xx", "status", resp.StatusCode, "correlation_id", correlationID)
-		return "", fmt.Errorf("tts returned status %d", resp.StatusCode)
+		resp.Body.Close()
+		cancel()
+		logging.Warn("tts: stream returned non-2xx", "status", resp.StatusCode, "correlation_id", correlationID)
+		return nil, fmt.Errorf("tts returned status %d", resp.StatusCode)
 	}
-	audioBytes, rerr := io.ReadAll(resp.Body)
-	if rerr != nil {
-		logging.Debugw("tts: failed to read response body", "err", rerr, "correlation_id", correlationID)
-		return "", rerr
+
+	out := make(chan []byte, 4)
+	go func() {
+		defer cancel()
+		t.streamToChannel(streamCtx, resp.Body, out, ssrc, correlationID)
+	}()
+	return out, nil
+}
+
+// streamToChannel reads body in streamChunkBytes-sized chunks, forwarding
+// each to out as it arrives while also buffering the raw stream so it can
+// be written to disk once reading stops — on EOF, a read error, or ctx
+// being canceled mid-stream (barge-in). Always closes out and body before
+// returning.
+func (t *TTSClient) streamToChannel(ctx context.Context, body io.ReadCloser, out chan<- []byte, ssrc uint32, correlationID string) {
+	defer close(out)
+	defer body.Close()
+
+	var full bytes.Buffer
+	buf := make([]byte, streamChunkBytes)
+	cancelled := false
+readLoop:
+	for {
+		select {
+		case <-ctx.Done():
+			cancelled = true
+			break readLoop
+		default:
+		}
+		n, err := body.Read(buf)
+		if n > 0 {
+			chunk := make([]byte, n)
+			copy(chunk, buf[:n])
+			full.Write(chunk)
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				cancelled = true
+				break readLoop
+			}
+		}
+		if err != nil {
+			if err != io.EOF {
+				logging.Debug("tts: stream read failed", "err", err, "correlation_id", correlationID)
+			}
+			break
+		}
 	}
-	if t.SaveDir == "" {
-		return "", nil
+	// A ctx cancellation mid-Read surfaces as a Read error (not io.EOF), not
+	// as one of the two select branches above catching ctx.Done() directly —
+	// so cancelled alone would miss it. ctx.Err() is non-nil for exactly
+	// those same two branches too, so checking it here covers all three
+	// cancellation paths uniformly.
+	if ctx.Err() != nil {
+		cancelled = true
 	}
-	tsTs := time.Now().UTC().Format("20060102T150405.000Z")
-	base := fmt.Sprintf("%s/%s_ssrc%d_tts", strings.TrimRight(t.SaveDir, "/"), tsTs, ssrc)
-	fname := base + ".wav"
-	tmp := fname + ".tmp"
-	if err := os.WriteFile(tmp, audioBytes, 0o644); err != nil {
-		logging.Debugw("tts: failed to write tmp file", "err", err, "path", tmp, "correlation_id", correlationID)
-		return "", err
-	}
-	if err := os.Rename(tmp, fname); err != nil {
-		logging.Debugw("tts: failed to rename tmp file", "err", err, "tmp", tmp, "final", fname, "correlation_id", correlationID)
-		_ = os.Remove(tmp)
-		return "", err
+
+	if correlationID == "" || t.Sidecar == nil {
+		return
 	}
-	logging.Infow("tts: saved audio to disk", "path", fname, "correlation_id", correlationID)
-	if correlationID != "" && t.Sidecar != nil {
+	if cancelled {
+		logging.Info("tts: stream cancelled (barge-in)", "correlation_id", correlationID, "ssrc", ssrc)
 		_ = t.Sidecar.MergeUpdatesForCID(correlationID, map[string]interface{}{
-			"tts_wav_path":  fname,
-			"tts_saved_utc": time.Now().UTC().Format(time.RFC3339Nano),
+			"tts_cancelled_utc": time.Now().UTC().Format(time.RFC3339Nano),
 		})
+		return
+	}
+	if t.SaveDir == "" || full.Len() == 0 {
+		return
+	}
+	wav := buildWAV(full.Bytes(), speakerSampleRate, speakerChannels, 16)
+	if _, err := saveAudioAtomically(t.SaveDir, ssrc, ".wav", wav, t.Sidecar, correlationID); err != nil {
+		logging.Debug("tts: failed to save streamed audio", "err", err, "correlation_id", correlationID)
 	}
-	return fname, nil
 }
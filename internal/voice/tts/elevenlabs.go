@@ -0,0 +1,86 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// elevenLabsDefaultURL is used when Config.URL is empty; Config.Voice
+// selects the voice ID path segment.
+const elevenLabsDefaultURL = "https://api.elevenlabs.io/v1/text-to-speech/"
+
+// ElevenLabsProvider synthesizes speech via the ElevenLabs text-to-speech
+// API, which returns audio/mpeg (MP3) rather than WAV.
+type ElevenLabsProvider struct {
+	Config Config
+	Client *http.Client
+}
+
+// NewElevenLabsProvider builds an ElevenLabsProvider. client == nil uses a
+// Config.timeout()-bounded default client.
+func NewElevenLabsProvider(cfg Config, client *http.Client) *ElevenLabsProvider {
+	if client == nil {
+		client = &http.Client{Timeout: cfg.timeout()}
+	}
+	return &ElevenLabsProvider{Config: cfg, Client: client}
+}
+
+func (p *ElevenLabsProvider) Name() string { return "elevenlabs" }
+
+func (p *ElevenLabsProvider) Synthesize(ctx context.Context, text, voice string) ([]byte, string, error) {
+	if voice == "" {
+		voice = p.Config.Voice
+	}
+	if voice == "" {
+		return nil, "", fmt.Errorf("elevenlabs: no voice ID configured")
+	}
+	url := p.Config.URL
+	if url == "" {
+		url = elevenLabsDefaultURL + voice
+	}
+	payload := map[string]interface{}{
+		"text":     text,
+		"model_id": "eleven_monolingual_v1",
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, "", fmt.Errorf("elevenlabs: marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(b))
+	if err != nil {
+		return nil, "", fmt.Errorf("elevenlabs: new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "audio/mpeg")
+	if p.Config.APIKey != "" {
+		req.Header.Set("xi-api-key", p.Config.APIKey)
+	}
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("elevenlabs: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	audio, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("elevenlabs: read response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, "", fmt.Errorf("elevenlabs: status %d: %s", resp.StatusCode, truncate(audio, 500))
+	}
+	mimeType := resp.Header.Get("Content-Type")
+	if mimeType == "" {
+		mimeType = "audio/mpeg"
+	}
+	return audio, mimeType, nil
+}
+
+func truncate(b []byte, n int) string {
+	if len(b) <= n {
+		return string(b)
+	}
+	return string(b[:n]) + "..."
+}
@@ -0,0 +1,82 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// openaiDefaultURL is used when Config.URL is empty.
+const openaiDefaultURL = "https://api.openai.com/v1/audio/speech"
+
+// OpenAIProvider synthesizes speech via OpenAI's /v1/audio/speech endpoint,
+// which defaults to returning audio/mpeg (MP3).
+type OpenAIProvider struct {
+	Config Config
+	Client *http.Client
+	Model  string
+}
+
+// NewOpenAIProvider builds an OpenAIProvider. client == nil uses a
+// Config.timeout()-bounded default client; model == "" defaults to "tts-1".
+func NewOpenAIProvider(cfg Config, client *http.Client, model string) *OpenAIProvider {
+	if client == nil {
+		client = &http.Client{Timeout: cfg.timeout()}
+	}
+	if model == "" {
+		model = "tts-1"
+	}
+	return &OpenAIProvider{Config: cfg, Client: client, Model: model}
+}
+
+func (p *OpenAIProvider) Name() string { return "openai" }
+
+func (p *OpenAIProvider) Synthesize(ctx context.Context, text, voice string) ([]byte, string, error) {
+	if voice == "" {
+		voice = p.Config.Voice
+	}
+	if voice == "" {
+		voice = "alloy"
+	}
+	url := p.Config.URL
+	if url == "" {
+		url = openaiDefaultURL
+	}
+	payload := map[string]string{
+		"model": p.Model,
+		"input": text,
+		"voice": voice,
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, "", fmt.Errorf("openai tts: marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(b))
+	if err != nil {
+		return nil, "", fmt.Errorf("openai tts: new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.Config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.Config.APIKey)
+	}
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("openai tts: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	audio, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("openai tts: read response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, "", fmt.Errorf("openai tts: status %d: %s", resp.StatusCode, truncate(audio, 500))
+	}
+	mimeType := resp.Header.Get("Content-Type")
+	if mimeType == "" {
+		mimeType = "audio/mpeg"
+	}
+	return audio, mimeType, nil
+}
@@ -0,0 +1,68 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// PiperProvider synthesizes speech via a Piper HTTP server (the same plain
+// {"text": "..."} POST voice.Processor used to send to TTS_URL before this
+// package existed). Piper always returns raw WAV.
+type PiperProvider struct {
+	Config Config
+	Client *http.Client
+}
+
+// NewPiperProvider builds a PiperProvider. client == nil uses a
+// Config.timeout()-bounded default client.
+func NewPiperProvider(cfg Config, client *http.Client) *PiperProvider {
+	if client == nil {
+		client = &http.Client{Timeout: cfg.timeout()}
+	}
+	return &PiperProvider{Config: cfg, Client: client}
+}
+
+func (p *PiperProvider) Name() string { return "piper" }
+
+func (p *PiperProvider) Synthesize(ctx context.Context, text, voice string) ([]byte, string, error) {
+	if voice == "" {
+		voice = p.Config.Voice
+	}
+	payload := map[string]string{"text": text}
+	if voice != "" {
+		payload["voice"] = voice
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return nil, "", fmt.Errorf("piper: marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", p.Config.URL, bytes.NewReader(b))
+	if err != nil {
+		return nil, "", fmt.Errorf("piper: new request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.Config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.Config.APIKey)
+	}
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("piper: request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	audio, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("piper: read response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, "", fmt.Errorf("piper: status %d", resp.StatusCode)
+	}
+	mimeType := resp.Header.Get("Content-Type")
+	if mimeType == "" {
+		mimeType = "audio/wav"
+	}
+	return audio, mimeType, nil
+}
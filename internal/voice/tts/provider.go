@@ -0,0 +1,55 @@
+// Package tts defines a pluggable text-to-speech backend abstraction used by
+// voice.Processor. Each backend implementation is responsible only for
+// turning text into audio bytes and reporting the audio's MIME type;
+// retry/backoff and sidecar persistence stay in the caller, same split as
+// internal/voice/stt and internal/voice/llm.
+package tts
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Provider synthesizes speech audio for text. Implementations should not
+// block longer than necessary and should honor ctx cancellation.
+type Provider interface {
+	Name() string
+	Synthesize(ctx context.Context, text, voice string) (audio []byte, mimeType string, err error)
+}
+
+// Config holds the subset of environment-driven settings shared across
+// backends. Individual backends may read additional env vars of their own.
+type Config struct {
+	URL       string
+	APIKey    string
+	Voice     string
+	TimeoutMs int
+}
+
+func (c Config) timeout() time.Duration {
+	if c.TimeoutMs <= 0 {
+		return 10 * time.Second
+	}
+	return time.Duration(c.TimeoutMs) * time.Millisecond
+}
+
+// ExtensionForMIME maps a synthesized audio MIME type to the file extension
+// voice.Processor should save it under, falling back to .wav (the
+// historical default, before any backend reported its own MIME type) for
+// anything unrecognized.
+func ExtensionForMIME(mimeType string) string {
+	if i := strings.IndexByte(mimeType, ';'); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	switch strings.ToLower(strings.TrimSpace(mimeType)) {
+	case "audio/mpeg", "audio/mp3":
+		return ".mp3"
+	case "audio/ogg":
+		return ".ogg"
+	case "audio/wav", "audio/x-wav", "audio/wave":
+		return ".wav"
+	default:
+		return ".wav"
+	}
+}
@@ -0,0 +1,53 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// SubprocessProvider synthesizes speech by shelling out to a local
+// command-line TTS engine (e.g. Piper's CLI or espeak-ng) instead of talking
+// to an HTTP server: text is written to the process's stdin and the
+// resulting audio is read back from its stdout. Useful for deployments that
+// want synthesis to work without any TTS backend reachable over the network.
+type SubprocessProvider struct {
+	// Command is the executable to run, e.g. "piper" or "espeak-ng".
+	Command string
+	// Args are passed to Command as-is. Synthesize doesn't substitute text
+	// into Args; text always goes in via stdin, since both Piper's
+	// `--output_file -` mode and espeak-ng's default mode read the utterance
+	// that way and write audio to stdout.
+	Args []string
+}
+
+// NewSubprocessProvider builds a SubprocessProvider. args == nil runs
+// Command with no arguments.
+func NewSubprocessProvider(command string, args []string) *SubprocessProvider {
+	return &SubprocessProvider{Command: command, Args: args}
+}
+
+func (p *SubprocessProvider) Name() string { return "piper-local" }
+
+// Synthesize ignores voice: unlike the HTTP-backed providers, a subprocess
+// engine's voice selection is baked into Args (e.g. Piper's --model flag) at
+// registration time rather than passed per-call.
+func (p *SubprocessProvider) Synthesize(ctx context.Context, text, voice string) ([]byte, string, error) {
+	if p.Command == "" {
+		return nil, "", fmt.Errorf("piper-local: no command configured")
+	}
+	cmd := exec.CommandContext(ctx, p.Command, p.Args...)
+	cmd.Stdin = strings.NewReader(text)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, "", fmt.Errorf("piper-local: %s: %w: %s", p.Command, err, strings.TrimSpace(stderr.String()))
+	}
+	if stdout.Len() == 0 {
+		return nil, "", fmt.Errorf("piper-local: %s produced no audio", p.Command)
+	}
+	return stdout.Bytes(), "audio/wav", nil
+}
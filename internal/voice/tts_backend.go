@@ -0,0 +1,84 @@
+package voice
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/discord-voice-lab/internal/voice/tts"
+)
+
+// ttsProviderRegistryFromEnv builds the set of tts.Provider backends a
+// Processor can route synthesis to, keyed by provider name. "piper" (the
+// default, built from the existing TTS_URL/TTS_AUTH_TOKEN env vars every
+// prior chunk already relies on) is always registered so routing overrides
+// always have a fallback to land on.
+func ttsProviderRegistryFromEnv() map[string]tts.Provider {
+	cfg := tts.Config{
+		URL:       os.Getenv("TTS_URL"),
+		APIKey:    os.Getenv("TTS_AUTH_TOKEN"),
+		Voice:     os.Getenv("TTS_VOICE"),
+		TimeoutMs: 10000,
+	}
+	if v := os.Getenv("TTS_TIMEOUT_MS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.TimeoutMs = n
+		}
+	}
+	reg := map[string]tts.Provider{
+		"piper": tts.NewPiperProvider(cfg, nil),
+	}
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("TTS_PROVIDER"))) {
+	case "", "piper":
+		// already registered above
+	case "elevenlabs":
+		elevenCfg := cfg
+		if k := os.Getenv("ELEVENLABS_API_KEY"); k != "" {
+			elevenCfg.APIKey = k
+		}
+		reg["elevenlabs"] = tts.NewElevenLabsProvider(elevenCfg, nil)
+	case "openai":
+		openaiCfg := cfg
+		if k := os.Getenv("OPENAI_API_KEY"); k != "" {
+			openaiCfg.APIKey = k
+		}
+		reg["openai"] = tts.NewOpenAIProvider(openaiCfg, nil, os.Getenv("OPENAI_TTS_MODEL"))
+	case "piper-local":
+		// Registered under "piper-local" rather than "piper": that name is
+		// already taken by the HTTP-backed Piper-server provider above,
+		// which every prior chunk's TTS_URL/TTS_AUTH_TOKEN env vars target,
+		// and renaming it out from under those would be a breaking change
+		// for existing deployments.
+		reg["piper-local"] = tts.NewSubprocessProvider(subprocessCommandFromEnv())
+	}
+	return reg
+}
+
+// subprocessCommandFromEnv reads the local TTS command and its arguments for
+// the "piper-local" provider: TTS_SUBPROCESS_CMD (default "piper"), with
+// TTS_SUBPROCESS_ARGS split on whitespace (e.g. "--model en_US-amy-medium
+// --output_file -").
+func subprocessCommandFromEnv() (string, []string) {
+	cmd := strings.TrimSpace(os.Getenv("TTS_SUBPROCESS_CMD"))
+	if cmd == "" {
+		cmd = "piper"
+	}
+	var args []string
+	if raw := strings.TrimSpace(os.Getenv("TTS_SUBPROCESS_ARGS")); raw != "" {
+		args = strings.Fields(raw)
+	}
+	return cmd, args
+}
+
+// defaultTTSProviderFromEnv reports which registry key ttsRouter should
+// fall back to when no per-user override applies: TTS_PROVIDER if it named
+// a known backend, else "piper" to match pre-chunk4-2 behavior.
+func defaultTTSProviderFromEnv() string {
+	name := strings.ToLower(strings.TrimSpace(os.Getenv("TTS_PROVIDER")))
+	switch name {
+	case "elevenlabs", "openai", "piper-local":
+		return name
+	default:
+		return "piper"
+	}
+}
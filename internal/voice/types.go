@@ -2,6 +2,8 @@ package voice
 
 import (
 	"time"
+
+	"github.com/discord-voice-lab/internal/voice/vad"
 )
 
 // pcmAccum holds accumulated PCM samples for an SSRC and timestamp of last append
@@ -13,6 +15,14 @@ type pcmAccum struct {
 	createdAt     time.Time
 	userID        string
 	username      string
+	// vadGate tracks the speech/silence state machine for this accumulator;
+	// created lazily on first append so Processor.vadBackend can be nil in
+	// older configurations that only set vadRmsThreshold.
+	vadGate *vad.Gate
+	// vadStartUTC/vadStopUTC record the most recent utterance start/stop
+	// edges observed by vadGate, persisted into the sidecar JSON on flush.
+	vadStartUTC string
+	vadStopUTC  string
 }
 
 // transcriptAgg holds an aggregated transcript for an SSRC and timestamp of last update
@@ -0,0 +1,75 @@
+package voice
+
+import (
+	"os"
+	"strings"
+
+	"github.com/discord-voice-lab/internal/logging"
+	"github.com/discord-voice-lab/internal/voice/llm"
+)
+
+// userTokenStoreFromEnv builds the llm.UserTokenStore backing per-user
+// orchestrator bearer tokens in place of a single shared ORCH_AUTH_TOKEN.
+// ORCH_TOKEN_STORE_BACKEND selects which backend(s) to consult, in order,
+// before falling through to ORCH_AUTH_TOKEN: "env" (ORCH_TOKEN_<userid> env
+// vars, the default) and/or "file" (a JSON {userid: token} object named by
+// ORCH_TOKEN_FILE), comma-separated to enable both at once. The returned
+// store is always usable even with neither backend configured - it just
+// falls straight through to ORCH_AUTH_TOKEN for every user, the same
+// single-shared-token behavior this package had before.
+func userTokenStoreFromEnv() llm.UserTokenStore {
+	raw := strings.TrimSpace(os.Getenv("ORCH_TOKEN_STORE_BACKEND"))
+	backends := []string{"env"}
+	if raw != "" {
+		backends = strings.Split(raw, ",")
+	}
+	var stores []llm.UserTokenStore
+	for _, b := range backends {
+		switch strings.ToLower(strings.TrimSpace(b)) {
+		case "env":
+			stores = append(stores, llm.EnvUserTokenStore{Prefix: "ORCH_TOKEN_"})
+		case "file":
+			path := os.Getenv("ORCH_TOKEN_FILE")
+			if path == "" {
+				logging.Warn("orchestrator: ORCH_TOKEN_STORE_BACKEND names file but ORCH_TOKEN_FILE is unset, skipping")
+				continue
+			}
+			store, err := llm.NewFileUserTokenStore(path)
+			if err != nil {
+				logging.Warn("orchestrator: failed to load user token file", "path", path, "err", err)
+				continue
+			}
+			stores = append(stores, store)
+		case "":
+			// empty entry from a trailing/doubled comma; ignore
+		default:
+			logging.Warn("orchestrator: unknown ORCH_TOKEN_STORE_BACKEND entry, ignoring", "backend", b)
+		}
+	}
+	return llm.FallbackUserTokenStore{Stores: stores, Default: os.Getenv("ORCH_AUTH_TOKEN")}
+}
+
+// orchestratorAuthToken resolves uid's per-user bearer token via
+// p.userTokenStore, falling through to ORCH_AUTH_TOKEN the same way
+// FallbackUserTokenStore's own Default already does.
+func (p *Processor) orchestratorAuthToken(uid string) string {
+	tok, _ := p.userTokenStore.Token(p.ctx, uid)
+	return tok
+}
+
+// orchestratorOriginHeaders returns the header set identifying which
+// Discord guild/channel/user a forwarded utterance originated from, so a
+// downstream orchestrator can apply per-guild/per-channel/per-user policy
+// and log real caller identity instead of a single shared service account.
+// guildID/channelID are omitted when unset (e.g. SetEventRouter/
+// SetChannelID were never called for this Processor); uid is always set.
+func orchestratorOriginHeaders(guildID, channelID, uid string) map[string]string {
+	h := map[string]string{"X-Discord-User-Id": uid}
+	if guildID != "" {
+		h["X-Discord-Guild-Id"] = guildID
+	}
+	if channelID != "" {
+		h["X-Discord-Channel-Id"] = channelID
+	}
+	return h
+}
@@ -0,0 +1,93 @@
+package vad
+
+import (
+	"os"
+	"strconv"
+)
+
+// Denoiser performs per-frame noise suppression and reports a
+// voice-activity probability in [0,1] for that frame — the richer signal
+// RNNoise's rnnoise_process_frame exposes over a plain speech/non-speech
+// Backend. frame is RNNoise's native 480-sample (10ms at 48kHz) size;
+// callers chunk larger frames (e.g. a 960-sample Opus frame) before calling.
+type Denoiser interface {
+	Name() string
+	// ProcessFrame denoises frame in place and returns its voice-activity
+	// probability.
+	ProcessFrame(frame []int16) float64
+}
+
+// DenoiserConfig tunes the hangover gate built around a Denoiser's
+// per-frame voice_prob, driven by VAD_SPEECH_ON/VAD_SPEECH_OFF/
+// VAD_HANGOVER_FRAMES.
+type DenoiserConfig struct {
+	// SpeechOn is the voice_prob threshold that opens the gate.
+	SpeechOn float64
+	// SpeechOff is the voice_prob threshold consecutive frames must stay
+	// below before the gate closes, so a brief dip mid-utterance doesn't
+	// chop it the way a single-frame threshold crossing would.
+	SpeechOff float64
+	// HangoverFrames is how many consecutive sub-SpeechOff frames are
+	// required before the gate closes.
+	HangoverFrames int
+}
+
+// DenoiserConfigFromEnv builds a DenoiserConfig from VAD_SPEECH_ON,
+// VAD_SPEECH_OFF and VAD_HANGOVER_FRAMES, defaulting to 0.6/0.35/10 (the
+// values chunk2-2 was requested against).
+func DenoiserConfigFromEnv() DenoiserConfig {
+	cfg := DenoiserConfig{SpeechOn: 0.6, SpeechOff: 0.35, HangoverFrames: 10}
+	if v := os.Getenv("VAD_SPEECH_ON"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f > 0 && f <= 1 {
+			cfg.SpeechOn = f
+		}
+	}
+	if v := os.Getenv("VAD_SPEECH_OFF"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 && f < 1 {
+			cfg.SpeechOff = f
+		}
+	}
+	if v := os.Getenv("VAD_HANGOVER_FRAMES"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.HangoverFrames = n
+		}
+	}
+	return cfg
+}
+
+// DenoiserGate turns a stream of per-frame voice_prob values into an
+// open/closed speech gate using separate on/off thresholds, the
+// probability-keyed analogue of Gate's time-keyed MinSpeechMs/HangoverMs.
+// Not safe for concurrent use; one Gate is owned per accumulator.
+type DenoiserGate struct {
+	cfg           DenoiserConfig
+	active        bool
+	belowOffCount int
+}
+
+// NewDenoiserGate returns a DenoiserGate configured with cfg.
+func NewDenoiserGate(cfg DenoiserConfig) *DenoiserGate {
+	return &DenoiserGate{cfg: cfg}
+}
+
+// Observe feeds one frame's voice_prob and returns whether the gate is
+// open after observing it.
+func (g *DenoiserGate) Observe(voiceProb float64) bool {
+	if !g.active {
+		if voiceProb > g.cfg.SpeechOn {
+			g.active = true
+			g.belowOffCount = 0
+		}
+		return g.active
+	}
+	if voiceProb < g.cfg.SpeechOff {
+		g.belowOffCount++
+		if g.belowOffCount >= g.cfg.HangoverFrames {
+			g.active = false
+			g.belowOffCount = 0
+		}
+	} else {
+		g.belowOffCount = 0
+	}
+	return g.active
+}
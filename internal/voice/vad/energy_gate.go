@@ -0,0 +1,106 @@
+package vad
+
+import (
+	"math"
+	"os"
+	"strconv"
+)
+
+// EnergyGateConfig tunes EnergyGate's two-threshold (enter/exit) amplitude
+// hysteresis: a frame's RMS must cross EnterRMS to start a speech run, and
+// must then stay at or below the lower ExitRMS for HangoverMs of
+// accumulated frame time before the gate reports speech has stopped. The
+// gap between EnterRMS and ExitRMS absorbs energy hovering right at a
+// single bare threshold, which otherwise flaps start/stop on every frame.
+type EnergyGateConfig struct {
+	EnterRMS   int
+	ExitRMS    int
+	HangoverMs int
+}
+
+// EnergyGateConfigFromEnv builds an EnergyGateConfig from
+// VAD_ENERGY_ENTER_RMS, VAD_ENERGY_EXIT_RMS and VAD_ENERGY_HANGOVER_MS.
+// Defaults mirror VAD_RMS_THRESHOLD's default (110) for EnterRMS, roughly
+// half that for ExitRMS, and 300ms hangover.
+func EnergyGateConfigFromEnv() EnergyGateConfig {
+	cfg := EnergyGateConfig{EnterRMS: 110, ExitRMS: 55, HangoverMs: 300}
+	if v := os.Getenv("VAD_ENERGY_ENTER_RMS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.EnterRMS = n
+		}
+	}
+	if v := os.Getenv("VAD_ENERGY_EXIT_RMS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.ExitRMS = n
+		}
+	}
+	if v := os.Getenv("VAD_ENERGY_HANGOVER_MS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.HangoverMs = n
+		}
+	}
+	return cfg
+}
+
+// EnergyGate is a per-accumulator two-threshold endpoint detector over raw
+// PCM energy, mirroring DenoiserGate's SpeechOn/SpeechOff hysteresis but
+// driven by RMS instead of a denoiser's voice_prob. One EnergyGate is owned
+// per accumulator; it is not safe for concurrent use.
+type EnergyGate struct {
+	cfg     EnergyGateConfig
+	active  bool
+	belowMs int
+}
+
+// NewEnergyGate returns an EnergyGate configured with cfg.
+func NewEnergyGate(cfg EnergyGateConfig) *EnergyGate {
+	return &EnergyGate{cfg: cfg}
+}
+
+// Observe feeds one frameMs-long frame and returns whichever start/stop
+// edge, if any, just fired, alongside a [0,1] probability derived from the
+// frame's RMS relative to EnterRMS (for parity with Backend.IsSpeech's
+// second return value).
+func (g *EnergyGate) Observe(frame []int16, frameMs int) (Event, float64) {
+	if len(frame) == 0 {
+		return EventNone, 0
+	}
+	var sumSq int64
+	for _, s := range frame {
+		v := int64(s)
+		sumSq += v * v
+	}
+	rms := int(math.Sqrt(float64(sumSq / int64(len(frame)))))
+	prob := float64(rms) / float64(2*g.cfg.EnterRMS)
+	if prob > 1 {
+		prob = 1
+	}
+
+	wasActive := g.active
+	if !g.active {
+		if rms >= g.cfg.EnterRMS {
+			g.active = true
+			g.belowMs = 0
+		}
+	} else if rms <= g.cfg.ExitRMS {
+		g.belowMs += frameMs
+		if g.belowMs >= g.cfg.HangoverMs {
+			g.active = false
+			g.belowMs = 0
+		}
+	} else {
+		g.belowMs = 0
+	}
+
+	switch {
+	case !wasActive && g.active:
+		return EventStart, prob
+	case wasActive && !g.active:
+		return EventStop, prob
+	default:
+		return EventNone, prob
+	}
+}
+
+// Active reports whether the gate currently considers speech in progress.
+func (g *EnergyGate) Active() bool { return g.active }
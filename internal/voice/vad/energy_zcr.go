@@ -0,0 +1,48 @@
+package vad
+
+import "math"
+
+// EnergyZCRBackend combines short-term energy with zero-crossing rate, a
+// lightweight heuristic closer to WebRTC VAD's behavior than a bare RMS
+// gate. It is selected by VAD_BACKEND=energy_zcr; VAD_BACKEND=webrtc builds
+// a WebRTCVADBackend that applies the same heuristic, and VAD_BACKEND=silero
+// builds a SileroBackend.
+type EnergyZCRBackend struct {
+	EnergyThreshold int
+	MaxZCR          float64
+}
+
+func (b *EnergyZCRBackend) Name() string { return "energy_zcr" }
+
+func (b *EnergyZCRBackend) IsSpeech(frame []int16, _ int) (bool, float64) {
+	if len(frame) < 2 {
+		return false, 0
+	}
+	var sumSq int64
+	crossings := 0
+	for i, s := range frame {
+		v := int64(s)
+		sumSq += v * v
+		if i > 0 && (frame[i-1] >= 0) != (s >= 0) {
+			crossings++
+		}
+	}
+	meanSq := sumSq / int64(len(frame))
+	rms := int(math.Sqrt(float64(meanSq)))
+	zcr := float64(crossings) / float64(len(frame))
+	// Voiced speech tends to have moderate-to-high energy with a bounded
+	// zero-crossing rate; silence lacks energy and broadband noise tends to
+	// cross zero much more often.
+	isSpeech := rms >= b.EnergyThreshold && zcr <= b.MaxZCR
+	prob := float64(rms) / float64(2*b.EnergyThreshold)
+	if zcr > b.MaxZCR {
+		prob -= (zcr - b.MaxZCR)
+	}
+	if prob > 1 {
+		prob = 1
+	}
+	if prob < 0 {
+		prob = 0
+	}
+	return isSpeech, prob
+}
@@ -0,0 +1,63 @@
+package vad
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/discord-voice-lab/internal/logging"
+)
+
+// aggressivenessThresholds mirrors webrtcvad's 0 (least aggressive) to 3
+// (most aggressive) scale by mapping it onto an RMS/energy cutoff, so
+// VAD_AGGRESSIVENESS stays meaningful for backends that don't wrap the
+// native webrtcvad library.
+var aggressivenessThresholds = [4]int{60, 110, 180, 260}
+
+// FromEnv builds a Backend and Config from VAD_BACKEND, VAD_AGGRESSIVENESS,
+// VAD_MIN_SPEECH_MS and VAD_HANGOVER_MS. legacyRMSThreshold, when positive,
+// overrides the aggressiveness-derived cutoff so existing VAD_RMS_THRESHOLD
+// deployments keep their tuned value after upgrading to the Gate-based flow.
+// VAD_BACKEND=webrtc and VAD_BACKEND=silero select WebRTCVADBackend/
+// SileroBackend, both energy-based approximations of their namesakes since
+// neither the webrtcvad cgo bindings nor an ONNX runtime for Silero is
+// vendored in this build (see their doc comments); a real binding can
+// replace either without callers or config changing.
+func FromEnv(legacyRMSThreshold int) (Backend, Config) {
+	aggr := 1
+	if v := os.Getenv("VAD_AGGRESSIVENESS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 && n <= 3 {
+			aggr = n
+		}
+	}
+	threshold := aggressivenessThresholds[aggr]
+	if legacyRMSThreshold > 0 {
+		threshold = legacyRMSThreshold
+	}
+
+	cfg := Config{MinSpeechMs: 200, HangoverMs: 500}
+	if v := os.Getenv("VAD_MIN_SPEECH_MS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.MinSpeechMs = n
+		}
+	}
+	if v := os.Getenv("VAD_HANGOVER_MS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.HangoverMs = n
+		}
+	}
+
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("VAD_BACKEND"))) {
+	case "", "rms":
+		return &RMSBackend{Threshold: threshold}, cfg
+	case "energy_zcr":
+		return &EnergyZCRBackend{EnergyThreshold: threshold, MaxZCR: 0.35}, cfg
+	case "webrtc":
+		return NewWebRTCVADBackend(aggr), cfg
+	case "silero":
+		return NewSileroBackend(os.Getenv("SILERO_MODEL_PATH"), sileroThresholdFromEnv()), cfg
+	default:
+		logging.Warn("vad: unknown VAD_BACKEND, falling back to rms", "requested", os.Getenv("VAD_BACKEND"))
+		return &RMSBackend{Threshold: threshold}, cfg
+	}
+}
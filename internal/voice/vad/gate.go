@@ -0,0 +1,58 @@
+package vad
+
+// Event reports an edge detected by Gate.Observe.
+type Event int
+
+const (
+	// EventNone means no start/stop edge fired on this frame.
+	EventNone Event = iota
+	// EventStart means accumulated speech just crossed MinSpeechMs.
+	EventStart
+	// EventStop means accumulated silence just crossed HangoverMs while the
+	// gate was active.
+	EventStop
+)
+
+// Gate turns a stream of per-frame speech/non-speech decisions into
+// utterance start/stop events, requiring N consecutive non-speech ms to
+// follow at least M speech ms (MinSpeechMs/HangoverMs) before firing an
+// edge. One Gate is owned per accumulator; it is not safe for concurrent use.
+type Gate struct {
+	cfg       Config
+	speechMs  int
+	silenceMs int
+	active    bool
+}
+
+// NewGate returns a Gate configured with cfg.
+func NewGate(cfg Config) *Gate {
+	return &Gate{cfg: cfg}
+}
+
+// Observe feeds one frame's speech decision (frameMs long) and returns
+// whichever edge, if any, just fired.
+func (g *Gate) Observe(isSpeech bool, frameMs int) Event {
+	if isSpeech {
+		g.speechMs += frameMs
+		g.silenceMs = 0
+		if !g.active && g.speechMs >= g.cfg.MinSpeechMs {
+			g.active = true
+			return EventStart
+		}
+		return EventNone
+	}
+	g.silenceMs += frameMs
+	if g.active {
+		if g.silenceMs >= g.cfg.HangoverMs {
+			g.active = false
+			g.speechMs = 0
+			return EventStop
+		}
+		return EventNone
+	}
+	g.speechMs = 0
+	return EventNone
+}
+
+// Active reports whether the gate currently considers speech in progress.
+func (g *Gate) Active() bool { return g.active }
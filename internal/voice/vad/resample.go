@@ -0,0 +1,20 @@
+package vad
+
+// downsampleTo16kHz box-filters and decimates a 48kHz frame down to 16kHz
+// (a fixed 3:1 ratio), which is the sample rate both webrtcvad and Silero's
+// published model expect. Averaging each group of 3 samples is a simple
+// polyphase low-pass filter adequate for a VAD decision; it is not a
+// high-quality resampler and should not be used for anything audible.
+func downsampleTo16kHz(frame []int16) []int16 {
+	const ratio = 3
+	n := len(frame) / ratio
+	out := make([]int16, n)
+	for i := 0; i < n; i++ {
+		var sum int32
+		for j := 0; j < ratio; j++ {
+			sum += int32(frame[i*ratio+j])
+		}
+		out[i] = int16(sum / ratio)
+	}
+	return out
+}
@@ -0,0 +1,34 @@
+package vad
+
+import "math"
+
+// RMSBackend classifies a frame as speech when its RMS amplitude meets
+// Threshold. This is the original inline heuristic the processor used
+// before VAD_BACKEND existed, kept as the default and as the fallback for
+// backends unavailable in a given build.
+type RMSBackend struct {
+	Threshold int
+}
+
+func (b *RMSBackend) Name() string { return "rms" }
+
+func (b *RMSBackend) IsSpeech(frame []int16, _ int) (bool, float64) {
+	if len(frame) == 0 {
+		return false, 0
+	}
+	var sumSq int64
+	for _, s := range frame {
+		v := int64(s)
+		sumSq += v * v
+	}
+	meanSq := sumSq / int64(len(frame))
+	rms := int(math.Sqrt(float64(meanSq)))
+	// Report how far rms sits past Threshold as a probability, so callers
+	// get a meaningful confidence value rather than just the bool; this is
+	// a ratio, not a calibrated likelihood.
+	prob := float64(rms) / float64(2*b.Threshold)
+	if prob > 1 {
+		prob = 1
+	}
+	return rms >= b.Threshold, prob
+}
@@ -0,0 +1,100 @@
+package vad
+
+import (
+	"math"
+	"os"
+
+	"github.com/discord-voice-lab/internal/logging"
+)
+
+// RNNoiseDenoiser stands in for a real librnnoise/cgo binding, which isn't
+// vendorable in this build. It approximates RNNoise's two jobs — spectral
+// noise suppression and a per-frame voice-activity probability — with an
+// adaptive noise-floor estimate: the floor tracks quiet frames via an EMA,
+// frames are suppressed toward that floor, and voice_prob is derived from
+// how far the frame's energy sits above it. This is the same
+// honest-fallback posture as EnergyZCRBackend, just modeled on energy
+// rather than zero-crossing rate.
+type RNNoiseDenoiser struct {
+	// noiseFloor is an exponential moving average of frame RMS, updated
+	// only on frames judged non-speech so transient loud speech doesn't
+	// drag the floor up.
+	noiseFloor float64
+	// floorAlpha is the EMA weight given to each new non-speech frame.
+	floorAlpha float64
+	primed     bool
+}
+
+// NewRNNoiseDenoiser returns an RNNoiseDenoiser. modelPath is accepted for
+// API compatibility with a real RNNoise model-file binding but unused by
+// this approximation.
+func NewRNNoiseDenoiser(modelPath string) (*RNNoiseDenoiser, error) {
+	if modelPath != "" {
+		logging.Warn("vad: RNNoise model path set but no native RNNoise binding is linked into this build; using the energy-floor approximation", "model_path", modelPath)
+	}
+	return &RNNoiseDenoiser{floorAlpha: 0.05}, nil
+}
+
+func (d *RNNoiseDenoiser) Name() string { return "rnnoise_approx" }
+
+// ProcessFrame suppresses frame toward the tracked noise floor in place and
+// returns a voice_prob in [0,1] derived from the frame's energy relative to
+// that floor.
+func (d *RNNoiseDenoiser) ProcessFrame(frame []int16) float64 {
+	if len(frame) == 0 {
+		return 0
+	}
+
+	var sumSq float64
+	for _, s := range frame {
+		v := float64(s)
+		sumSq += v * v
+	}
+	rms := math.Sqrt(sumSq / float64(len(frame)))
+
+	if !d.primed {
+		d.noiseFloor = rms
+		d.primed = true
+	}
+
+	// voice_prob rises as rms pulls away from the floor; a 6x-over-floor
+	// frame is treated as confidently speech.
+	voiceProb := 0.0
+	if d.noiseFloor > 1 {
+		ratio := rms / d.noiseFloor
+		voiceProb = math.Min(1, math.Max(0, (ratio-1)/5))
+	} else if rms > 50 {
+		voiceProb = 1
+	}
+
+	if voiceProb < 0.3 {
+		d.noiseFloor += d.floorAlpha * (rms - d.noiseFloor)
+	}
+
+	if d.noiseFloor > 0 && rms > 0 {
+		gain := 1 - math.Min(0.9, d.noiseFloor/rms*0.5)
+		for i, s := range frame {
+			frame[i] = int16(float64(s) * gain)
+		}
+	}
+
+	return voiceProb
+}
+
+// DenoiserFromEnv builds a Denoiser and DenoiserConfig from VAD_MODE,
+// VAD_RNNOISE_MODEL and DenoiserConfigFromEnv's variables. VAD_MODE=rnnoise
+// selects RNNoiseDenoiser; anything else (including unset) returns a nil
+// Denoiser, the caller's signal to keep using the RMS/energy_zcr Backend
+// path unchanged.
+func DenoiserFromEnv() (Denoiser, DenoiserConfig) {
+	cfg := DenoiserConfigFromEnv()
+	if os.Getenv("VAD_MODE") != "rnnoise" {
+		return nil, cfg
+	}
+	d, err := NewRNNoiseDenoiser(os.Getenv("VAD_RNNOISE_MODEL"))
+	if err != nil {
+		logging.Warn("vad: RNNoise denoiser unavailable, falling back to the RMS/energy_zcr VAD path", "err", err)
+		return nil, cfg
+	}
+	return d, cfg
+}
@@ -0,0 +1,27 @@
+// Package vad provides a pluggable voice-activity-detection abstraction so
+// the accumulator can gate STT flushes on an actual start/stop decision
+// instead of a bare per-chunk RMS threshold.
+package vad
+
+// Backend classifies a single PCM frame (typically 10/20/30ms of 48kHz
+// little-endian int16 samples) as speech or non-speech, alongside a
+// probability in [0,1] callers can record for per-utterance VAD
+// statistics (mean probability, etc.) without needing a second call.
+// Callers serialize frames per SSRC, so an implementation need not be
+// reentrant for a single accumulator, only safe to share across different
+// ones.
+type Backend interface {
+	Name() string
+	IsSpeech(frame []int16, sampleRateHz int) (bool, float64)
+}
+
+// Config holds the hangover/min-speech tuning shared by Gate, driven by
+// VAD_MIN_SPEECH_MS and VAD_HANGOVER_MS.
+type Config struct {
+	// MinSpeechMs is how much accumulated speech is required before a Gate
+	// reports an utterance as started; it suppresses single-frame blips.
+	MinSpeechMs int
+	// HangoverMs is how much accumulated non-speech must follow started
+	// speech before a Gate reports the utterance as stopped.
+	HangoverMs int
+}
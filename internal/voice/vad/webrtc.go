@@ -0,0 +1,77 @@
+package vad
+
+import "math"
+
+// WebRTCVADBackend stands in for the real webrtcvad cgo binding, which
+// isn't vendorable in this build. It reproduces webrtcvad's externally
+// visible contract — 10/20/30ms frames, downsampled to 16kHz, judged
+// against an aggressiveness level 0 (least) to 3 (most) — using the same
+// energy+zero-crossing heuristic as EnergyZCRBackend, just parameterized
+// and named to match what VAD_BACKEND=webrtc configures so swapping in a
+// real binding later is a drop-in change, not a config migration.
+type WebRTCVADBackend struct {
+	// Aggressiveness is 0-3; higher values raise EnergyThreshold and tighten
+	// MaxZCR, trading missed quiet speech for fewer noise false-positives,
+	// mirroring webrtcvad's own aggressiveness scale.
+	Aggressiveness  int
+	EnergyThreshold int
+	MaxZCR          float64
+}
+
+// NewWebRTCVADBackend builds a WebRTCVADBackend for the given aggressiveness
+// (0-3, clamped), deriving EnergyThreshold/MaxZCR from it.
+func NewWebRTCVADBackend(aggressiveness int) *WebRTCVADBackend {
+	if aggressiveness < 0 {
+		aggressiveness = 0
+	}
+	if aggressiveness > 3 {
+		aggressiveness = 3
+	}
+	// Same aggressiveness->threshold mapping FromEnv already uses for
+	// energy_zcr, so VAD_AGGRESSIVENESS means the same thing regardless of
+	// which backend ends up selected.
+	thresholds := [4]int{60, 110, 180, 260}
+	maxZCR := [4]float64{0.45, 0.4, 0.35, 0.3}
+	return &WebRTCVADBackend{
+		Aggressiveness:  aggressiveness,
+		EnergyThreshold: thresholds[aggressiveness],
+		MaxZCR:          maxZCR[aggressiveness],
+	}
+}
+
+func (b *WebRTCVADBackend) Name() string { return "webrtc_approx" }
+
+// IsSpeech downsamples frame to 16kHz (webrtcvad's native rate) when it
+// arrives at 48kHz, then applies the energy+ZCR heuristic.
+func (b *WebRTCVADBackend) IsSpeech(frame []int16, sampleRateHz int) (bool, float64) {
+	if sampleRateHz == 48000 {
+		frame = downsampleTo16kHz(frame)
+	}
+	if len(frame) < 2 {
+		return false, 0
+	}
+	var sumSq int64
+	crossings := 0
+	for i, s := range frame {
+		v := int64(s)
+		sumSq += v * v
+		if i > 0 && (frame[i-1] >= 0) != (s >= 0) {
+			crossings++
+		}
+	}
+	meanSq := sumSq / int64(len(frame))
+	rms := int(math.Sqrt(float64(meanSq)))
+	zcr := float64(crossings) / float64(len(frame))
+	isSpeech := rms >= b.EnergyThreshold && zcr <= b.MaxZCR
+	prob := float64(rms) / float64(2*b.EnergyThreshold)
+	if zcr > b.MaxZCR {
+		prob -= zcr - b.MaxZCR
+	}
+	if prob > 1 {
+		prob = 1
+	}
+	if prob < 0 {
+		prob = 0
+	}
+	return isSpeech, prob
+}
@@ -0,0 +1,176 @@
+package voice
+
+import "strings"
+
+// metaphoneCode is one word's Double Metaphone encoding: a primary code plus
+// an optional secondary (alternate pronunciation) code.
+type metaphoneCode struct {
+	Primary   string
+	Secondary string
+}
+
+// doubleMetaphone is a simplified approximation of Lawrence Philips' Double
+// Metaphone algorithm, not a port of the full published rule set (which runs
+// to hundreds of language-specific cases). It covers the English phonetic
+// substitutions most likely to explain an ASR wake-word miss (silent
+// letters, c/g soft-vs-hard, ph/th/sh/ck digraphs, doubled consonants) and
+// produces a primary code plus a secondary code for the handful of letters
+// with a common alternate pronunciation (c, g). Good enough to let "hey
+// computer" match a transcript of "hey kompyooter"; not a substitute for the
+// reference implementation if exact parity with it is ever required.
+func doubleMetaphone(word string) (primary, secondary string) {
+	w := strings.ToLower(strings.TrimSpace(word))
+	if w == "" {
+		return "", ""
+	}
+	// Silent leading letter pairs.
+	switch {
+	case strings.HasPrefix(w, "kn"), strings.HasPrefix(w, "gn"), strings.HasPrefix(w, "pn"):
+		w = w[1:]
+	case strings.HasPrefix(w, "wr"):
+		w = w[1:]
+	}
+
+	var prim, sec strings.Builder
+	runes := []rune(w)
+	n := len(runes)
+	isVowel := func(r rune) bool {
+		switch r {
+		case 'a', 'e', 'i', 'o', 'u', 'y':
+			return true
+		}
+		return false
+	}
+
+	for i := 0; i < n; i++ {
+		r := runes[i]
+		// Collapse doubled consonants (but not 'c', handled specially below).
+		if i > 0 && r == runes[i-1] && r != 'c' {
+			continue
+		}
+		switch r {
+		case 'a', 'e', 'i', 'o', 'u', 'y':
+			if i == 0 {
+				prim.WriteRune(r)
+				sec.WriteRune(r)
+			}
+		case 'b':
+			prim.WriteByte('p')
+			sec.WriteByte('p')
+		case 'c':
+			switch {
+			case i+1 < n && runes[i+1] == 'h':
+				prim.WriteByte('x')
+				sec.WriteByte('x')
+				i++
+			case i+1 < n && (runes[i+1] == 'e' || runes[i+1] == 'i' || runes[i+1] == 'y'):
+				prim.WriteByte('s')
+				sec.WriteByte('k') // alternate: hard-c pronunciation
+			default:
+				prim.WriteByte('k')
+				sec.WriteByte('k')
+			}
+		case 'd':
+			if i+2 < n && runes[i+1] == 'g' && (runes[i+2] == 'e' || runes[i+2] == 'i' || runes[i+2] == 'y') {
+				prim.WriteByte('j')
+				sec.WriteByte('j')
+				i += 2
+			} else {
+				prim.WriteByte('t')
+				sec.WriteByte('t')
+			}
+		case 'g':
+			switch {
+			case i+1 < n && runes[i+1] == 'h' && (i+2 >= n || !isVowel(runes[i+2])):
+				// silent gh (e.g. "light"); skip entirely
+			case i+1 < n && (runes[i+1] == 'e' || runes[i+1] == 'i' || runes[i+1] == 'y'):
+				prim.WriteByte('j')
+				sec.WriteByte('k') // alternate: hard-g pronunciation
+			default:
+				prim.WriteByte('k')
+				sec.WriteByte('k')
+			}
+		case 'h':
+			if i > 0 && isVowel(runes[i-1]) && (i+1 >= n || !isVowel(runes[i+1])) {
+				// silent h between a vowel and a non-vowel (or end)
+			} else {
+				prim.WriteByte('h')
+				sec.WriteByte('h')
+			}
+		case 'k':
+			// The 'c' of a "ck" digraph already wrote this k.
+			if i == 0 || runes[i-1] != 'c' {
+				prim.WriteByte('k')
+				sec.WriteByte('k')
+			}
+		case 'p':
+			if i+1 < n && runes[i+1] == 'h' {
+				prim.WriteByte('f')
+				sec.WriteByte('f')
+				i++
+			} else {
+				prim.WriteByte('p')
+				sec.WriteByte('p')
+			}
+		case 'q':
+			prim.WriteByte('k')
+			sec.WriteByte('k')
+		case 's':
+			if i+1 < n && runes[i+1] == 'h' {
+				prim.WriteByte('x')
+				sec.WriteByte('x')
+				i++
+			} else {
+				prim.WriteByte('s')
+				sec.WriteByte('s')
+			}
+		case 't':
+			if i+1 < n && runes[i+1] == 'h' {
+				prim.WriteByte('0')
+				sec.WriteByte('0')
+				i++
+			} else {
+				prim.WriteByte('t')
+				sec.WriteByte('t')
+			}
+		case 'v':
+			prim.WriteByte('f')
+			sec.WriteByte('f')
+		case 'w':
+			if i+1 < n && isVowel(runes[i+1]) {
+				prim.WriteByte('w')
+				sec.WriteByte('w')
+			}
+		case 'x':
+			prim.WriteString("ks")
+			sec.WriteString("ks")
+		case 'z':
+			prim.WriteByte('s')
+			sec.WriteByte('s')
+		case 'f', 'j', 'l', 'm', 'n', 'r':
+			prim.WriteRune(r)
+			sec.WriteRune(r)
+		}
+	}
+	primary = prim.String()
+	secondary = sec.String()
+	if secondary == primary {
+		secondary = ""
+	}
+	return primary, secondary
+}
+
+// wakePhrasePhoneticsFor precomputes the per-token Double Metaphone codes for
+// each entry of phrases, in the shape wakeWindowMatches expects from
+// Processor.wakePhrasePhonetics. Shared by NewProcessorWithResolver's initial
+// load and SetWakePhrases' live update so the two don't drift.
+func wakePhrasePhoneticsFor(phrases []string) [][]metaphoneCode {
+	out := make([][]metaphoneCode, len(phrases))
+	for i, wp := range phrases {
+		for _, tok := range strings.Fields(wp) {
+			primary, secondary := doubleMetaphone(normalizeWakeToken(tok))
+			out[i] = append(out[i], metaphoneCode{Primary: primary, Secondary: secondary})
+		}
+	}
+	return out
+}
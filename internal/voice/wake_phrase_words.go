@@ -0,0 +1,188 @@
+package voice
+
+import (
+	"regexp"
+	"strings"
+)
+
+// sttWord is one word-level timestamp entry as faster-whisper's
+// word_timestamps=1 output reports it (see the "words" array nested under
+// each entry of the STT response's "segments").
+type sttWord struct {
+	Word       string
+	StartS     float64
+	EndS       float64
+	Confidence float64
+}
+
+// wakePhoneticMap collapses a handful of common homophones STT backends
+// confuse with wake-phrase words onto a single canonical spelling before
+// comparison, so e.g. a transcript of "hey computer" still matches a wake
+// phrase configured as "hey computer" even if the backend heard a
+// homophone for one of its words. Deliberately small and hand-maintained;
+// extend it as real false-negatives are observed in production logs rather
+// than guessing at a comprehensive phonetic dictionary up front.
+var wakePhoneticMap = map[string]string{
+	"weight": "wait",
+	"there":  "their",
+	"here":   "hear",
+	"know":   "no",
+}
+
+var wakeWordPunct = regexp.MustCompile(`[^\w]+`)
+
+// normalizeWakeToken lowercases tok, strips surrounding punctuation, and
+// maps known homophones onto a canonical spelling.
+func normalizeWakeToken(tok string) string {
+	tok = strings.ToLower(strings.TrimSpace(tok))
+	tok = wakeWordPunct.ReplaceAllString(tok, "")
+	if canon, ok := wakePhoneticMap[tok]; ok {
+		return canon
+	}
+	return tok
+}
+
+// levenshtein returns the edit distance between a and b, used to let a
+// wake-phrase word match tolerate one misrecognized character (e.g.
+// "compuper" vs "computer") instead of requiring an exact token match.
+func levenshtein(a, b string) int {
+	if a == b {
+		return 0
+	}
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			del := prev[j] + 1
+			ins := curr[j-1] + 1
+			sub := prev[j-1] + cost
+			min := del
+			if ins < min {
+				min = ins
+			}
+			if sub < min {
+				min = sub
+			}
+			curr[j] = min
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// parseSegmentWords flattens the STT response's decoded "segments" field
+// (JSON already unmarshaled into interface{} by the caller) into a single
+// ordered word list with per-word timing/confidence. Returns nil if
+// segments is missing, not a list, or carries no word-level timestamps
+// (e.g. word_timestamps wasn't honored by the backend), so callers can
+// fall back to the flattened-text matcher in hasWakePhrase.
+func parseSegmentWords(segments interface{}) []sttWord {
+	segList, ok := segments.([]interface{})
+	if !ok {
+		return nil
+	}
+	var words []sttWord
+	for _, segAny := range segList {
+		seg, ok := segAny.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		wordsAny, ok := seg["words"].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, wAny := range wordsAny {
+			w, ok := wAny.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			text, _ := w["word"].(string)
+			text = strings.TrimSpace(text)
+			if text == "" {
+				continue
+			}
+			start, _ := w["start"].(float64)
+			end, _ := w["end"].(float64)
+			conf, confOK := w["probability"].(float64)
+			if !confOK {
+				conf, confOK = w["confidence"].(float64)
+			}
+			if !confOK {
+				conf = 1
+			}
+			words = append(words, sttWord{Word: text, StartS: start, EndS: end, Confidence: conf})
+		}
+	}
+	return words
+}
+
+// wakeWordMatch is what detectWakePhraseWords returns on a hit.
+type wakeWordMatch struct {
+	Confidence   float64
+	StartMs      int
+	EndMs        int
+	StrippedText string
+}
+
+// detectWakePhraseWords slides a window the length of each configured wake
+// phrase over words, accepting a match when every token's edit distance
+// (after normalizeWakeToken) is within p.wakeMaxEditDistance and the
+// matched window's mean confidence clears p.wakeMinConfidence. This
+// locates the wake phrase by its actual word boundaries instead of
+// substring-matching the flattened transcript, so it can't misfire on a
+// homophone appearing mid-utterance and can report the exact millisecond
+// offset where post-wake content starts.
+func (p *Processor) detectWakePhraseWords(words []sttWord) (bool, wakeWordMatch) {
+	p.wakeMu.RLock()
+	defer p.wakeMu.RUnlock()
+	for _, wp := range p.wakePhrases {
+		wpWords := strings.Fields(wp)
+		if len(wpWords) == 0 || len(words) < len(wpWords) {
+			continue
+		}
+		wpNorm := make([]string, len(wpWords))
+		for i, w := range wpWords {
+			wpNorm[i] = normalizeWakeToken(w)
+		}
+		for start := 0; start+len(wpNorm) <= len(words); start++ {
+			matched := true
+			var confSum float64
+			for j, target := range wpNorm {
+				tok := normalizeWakeToken(words[start+j].Word)
+				if levenshtein(tok, target) > p.wakeMaxEditDistance {
+					matched = false
+					break
+				}
+				confSum += words[start+j].Confidence
+			}
+			if !matched {
+				continue
+			}
+			meanConf := confSum / float64(len(wpNorm))
+			if meanConf < p.wakeMinConfidence {
+				continue
+			}
+			end := start + len(wpNorm)
+			var strippedWords []string
+			for _, w := range words[end:] {
+				strippedWords = append(strippedWords, w.Word)
+			}
+			return true, wakeWordMatch{
+				Confidence:   meanConf,
+				StartMs:      int(words[start].StartS * 1000),
+				EndMs:        int(words[end-1].EndS * 1000),
+				StrippedText: strings.Trim(strings.Join(strippedWords, " "), " ,.!?;:-\"'`~"),
+			}
+		}
+	}
+	return false, wakeWordMatch{}
+}
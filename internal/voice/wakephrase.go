@@ -5,24 +5,77 @@ import (
 	"strings"
 )
 
-// WakeDetector encapsulates wake-phrase detection configuration.
+// defaultWakeDetectorMinConfidence is used by Detect's fuzzy path when
+// MinConfidence is left at its zero value.
+const defaultWakeDetectorMinConfidence = 0.6
+
+// wsCollapseRegex collapses runs of whitespace to a single space. Compiled
+// once rather than per-call.
+var wsCollapseRegex = regexp.MustCompile(`\s+`)
+
+// normalizeUtterance applies the same lowercase/trim/whitespace-collapse
+// normalization Detect's exact-match path uses, shared so Score and
+// bestFuzzyMatch can't drift from it.
+func normalizeUtterance(text string) string {
+	s := strings.ToLower(strings.TrimSpace(text))
+	return wsCollapseRegex.ReplaceAllString(s, " ")
+}
+
+// WakeDetector encapsulates wake-phrase detection configuration, including
+// an optional fuzzy/phonetic fallback for common ASR substitution errors
+// ("computer" heard as "commuter", "hey computer" heard as "hay computer")
+// that exact token equality would otherwise reject outright.
 type WakeDetector struct {
 	Phrases []string
 	WindowS int
+
+	// Fuzzy enables the phonetic + edit-distance fallback below when the
+	// exact-match fast path fails. Off by default, so existing callers that
+	// only want exact matching see no behavior change and pay no extra cost.
+	Fuzzy bool
+	// MinConfidence is the minimum Score a fuzzy match must reach to count
+	// as a match in Detect. Zero (the default) falls back to
+	// defaultWakeDetectorMinConfidence. Ignored entirely when Fuzzy is false.
+	MinConfidence float64
+
+	phonetics [][]metaphoneCode // built lazily by ensurePhonetics from Phrases
 }
 
 func NewWakeDetector(phrases []string, windowS int) *WakeDetector {
 	return &WakeDetector{Phrases: phrases, WindowS: windowS}
 }
 
+// ensurePhonetics builds w.phonetics from w.Phrases the first time it's
+// needed, reusing wakePhrasePhoneticsFor so the encoding matches what
+// Processor's own "phonetic" wake-match mode computes.
+func (w *WakeDetector) ensurePhonetics() {
+	if w.phonetics != nil {
+		return
+	}
+	w.phonetics = wakePhrasePhoneticsFor(w.Phrases)
+}
+
+// maxEditDistanceForToken returns the Damerau-Levenshtein tolerance for a
+// wake-phrase token of this length: short words (<=5 runes, e.g. "hey",
+// "computer" is 8 so doesn't qualify) have less room for a false positive,
+// so they get a tighter tolerance than longer ones.
+func maxEditDistanceForToken(tok string) int {
+	if len([]rune(tok)) <= 5 {
+		return 1
+	}
+	return 2
+}
+
 // Detect returns (matched, stripped). 'stripped' is the text after removing
-// the detected wake phrase, or empty when none matched.
+// the detected wake phrase, or empty when none matched. The exact-match
+// logic below is unchanged from before fuzzy matching was added, so it
+// remains the fast path; fuzzy/phonetic matching only runs as a fallback,
+// and only when w.Fuzzy is set.
 func (w *WakeDetector) Detect(text string) (bool, string) {
 	if text == "" {
 		return false, ""
 	}
-	s := strings.ToLower(strings.TrimSpace(text))
-	s = regexp.MustCompile(`\s+`).ReplaceAllString(s, " ")
+	s := normalizeUtterance(text)
 	s = strings.TrimLeft(s, " \t\n\r\f\v\"'`~")
 	windowS := w.WindowS
 	for _, wp := range w.Phrases {
@@ -66,7 +119,7 @@ func (w *WakeDetector) Detect(text string) (bool, string) {
 				}
 			}
 			if match {
-				fullWords := strings.Fields(strings.TrimSpace(regexp.MustCompile(`\s+`).ReplaceAllString(s, " ")))
+				fullWords := strings.Fields(s)
 				foundIdx := -1
 				for fi := 0; fi+len(wpWords) <= len(fullWords); fi++ {
 					okMatch := true
@@ -92,5 +145,149 @@ func (w *WakeDetector) Detect(text string) (bool, string) {
 			}
 		}
 	}
-	return false, ""
+	if !w.Fuzzy {
+		return false, ""
+	}
+	minConfidence := w.MinConfidence
+	if minConfidence <= 0 {
+		minConfidence = defaultWakeDetectorMinConfidence
+	}
+	score, wpIdx, foundIdx, words := w.bestFuzzyMatch(s, windowS)
+	if score < minConfidence || wpIdx < 0 {
+		return false, ""
+	}
+	wpWords := strings.Fields(w.Phrases[wpIdx])
+	stripped := ""
+	if end := foundIdx + len(wpWords); end < len(words) {
+		stripped = strings.Trim(strings.Join(words[end:], " "), " ,.!?;:-\"'`~")
+	}
+	return true, stripped
+}
+
+// Score returns the confidence of the best fuzzy/phonetic match for text
+// across all configured phrases, in [0, 1], or 0 if nothing came close
+// enough for the leading-token metaphone gate to even consider it. It does
+// not apply MinConfidence or require w.Fuzzy to be set, so callers can use
+// it to inspect match strength independent of Detect's pass/fail gate.
+func (w *WakeDetector) Score(text string) float64 {
+	s := normalizeUtterance(text)
+	score, _, _, _ := w.bestFuzzyMatch(s, w.WindowS)
+	return score
+}
+
+// bestFuzzyMatch finds the highest-scoring phrase/window match for the
+// already-normalized text s, considering candidate windows starting anywhere
+// within the first windowS*3 words (same bound the exact-match path above
+// applies to `words`; windowS <= 0 falls back to just the first 3). The
+// leading-token metaphone gate is evaluated per candidate start (not just
+// against the utterance's very first word), since a wake phrase can appear
+// mid-utterance within that window, not only at its head; only starts whose
+// leading token phonetically matches a phrase's first token pay for the
+// full per-token edit-distance comparison.
+// Returns (score, matched phrase index, window start index, input words);
+// phrase index is -1 if no phrase's leading-token gate ever passed.
+func (w *WakeDetector) bestFuzzyMatch(s string, windowS int) (bestScore float64, bestPhrase, bestStart int, words []string) {
+	bestPhrase = -1
+	if s == "" {
+		return 0, -1, -1, nil
+	}
+	words = strings.Fields(s)
+	if len(words) == 0 {
+		return 0, -1, -1, nil
+	}
+	k := windowS * 3
+	if k < 3 {
+		k = 3
+	}
+	if len(words) > k {
+		words = words[:k]
+	}
+	w.ensurePhonetics()
+
+	for pi, wp := range w.Phrases {
+		wpWords := strings.Fields(wp)
+		if len(wpWords) == 0 || pi >= len(w.phonetics) || len(w.phonetics[pi]) == 0 {
+			continue
+		}
+		firstCode := w.phonetics[pi][0]
+		for start := 0; start+len(wpWords) <= len(words); start++ {
+			leadPrimary, leadSecondary := doubleMetaphone(normalizeWakeToken(words[start]))
+			gated := firstCode.Primary != "" && (firstCode.Primary == leadPrimary || firstCode.Primary == leadSecondary ||
+				(firstCode.Secondary != "" && (firstCode.Secondary == leadPrimary || firstCode.Secondary == leadSecondary)))
+			if !gated {
+				continue
+			}
+			total := 0.0
+			ok := true
+			for j, target := range wpWords {
+				tok := normalizeWakeToken(words[start+j])
+				maxDist := maxEditDistanceForToken(target)
+				dist := damerauLevenshtein(tok, normalizeWakeToken(target))
+				if dist > maxDist {
+					ok = false
+					break
+				}
+				total += 1 - float64(dist)/float64(maxDist+1)
+			}
+			if !ok {
+				continue
+			}
+			score := total / float64(len(wpWords))
+			if score > bestScore {
+				bestScore = score
+				bestPhrase = pi
+				bestStart = start
+			}
+		}
+	}
+	return bestScore, bestPhrase, bestStart, words
+}
+
+// damerauLevenshtein returns the Damerau-Levenshtein edit distance between a
+// and b: the usual insert/delete/substitute Levenshtein operations, plus
+// adjacent-transposition as a fourth, since transposed letters ("computer"
+// -> "comupter") are a common transcription slip a plain Levenshtein
+// distance charges two edits for instead of one.
+func damerauLevenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	la, lb := len(ra), len(rb)
+	if la == 0 {
+		return lb
+	}
+	if lb == 0 {
+		return la
+	}
+	d := make([][]int, la+1)
+	for i := range d {
+		d[i] = make([]int, lb+1)
+		d[i][0] = i
+	}
+	for j := 0; j <= lb; j++ {
+		d[0][j] = j
+	}
+	for i := 1; i <= la; i++ {
+		for j := 1; j <= lb; j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			del := d[i-1][j] + 1
+			ins := d[i][j-1] + 1
+			sub := d[i-1][j-1] + cost
+			best := del
+			if ins < best {
+				best = ins
+			}
+			if sub < best {
+				best = sub
+			}
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				if trans := d[i-2][j-2] + 1; trans < best {
+					best = trans
+				}
+			}
+			d[i][j] = best
+		}
+	}
+	return d[la][lb]
 }
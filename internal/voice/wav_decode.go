@@ -0,0 +1,82 @@
+package voice
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// decodeWAVPCM16 is buildWAV's inverse: it walks a canonical RIFF/WAVE
+// container to find the "fmt " and "data" chunks and returns the data
+// chunk's contents as 16-bit little-endian samples plus the format's sample
+// rate and channel count. It only understands PCM (format code 1); a
+// provider emitting compressed audio (e.g. MP3, Opus) isn't handled, since
+// nothing in this package currently needs to decode those.
+func decodeWAVPCM16(wav []byte) (samples []int16, sampleRate int, channels int, err error) {
+	if len(wav) < 12 || string(wav[0:4]) != "RIFF" || string(wav[8:12]) != "WAVE" {
+		return nil, 0, 0, fmt.Errorf("decodeWAVPCM16: not a RIFF/WAVE file")
+	}
+	var (
+		haveFmt       bool
+		formatCode    uint16
+		bitsPerSample uint16
+		dataStart     = -1
+		dataLen       = 0
+	)
+	off := 12
+	for off+8 <= len(wav) {
+		chunkID := string(wav[off : off+4])
+		chunkLen := int(binary.LittleEndian.Uint32(wav[off+4 : off+8]))
+		body := off + 8
+		if body+chunkLen > len(wav) {
+			break
+		}
+		switch chunkID {
+		case "fmt ":
+			if chunkLen < 16 {
+				return nil, 0, 0, fmt.Errorf("decodeWAVPCM16: fmt chunk too short")
+			}
+			formatCode = binary.LittleEndian.Uint16(wav[body : body+2])
+			channels = int(binary.LittleEndian.Uint16(wav[body+2 : body+4]))
+			sampleRate = int(binary.LittleEndian.Uint32(wav[body+4 : body+8]))
+			bitsPerSample = binary.LittleEndian.Uint16(wav[body+14 : body+16])
+			haveFmt = true
+		case "data":
+			dataStart = body
+			dataLen = chunkLen
+		}
+		// Chunks are word-aligned; an odd-length chunk has a padding byte.
+		off = body + chunkLen + (chunkLen & 1)
+	}
+	if !haveFmt || dataStart < 0 {
+		return nil, 0, 0, fmt.Errorf("decodeWAVPCM16: missing fmt or data chunk")
+	}
+	if formatCode != 1 {
+		return nil, 0, 0, fmt.Errorf("decodeWAVPCM16: unsupported format code %d (only PCM is supported)", formatCode)
+	}
+	if bitsPerSample != 16 {
+		return nil, 0, 0, fmt.Errorf("decodeWAVPCM16: unsupported bits per sample %d (only 16 is supported)", bitsPerSample)
+	}
+	raw := wav[dataStart : dataStart+dataLen]
+	samples = make([]int16, len(raw)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(raw[i*2 : i*2+2]))
+	}
+	return samples, sampleRate, channels, nil
+}
+
+// downmixToMono averages interleaved multi-channel samples down to mono.
+// channels == 1 returns samples unchanged.
+func downmixToMono(samples []int16, channels int) []int16 {
+	if channels <= 1 {
+		return samples
+	}
+	out := make([]int16, len(samples)/channels)
+	for i := range out {
+		var sum int32
+		for c := 0; c < channels; c++ {
+			sum += int32(samples[i*channels+c])
+		}
+		out[i] = int16(sum / int32(channels))
+	}
+	return out
+}
@@ -48,7 +48,7 @@ func buildWAV(pcm []byte, sampleRate, channels, bitsPerSample int) []byte {
 func (p *Processor) sendPCMToWhisper(ssrc uint32, pcmBytes []byte, correlationID string, accumCreatedAt time.Time, capturedUserID string, capturedUsername string) error {
 	whisper := os.Getenv("WHISPER_URL")
 	if whisper == "" {
-		logging.Warnw("WHISPER_URL not set, dropping audio", "ssrc", ssrc, "correlation_id", correlationID)
+		logging.Warn("WHISPER_URL not set, dropping audio", "ssrc", ssrc, "correlation_id", correlationID)
 		return fmt.Errorf("WHISPER_URL not set")
 	}
 
@@ -110,9 +110,9 @@ func (p *Processor) sendPCMToWhisper(ssrc uint32, pcmBytes []byte, correlationID
 
 		// Save WAV; ignore errors but log them at debug level so operators can inspect
 		if err := SaveFileAtomic(wavPath, wav, 0o644); err != nil {
-			logging.Debugw("saveaudio: failed to write decoded wav", "err", err, "path", wavPath, "correlation_id", correlationID)
+			logging.Debug("saveaudio: failed to write decoded wav", "err", err, "path", wavPath, "correlation_id", correlationID)
 		} else {
-			logging.Infow("saveaudio: wrote decoded wav", "path", wavPath, "correlation_id", correlationID)
+			logging.Info("saveaudio: wrote decoded wav", "path", wavPath, "correlation_id", correlationID)
 		}
 
 		// create initial sidecar JSON containing correlation id and created timestamp
@@ -123,12 +123,12 @@ func (p *Processor) sendPCMToWhisper(ssrc uint32, pcmBytes []byte, correlationID
 		}
 		if b, err := json.MarshalIndent(sc, "", "  "); err == nil {
 			if err := SaveFileAtomic(jsonPath, b, 0o644); err != nil {
-				logging.Debugw("saveaudio: failed to write sidecar json", "err", err, "path", jsonPath, "correlation_id", correlationID)
+				logging.Debug("saveaudio: failed to write sidecar json", "err", err, "path", jsonPath, "correlation_id", correlationID)
 			} else {
-				logging.Infow("saveaudio: wrote sidecar json", "path", jsonPath, "correlation_id", correlationID)
+				logging.Info("saveaudio: wrote sidecar json", "path", jsonPath, "correlation_id", correlationID)
 			}
 		} else {
-			logging.Debugw("saveaudio: failed to marshal sidecar json", "err", err, "correlation_id", correlationID)
+			logging.Debug("saveaudio: failed to marshal sidecar json", "err", err, "correlation_id", correlationID)
 		}
 	}
 
@@ -156,9 +156,9 @@ func (p *Processor) sendPCMToWhisper(ssrc uint32, pcmBytes []byte, correlationID
 				uid = p.ssrcMap[ssrc]
 				p.mu.Unlock()
 			}
-			logging.Debugw("sending audio to whisper", "ssrc", ssrc, "url", whisperURL, "correlation_id", correlationID, "bytes", len(pcmBytes), "samples", samples, "duration_ms", durationMs, "user_id", uid)
+			logging.Debug("sending audio to whisper", "ssrc", ssrc, "url", whisperURL, "correlation_id", correlationID, "bytes", len(pcmBytes), "samples", samples, "duration_ms", durationMs, "user_id", uid)
 		} else {
-			logging.Debugw("sending audio to whisper", "ssrc", ssrc, "url", whisperURL, "correlation_id", correlationID)
+			logging.Debug("sending audio to whisper", "ssrc", ssrc, "url", whisperURL, "correlation_id", correlationID)
 		}
 
 		resp, err := p.httpClient.Do(req)
@@ -166,7 +166,7 @@ func (p *Processor) sendPCMToWhisper(ssrc uint32, pcmBytes []byte, correlationID
 		if err != nil {
 			atomic.AddInt64(&p.sendFailCount, 1)
 			lastErr = err
-			logging.Warnw("HTTP send error to whisper", "ssrc", ssrc, "err", err, "attempt", attempt)
+			logging.Warn("HTTP send error to whisper", "ssrc", ssrc, "err", err, "attempt", attempt)
 			backoff := time.Duration(1<<attempt) * time.Second
 			time.Sleep(backoff)
 			continue
@@ -177,7 +177,7 @@ func (p *Processor) sendPCMToWhisper(ssrc uint32, pcmBytes []byte, correlationID
 		if resp.StatusCode >= 500 {
 			atomic.AddInt64(&p.sendFailCount, 1)
 			lastErr = fmt.Errorf("server error status=%d", resp.StatusCode)
-			logging.Warnw("STT server error", "ssrc", ssrc, "status", resp.StatusCode, "attempt", attempt)
+			logging.Warn("STT server error", "ssrc", ssrc, "status", resp.StatusCode, "attempt", attempt)
 			backoff := time.Duration(1<<attempt) * time.Second
 			time.Sleep(backoff)
 			continue
@@ -249,7 +249,7 @@ func (p *Processor) sendPCMToWhisper(ssrc uint32, pcmBytes []byte, correlationID
 		if username == "" {
 			username = "unknown"
 		}
-		logging.Infow("STT response received", "ssrc", ssrc, "user", username, "user_id", uid, "correlation_id", correlationID, "status", resp.StatusCode, "stt_latency_ms", sttLatencyMs, "stt_server_ms", sttServerMs, "end_to_end_ms", endToEndMs)
+		logging.Info("STT response received", "ssrc", ssrc, "user", username, "user_id", uid, "correlation_id", correlationID, "status", resp.StatusCode, "stt_latency_ms", sttLatencyMs, "stt_server_ms", sttServerMs, "end_to_end_ms", endToEndMs)
 
 		transcript := ""
 		if t, ok := out["text"].(string); ok {
@@ -308,7 +308,7 @@ func (p *Processor) sendPCMToWhisper(ssrc uint32, pcmBytes []byte, correlationID
 					}
 					if p.sidecar != nil {
 						if err := p.sidecar.MergeUpdatesForCID(correlationID, upd); err != nil {
-							logging.Warnw("failed to update sidecar via manager", "cid", correlationID, "err", err)
+							logging.Warn("failed to update sidecar via manager", "cid", correlationID, "err", err)
 						}
 					}
 				}
@@ -317,7 +317,7 @@ func (p *Processor) sendPCMToWhisper(ssrc uint32, pcmBytes []byte, correlationID
 
 		_, strippedText := p.wakeDetector.Detect(transcript)
 		if transcript != "" {
-			p.addAggregatedTranscript(ssrc, username, transcript, correlationID, accumCreatedAt, strippedText)
+			p.addAggregatedTranscript(ssrc, username, transcript, correlationID, accumCreatedAt, strippedText, true, nil)
 		}
 		return nil
 	}
@@ -62,6 +62,55 @@ func TestPermanentError(t *testing.T) {
 	}
 }
 
+func TestTransientErrorWithoutFallback(t *testing.T) {
+	// mock server that always returns 503, no fallback model configured
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", 503)
+	}))
+	defer ts.Close()
+
+	os.Setenv("OPENAI_BASE_URL", ts.URL)
+	os.Setenv("OPENAI_MODEL", "gpt-5")
+	os.Setenv("OPENAI_FALLBACK_MODEL", "")
+	os.Setenv("GPT5_ENABLED", "true")
+
+	client := NewClientFromEnv()
+	_, err := client.CreateChatCompletion(context.Background(), ChatRequest{Messages: []string{"hello"}})
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	if isPermanent(err) {
+		t.Fatalf("expected a transient error, got: %v", err)
+	}
+}
+
+func TestMaxTokensClampedToConfiguredLimit(t *testing.T) {
+	var gotMaxTokens float64
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&p)
+		gotMaxTokens, _ = p["max_tokens"].(float64)
+		resp := map[string]interface{}{"choices": []map[string]interface{}{{"message": map[string]string{"content": "ok"}}}}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer ts.Close()
+
+	os.Setenv("OPENAI_BASE_URL", ts.URL)
+	os.Setenv("OPENAI_MODEL", "local")
+	os.Setenv("OPENAI_FALLBACK_MODEL", "")
+	os.Setenv("GPT5_ENABLED", "true")
+	os.Setenv("LLM_MAX_TOKENS", "100")
+	defer os.Unsetenv("LLM_MAX_TOKENS")
+
+	client := NewClientFromEnv()
+	if _, err := client.CreateChatCompletion(context.Background(), ChatRequest{Messages: []string{"hi"}, MaxTokens: 5000}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotMaxTokens != 100 {
+		t.Fatalf("want max_tokens clamped to 100, got %v", gotMaxTokens)
+	}
+}
+
 func isPermanent(err error) bool {
 	if err == nil {
 		return false
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// commandTransport/commandConnection give this binary a stdio transport
+// alongside wsTransport's WebSocket one, for local launch by Claude
+// Desktop-style clients that spawn the server as a subprocess and speak MCP
+// over its stdin/stdout rather than a socket. Newline-delimited JSON-RPC,
+// same framing services/internal/mcp's commandTransport uses for its own
+// stdio launch path.
+type commandTransport struct {
+	conn *commandConnection
+}
+
+// NewCommandTransport wraps r/w (typically os.Stdin/os.Stdout) as an
+// mcp.Transport.
+func NewCommandTransport(r io.Reader, w io.Writer) mcp.Transport {
+	return &commandTransport{conn: newCommandConnection(r, w)}
+}
+
+func (t *commandTransport) Connect(context.Context) (mcp.Connection, error) {
+	return t.conn, nil
+}
+
+type commandConnection struct {
+	reader       io.Reader
+	writer       io.Writer
+	incoming     chan commandReadResult
+	done         chan struct{} // closed by Close to unstick a readLoop blocked sending
+	writeMu      sync.Mutex
+	closeOnce    sync.Once
+	incomingOnce sync.Once
+}
+
+type commandReadResult struct {
+	msg jsonrpc.Message
+	err error
+}
+
+func newCommandConnection(r io.Reader, w io.Writer) *commandConnection {
+	c := &commandConnection{reader: r, writer: w, incoming: make(chan commandReadResult, 1), done: make(chan struct{})}
+	go c.readLoop()
+	return c
+}
+
+// readLoop decodes messages and hands them to Read via incoming. incoming's
+// buffer holds only one undelivered message, so if Read stops being called
+// (e.g. its caller gave up on ctx cancellation) a second decoded message
+// would otherwise block this goroutine forever; selecting on done as well
+// lets Close release it instead of leaking it for the process's lifetime.
+func (c *commandConnection) readLoop() {
+	dec := json.NewDecoder(c.reader)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			select {
+			case c.incoming <- commandReadResult{err: err}:
+			case <-c.done:
+			}
+			c.closeIncoming()
+			return
+		}
+		msg, err := jsonrpc.DecodeMessage(raw)
+		select {
+		case c.incoming <- commandReadResult{msg: msg, err: err}:
+		case <-c.done:
+			c.closeIncoming()
+			return
+		}
+		if err != nil {
+			c.closeIncoming()
+			return
+		}
+	}
+}
+
+func (c *commandConnection) closeIncoming() {
+	c.incomingOnce.Do(func() { close(c.incoming) })
+}
+
+func (c *commandConnection) Read(ctx context.Context) (jsonrpc.Message, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res, ok := <-c.incoming:
+		if !ok {
+			return nil, io.EOF
+		}
+		return res.msg, res.err
+	}
+}
+
+func (c *commandConnection) Write(ctx context.Context, msg jsonrpc.Message) error {
+	data, err := jsonrpc.EncodeMessage(msg)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err = c.writer.Write(data)
+	return err
+}
+
+func (c *commandConnection) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+		if closer, ok := c.reader.(io.Closer); ok {
+			err = errors.Join(err, closer.Close())
+		}
+		if closer, ok := c.writer.(io.Closer); ok {
+			err = errors.Join(err, closer.Close())
+		}
+	})
+	return err
+}
+
+func (c *commandConnection) SessionID() string { return "" }
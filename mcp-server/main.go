@@ -6,14 +6,49 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/discord-voice-lab/internal/voice"
 	"github.com/gorilla/websocket"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 func main() {
-	// Create a simple MCP server with no special tools.
 	server := mcp.NewServer(&mcp.Implementation{Name: "mcp-server", Version: "v0.0.0"}, nil)
 
+	// When VOICE_TOOLS_ENABLED=true, host a Processor in this process and
+	// expose it via voice.* tools. Note this Processor has no Discord voice
+	// connection of its own (no SSRC/session traffic will ever reach it
+	// unless this binary is also wired up to receive it); voice.transcribe_wav
+	// works standalone, while list_sessions/get_transcript/subscribe_transcripts
+	// are only useful when the same Processor instance is also fed by the bot.
+	if os.Getenv("VOICE_TOOLS_ENABLED") == "true" {
+		p, err := voice.NewProcessor()
+		if err != nil {
+			log.Printf("voice tools: failed to create processor: %v", err)
+		} else {
+			registerVoiceTools(server, p)
+		}
+	}
+
+	// When MCP_STDIO_ENABLED=true, also speak MCP over this process's own
+	// stdin/stdout, for Claude Desktop-style clients that launch the server
+	// as a subprocess instead of dialing /mcp/ws. Runs alongside the HTTP
+	// listener below, not instead of it.
+	if os.Getenv("MCP_STDIO_ENABLED") == "true" {
+		go func() {
+			t := NewCommandTransport(os.Stdin, os.Stdout)
+			conn, err := server.Connect(context.Background(), t, nil)
+			if err != nil {
+				log.Printf("mcp stdio connect error: %v", err)
+				return
+			}
+			if err := conn.Wait(); err != nil {
+				log.Printf("mcp stdio session ended with error: %v", err)
+			} else {
+				log.Printf("mcp stdio session ended")
+			}
+		}()
+	}
+
 	// Simple HTTP health endpoint
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("ok"))
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"time"
+
+	"github.com/discord-voice-lab/internal/voice"
+	"github.com/google/uuid"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// registerVoiceTools exposes p's session/transcript state as MCP tools so
+// any MCP client (Claude Desktop, IDE agents, etc.) connected over /mcp/ws
+// can query the voice pipeline without a bespoke REST layer. p must be
+// non-nil: main only calls this when VOICE_TOOLS_ENABLED=true and
+// voice.NewProcessor succeeded.
+func registerVoiceTools(server *mcp.Server, p *voice.Processor) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "voice.list_sessions",
+		Description: "List currently known SSRC -> Discord user mappings for the voice pipeline",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+		sessions := p.ListSessions()
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: formatSessions(sessions)}}}, sessions, nil
+	})
+
+	type getTranscriptArgs struct {
+		SSRC  uint32 `json:"ssrc"`
+		Since string `json:"since,omitempty"`
+	}
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "voice.get_transcript",
+		Description: "Get finalized transcripts for an SSRC (0 = all) emitted since an RFC3339 timestamp",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args getTranscriptArgs) (*mcp.CallToolResult, any, error) {
+		var since time.Time
+		if args.Since != "" {
+			if t, err := time.Parse(time.RFC3339, args.Since); err == nil {
+				since = t
+			}
+		}
+		entries := p.GetTranscript(args.SSRC, since)
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: formatTranscripts(entries)}}}, entries, nil
+	})
+
+	type transcribeWavArgs struct {
+		WavBase64 string `json:"wav_base64"`
+	}
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "voice.transcribe_wav",
+		Description: "Transcribe a base64-encoded WAV payload using the configured WHISPER_URL backend",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args transcribeWavArgs) (*mcp.CallToolResult, any, error) {
+		wav, err := base64.StdEncoding.DecodeString(args.WavBase64)
+		if err != nil {
+			return &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: "invalid wav_base64: " + err.Error()}}}, nil, nil
+		}
+		text, err := p.TranscribeWAV(ctx, wav)
+		if err != nil {
+			return &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}}}, nil, nil
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, text, nil
+	})
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "voice.subscribe_transcripts",
+		Description: "Block until the next finalized transcript is emitted, then return it (poll again for more)",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+		ch, cancel := p.SubscribeTranscripts()
+		defer cancel()
+		select {
+		case entry := <-ch:
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: formatTranscripts([]voice.TranscriptEntry{entry})}}}, entry, nil
+		case <-ctx.Done():
+			return &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: ctx.Err().Error()}}}, nil, nil
+		}
+	})
+
+	type resetUserQuotaArgs struct {
+		UserID string `json:"user_id"`
+	}
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "voice.reset_user_quota",
+		Description: "Clear a user's STT rate-limit bucket and monthly seconds cap (admin tool)",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args resetUserQuotaArgs) (*mcp.CallToolResult, any, error) {
+		if args.UserID == "" {
+			return &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: "user_id is required"}}}, nil, nil
+		}
+		p.ResetUserQuota(args.UserID)
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "quota reset for user " + args.UserID}}}, nil, nil
+	})
+
+	type speakArgs struct {
+		Text      string `json:"text"`
+		ChannelID string `json:"channel_id,omitempty"`
+	}
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "voice.speak",
+		Description: "Synthesize text via the configured TTS provider and play it back into the voice connection",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args speakArgs) (*mcp.CallToolResult, any, error) {
+		if args.Text == "" {
+			return &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: "text is required"}}}, nil, nil
+		}
+		if err := p.Speak(ctx, args.Text, args.ChannelID, uuid.NewString()); err != nil {
+			return &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}}}, nil, nil
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "spoke " + strconv.Itoa(len(args.Text)) + " characters"}}}, nil, nil
+	})
+
+	type setWakePhrasesArgs struct {
+		Phrases []string `json:"phrases"`
+	}
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "voice.set_wake_phrases",
+		Description: "Replace the configured wake phrases that gate orchestrator forwarding",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args setWakePhrasesArgs) (*mcp.CallToolResult, any, error) {
+		p.SetWakePhrases(args.Phrases)
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: strconv.Itoa(len(args.Phrases)) + " wake phrase(s) set"}}}, nil, nil
+	})
+
+	type resolveUserArgs struct {
+		UserID string `json:"user_id"`
+	}
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "voice.resolve_user",
+		Description: "Resolve a Discord user ID to its human-friendly username, if a resolver is configured",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args resolveUserArgs) (*mcp.CallToolResult, any, error) {
+		if args.UserID == "" {
+			return &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: "user_id is required"}}}, nil, nil
+		}
+		name := p.ResolveUser(args.UserID)
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: name}}}, name, nil
+	})
+
+	type tailEventsArgs struct {
+		SinceTS string `json:"since_ts,omitempty"`
+	}
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "voice.tail_events",
+		Description: "Get finalized transcripts across all SSRCs emitted since an RFC3339 timestamp",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args tailEventsArgs) (*mcp.CallToolResult, any, error) {
+		var since time.Time
+		if args.SinceTS != "" {
+			if t, err := time.Parse(time.RFC3339, args.SinceTS); err == nil {
+				since = t
+			}
+		}
+		entries := p.TailEvents(since)
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: formatTranscripts(entries)}}}, entries, nil
+	})
+}
+
+func formatSessions(sessions []voice.SessionInfo) string {
+	if len(sessions) == 0 {
+		return "no active sessions"
+	}
+	out := ""
+	for _, s := range sessions {
+		out += s.Username + " (ssrc=" + strconv.FormatUint(uint64(s.SSRC), 10) + " user=" + s.UserID + ")\n"
+	}
+	return out
+}
+
+func formatTranscripts(entries []voice.TranscriptEntry) string {
+	if len(entries) == 0 {
+		return "no transcripts"
+	}
+	out := ""
+	for _, e := range entries {
+		out += e.At.Format(time.RFC3339) + " ssrc=" + strconv.FormatUint(uint64(e.SSRC), 10) + ": " + e.Text + "\n"
+	}
+	return out
+}
@@ -2,6 +2,11 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -9,21 +14,119 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
+// defaultWSReadLimitBytes bounds a single inbound WebSocket message
+// (websocket.Conn.SetReadLimit) so a misbehaving peer can't force
+// unbounded buffering for one frame.
+const defaultWSReadLimitBytes = 1 << 20 // 1MiB
+
+// wsFrameType resolves to websocket.TextMessage when MCP_WS_FRAMING=text,
+// otherwise websocket.BinaryMessage (the default, and what every Go peer in
+// this repo speaks) - set for interop with a non-Go MCP peer that only
+// accepts text frames.
+func wsFrameType() int {
+	if strings.EqualFold(strings.TrimSpace(os.Getenv("MCP_WS_FRAMING")), "text") {
+		return websocket.TextMessage
+	}
+	return websocket.BinaryMessage
+}
+
+// wsPingIntervalFromEnv is the keepalive ping cadence, via
+// MCP_WS_PING_INTERVAL_SEC (default 30).
+func wsPingIntervalFromEnv() time.Duration {
+	if v := os.Getenv("MCP_WS_PING_INTERVAL_SEC"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return time.Duration(n) * time.Second
+		}
+	}
+	return 30 * time.Second
+}
+
+// wsReadLimitFromEnv overrides defaultWSReadLimitBytes via
+// MCP_WS_READ_LIMIT_BYTES.
+func wsReadLimitFromEnv() int64 {
+	if v := os.Getenv("MCP_WS_READ_LIMIT_BYTES"); v != "" {
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
+			return n
+		}
+	}
+	return defaultWSReadLimitBytes
+}
+
+// closeError surfaces a peer's WebSocket close code/reason in place of the
+// underlying *websocket.CloseError, so callers in this package don't need
+// to import gorilla/websocket to branch on a graceful close.
+type closeError struct {
+	Code int
+	Text string
+}
+
+func (e *closeError) Error() string {
+	return fmt.Sprintf("mcp: websocket closed (code=%d): %s", e.Code, e.Text)
+}
+
 type wsTransport struct{ conn *websocket.Conn }
 
 func (t *wsTransport) Connect(ctx context.Context) (mcp.Connection, error) {
-	return &wsConnection{conn: t.conn}, nil
+	return newWSConnection(t.conn), nil
 }
 
-type wsConnection struct{ conn *websocket.Conn }
+// wsConnection wraps a *websocket.Conn as an mcp.Connection, with a
+// background keepalive goroutine that pings the peer every
+// wsPingIntervalFromEnv and, via SetPongHandler, extends the read deadline
+// on every pong - so a half-open connection (peer gone, no FIN ever
+// arrives) is detected by Read timing out instead of blocking forever.
+// writeMu serializes Write, since gorilla/websocket requires a single
+// writer at a time and the keepalive ping is itself a write.
+type wsConnection struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
 
-func (w *wsConnection) Read(ctx context.Context) (jsonrpc.Message, error) {
-	if dl, ok := ctx.Deadline(); ok {
-		_ = w.conn.SetReadDeadline(dl)
-		defer w.conn.SetReadDeadline(time.Time{})
+	stopOnce sync.Once
+	stopPing chan struct{}
+	pingDone chan struct{}
+}
+
+func newWSConnection(conn *websocket.Conn) *wsConnection {
+	pongWait := wsPingIntervalFromEnv() * 2
+	conn.SetReadLimit(wsReadLimitFromEnv())
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	w := &wsConnection{
+		conn:     conn,
+		stopPing: make(chan struct{}),
+		pingDone: make(chan struct{}),
 	}
+	go w.keepalive(wsPingIntervalFromEnv())
+	return w
+}
+
+func (w *wsConnection) keepalive(interval time.Duration) {
+	defer close(w.pingDone)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stopPing:
+			return
+		case <-ticker.C:
+			w.writeMu.Lock()
+			err := w.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second))
+			w.writeMu.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (w *wsConnection) Read(ctx context.Context) (jsonrpc.Message, error) {
 	_, data, err := w.conn.ReadMessage()
 	if err != nil {
+		if ce, ok := err.(*websocket.CloseError); ok {
+			return nil, &closeError{Code: ce.Code, Text: ce.Text}
+		}
 		return nil, err
 	}
 	return jsonrpc.DecodeMessage(data)
@@ -34,14 +137,21 @@ func (w *wsConnection) Write(ctx context.Context, msg jsonrpc.Message) error {
 	if err != nil {
 		return err
 	}
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
 	if dl, ok := ctx.Deadline(); ok {
 		_ = w.conn.SetWriteDeadline(dl)
 		defer w.conn.SetWriteDeadline(time.Time{})
 	}
-	return w.conn.WriteMessage(websocket.BinaryMessage, data)
+	return w.conn.WriteMessage(wsFrameType(), data)
+}
+
+func (w *wsConnection) Close() error {
+	w.stopOnce.Do(func() { close(w.stopPing) })
+	<-w.pingDone
+	return w.conn.Close()
 }
 
-func (w *wsConnection) Close() error      { return w.conn.Close() }
 func (w *wsConnection) SessionID() string { return "" }
 
 func NewWebSocketTransport(conn *websocket.Conn) mcp.Transport {
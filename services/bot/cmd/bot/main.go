@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
 	"strings"
@@ -20,9 +21,13 @@ import (
 // to keep this entrypoint focused and compact.
 
 func main() {
-	// Initialize centralized logging
-	logging.Init()
-	defer logging.Sync()
+	// Initialize centralized logging. A failed Init (bad LOG_SINKS config,
+	// a sink that couldn't come up) leaves the package's noop logger in
+	// place rather than crashing startup over a logging misconfiguration.
+	if err := logging.Init(); err != nil {
+		fmt.Fprintf(os.Stderr, "logging: init failed, continuing with a no-op logger: %v\n", err)
+	}
+	defer logging.Close()
 
 	rootCtx, rootCancel := context.WithCancel(context.Background())
 	defer rootCancel()
@@ -32,55 +37,31 @@ func main() {
 		serviceName = "bot"
 	}
 
-	var mcpClients []*mcp.ClientWrapper
-
-	manifestResult, manifestErr := mcpconfig.LoadResult()
-	if manifestErr != nil {
-		logging.Warnw("failed to load mcp manifest", "err", manifestErr)
-	} else if len(manifestResult.Order) > 0 {
-		logging.Infow("loaded mcp configuration", "sources", manifestResult.Sources, "servers", len(manifestResult.Order))
-		for _, serverName := range manifestResult.Order {
-			server := manifestResult.Servers[serverName]
-			if !server.EnabledValue() {
-				logging.Debugw("skipping disabled mcp server", "server", serverName)
-				continue
-			}
-
-			client := mcp.NewClientWrapper(serviceName, "v0.0.0")
-			switch {
-			case server.Transport != nil && strings.EqualFold(server.Transport.Type, "websocket"):
-				wsURL := server.Transport.URL
-				if wsURL == "" {
-					logging.Warnw("mcp server missing websocket url", "server", serverName)
-					continue
-				}
-				connectCtx, cancel := context.WithTimeout(rootCtx, 5*time.Second)
-				err := client.ConnectWebSocket(connectCtx, wsURL)
-				cancel()
-				if err != nil {
-					logging.Warnw("mcp websocket connect failed", "server", serverName, "err", err)
-					continue
-				}
-				logging.Infow("connected mcp websocket server", "server", serverName)
-			case server.Command != "":
-				connectCtx, cancel := context.WithTimeout(rootCtx, 10*time.Second)
-				err := client.ConnectCommand(connectCtx, serverName, server.Command, server.Args, server.Env)
-				cancel()
-				if err != nil {
-					logging.Warnw("mcp command connect failed", "server", serverName, "err", err)
-					continue
-				}
-				logging.Infow("started mcp command server", "server", serverName)
-			default:
-				logging.Warnw("mcp server missing transport configuration", "server", serverName)
-				continue
-			}
+	registry := mcp.NewRegistry(serviceName)
+	var fallbackClient *mcp.ClientWrapper
 
-			mcpClients = append(mcpClients, client)
+	if watcher, err := mcpconfig.NewWatcher(); err != nil {
+		logging.Warnw("failed to load mcp manifest", "err", err)
+	} else {
+		manifestResult := watcher.Current()
+		if len(manifestResult.Order) > 0 {
+			logging.Infow("loaded mcp configuration", "sources", manifestResult.Sources, "servers", len(manifestResult.Order))
+			registry.LoadAll(rootCtx, manifestResult)
 		}
+		// Hot-reload: watch the manifest paths (fsnotify events debounced by
+		// DefaultWatchInterval, or mtime polling at that interval if fsnotify
+		// can't start) and reconcile the registry against whatever changed, instead of
+		// requiring a restart to pick up an added/removed/edited server.
+		// Apply runs in its own goroutine (as watcher.Run's doc comment asks
+		// of onChange) so a slow reconnect can't stall detection of the next
+		// manifest edit; Registry serializes its own Apply calls internally.
+		go watcher.Run(rootCtx, mcpconfig.DefaultWatchInterval, func(result mcpconfig.Result, diff mcpconfig.Diff) {
+			logging.Infow("mcp manifest changed", "added", diff.Added, "removed", diff.Removed, "changed", diff.Changed)
+			go registry.Apply(rootCtx, result, diff)
+		})
 	}
 
-	if len(mcpClients) == 0 {
+	if registry.Len() == 0 {
 		if mcpURL := os.Getenv("MCP_SERVER_URL"); mcpURL != "" {
 			wsURL := mcpURL
 			if !strings.HasPrefix(wsURL, "ws://") && !strings.HasPrefix(wsURL, "wss://") {
@@ -110,7 +91,7 @@ func main() {
 					logging.Warnw("mcp register failed", "err", err)
 				}
 			} else {
-				mcpClients = append(mcpClients, client)
+				fallbackClient = client
 			}
 		}
 	}
@@ -275,8 +256,11 @@ func main() {
 		if err := dg.Close(); err != nil {
 			logging.Warnw("discord session close error", "err", err)
 		}
-		for _, client := range mcpClients {
-			if err := client.Close(); err != nil {
+		if err := registry.Close(); err != nil {
+			logging.Warnw("mcp registry close error", "err", err)
+		}
+		if fallbackClient != nil {
+			if err := fallbackClient.Close(); err != nil {
 				logging.Warnw("mcp client close error", "err", err)
 			}
 		}
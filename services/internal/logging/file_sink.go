@@ -0,0 +1,30 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// newFileSink builds a size/age/count-rotated file sink from LOG_FILE
+// (required) plus LOG_MAX_SIZE_MB (default 100), LOG_MAX_AGE_DAYS (default
+// 0, meaning no age-based pruning), and LOG_MAX_BACKUPS (default 0, meaning
+// no count-based pruning) - the same knobs lumberjack.Logger itself
+// exposes as MaxSize/MaxAge/MaxBackups. lumberjack.Logger implements
+// io.Writer but not zapcore.Sync, so it's wrapped in zapcore.AddSync
+// (a no-op Sync) same as the stdout sink in sinksFromEnv.
+func newFileSink() (zapcore.WriteSyncer, error) {
+	path := strings.TrimSpace(os.Getenv("LOG_FILE"))
+	if path == "" {
+		return nil, fmt.Errorf("LOG_FILE is required for the file sink")
+	}
+	return zapcore.AddSync(&lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    envInt("LOG_MAX_SIZE_MB", 100),
+		MaxAge:     envInt("LOG_MAX_AGE_DAYS", 0),
+		MaxBackups: envInt("LOG_MAX_BACKUPS", 0),
+	}), nil
+}
@@ -13,6 +13,14 @@ import (
 var (
 	sugar *zap.SugaredLogger
 	once  sync.Once
+
+	// atomicLevel gates every configured sink at once, and can be changed
+	// after Init via SetLevel without restarting the process.
+	atomicLevel = zap.NewAtomicLevel()
+
+	// sinkClosers holds the cleanup func for any sink Init built that owns a
+	// connection (syslog, otlp) rather than just wrapping an os.File.
+	sinkClosers []func() error
 )
 
 // Logger is the canonical structured logging interface used by the project.
@@ -41,42 +49,69 @@ func (n noopLogger) Sync() error                                     { return ni
 // always safe even if Init() hasn't been called yet.
 var current Logger = noopLogger{}
 
+// jsonEncoderConfig is the EncoderConfig shared by every sink: ISO8601
+// timestamps keyed "ts" and a "caller" key, for consistent field names
+// across whichever destinations LOG_SINKS enables.
+func jsonEncoderConfig() zapcore.EncoderConfig {
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.TimeKey = "ts"
+	cfg.EncodeTime = zapcore.ISO8601TimeEncoder
+	cfg.CallerKey = "caller"
+	return cfg
+}
+
+// initErr is the first call's result, returned as-is by every later call -
+// once.Do only runs the body once, so a local variable would silently
+// report nil on a second call even if the first one failed.
+var initErr error
+
 // Init initializes the global sugared logger based on LOG_LEVEL and
-// redirects the standard library logger into zap. Callers must invoke this
-// in main() to enable structured logging. It's safe to call multiple times.
-func Init() *zap.SugaredLogger {
+// LOG_SINKS, and redirects the standard library logger into zap. Callers
+// must invoke this in main() to enable structured logging. It's safe to
+// call multiple times; only the first call's LOG_LEVEL/LOG_SINKS take
+// effect, and every call (including repeats) returns that first call's
+// result. Unlike the earlier single-sink version, a sink that fails to
+// construct (e.g. the file sink's LOG_FILE unset, or an unknown LOG_SINKS
+// entry) now fails Init with an error instead of silently swallowing it
+// and leaving the caller believing logging came up.
+func Init() error {
 	once.Do(func() {
 		level := strings.ToLower(os.Getenv("LOG_LEVEL"))
-		// Configure JSON encoder with ISO8601 time and canonical field names
-		cfg := zap.Config{
-			Encoding:         "json",
-			EncoderConfig:    zap.NewProductionEncoderConfig(),
-			OutputPaths:      []string{"stdout"},
-			ErrorOutputPaths: []string{"stderr"},
-		}
-		// Use ISO8601 time format for easier ingestion
-		cfg.EncoderConfig.TimeKey = "ts"
-		cfg.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
-		// Include caller and stacktrace for error-level logs
-		cfg.EncoderConfig.CallerKey = "caller"
-		// Set level from LOG_LEVEL env
 		lvl := zap.InfoLevel
-		if level == "debug" {
+		switch level {
+		case "debug":
 			lvl = zap.DebugLevel
-		} else if level == "warn" {
+		case "warn":
 			lvl = zap.WarnLevel
-		} else if level == "error" {
+		case "error":
 			lvl = zap.ErrorLevel
 		}
-		cfg.Level = zap.NewAtomicLevelAt(lvl)
+		atomicLevel.SetLevel(lvl)
+
+		cores, closers, err := sinksFromEnv(atomicLevel)
+		if err != nil {
+			initErr = err
+			for _, c := range closers {
+				_ = c()
+			}
+			return
+		}
+		sinkClosers = closers
 
-		logger, _ := cfg.Build(zap.AddCaller(), zap.AddStacktrace(zap.ErrorLevel))
+		logger := zap.New(zapcore.NewTee(cores...), zap.AddCaller(), zap.AddStacktrace(zap.ErrorLevel))
 		// Redirect standard library logs into zap so all logs are unified.
 		_ = zap.RedirectStdLog(logger)
 		sugar = logger.Sugar()
 		current = sugar
 	})
-	return sugar
+	return initErr
+}
+
+// SetLevel changes the minimum level every sink Init configured logs at,
+// in place and with no process restart - e.g. from an admin HTTP endpoint
+// that flips to debug for a few minutes to chase down a live issue.
+func SetLevel(level zapcore.Level) {
+	atomicLevel.SetLevel(level)
 }
 
 // Sugar returns the initialized sugared logger (may be nil if Init not called).
@@ -143,6 +178,19 @@ func Sync() error {
 	return nil
 }
 
+// Close flushes buffered logs and closes any sink holding an open
+// connection (syslog, otlp). Safe to call even if Init was never called or
+// returned an error.
+func Close() error {
+	err := Sync()
+	for _, c := range sinkClosers {
+		if cerr := c(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
 // Context helpers: attach small canonical key/value slices to context.Context
 // so they can be merged into log calls downstream.
 type ctxKeyType struct{}
@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	otellog "go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// otlpWriteSyncer emits each zap Write as one log record through a real
+// OTel SDK LoggerProvider (otlploghttp exporter, batched via
+// sdklog.NewBatchProcessor), rather than hand-rolling the OTLP/HTTP JSON
+// wire format and POSTing it directly - batching, retry, and the
+// resourceLogs/scopeLogs/logRecords envelope are all the SDK's job, not
+// this file's.
+type otlpWriteSyncer struct {
+	logger   otellog.Logger
+	provider *sdklog.LoggerProvider
+}
+
+func newOTLPSink() (otlpWriteSyncer, func() error, error) {
+	endpoint := strings.TrimSpace(os.Getenv("LOG_OTLP_ENDPOINT"))
+	if endpoint == "" {
+		return otlpWriteSyncer{}, nil, fmt.Errorf("LOG_OTLP_ENDPOINT is required for the otlp sink")
+	}
+	serviceName := os.Getenv("MCP_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = "audio-orchestrator"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	exporter, err := otlploghttp.New(ctx, otlploghttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return otlpWriteSyncer{}, nil, fmt.Errorf("otlp sink: build exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx, resource.WithAttributes(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return otlpWriteSyncer{}, nil, fmt.Errorf("otlp sink: build resource: %w", err)
+	}
+
+	provider := sdklog.NewLoggerProvider(
+		sdklog.WithResource(res),
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
+	)
+
+	w := otlpWriteSyncer{
+		logger:   provider.Logger("audio-orchestrator/internal/logging"),
+		provider: provider,
+	}
+	return w, func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return provider.Shutdown(ctx)
+	}, nil
+}
+
+func (w otlpWriteSyncer) Write(p []byte) (int, error) {
+	var rec otellog.Record
+	rec.SetTimestamp(time.Now())
+	rec.SetBody(otellog.StringValue(string(bytes.TrimRight(p, "\n"))))
+	w.logger.Emit(context.Background(), rec)
+	return len(p), nil
+}
+
+func (w otlpWriteSyncer) Sync() error { return nil }
@@ -0,0 +1,82 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// sinksFromEnv builds the sinks named in LOG_SINKS (comma-separated,
+// default "stdout") into zapcore.Cores composed via zapcore.NewTee, so
+// every sink receives every enabled log entry independently - an otlp
+// sink being unreachable doesn't affect the file sink, and vice versa.
+// level gates all of them at once (see SetLevel). An unknown entry, or a
+// sink that fails to construct (missing required env, can't dial), fails
+// Init with an error rather than silently dropping that destination -
+// an operator relying on a configured sink needs to know immediately if
+// it didn't come up, not discover the gap later when they go looking for
+// logs that were never written.
+// On error, sinksFromEnv still returns every closer built before the
+// failing sink, rather than nil - a caller (Init) that bails out on error
+// must still be able to close any connection (syslog, otlp) an earlier
+// sink in the list already opened.
+func sinksFromEnv(level zapcore.LevelEnabler) ([]zapcore.Core, []func() error, error) {
+	raw := strings.TrimSpace(os.Getenv("LOG_SINKS"))
+	if raw == "" {
+		raw = "stdout"
+	}
+	encCfg := jsonEncoderConfig()
+
+	var cores []zapcore.Core
+	var closers []func() error
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		switch name {
+		case "stdout":
+			cores = append(cores, zapcore.NewCore(zapcore.NewJSONEncoder(encCfg), zapcore.AddSync(os.Stdout), level))
+		case "file":
+			w, err := newFileSink()
+			if err != nil {
+				return cores, closers, fmt.Errorf("logging: file sink: %w", err)
+			}
+			cores = append(cores, zapcore.NewCore(zapcore.NewJSONEncoder(encCfg), w, level))
+		case "syslog":
+			w, closer, err := newSyslogSink()
+			if err != nil {
+				return cores, closers, fmt.Errorf("logging: syslog sink: %w", err)
+			}
+			cores = append(cores, zapcore.NewCore(zapcore.NewJSONEncoder(encCfg), w, level))
+			closers = append(closers, closer)
+		case "otlp":
+			w, closer, err := newOTLPSink()
+			if err != nil {
+				return cores, closers, fmt.Errorf("logging: otlp sink: %w", err)
+			}
+			cores = append(cores, zapcore.NewCore(zapcore.NewJSONEncoder(encCfg), w, level))
+			closers = append(closers, closer)
+		default:
+			return cores, closers, fmt.Errorf("logging: unknown LOG_SINKS entry %q", name)
+		}
+	}
+	if len(cores) == 0 {
+		return cores, closers, errors.New("logging: LOG_SINKS resolved to no sinks")
+	}
+	return cores, closers, nil
+}
+
+// envInt reads key as an int, falling back to def if unset or unparseable.
+func envInt(key string, def int) int {
+	if v := os.Getenv(key); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return def
+}
@@ -0,0 +1,44 @@
+package logging
+
+import (
+	"fmt"
+	"log/syslog"
+	"os"
+	"strings"
+)
+
+// syslogWriteSyncer adapts *syslog.Writer (which has Write but no Sync) to
+// zapcore.WriteSyncer; syslog.Writer has no buffer of its own to flush, so
+// Sync is a no-op.
+type syslogWriteSyncer struct{ w *syslog.Writer }
+
+func (s syslogWriteSyncer) Write(p []byte) (int, error) { return s.w.Write(p) }
+func (s syslogWriteSyncer) Sync() error                 { return nil }
+
+// newSyslogSink opens a connection to the local syslog daemon, or a remote
+// one at LOG_SYSLOG_ADDR (host:port; LOG_SYSLOG_NETWORK selects "udp"
+// (default) or "tcp"). Uses the standard library's log/syslog, which speaks
+// the older BSD format (RFC 3164) rather than RFC 5424.
+func newSyslogSink() (syslogWriteSyncer, func() error, error) {
+	addr := strings.TrimSpace(os.Getenv("LOG_SYSLOG_ADDR"))
+	network := os.Getenv("LOG_SYSLOG_NETWORK")
+	if network == "" {
+		network = "udp"
+	}
+	tag := os.Getenv("LOG_SYSLOG_TAG")
+	if tag == "" {
+		tag = "audio-orchestrator"
+	}
+
+	var w *syslog.Writer
+	var err error
+	if addr == "" {
+		w, err = syslog.New(syslog.LOG_INFO|syslog.LOG_USER, tag)
+	} else {
+		w, err = syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_USER, tag)
+	}
+	if err != nil {
+		return syslogWriteSyncer{}, nil, fmt.Errorf("dial syslog: %w", err)
+	}
+	return syslogWriteSyncer{w: w}, w.Close, nil
+}
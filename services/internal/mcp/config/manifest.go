@@ -88,6 +88,28 @@ func LoadResult() (Result, error) {
 	return result, nil
 }
 
+// ResolvedPaths returns the manifest file paths LoadResult reads from, in
+// the same precedence order: just MCP_CONFIG_PATH if it's set, otherwise
+// the workspace and user manifest paths (whether or not they currently
+// exist - Watcher watches these paths, and a manifest being created
+// where none existed before is itself a change worth picking up).
+func ResolvedPaths() []string {
+	if overridePath := os.Getenv("MCP_CONFIG_PATH"); overridePath != "" {
+		if path, err := expandPath(overridePath); err == nil {
+			return []string{path}
+		}
+		return nil
+	}
+	var paths []string
+	if p, err := workspaceManifestPath(); err == nil || errors.Is(err, os.ErrNotExist) {
+		paths = append(paths, p)
+	}
+	if p, err := userManifestPath(); err == nil || errors.Is(err, os.ErrNotExist) {
+		paths = append(paths, p)
+	}
+	return paths
+}
+
 // Enabled reports whether the server should be used.
 func (s ServerConfig) EnabledValue() bool {
 	if s.Enabled == nil {
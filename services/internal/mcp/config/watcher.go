@@ -0,0 +1,228 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultWatchInterval is Run's debounce window: an editor that writes a
+// file in several small operations (common with atomic-save-via-rename)
+// settles well within 250ms, so a burst of fsnotify events collapses into
+// one reload of the final state rather than reloading mid-write.
+const DefaultWatchInterval = 250 * time.Millisecond
+
+// Diff describes which MCP servers changed between two successive loads of
+// the manifest.
+type Diff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+// IsEmpty reports whether d has no changes.
+func (d Diff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Watcher watches the manifest paths LoadResult reads (MCP_CONFIG_PATH, or
+// the workspace/user manifests) via fsnotify and re-loads the merged
+// configuration whenever any of them change, falling back to polling their
+// mtimes if fsnotify can't be started.
+type Watcher struct {
+	paths []string
+
+	mu       sync.Mutex
+	current  Result
+	modTimes map[string]time.Time
+}
+
+// NewWatcher resolves the manifest paths and loads the initial
+// configuration, returning an error only if that initial LoadResult fails
+// (e.g. malformed JSON) - a path that doesn't exist yet is not an error,
+// since Watcher's whole point is picking it up once it is created.
+func NewWatcher() (*Watcher, error) {
+	result, err := LoadResult()
+	if err != nil {
+		return nil, err
+	}
+	w := &Watcher{paths: ResolvedPaths(), current: result, modTimes: make(map[string]time.Time)}
+	for _, p := range w.paths {
+		w.modTimes[p] = modTimeOrZero(p)
+	}
+	return w, nil
+}
+
+// Current returns the most recently loaded configuration.
+func (w *Watcher) Current() Result {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// Run watches until ctx is canceled, calling onChange with the newly loaded
+// Result and a Diff against the previous one whenever a watched path
+// changes and that change actually altered the merged server set (a touch
+// with no content change produces an empty Diff and no callback). interval
+// is the debounce window applied after each fsnotify event (see
+// DefaultWatchInterval) and also the poll period if fsnotify can't be
+// started at all. onChange runs synchronously on the goroutine doing the
+// reload: the debounce timer's (time.AfterFunc) goroutine under fsnotify,
+// Run's own goroutine when polling; callers that need it non-blocking should
+// dispatch their own goroutine from it.
+func (w *Watcher) Run(ctx context.Context, interval time.Duration, onChange func(Result, Diff)) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		w.pollRun(ctx, interval, onChange)
+		return
+	}
+	defer watcher.Close()
+
+	dirs := map[string]struct{}{}
+	for _, p := range w.paths {
+		dirs[filepath.Dir(p)] = struct{}{}
+	}
+	watching := false
+	for dir := range dirs {
+		if err := watcher.Add(dir); err == nil {
+			watching = true
+		}
+	}
+	if !watching {
+		w.pollRun(ctx, interval, onChange)
+		return
+	}
+
+	names := map[string]struct{}{}
+	for _, p := range w.paths {
+		names[filepath.Base(p)] = struct{}{}
+	}
+
+	reload := func() {
+		result, err := LoadResult()
+		if err != nil {
+			// A transient parse error (e.g. caught mid-write by a non-atomic
+			// editor) shouldn't tear down servers that are still running
+			// fine under the last good config.
+			return
+		}
+		w.mu.Lock()
+		prev := w.current
+		w.current = result
+		w.mu.Unlock()
+		if diff := diffResults(prev, result); !diff.IsEmpty() {
+			onChange(result, diff)
+		}
+	}
+
+	// debounce collapses the burst of CREATE/WRITE/RENAME events a single
+	// atomic-save-via-rename produces into one reload of the settled file,
+	// same as the old poll interval did.
+	var debounce *time.Timer
+	defer func() {
+		if debounce != nil {
+			debounce.Stop()
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if _, ok := names[filepath.Base(ev.Name)]; !ok {
+				continue
+			}
+			if debounce == nil {
+				debounce = time.AfterFunc(interval, reload)
+			} else {
+				debounce.Reset(interval)
+			}
+		case _, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// pollRun is Run's fallback when fsnotify can't be started, re-checking the
+// watched paths' mtimes every interval until ctx is canceled.
+func (w *Watcher) pollRun(ctx context.Context, interval time.Duration, onChange func(Result, Diff)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !w.pathsChanged() {
+				continue
+			}
+			result, err := LoadResult()
+			if err != nil {
+				continue
+			}
+			w.mu.Lock()
+			prev := w.current
+			w.current = result
+			for _, p := range w.paths {
+				w.modTimes[p] = modTimeOrZero(p)
+			}
+			w.mu.Unlock()
+			if diff := diffResults(prev, result); !diff.IsEmpty() {
+				onChange(result, diff)
+			}
+		}
+	}
+}
+
+func (w *Watcher) pathsChanged() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, p := range w.paths {
+		if modTimeOrZero(p) != w.modTimes[p] {
+			return true
+		}
+	}
+	return false
+}
+
+func modTimeOrZero(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// diffResults computes which server names were added, removed, or had their
+// ServerConfig change between prev and next.
+func diffResults(prev, next Result) Diff {
+	var d Diff
+	for name, cfg := range next.Servers {
+		old, ok := prev.Servers[name]
+		if !ok {
+			d.Added = append(d.Added, name)
+		} else if !reflect.DeepEqual(old, cfg) {
+			d.Changed = append(d.Changed, name)
+		}
+	}
+	for name := range prev.Servers {
+		if _, ok := next.Servers[name]; !ok {
+			d.Removed = append(d.Removed, name)
+		}
+	}
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	sort.Strings(d.Changed)
+	return d
+}
@@ -0,0 +1,170 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/discord-voice-lab/internal/logging"
+	"github.com/discord-voice-lab/internal/mcp/config"
+)
+
+// Registry owns one ClientWrapper per connected MCP server, keyed by its
+// manifest name, so a config.Watcher's Diff can reconnect just the servers
+// that changed instead of tearing every client down on any single manifest
+// edit.
+type Registry struct {
+	serviceName string
+
+	// applyMu serializes LoadAll/Apply so two overlapping reconciliations
+	// (e.g. the watcher firing again before a slow connect finishes) can't
+	// interleave their close/connect steps against each other.
+	applyMu sync.Mutex
+
+	mu      sync.Mutex
+	closed  bool
+	clients map[string]*ClientWrapper
+}
+
+// NewRegistry returns an empty Registry; serviceName is passed to
+// NewClientWrapper for every server it connects.
+func NewRegistry(serviceName string) *Registry {
+	return &Registry{serviceName: serviceName, clients: make(map[string]*ClientWrapper)}
+}
+
+// LoadAll connects every enabled server in result, logging and skipping any
+// that fail - the one-shot connect loop main() used to run inline at
+// startup, now reusable for both the first load and a full Apply(added).
+func (r *Registry) LoadAll(ctx context.Context, result config.Result) {
+	r.applyMu.Lock()
+	defer r.applyMu.Unlock()
+	for _, name := range result.Order {
+		r.connect(ctx, name, result.Servers[name])
+	}
+}
+
+// Apply reconciles the Registry against diff: closes removed servers,
+// closes-then-reconnects changed ones (ClientWrapper has no in-place
+// reconfigure), and connects added ones. result must be the config.Result
+// diff was computed against.
+func (r *Registry) Apply(ctx context.Context, result config.Result, diff config.Diff) {
+	r.applyMu.Lock()
+	defer r.applyMu.Unlock()
+	for _, name := range diff.Removed {
+		r.closeServer(name)
+	}
+	for _, name := range diff.Changed {
+		r.closeServer(name)
+		r.connect(ctx, name, result.Servers[name])
+	}
+	for _, name := range diff.Added {
+		r.connect(ctx, name, result.Servers[name])
+	}
+}
+
+// connect dials server under name and, on success, stores the resulting
+// ClientWrapper, replacing whatever (if anything) was already registered
+// under that name.
+func (r *Registry) connect(ctx context.Context, name string, server config.ServerConfig) {
+	if !server.EnabledValue() {
+		logging.Debugw("skipping disabled mcp server", "server", name)
+		return
+	}
+
+	client := NewClientWrapper(r.serviceName, "v0.0.0")
+	switch {
+	case server.Transport != nil && strings.EqualFold(server.Transport.Type, "websocket"):
+		wsURL := server.Transport.URL
+		if wsURL == "" {
+			logging.Warnw("mcp server missing websocket url", "server", name)
+			return
+		}
+		connectCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		err := client.ConnectWebSocket(connectCtx, wsURL)
+		cancel()
+		if err != nil {
+			logging.Warnw("mcp websocket connect failed", "server", name, "err", err)
+			return
+		}
+		logging.Infow("connected mcp websocket server", "server", name)
+	case server.Command != "":
+		connectCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		err := client.ConnectCommand(connectCtx, name, server.Command, server.Args, server.Env)
+		cancel()
+		if err != nil {
+			logging.Warnw("mcp command connect failed", "server", name, "err", err)
+			return
+		}
+		logging.Infow("started mcp command server", "server", name)
+	default:
+		logging.Warnw("mcp server missing transport configuration", "server", name)
+		return
+	}
+
+	r.mu.Lock()
+	if r.closed {
+		// Close() ran while this connect was in flight; don't resurrect an
+		// entry in a registry that's shutting down, and don't leak the
+		// client/subprocess we just stood up.
+		r.mu.Unlock()
+		if err := client.Close(); err != nil {
+			logging.Warnw("mcp client close error", "server", name, "err", err)
+		}
+		return
+	}
+	if old, ok := r.clients[name]; ok {
+		if err := old.Close(); err != nil {
+			logging.Warnw("mcp client close error", "server", name, "err", err)
+		}
+	}
+	r.clients[name] = client
+	r.mu.Unlock()
+}
+
+func (r *Registry) closeServer(name string) {
+	r.mu.Lock()
+	client, ok := r.clients[name]
+	delete(r.clients, name)
+	r.mu.Unlock()
+	if !ok {
+		return
+	}
+	if err := client.Close(); err != nil {
+		logging.Warnw("mcp client close error", "server", name, "err", err)
+	} else {
+		logging.Infow("closed mcp server", "server", name)
+	}
+}
+
+// Len returns the number of currently connected servers.
+func (r *Registry) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.clients)
+}
+
+// Close closes every connected server and marks the Registry closed, so any
+// connect already in flight closes its client instead of registering it.
+// Safe to call more than once.
+func (r *Registry) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	clients := r.clients
+	r.clients = make(map[string]*ClientWrapper)
+	r.mu.Unlock()
+
+	var errs []error
+	for name, c := range clients {
+		if err := c.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
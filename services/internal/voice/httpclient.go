@@ -4,23 +4,216 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"math/rand"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/discord-voice-lab/internal/logging"
 )
 
-// PostWithRetries posts JSON to url with retry/backoff and returns the response.
-// Caller must close resp.Body.
-func PostWithRetries(client *http.Client, url string, body []byte, authToken string, timeoutMs int, attempts int, correlationID string) (*http.Response, error) {
+// circuitBreakerState is one of the three classic circuit-breaker states.
+type circuitBreakerState int
+
+const (
+	breakerClosed circuitBreakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+func (s circuitBreakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// circuitBreaker trips to open once at least minSamples of the most recent
+// outcomes have a failure ratio at or above failureRatio, and stays open for
+// coolDown before allowing a single half-open probe through. A failed probe
+// reopens it for another coolDown; a successful probe closes it and resets
+// the window. This is the same shape as internal/voice/outbound.CircuitBreaker
+// in the root module, reimplemented here because this services build is a
+// separate Go module that doesn't depend on internal/voice/outbound.
+type circuitBreaker struct {
+	mu           sync.Mutex
+	state        circuitBreakerState
+	failureRatio float64
+	minSamples   int
+	windowSize   int
+	window       []bool
+	openedAt     time.Time
+	coolDown     time.Duration
+}
+
+func newCircuitBreaker(failureRatio float64, minSamples int, coolDown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		failureRatio: failureRatio,
+		minSamples:   minSamples,
+		windowSize:   minSamples * 4,
+		coolDown:     coolDown,
+	}
+}
+
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case breakerClosed:
+		return true
+	case breakerOpen:
+		if time.Since(b.openedAt) >= b.coolDown {
+			b.state = breakerHalfOpen
+			return true
+		}
+		return false
+	case breakerHalfOpen:
+		return false
+	default:
+		return true
+	}
+}
+
+func (b *circuitBreaker) recordResult(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == breakerHalfOpen {
+		if success {
+			b.state = breakerClosed
+			b.window = nil
+		} else {
+			b.state = breakerOpen
+			b.openedAt = time.Now()
+		}
+		return
+	}
+	b.window = append(b.window, success)
+	if len(b.window) > b.windowSize {
+		b.window = b.window[len(b.window)-b.windowSize:]
+	}
+	if len(b.window) < b.minSamples {
+		return
+	}
+	failures := 0
+	for _, ok := range b.window {
+		if !ok {
+			failures++
+		}
+	}
+	if float64(failures)/float64(len(b.window)) >= b.failureRatio {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+func (b *circuitBreaker) stateString() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state.String()
+}
+
+// postBreakers holds one circuitBreaker per destination URL, keyed so that
+// every PostWithRetries call against a given URL shares the same failure
+// signal.
+var (
+	postBreakersMu sync.Mutex
+	postBreakers   = map[string]*circuitBreaker{}
+)
+
+func postBreakerFor(url string) *circuitBreaker {
+	postBreakersMu.Lock()
+	defer postBreakersMu.Unlock()
+	b, ok := postBreakers[url]
+	if !ok {
+		b = newCircuitBreaker(0.5, 5, 30*time.Second)
+		postBreakers[url] = b
+	}
+	return b
+}
+
+// PostStats is one destination URL's circuit breaker state.
+type PostStats struct {
+	URL   string `json:"url"`
+	State string `json:"state"`
+}
+
+// Stats returns the current breaker state for every URL PostWithRetries has
+// been called against in this process, for admin/debug inspection.
+func Stats() []PostStats {
+	postBreakersMu.Lock()
+	defer postBreakersMu.Unlock()
+	out := make([]PostStats, 0, len(postBreakers))
+	for url, b := range postBreakers {
+		out = append(out, PostStats{URL: url, State: b.stateString()})
+	}
+	return out
+}
+
+// isPermanentStatus reports whether status indicates a request that retrying
+// unmodified won't fix: any 4xx except 408 (request timeout), 425 (too
+// early) and 429 (rate limited), all three of which are worth another try.
+func isPermanentStatus(status int) bool {
+	if status < 400 || status >= 500 {
+		return false
+	}
+	switch status {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests:
+		return false
+	}
+	return true
+}
+
+const (
+	postBaseBackoff = 200 * time.Millisecond
+	postMaxBackoff  = 10 * time.Second
+)
+
+// fullJitterBackoff implements the "full jitter" strategy from
+// https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/:
+// sleep = rand(0, min(max, base*2^attempt)).
+func fullJitterBackoff(base time.Duration, attempt int, max time.Duration) time.Duration {
+	d := base * time.Duration(uint64(1)<<uint(attempt))
+	if d <= 0 || d > max {
+		d = max
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// PostWithRetries posts JSON to url with error classification, full-jitter
+// backoff, and a per-URL circuit breaker (see postBreakerFor) shared across
+// every call site in this package. A permanent client error (4xx other than
+// 408/425/429) is returned immediately without retrying or affecting the
+// breaker - retrying the same request won't help, and it isn't evidence the
+// destination host is unhealthy. A transient failure (network error,
+// timeout, 5xx, 408/425/429) is retried with backoff, up to attempts tries in
+// all, and counts against the breaker; an already-open breaker fails the call before
+// a request is ever attempted. Caller must close resp.Body on success.
+//
+// ctx bounds every attempt in addition to timeoutMs, so a caller canceling
+// ctx (e.g. during shutdown) aborts an in-flight attempt immediately instead
+// of waiting out the full per-attempt timeout. Pass context.Background() if
+// the call has no narrower context to bound it with.
+func PostWithRetries(ctx context.Context, client *http.Client, url string, body []byte, authToken string, timeoutMs int, attempts int, correlationID string) (*http.Response, error) {
 	if attempts <= 0 {
 		attempts = 1
 	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	breaker := postBreakerFor(url)
+	if !breaker.allow() {
+		return nil, fmt.Errorf("postWithRetries: circuit open for %s", url)
+	}
+
+	var lastErr error
 	for i := 0; i < attempts; i++ {
-		ctxReq, cancelReq := context.WithTimeout(context.Background(), time.Duration(timeoutMs)*time.Millisecond)
+		ctxReq, cancelReq := context.WithTimeout(ctx, time.Duration(timeoutMs)*time.Millisecond)
 		req, rerr := http.NewRequestWithContext(ctxReq, "POST", url, bytes.NewReader(body))
 		if rerr != nil {
-			logging.Debugw("postWithRetries: new request error", "err", rerr, "correlation_id", correlationID)
 			cancelReq()
 			return nil, rerr
 		}
@@ -29,24 +222,42 @@ func PostWithRetries(client *http.Client, url string, body []byte, authToken str
 			req.Header.Set("Authorization", "Bearer "+authToken)
 		}
 
-		var resp *http.Response
-		var err error
-		if client != nil {
-			resp, err = client.Do(req)
-		} else {
-			tmp := &http.Client{Timeout: time.Duration(timeoutMs) * time.Millisecond}
-			resp, err = tmp.Do(req)
+		c := client
+		if c == nil {
+			c = &http.Client{Timeout: time.Duration(timeoutMs) * time.Millisecond}
 		}
+		resp, err := c.Do(req)
 		cancelReq()
+
 		if err != nil {
 			logging.Debugw("postWithRetries: POST attempt failed", "attempt", i+1, "err", err, "correlation_id", correlationID)
-			if i < attempts-1 {
-				time.Sleep(time.Duration(200*(1<<i)) * time.Millisecond)
-				continue
+			lastErr = err
+			if i == attempts-1 {
+				breaker.recordResult(false)
+				return nil, err
 			}
-			return nil, err
+			time.Sleep(fullJitterBackoff(postBaseBackoff, i, postMaxBackoff))
+			continue
+		}
+
+		if isPermanentStatus(resp.StatusCode) {
+			return resp, nil
 		}
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout ||
+			resp.StatusCode == http.StatusTooEarly || resp.StatusCode == http.StatusTooManyRequests {
+			if i == attempts-1 {
+				breaker.recordResult(false)
+				return resp, nil
+			}
+			logging.Debugw("postWithRetries: POST attempt returned retryable status", "attempt", i+1, "status", resp.StatusCode, "correlation_id", correlationID)
+			resp.Body.Close()
+			lastErr = fmt.Errorf("postWithRetries: status %d", resp.StatusCode)
+			time.Sleep(fullJitterBackoff(postBaseBackoff, i, postMaxBackoff))
+			continue
+		}
+
+		breaker.recordResult(true)
 		return resp, nil
 	}
-	return nil, fmt.Errorf("no response from postWithRetries")
+	return nil, lastErr
 }
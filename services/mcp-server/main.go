@@ -2,10 +2,15 @@ package main
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -15,25 +20,36 @@ func main() {
 	// Create a simple MCP server with no special tools.
 	server := mcp.NewServer(&mcp.Implementation{Name: "mcp-server", Version: "v0.0.0"}, nil)
 
+	registry := newServiceRegistry(registryFileFromEnv(), serviceTTLFromEnv())
+	registerRegistryTools(server, registry)
+	go registry.sweepStaleLoop(sweepIntervalFromEnv())
+
 	// Simple HTTP health endpoint
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("ok"))
 	})
 
-	// Simple HTTP registration endpoint used by services that attempt an
-	// HTTP-based register before falling back to WebSocket. This accepts a
-	// small JSON object {name, url, description} and responds 200 on success.
+	// HTTP registration endpoint: services POST {name, url, description,
+	// capabilities} here, bearer-token gated by MCP_REGISTRATION_TOKEN, and
+	// must re-POST at least every MCP_SERVICE_TTL_SEC seconds or
+	// sweepStaleLoop marks them stale. Persisted to registry's bbolt db
+	// and pushed to connected WS sessions via services/updated when the
+	// registered set changes.
 	http.HandleFunc("/mcp/register", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			w.WriteHeader(http.StatusMethodNotAllowed)
 			return
 		}
-		// Accept body but we don't need to persist registrations in this
-		// simple server; just validate some JSON and return 200.
+		if !checkRegistrationToken(r) {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("unauthorized"))
+			return
+		}
 		type regReq struct {
-			Name        string `json:"name"`
-			URL         string `json:"url"`
-			Description string `json:"description"`
+			Name         string   `json:"name"`
+			URL          string   `json:"url"`
+			Description  string   `json:"description"`
+			Capabilities []string `json:"capabilities"`
 		}
 		var req regReq
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -41,17 +57,22 @@ func main() {
 			w.Write([]byte("invalid json"))
 			return
 		}
-		// Very small validation
 		if req.Name == "" || req.URL == "" {
 			w.WriteHeader(http.StatusBadRequest)
 			w.Write([]byte("name and url required"))
 			return
 		}
+		registry.Upsert(ServiceRegistration{
+			Name:         req.Name,
+			URL:          req.URL,
+			Description:  req.Description,
+			Capabilities: req.Capabilities,
+		}, time.Now())
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("ok"))
 	})
 
-	upgrader := websocket.Upgrader{}
+	upgrader := websocket.Upgrader{CheckOrigin: wsCheckOrigin}
 	// WebSocket endpoint to accept MCP connections. Each WS is bridged to an
 	// SDK Transport by wrapping the WS as an io.ReadWriteCloser.
 	http.HandleFunc("/mcp/ws", func(w http.ResponseWriter, r *http.Request) {
@@ -60,21 +81,30 @@ func main() {
 			log.Printf("ws upgrade failed: %v", err)
 			return
 		}
-		// Wrap ws and create a transport that returns a Connection using the
-		// SDK's InMemoryTransport pattern via a custom wrapper.
-		t := NewWebSocketTransport(conn)
-		// Use SDK connect helper to bind the transport to the server handler.
+		// Build the mcp.Connection once and hand the exact same instance to
+		// both the registry (for services/updated pushes) and server.Connect
+		// (for normal protocol traffic), via singleConnTransport - so there's
+		// only one wsConnection per socket, not two independent wrappers
+		// racing to write the same underlying websocket.Conn.
+		mcpConn, err := NewWebSocketTransport(conn).Connect(r.Context())
+		if err != nil {
+			log.Printf("mcp connection setup failed: %v", err)
+			_ = conn.Close()
+			return
+		}
+		registry.AddSession(mcpConn)
 		go func() {
+			defer registry.RemoveSession(mcpConn)
 			// Connect the server over the transport. Server.Connect starts handling
 			// messages and returns a connection object that can be used to Close or
 			// Wait for client termination.
-			conn, err := server.Connect(context.Background(), t, nil)
+			sess, err := server.Connect(context.Background(), singleConnTransport{conn: mcpConn}, nil)
 			if err != nil {
 				log.Printf("mcp server connect error: %v", err)
 				return
 			}
 			// Wait for the client to disconnect (or for the connection to be closed).
-			if err := conn.Wait(); err != nil {
+			if err := sess.Wait(); err != nil {
 				log.Printf("mcp server session ended with error: %v", err)
 			} else {
 				log.Printf("mcp server session ended")
@@ -89,3 +119,74 @@ func main() {
 	log.Printf("mcp server listening on :%s", port)
 	log.Fatal(http.ListenAndServe(":"+port, nil))
 }
+
+// checkRegistrationToken requires the caller's Authorization: Bearer header
+// to match MCP_REGISTRATION_TOKEN exactly. An unset token rejects every
+// registration rather than accepting all of them - "no token configured"
+// must fail closed, not open.
+func checkRegistrationToken(r *http.Request) bool {
+	want := os.Getenv("MCP_REGISTRATION_TOKEN")
+	if want == "" {
+		return false
+	}
+	got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// wsCheckOrigin gates the /mcp/ws upgrade. MCP_ALLOWED_ORIGINS, if set, is a
+// comma-separated allowlist ("*" allows any Origin); a request with an
+// Origin header not on the list is rejected. With MCP_ALLOWED_ORIGINS unset,
+// falls back to gorilla's own default same-origin check (no Origin header,
+// or Origin's host matches the request's Host) rather than the previous
+// zero-value upgrader's "allow everything" behavior.
+func wsCheckOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	allowed := strings.TrimSpace(os.Getenv("MCP_ALLOWED_ORIGINS"))
+	if allowed == "" {
+		if origin == "" {
+			return true
+		}
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+	if origin == "" {
+		return false
+	}
+	for _, o := range strings.Split(allowed, ",") {
+		o = strings.TrimSpace(o)
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func registryFileFromEnv() string {
+	if v := os.Getenv("MCP_REGISTRY_FILE"); v != "" {
+		return v
+	}
+	return "mcp_registry.db"
+}
+
+// serviceTTLFromEnv is how long a registered service can go without a
+// re-POST to /mcp/register before sweepStaleLoop marks it stale, via
+// MCP_SERVICE_TTL_SEC (default 60).
+func serviceTTLFromEnv() time.Duration {
+	if v := os.Getenv("MCP_SERVICE_TTL_SEC"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return time.Duration(n) * time.Second
+		}
+	}
+	return 60 * time.Second
+}
+
+// sweepIntervalFromEnv is how often sweepStaleLoop checks for expired
+// heartbeats, via MCP_REGISTRY_SWEEP_SEC (default 15).
+func sweepIntervalFromEnv() time.Duration {
+	if v := os.Getenv("MCP_REGISTRY_SWEEP_SEC"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return time.Duration(n) * time.Second
+		}
+	}
+	return 15 * time.Second
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// serviceRegistryBucket is the single bbolt bucket ServiceRegistry persists
+// its services map into, keyed by service name.
+var serviceRegistryBucket = []byte("services")
+
+// ServiceRegistration is one entry in the registry: a peer MCP service that
+// POSTed to /mcp/register, kept around so clients connected to this
+// server's /mcp/ws can discover it via the list_services tool instead of
+// each needing its own static config of every other service's URL.
+type ServiceRegistration struct {
+	Name         string    `json:"name"`
+	URL          string    `json:"url"`
+	Description  string    `json:"description,omitempty"`
+	Capabilities []string  `json:"capabilities,omitempty"`
+	LastSeen     time.Time `json:"last_seen"`
+	Stale        bool      `json:"stale"`
+}
+
+// ServiceRegistry persists registrations to a bbolt db (one bucket, keyed by
+// service name) so they survive a restart. It also tracks every connected
+// /mcp/ws session so a registration change can be pushed out as a
+// services/updated notification instead of requiring clients to poll
+// list_services.
+type ServiceRegistry struct {
+	mu       sync.Mutex
+	db       *bbolt.DB
+	ttl      time.Duration
+	services map[string]ServiceRegistration
+	sessions map[mcp.Connection]struct{}
+}
+
+// newServiceRegistry opens path's bbolt db, loading any existing services
+// from it, and returns a registry that considers a service stale once ttl
+// has passed since its last /mcp/register heartbeat. path == "" disables
+// persistence (registry state then only lives as long as the process).
+func newServiceRegistry(path string, ttl time.Duration) *ServiceRegistry {
+	r := &ServiceRegistry{
+		ttl:      ttl,
+		services: make(map[string]ServiceRegistration),
+		sessions: make(map[mcp.Connection]struct{}),
+	}
+	if path == "" {
+		return r
+	}
+	db, err := bbolt.Open(path, 0o644, &bbolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		log.Printf("mcp registry: failed to open db %q, running unpersisted: %v", path, err)
+		return r
+	}
+	if err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(serviceRegistryBucket)
+		return err
+	}); err != nil {
+		log.Printf("mcp registry: failed to create bucket, running unpersisted: %v", err)
+		_ = db.Close()
+		return r
+	}
+	r.db = db
+	_ = db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(serviceRegistryBucket).ForEach(func(k, v []byte) error {
+			var reg ServiceRegistration
+			if err := json.Unmarshal(v, &reg); err != nil {
+				return nil // skip a corrupt row rather than failing the whole load
+			}
+			r.services[string(k)] = reg
+			return nil
+		})
+	})
+	return r
+}
+
+// Close releases the underlying bbolt db, if persistence is enabled. Safe to
+// call on a nil registry.
+func (r *ServiceRegistry) Close() error {
+	if r == nil || r.db == nil {
+		return nil
+	}
+	return r.db.Close()
+}
+
+// Upsert records or refreshes reg (keyed by reg.Name), stamps LastSeen to
+// now and clears Stale, persists its row, and broadcasts
+// services/updated to every connected WS session if anything actually
+// changed - a heartbeat re-POST with identical fields from an
+// already-fresh service is a no-op notification-wise.
+func (r *ServiceRegistry) Upsert(reg ServiceRegistration, now time.Time) {
+	reg.LastSeen = now
+	reg.Stale = false
+	r.mu.Lock()
+	existing, existed := r.services[reg.Name]
+	changed := !existed || existing.URL != reg.URL || existing.Description != reg.Description ||
+		!stringSlicesEqual(existing.Capabilities, reg.Capabilities) || existing.Stale
+	r.services[reg.Name] = reg
+	r.mu.Unlock()
+	r.persistOne(reg.Name, reg)
+	if changed {
+		r.broadcastUpdated()
+	}
+}
+
+// List returns every registered service, sorted by name, for list_services
+// and any future /debug-style inspection.
+func (r *ServiceRegistry) List() []ServiceRegistration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]ServiceRegistration, 0, len(r.services))
+	for _, s := range r.services {
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// sweepStaleLoop runs for the life of the process, marking any service
+// whose LastSeen is older than r.ttl as stale once per interval - the other
+// half of the heartbeat contract: a service that stops re-POSTing doesn't
+// just linger looking healthy forever.
+func (r *ServiceRegistry) sweepStaleLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		r.sweepOnce(time.Now())
+	}
+}
+
+func (r *ServiceRegistry) sweepOnce(now time.Time) {
+	r.mu.Lock()
+	touched := make(map[string]ServiceRegistration)
+	for name, svc := range r.services {
+		if !svc.Stale && now.Sub(svc.LastSeen) > r.ttl {
+			svc.Stale = true
+			r.services[name] = svc
+			touched[name] = svc
+		}
+	}
+	r.mu.Unlock()
+	if len(touched) > 0 {
+		r.persistMany(touched)
+		r.broadcastUpdated()
+	}
+}
+
+// AddSession registers conn to receive services/updated notifications for
+// the lifetime of its WS connection. RemoveSession must be called once that
+// connection ends (see main.go's /mcp/ws handler).
+func (r *ServiceRegistry) AddSession(conn mcp.Connection) {
+	r.mu.Lock()
+	r.sessions[conn] = struct{}{}
+	r.mu.Unlock()
+}
+
+// RemoveSession un-registers conn, e.g. once its session's Wait() returns.
+func (r *ServiceRegistry) RemoveSession(conn mcp.Connection) {
+	r.mu.Lock()
+	delete(r.sessions, conn)
+	r.mu.Unlock()
+}
+
+// broadcastUpdated pushes a services/updated JSON-RPC notification (no id,
+// per JSON-RPC 2.0) to every currently connected session via the same
+// sdk Connection.Write each session's protocol traffic already goes
+// through. A session whose Write fails (socket already gone) is left for
+// its own RemoveSession call to clean up, rather than deleted here, so this
+// doesn't mutate r.sessions while it's being ranged over.
+func (r *ServiceRegistry) broadcastUpdated() {
+	r.mu.Lock()
+	conns := make([]mcp.Connection, 0, len(r.sessions))
+	for c := range r.sessions {
+		conns = append(conns, c)
+	}
+	r.mu.Unlock()
+	if len(conns) == 0 {
+		return
+	}
+	msg := &jsonrpc.Request{Method: "services/updated"}
+	for _, c := range conns {
+		// Each connection gets its own 5s deadline - a slow/stuck write to
+		// one session must not eat into the budget the rest still need.
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err := c.Write(ctx, msg)
+		cancel()
+		if err != nil {
+			log.Printf("mcp registry: services/updated push failed: %v", err)
+		}
+	}
+}
+
+// persistOne write-throughs a single service's registration to the bbolt
+// bucket. A no-op when persistence is disabled (r.db == nil).
+func (r *ServiceRegistry) persistOne(name string, reg ServiceRegistration) {
+	if r.db == nil {
+		return
+	}
+	b, err := json.Marshal(reg)
+	if err != nil {
+		log.Printf("mcp registry: marshal failed: %v", err)
+		return
+	}
+	if err := r.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(serviceRegistryBucket).Put([]byte(name), b)
+	}); err != nil {
+		log.Printf("mcp registry: persist failed: %v", err)
+	}
+}
+
+// persistMany write-throughs a batch of registrations in a single bbolt
+// transaction, used by sweepOnce so marking many services stale at once
+// costs one fsync instead of one per service.
+func (r *ServiceRegistry) persistMany(regs map[string]ServiceRegistration) {
+	if r.db == nil {
+		return
+	}
+	if err := r.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(serviceRegistryBucket)
+		for name, reg := range regs {
+			b, err := json.Marshal(reg)
+			if err != nil {
+				continue
+			}
+			if err := bucket.Put([]byte(name), b); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		log.Printf("mcp registry: batch persist failed: %v", err)
+	}
+}
+
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
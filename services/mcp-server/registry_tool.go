@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// registerRegistryTools exposes registry's registered-service set as an MCP
+// tool, so a client already connected over /mcp/ws can discover peers
+// through the same JSON-RPC channel instead of a separate REST call.
+func registerRegistryTools(server *mcp.Server, registry *ServiceRegistry) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_services",
+		Description: "List services currently registered via POST /mcp/register, including staleness",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+		services := registry.List()
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: formatServices(services)}}}, services, nil
+	})
+}
+
+func formatServices(services []ServiceRegistration) string {
+	if len(services) == 0 {
+		return "no registered services"
+	}
+	out := ""
+	for _, s := range services {
+		status := "ok"
+		if s.Stale {
+			status = "stale"
+		}
+		out += s.Name + " (" + s.URL + ") [" + status + "] last_seen=" + s.LastSeen.Format(time.RFC3339)
+		if len(s.Capabilities) > 0 {
+			out += " capabilities=" + strings.Join(s.Capabilities, ",")
+		}
+		out += "\n"
+	}
+	return out
+}